@@ -0,0 +1,39 @@
+// Package atomicfile replaces files without leaving them truncated when a write is interrupted
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile replaces path with data by writing a temporary file in the same directory
+// and renaming it over path, so concurrent writers and crashes never leave a partial file
+// A symlinked path is followed, so the link itself is kept
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	writeErr := tmp.Chmod(perm)
+	if writeErr == nil {
+		_, writeErr = tmp.Write(data)
+	}
+	if writeErr == nil {
+		writeErr = tmp.Sync()
+	}
+	if err := errors.Join(writeErr, tmp.Close()); err != nil {
+		return errors.Join(err, os.Remove(tmpPath))
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.Join(err, os.Remove(tmpPath))
+	}
+	return nil
+}
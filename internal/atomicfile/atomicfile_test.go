@@ -0,0 +1,70 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.yaml")
+
+	if err := WriteFile(path, []byte("first"), 0600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := WriteFile(path, []byte("second"), 0600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("expected %q, got %q", "second", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to remain, got %d files", len(entries))
+	}
+}
+
+func TestWriteFile_FailureKeepsOldFile(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for root")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.yaml")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatalf("failed to chmod dir: %v", err)
+	}
+	defer func() { _ = os.Chmod(dir, 0700) }()
+
+	if err := WriteFile(path, []byte("new"), 0600); err == nil {
+		t.Fatal("expected WriteFile() to fail in a read-only directory")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "old" {
+		t.Errorf("expected old content to be untouched, got %q", data)
+	}
+}
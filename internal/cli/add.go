@@ -5,44 +5,87 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
 )
 
-func runAdd(args []string) int {
+func runAdd(s session, args []string) int {
 	fs := flag.NewFlagSet("add", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
 	tags := fs.String("tags", "", "Tags for the entry (comma-separated)")
 	fs.StringVar(tags, "t", "", "Tags for the entry (shorthand)")
+	category := fs.String("category", "", "Category for the entry (stored as a tag)")
+	profileName := fs.String("profile", "", "Profile from config whose defaults to apply")
+	fs.StringVar(profileName, "p", "", "Profile from config (shorthand)")
+	dateValue := fs.String("date", "", "Entry date for backdating (YYYY-MM-DD or RFC3339; default: now)")
+	title := fs.String("title", "", "Title for the entry (default: first line of the text)")
+	templateName := fs.String("template", "", "Saved template to start the entry with (see 'journal template')")
+	mood := fs.Int("mood", 0, "Mood rating for the entry, from 1 (worst) to 5 (best)")
+	fs.IntVar(mood, "rating", 0, "Mood rating for the entry (alias for --mood)")
+	links := fs.String("link", "", "IDs or ID prefixes of existing entries this entry refers to (comma-separated)")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal add [text] [flags]")
 		fmt.Println("\nAdd a new journal entry")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
+		fmt.Println("\nA profile supplies default tags, category, template, and date format;")
+		fmt.Println("explicit --tags, --category, and --template replace the profile's values.")
 		fmt.Println("\nExamples:")
 		fmt.Println("  journal add \"Today was great!\" -j personal")
 		fmt.Println("  journal add \"Team meeting\" -j work -t meeting,notes")
+		fmt.Println("  journal add \"Shipped the release\" --profile worklog")
+		fmt.Println("  journal add \"Hiked the ridge\" --date 2024-06-01")
+		fmt.Println("  journal add \"Notes from the offsite\" --title \"Offsite day 1\"")
+		fmt.Println("  journal add \"Sunny walk\" --template gratitude")
+		fmt.Println("  journal add \"Great run this morning\" --mood 5")
+		fmt.Println("  journal add \"Fixed the leak for good\" --link 1a2b3c4d")
 	}
 	if err := fs.Parse(args); err != nil {
 		return 1
 	}
 
-	if fs.NArg() == 0 {
-		if _, err := fmt.Fprintf(os.Stderr, "Error: entry text is required\n\n"); err != nil {
+	var entryDate time.Time
+	if *dateValue != "" {
+		var err error
+		entryDate, err = parseEntryDate(*dateValue)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n\n", err); ferr != nil {
+				return 1
+			}
+			fs.Usage()
 			return 1
 		}
-		fs.Usage()
-		return 1
 	}
 
-	j, _, err := openJournal(*journalName)
-	if err != nil {
-		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+	if err := models.ValidateMood(*mood); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n\n", err); ferr != nil {
 			return 1
 		}
+		fs.Usage()
 		return 1
 	}
 
-	content := strings.Join(fs.Args(), " ")
+	var profile *config.Profile
+	if *profileName != "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		profile, err = cfg.GetProfile(*profileName)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get profile: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
 
 	var tagList []string
 	if *tags != "" {
@@ -52,7 +95,50 @@ func runAdd(args []string) int {
 		}
 	}
 
-	ent, err := j.Add(content, tagList)
+	opts := profile.Merge(config.Profile{
+		Tags:     tagList,
+		Category: *category,
+	})
+
+	if fs.NArg() == 0 && opts.Template == "" && *templateName == "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: entry text is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if *templateName != "" {
+		opts.Template, err = j.GetTemplate(*templateName)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get template: %v\n", err); ferr != nil {
+				return 1
+			}
+			return errorExitCode(err)
+		}
+	}
+
+	content := applyTemplate(opts.Template, strings.Join(fs.Args(), " "))
+
+	tagList = append([]string(nil), opts.Tags...)
+	if opts.Category != "" {
+		tagList = appendTagUnique(tagList, opts.Category)
+	}
+
+	var linkList []string
+	if *links != "" {
+		linkList = splitTagList(*links)
+	}
+
+	ent, err := j.AddWithOptions(content, tagList, entry.AddOptions{Date: entryDate, Title: *title, Mood: *mood, Links: linkList})
 	if err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to add entry: %v\n", err); ferr != nil {
 			return 1
@@ -60,16 +146,69 @@ func runAdd(args []string) int {
 		return 1
 	}
 
-	if _, err := fmt.Printf("Entry added: %s\n", ent.GetID()[:8]); err != nil {
+	dateFormat := opts.DateFormat
+	if dateFormat == "" {
+		dateFormat = "2006-01-02 15:04:05"
+	}
+
+	if err := s.infof("Entry added: %s\n", ent.GetID()[:8]); err != nil {
 		return 1
 	}
-	if _, err := fmt.Printf("Date: %s\n", ent.GetDate().Format("2006-01-02 15:04:05")); err != nil {
+	if err := s.infof("Date: %s\n", ent.GetDate().Format(dateFormat)); err != nil {
 		return 1
 	}
-	if len(tagList) > 0 {
-		if _, err := fmt.Printf("Tags: %s\n", strings.Join(tagList, ", ")); err != nil {
+	if ent.GetTitle() != "" {
+		if err := s.infof("Title: %s\n", ent.GetTitle()); err != nil {
+			return 1
+		}
+	}
+	if ent.GetMood() != 0 {
+		if err := s.infof("Mood: %d/%d\n", ent.GetMood(), models.MaxMood); err != nil {
+			return 1
+		}
+	}
+	// The journal's default tags are merged in when the entry is added, so show the tags it was saved with
+	if len(ent.GetTags()) > 0 {
+		if err := s.infof("Tags: %s\n", strings.Join(ent.GetTags(), ", ")); err != nil {
+			return 1
+		}
+	}
+	if len(ent.GetLinks()) > 0 {
+		if err := s.infof("Links: %s\n", shortIDs(ent.GetLinks())); err != nil {
 			return 1
 		}
 	}
 	return 0
 }
+
+// parseEntryDate parses a --date value as a local YYYY-MM-DD date or an RFC3339 timestamp
+func parseEntryDate(value string) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC3339 (e.g. 2024-06-01T18:30:00+02:00)", value)
+}
+
+// applyTemplate places text after the template body, or returns text unchanged without a template
+func applyTemplate(template string, text string) string {
+	if template == "" {
+		return text
+	}
+	if text == "" {
+		return template
+	}
+	return template + "\n" + text
+}
+
+// appendTagUnique appends tag to tags unless it is already present
+func appendTagUnique(tags []string, tag string) []string {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
 )
 
 func TestRunAdd_Success(t *testing.T) {
 	setupTestJournal(t, "", "")
 
 	args := []string{"-j", "test", "Test entry content"}
-	exitCode := runAdd(args)
+	exitCode := runAdd(session{}, args)
 
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
@@ -19,20 +25,309 @@ func TestRunAdd_WithTags(t *testing.T) {
 	setupTestJournal(t, "", "")
 
 	args := []string{"-j", "test", "-t", "tag1,tag2", "Test entry with tags"}
-	exitCode := runAdd(args)
+	exitCode := runAdd(session{}, args)
 
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
 }
 
+func TestRunAdd_ShowsDefaultTags(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runTag([]string{"defaults", "-j", "test", "daily"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runAdd(session{}, []string{"-j", "test", "-t", "work", "Entry with default tags"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Tags: daily, work\n") {
+		t.Errorf("expected the stored tags, including the default ones, got %q", output)
+	}
+}
+
+func TestRun_AddQuiet(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"long flag", []string{"journal", "--quiet", "add", "-j", "test", "-t", "work", "Quiet entry"}},
+		{"short flag after command", []string{"journal", "add", "-j", "test", "-q", "Quiet entry"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureStdout(t, func() {
+				if exitCode := Run(tt.args); exitCode != 0 {
+					t.Errorf("expected exit code 0, got %d", exitCode)
+				}
+			})
+			if output != "" {
+				t.Errorf("expected no output in quiet mode, got %q", output)
+			}
+		})
+	}
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if count := j.Count(); count != 2 {
+		t.Errorf("expected 2 entries to be added quietly, got %d", count)
+	}
+}
+
 func TestRunAdd_MissingContent(t *testing.T) {
 	setupTestJournal(t, "", "")
 
 	args := []string{"-j", "test"}
-	exitCode := runAdd(args)
+	exitCode := runAdd(session{}, args)
 
 	if exitCode == 0 {
 		t.Error("expected non-zero exit code for missing content")
 	}
 }
+
+// setupTestProfile stores a profile in the test config
+func setupTestProfile(t *testing.T, name string, profile *config.Profile) {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	cfg.Profiles = map[string]*config.Profile{name: profile}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+}
+
+// onlyEntry returns the single entry in the journal, failing if there isn't exactly one
+func onlyEntry(t *testing.T, journalCfg *config.Journal) models.Entry {
+	t.Helper()
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	entries, err := j.ListRecent(10)
+	if err != nil {
+		t.Fatalf("failed to list entries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	return entries[0]
+}
+
+func TestRunAdd_WithProfile(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+	setupTestProfile(t, "daily", &config.Profile{
+		Tags:     []string{"daily"},
+		Category: "personal",
+		Template: "Gratitude:",
+	})
+
+	args := []string{"-j", "test", "--profile", "daily", "the sunshine"}
+	exitCode := runAdd(session{}, args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	ent := onlyEntry(t, journalCfg)
+	if strings.Join(ent.GetTags(), ",") != "daily,personal" {
+		t.Errorf("expected profile tags and category, got %v", ent.GetTags())
+	}
+	if ent.GetContent() != "Gratitude:\nthe sunshine" {
+		t.Errorf("expected templated content, got %q", ent.GetContent())
+	}
+}
+
+func TestRunAdd_FlagsOverrideProfile(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+	setupTestProfile(t, "daily", &config.Profile{
+		Tags:     []string{"daily"},
+		Category: "personal",
+	})
+
+	args := []string{"-j", "test", "--profile", "daily", "-t", "work", "--category", "worklog", "Standup"}
+	exitCode := runAdd(session{}, args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	ent := onlyEntry(t, journalCfg)
+	if strings.Join(ent.GetTags(), ",") != "work,worklog" {
+		t.Errorf("expected explicit tags and category, got %v", ent.GetTags())
+	}
+}
+
+func TestRunAdd_UnknownProfile(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--profile", "missing", "Text"}
+	exitCode := runAdd(session{}, args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for unknown profile")
+	}
+}
+
+func TestRunAdd_WithDate(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--date", "2023-03-14", "Backdated entry"}
+	exitCode := runAdd(session{}, args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	ent := onlyEntry(t, journalCfg)
+	if got := ent.GetDate().Format("2006-01-02"); got != "2023-03-14" {
+		t.Errorf("expected date 2023-03-14, got %s", got)
+	}
+
+	if !strings.HasPrefix(ent.GetFilePath(), filepath.Join("2023", "03")) {
+		t.Errorf("expected entry under 2023/03, got %s", ent.GetFilePath())
+	}
+}
+
+func TestRunAdd_WithTitle(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--title", "Standup", "Discussed the release"}
+	if exitCode := runAdd(session{}, args); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	if ent := onlyEntry(t, journalCfg); ent.GetTitle() != "Standup" {
+		t.Errorf("expected title 'Standup', got %q", ent.GetTitle())
+	}
+
+	output := captureStdout(t, func() {
+		if exitCode := runList([]string{"-j", "test"}); exitCode != 0 {
+			t.Errorf("expected list exit code 0, got %d", exitCode)
+		}
+	})
+	if !strings.Contains(output, "Standup") {
+		t.Errorf("expected title in list output, got %q", output)
+	}
+}
+
+func TestRunAdd_WithMood(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	if exitCode := runAdd(session{}, []string{"-j", "test", "--rating", "4", "Good day"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if ent := onlyEntry(t, journalCfg); ent.GetMood() != 4 {
+		t.Errorf("expected mood 4, got %d", ent.GetMood())
+	}
+
+	if exitCode := runAdd(session{}, []string{"-j", "test", "--mood", "6", "Too good"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for a mood outside 1-5")
+	}
+}
+
+func TestRunAdd_WithLink(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	original, err := j.Add("Started the migration", nil)
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	captureStdout(t, func() {
+		exitCode = runAdd(session{}, []string{"-j", "test", "--link", original.GetID()[:8], "Finished the migration"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	backlinks, err := j.Backlinks(original.GetID())
+	if err != nil {
+		t.Fatalf("Backlinks failed: %v", err)
+	}
+	if len(backlinks) != 1 {
+		t.Fatalf("expected one entry linking to the original, got %v", backlinks)
+	}
+
+	output := captureStdout(t, func() {
+		exitCode = runShow([]string{"-j", "test", original.GetID()})
+	})
+	if exitCode != 0 || !strings.Contains(output, "Linked from: "+backlinks[0][:8]) {
+		t.Errorf("expected show to list the backlink, got %q (exit code %d)", output, exitCode)
+	}
+	output = captureStdout(t, func() {
+		exitCode = runShow([]string{"-j", "test", backlinks[0]})
+	})
+	if exitCode != 0 || !strings.Contains(output, "Links: "+original.GetID()[:8]) {
+		t.Errorf("expected show to list the link, got %q (exit code %d)", output, exitCode)
+	}
+
+	captureStdout(t, func() {
+		exitCode = runAdd(session{}, []string{"-j", "test", "--link", "does-not-exist", "Dangling"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for a link to an unknown entry")
+	}
+}
+
+func TestRunAdd_InvalidDate(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--date", "14/03/2023", "Bad date"}
+	exitCode := runAdd(session{}, args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for invalid date")
+	}
+}
+
+func TestParseEntryDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"2024-06-01", "2024-06-01", false},
+		{"2024-06-01T18:30:00+02:00", "2024-06-01", false},
+		{"2024-13-01", "", true},
+		{"yesterday", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			date, err := parseEntryDate(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := date.Format("2006-01-02"); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+// browseListing is one entry in browse <month> --json output
+type browseListing struct {
+	ID    string    `json:"id"`
+	Date  time.Time `json:"date"`
+	Title string    `json:"title,omitempty"`
+	Tags  []string  `json:"tags,omitempty"`
+}
+
+func runBrowse(args []string) int {
+	fs := flag.NewFlagSet("browse", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	jsonOutput := fs.Bool("json", false, "Print months or entries as JSON")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal browse [YYYY-MM] [flags]")
+		fmt.Println("\nWithout a month, list every month from the first entry to the last with its")
+		fmt.Println("number of entries, oldest first; months without entries are shown with 0")
+		fmt.Println("With a month, list the entries written in it, oldest first")
+		fmt.Println("Only the index is read, so no entries are decrypted")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	// Flags may also follow the month
+	var month string
+	if fs.NArg() > 0 {
+		month = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	if fs.NArg() > 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: expected at most one month\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	if month != "" {
+		if _, err := time.Parse(entry.MonthFormat, month); err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Error: invalid month %q (use YYYY-MM)\n\n", month); ferr != nil {
+				return 1
+			}
+			fs.Usage()
+			return 1
+		}
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if month != "" {
+		return printBrowseMonth(j, month, *jsonOutput)
+	}
+
+	months := fillMonthGaps(j.Months())
+
+	if *jsonOutput {
+		return printBrowseJSON(months)
+	}
+
+	if len(months) == 0 {
+		if _, err := fmt.Println("No entries found"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	for _, m := range months {
+		if _, err := fmt.Printf("%s  %d\n", colorDate(m.Month), m.Entries); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// printBrowseMonth lists the entries in month
+func printBrowseMonth(j *entry.Journal, month string, jsonOutput bool) int {
+	metas := j.MonthEntries(month)
+
+	if jsonOutput {
+		listings := make([]browseListing, 0, len(metas))
+		for _, meta := range metas {
+			listings = append(listings, browseListing{
+				ID:    meta.Id,
+				Date:  meta.Date,
+				Title: meta.Title,
+				Tags:  meta.Tags,
+			})
+		}
+		return printBrowseJSON(listings)
+	}
+
+	if len(metas) == 0 {
+		if _, err := fmt.Printf("No entries in %s\n", month); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	for _, meta := range metas {
+		if _, err := fmt.Printf("[%s] %s%s\n", colorDate(meta.Date.Format("2006-01-02 15:04")), colorID(meta.Id[:8]), titleSuffix(meta.Title)); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// printBrowseJSON prints v as indented JSON
+func printBrowseJSON(v any) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to encode output: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	if _, err := fmt.Println(string(data)); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// fillMonthGaps returns months with every month between the first and the last added with
+// zero entries, so gaps in the journal are visible
+func fillMonthGaps(months []entry.MonthCount) []entry.MonthCount {
+	if len(months) == 0 {
+		return []entry.MonthCount{}
+	}
+
+	counts := make(map[string]int, len(months))
+	for _, m := range months {
+		counts[m.Month] = m.Entries
+	}
+
+	// Month strings of existing entries always parse, as they are formatted with MonthFormat
+	first, _ := time.Parse(entry.MonthFormat, months[0].Month)
+	last, _ := time.Parse(entry.MonthFormat, months[len(months)-1].Month)
+
+	var filled []entry.MonthCount
+	for t := first; !t.After(last); t = t.AddDate(0, 1, 0) {
+		month := t.Format(entry.MonthFormat)
+		filled = append(filled, entry.MonthCount{Month: month, Entries: counts[month]})
+	}
+	return filled
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func addBrowseEntries(t *testing.T) {
+	t.Helper()
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	for _, date := range []time.Time{
+		time.Date(2024, 9, 30, 9, 0, 0, 0, time.Local),
+		time.Date(2024, 11, 2, 9, 0, 0, 0, time.Local),
+		time.Date(2024, 11, 1, 9, 0, 0, 0, time.Local),
+	} {
+		if _, err := j.AddWithDate("Entry on "+date.Format("Jan 2"), nil, date); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+}
+
+func TestRunBrowse_Months(t *testing.T) {
+	addBrowseEntries(t)
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runBrowse([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	if output != "2024-09  1\n2024-10  0\n2024-11  2\n" {
+		t.Errorf("expected months with the gap shown as 0, got %q", output)
+	}
+}
+
+func TestRunBrowse_Month(t *testing.T) {
+	addBrowseEntries(t)
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runBrowse([]string{"2024-11", "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	first, second := strings.Index(output, "Entry on Nov 1"), strings.Index(output, "Entry on Nov 2")
+	if first < 0 || second < 0 || first > second {
+		t.Errorf("expected November entries oldest first, got %q", output)
+	}
+	if strings.Contains(output, "Sep") {
+		t.Errorf("expected only November entries, got %q", output)
+	}
+}
+
+func TestRunBrowse_JSON(t *testing.T) {
+	addBrowseEntries(t)
+
+	output := captureStdout(t, func() {
+		if exitCode := runBrowse([]string{"-j", "test", "--json"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+
+	var months []entry.MonthCount
+	if err := json.Unmarshal([]byte(output), &months); err != nil {
+		t.Fatalf("failed to parse JSON output %q: %v", output, err)
+	}
+	want := []entry.MonthCount{{Month: "2024-09", Entries: 1}, {Month: "2024-10", Entries: 0}, {Month: "2024-11", Entries: 2}}
+	if !reflect.DeepEqual(months, want) {
+		t.Errorf("expected %v, got %v", want, months)
+	}
+
+	output = captureStdout(t, func() {
+		if exitCode := runBrowse([]string{"2024-09", "-j", "test", "--json"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+
+	var listings []browseListing
+	if err := json.Unmarshal([]byte(output), &listings); err != nil {
+		t.Fatalf("failed to parse JSON output %q: %v", output, err)
+	}
+	if len(listings) != 1 || listings[0].Title != "Entry on Sep 30" {
+		t.Errorf("expected the September entry, got %+v", listings)
+	}
+}
+
+func TestRunBrowse_InvalidMonth(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runBrowse([]string{"-j", "test", "November"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an invalid month")
+	}
+}
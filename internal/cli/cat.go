@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+// runCat prints a decrypted entry file as-is; it is left out of the usage as a debugging aid
+func runCat(args []string) int {
+	fs := flag.NewFlagSet("cat", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use (required)")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal cat <path> -j <journal>")
+		fmt.Println("\nPrint the decrypted contents of an entry file without parsing it, to debug unreadable entries")
+		fmt.Println("The path is relative to the journal's entries directory, as shown by 'journal verify'")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	// Allow flags after the path
+	relFilePath := fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	if relFilePath == "" || fs.NArg() > 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one entry path is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	if *journalName == "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: -j is required, so the file is not read from the wrong journal\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	journalCfg, err := resolveJournalConfig(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	data, err := entry.DecryptEntryFile(journalCfg, relFilePath)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to decrypt %s: %v\n", relFilePath, err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if _, err := os.Stdout.Write(data); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func TestRunCat(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Raw entry content", []string{"debug"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runCat([]string{ent.GetFilePath(), "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	for _, want := range []string{"id: " + ent.GetID(), "content: Raw entry content", "- debug"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in decrypted YAML, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "sops:") {
+		t.Errorf("expected SOPS metadata to be stripped, got %q", output)
+	}
+}
+
+func TestRunCatDecrypt_Compressed(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	journalCfg.Compress = true
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Compressed entry content", []string{"debug"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() int
+	}{
+		{"cat", func() int { return runCat([]string{ent.GetFilePath(), "-j", "test"}) }},
+		{"decrypt", func() int {
+			return runDecrypt([]string{filepath.Join(journalCfg.Path, storage.EntriesDir, ent.GetFilePath()), "-j", "test"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exitCode int
+			output := captureStdout(t, func() {
+				exitCode = tt.run()
+			})
+			if exitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d", exitCode)
+			}
+			if !strings.Contains(output, "content: Compressed entry content") {
+				t.Errorf("expected the decompressed entry YAML, got %q", output)
+			}
+			if strings.Contains(output, "compression:") {
+				t.Errorf("expected the compression wrapper to be removed, got %q", output)
+			}
+		})
+	}
+}
+
+func TestRunCat_Invalid(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing path", []string{"-j", "test"}},
+		{"missing journal", []string{"2024/11/entry.yaml"}},
+		{"outside entries", []string{"../index.yaml", "-j", "test"}},
+		{"missing file", []string{"2024/11/missing.yaml", "-j", "test"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runCat(tt.args); exitCode == 0 {
+				t.Error("expected non-zero exit code")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Modes accepted by the global --color flag
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+var colorModes = []string{colorAuto, colorAlways, colorNever}
+
+// ANSI escape sequences used for highlighting output
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "\x1b[2m"
+	ansiCyan   = "\x1b[36m"
+	ansiYellow = "\x1b[33m"
+	// ansiMatch is bold and inverse, used for search matches
+	ansiMatch = "\x1b[1;7m"
+)
+
+// colorMode is the --color setting of the current run
+var colorMode = colorAuto
+
+// colorEnabled reports whether output should be colored
+// In auto mode color is used only when stdout is a terminal and NO_COLOR is not set
+func colorEnabled() bool {
+	switch colorMode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether file is a character device such as a terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in the given ANSI code when color is enabled
+func colorize(code string, s string) string {
+	if s == "" || !colorEnabled() {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// colorDate renders a date for output
+func colorDate(s string) string {
+	return colorize(ansiDim, s)
+}
+
+// colorID renders an entry ID for output
+func colorID(s string) string {
+	return colorize(ansiCyan, s)
+}
+
+// colorTags renders a list of tags for output
+func colorTags(tags []string) string {
+	return colorize(ansiYellow, strings.Join(tags, ", "))
+}
+
+// highlightMatches renders every match of re in s for output; a nil re leaves s unchanged
+func highlightMatches(s string, re *regexp.Regexp) string {
+	if re == nil || !colorEnabled() {
+		return s
+	}
+	return re.ReplaceAllStringFunc(s, func(match string) string {
+		return colorize(ansiMatch, match)
+	})
+}
+
+// extractColorFlag removes the global --color and --no-color flags from args and returns the mode
+// Like --config, the flags may appear anywhere before a "--" terminator
+func extractColorFlag(args []string) (string, []string, error) {
+	mode := colorAuto
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		switch {
+		case arg == "--color" || arg == "-color":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("flag %s requires one of: %s", arg, strings.Join(colorModes, ", "))
+			}
+			i++
+			mode = args[i]
+		case strings.HasPrefix(arg, "--color="):
+			mode = strings.TrimPrefix(arg, "--color=")
+		case strings.HasPrefix(arg, "-color="):
+			mode = strings.TrimPrefix(arg, "-color=")
+		case arg == "--no-color" || arg == "-no-color":
+			mode = colorNever
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if !slices.Contains(colorModes, mode) {
+		return "", nil, fmt.Errorf("unknown color mode %q (valid: %s)", mode, strings.Join(colorModes, ", "))
+	}
+	return mode, rest, nil
+}
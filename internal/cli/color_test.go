@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestExtractColorFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantMode string
+		wantRest []string
+		wantErr  bool
+	}{
+		{"default", []string{"journal", "list"}, colorAuto, []string{"journal", "list"}, false},
+		{"equals", []string{"journal", "--color=always", "list"}, colorAlways, []string{"journal", "list"}, false},
+		{"separate value", []string{"journal", "list", "--color", "never"}, colorNever, []string{"journal", "list"}, false},
+		{"no-color", []string{"journal", "--no-color", "show", "abc"}, colorNever, []string{"journal", "show", "abc"}, false},
+		{"after terminator", []string{"journal", "add", "--", "--no-color"}, colorAuto, []string{"journal", "add", "--", "--no-color"}, false},
+		{"unknown mode", []string{"journal", "--color=sometimes", "list"}, "", nil, true},
+		{"missing value", []string{"journal", "list", "--color"}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, rest, err := extractColorFlag(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if mode != tt.wantMode {
+				t.Errorf("expected mode %q, got %q", tt.wantMode, mode)
+			}
+			if strings.Join(rest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("expected args %v, got %v", tt.wantRest, rest)
+			}
+		})
+	}
+}
+
+func TestRun_ColorSuppressedWhenPiped(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Colorful entry", []string{"work"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if exitCode := Run([]string{"journal", "list", "-j", "test"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("expected no ANSI codes when stdout is piped, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if exitCode := Run([]string{"journal", "--color=always", "list", "-j", "test"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+	if !strings.Contains(output, ansiYellow+"work"+ansiReset) {
+		t.Errorf("expected colored tags with --color=always, got %q", output)
+	}
+}
+
+func TestColorEnabled_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	if colorEnabled() {
+		t.Error("expected color to be disabled when NO_COLOR is set")
+	}
+
+	colorMode = colorAlways
+	t.Cleanup(func() { colorMode = colorAuto })
+	if !colorEnabled() {
+		t.Error("expected --color=always to override NO_COLOR")
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	match := regexp.MustCompile("(?i)dog")
+
+	if got := highlightMatches("The Dog and the dog", match); got != "The Dog and the dog" {
+		t.Errorf("expected no highlighting when piped, got %q", got)
+	}
+
+	colorMode = colorAlways
+	t.Cleanup(func() { colorMode = colorAuto })
+
+	want := "The " + ansiMatch + "Dog" + ansiReset + " and the " + ansiMatch + "dog" + ansiReset
+	if got := highlightMatches("The Dog and the dog", match); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := highlightMatches("No match", nil); got != "No match" {
+		t.Errorf("expected text unchanged without a pattern, got %q", got)
+	}
+}
@@ -0,0 +1,398 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+// errUnknownConfigKey is returned for keys that config get and set do not support
+var errUnknownConfigKey = errors.New("unknown config key")
+
+// globalConfigKeys are the settings outside any journal, in the order config list prints them
+var globalConfigKeys = []string{"default_journal", "default_recipients", "journals_dir"}
+
+// journalConfigKeys are the per-journal settings, addressed as journals.<name>.<key>
+var journalConfigKeys = []string{"path", "filename_template", "key_files", "default_tags", "use_index", "compress"}
+
+func runConfig(args []string) int {
+	if len(args) < 1 {
+		printConfigUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "get":
+		return runConfigGet(args[1:])
+	case "set":
+		return runConfigSet(args[1:])
+	case "list":
+		return runConfigList(args[1:])
+	case "set-recipients":
+		return runConfigSetRecipients(args[1:])
+	case "help", "-h", "--help":
+		printConfigUsage()
+		return 0
+	default:
+		if _, err := fmt.Fprintf(os.Stderr, "Unknown config command: %s\n\n", args[0]); err != nil {
+			return 1
+		}
+		printConfigUsage()
+		return 1
+	}
+}
+
+func printConfigUsage() {
+	fmt.Println(`Usage: journal config <command> [flags]
+
+Read and change settings in the config file, validating values before they are saved
+
+Available Commands:
+  get <key>            Print the value of a setting
+  set <key> <value>    Change a setting; an empty value clears it
+  list                 Print every setting
+  set-recipients       Set the recipients 'journal init' uses when --recipients is omitted
+
+Keys:
+  default_journal                    Journal used when -j is not given; must exist
+  default_recipients                 Public keys for 'journal init' (comma-separated)
+  journals_dir                       Where 'journal init' creates journals without --path; must be absolute
+  journals.<name>.path               Journal directory (read-only; use 'journal relocate')
+  journals.<name>.filename_template  Name of new entry files; must contain {id}
+  journals.<name>.key_files          Age key files or directories (comma-separated); must exist
+  journals.<name>.default_tags       Tags added to every new entry (comma-separated)
+  journals.<name>.use_index          Keep an encrypted index (default true); false decrypts every
+                                     entry on each run instead. Run 'journal rebuild' after enabling
+  journals.<name>.compress           Gzip new and changed entries before encrypting them (true or false);
+                                     'journal re-encrypt' applies it to existing entries`)
+}
+
+func runConfigGet(args []string) int {
+	if len(args) != 1 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: config key is required\n\n"); err != nil {
+			return 1
+		}
+		printConfigUsage()
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	value, err := getConfigValue(cfg, args[0])
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Println(value); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runConfigSet(args []string) int {
+	if len(args) != 2 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: config key and value are required\n\n"); err != nil {
+			return 1
+		}
+		printConfigUsage()
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := setConfigValue(cfg, args[0], args[1]); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := cfg.Save(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	value, err := getConfigValue(cfg, args[0])
+	if err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("%s = %s\n", args[0], value); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runConfigList(args []string) int {
+	if len(args) != 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: config list takes no arguments\n\n"); err != nil {
+			return 1
+		}
+		printConfigUsage()
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	keys := slices.Clone(globalConfigKeys)
+	names := cfg.ListJournals()
+	slices.Sort(names)
+	for _, name := range names {
+		for _, key := range journalConfigKeys {
+			keys = append(keys, "journals."+name+"."+key)
+		}
+	}
+
+	for _, key := range keys {
+		value, err := getConfigValue(cfg, key)
+		if err != nil {
+			return 1
+		}
+		if _, err := fmt.Printf("%s = %s\n", key, value); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// getConfigValue returns a setting formatted as config set accepts it, with lists joined by commas
+func getConfigValue(cfg *config.Config, key string) (string, error) {
+	switch key {
+	case "default_journal":
+		return cfg.DefaultJournal, nil
+	case "default_recipients":
+		return strings.Join(cfg.DefaultRecipients, ","), nil
+	case "journals_dir":
+		return cfg.JournalsDir, nil
+	}
+
+	journalCfg, setting, err := journalConfigKey(cfg, key)
+	if err != nil {
+		return "", err
+	}
+
+	switch setting {
+	case "path":
+		return journalCfg.Path, nil
+	case "filename_template":
+		return journalCfg.FilenameTemplate, nil
+	case "key_files":
+		return strings.Join(journalCfg.KeyFiles, ","), nil
+	case "default_tags":
+		return strings.Join(journalCfg.DefaultTags, ","), nil
+	case "use_index":
+		return strconv.FormatBool(journalCfg.IndexEnabled()), nil
+	case "compress":
+		return strconv.FormatBool(journalCfg.Compress), nil
+	}
+	return "", fmt.Errorf("%w: %s", errUnknownConfigKey, key)
+}
+
+// setConfigValue validates value and stores it in cfg; the caller saves cfg
+func setConfigValue(cfg *config.Config, key, value string) error {
+	switch key {
+	case "default_journal":
+		return cfg.SetDefaultJournal(value)
+	case "default_recipients":
+		recipients, err := parseRecipientList([]string{value})
+		if err != nil {
+			return err
+		}
+		cfg.DefaultRecipients = recipients
+		return nil
+	case "journals_dir":
+		// ~ is kept as given and expanded by init, so the config stays portable across machines
+		expanded, err := expandHome(value)
+		if err != nil {
+			return err
+		}
+		if value != "" && !filepath.IsAbs(expanded) {
+			return fmt.Errorf("%s must be an absolute path or start with ~", key)
+		}
+		cfg.JournalsDir = value
+		return nil
+	}
+
+	journalCfg, setting, err := journalConfigKey(cfg, key)
+	if err != nil {
+		return err
+	}
+
+	switch setting {
+	case "path":
+		return fmt.Errorf("%s cannot be set, use 'journal relocate %s <path>' to move the journal", key, journalCfg.Name)
+	case "filename_template":
+		if err := storage.ValidateFilenameTemplate(value); err != nil {
+			return err
+		}
+		journalCfg.FilenameTemplate = value
+		return nil
+	case "key_files":
+		var keyFiles []string
+		for _, path := range uniqueTagList([]string{value}) {
+			expanded, err := expandHome(path)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(expanded); err != nil {
+				return fmt.Errorf("key file %s: %w", path, err)
+			}
+			keyFiles = append(keyFiles, expanded)
+		}
+		journalCfg.KeyFiles = keyFiles
+		return nil
+	case "default_tags":
+		journalCfg.DefaultTags = uniqueTagList([]string{value})
+		return nil
+	case "use_index":
+		if value == "" {
+			journalCfg.UseIndex = nil
+			return nil
+		}
+		useIndex, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+		journalCfg.UseIndex = &useIndex
+		return nil
+	case "compress":
+		if value == "" {
+			journalCfg.Compress = false
+			return nil
+		}
+		compress, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+		journalCfg.Compress = compress
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errUnknownConfigKey, key)
+}
+
+// journalConfigKey splits a journals.<name>.<setting> key and looks up the journal
+func journalConfigKey(cfg *config.Config, key string) (*config.Journal, string, error) {
+	rest, ok := strings.CutPrefix(key, "journals.")
+	i := strings.LastIndex(rest, ".")
+	if !ok || i <= 0 {
+		return nil, "", fmt.Errorf("%w: %s", errUnknownConfigKey, key)
+	}
+
+	journalCfg, err := cfg.GetJournal(rest[:i])
+	if err != nil {
+		return nil, "", err
+	}
+	return journalCfg, rest[i+1:], nil
+}
+
+// parseRecipientList splits comma-separated recipient keys and validates each of them
+func parseRecipientList(values []string) ([]string, error) {
+	var recipients []string
+	for _, value := range values {
+		for recipient := range strings.SplitSeq(value, ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" && !slices.Contains(recipients, recipient) {
+				recipients = append(recipients, recipient)
+			}
+		}
+	}
+
+	for _, recipient := range recipients {
+		if err := crypto.ValidateRecipient(recipient); err != nil {
+			return nil, fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+	}
+	return recipients, nil
+}
+
+func runConfigSetRecipients(args []string) int {
+	fs := flag.NewFlagSet("config set-recipients", flag.ExitOnError)
+	clearRecipients := fs.Bool("clear", false, "Remove the default recipients")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal config set-recipients <keys>")
+		fmt.Println("       journal config set-recipients --clear")
+		fmt.Println("\nSet the age or ssh public keys that 'journal init' encrypts new journals to")
+		fmt.Println("when --recipients is omitted; separate keys with commas or give several arguments")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal config set-recipients age1laptop...,age1desktop...")
+		fmt.Println("  journal config set-recipients age1laptop... \"$(cat ~/.ssh/id_ed25519.pub)\"")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	recipients, err := parseRecipientList(fs.Args())
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if *clearRecipients == (len(recipients) > 0) {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: give either recipient keys or --clear\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	cfg.DefaultRecipients = recipients
+	if err := cfg.Save(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if *clearRecipients {
+		if _, err := fmt.Println("Default recipients cleared"); err != nil {
+			return 1
+		}
+		return 0
+	}
+	if _, err := fmt.Printf("Default recipients set: %d\n", len(recipients)); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/data-castle/journal/internal/config"
+)
+
+func TestRunConfigSetRecipients(t *testing.T) {
+	setupTestConfig(t)
+
+	var keys []string
+	for range 2 {
+		identity, err := age.GenerateX25519Identity()
+		if err != nil {
+			t.Fatalf("failed to generate identity: %v", err)
+		}
+		keys = append(keys, identity.Recipient().String())
+	}
+
+	if exitCode := runConfig([]string{"set-recipients", keys[0] + ", " + keys[1]}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if !slices.Equal(cfg.DefaultRecipients, keys) {
+		t.Errorf("expected default recipients %v, got %v", keys, cfg.DefaultRecipients)
+	}
+
+	if exitCode := runConfig([]string{"set-recipients", "--clear"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0 for --clear, got %d", exitCode)
+	}
+
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(cfg.DefaultRecipients) != 0 {
+		t.Errorf("expected default recipients to be cleared, got %v", cfg.DefaultRecipients)
+	}
+}
+
+func TestRunConfigSetRecipients_Invalid(t *testing.T) {
+	setupTestConfig(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"invalid key", []string{"set-recipients", "age1notakey"}},
+		{"no keys", []string{"set-recipients"}},
+		{"keys and clear", []string{"set-recipients", "--clear", "age1notakey"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runConfig(tt.args); exitCode == 0 {
+				t.Error("expected non-zero exit code")
+			}
+		})
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(cfg.DefaultRecipients) != 0 {
+		t.Errorf("expected no default recipients to be stored, got %v", cfg.DefaultRecipients)
+	}
+}
+
+func TestRunConfig_UnknownCommand(t *testing.T) {
+	if exitCode := runConfig([]string{"frobnicate"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for unknown command")
+	}
+}
+
+func TestRunConfigSetAndGet(t *testing.T) {
+	tmpDir, _, keyPath := setupTestJournal(t, "", "")
+
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{"default_journal", "test", "test"},
+		{"journals.test.default_tags", "work, notes,work", "work,notes"},
+		{"journals.test.filename_template", "{date}-{id}", "{date}-{id}"},
+		{"journals.test.key_files", keyPath, keyPath},
+		{"journals.test.default_tags", "", ""},
+		{"journals_dir", "~/journals", "~/journals"},
+		{"journals.test.use_index", "false", "false"},
+		{"journals.test.use_index", "", "true"},
+		{"journals.test.compress", "true", "true"},
+	}
+
+	for _, tt := range tests {
+		if exitCode := runConfig([]string{"set", tt.key, tt.value}); exitCode != 0 {
+			t.Fatalf("set %s: expected exit code 0, got %d", tt.key, exitCode)
+		}
+
+		var exitCode int
+		output := captureStdout(t, func() {
+			exitCode = runConfig([]string{"get", tt.key})
+		})
+		if exitCode != 0 {
+			t.Fatalf("get %s: expected exit code 0, got %d", tt.key, exitCode)
+		}
+		if got := strings.TrimSuffix(output, "\n"); got != tt.want {
+			t.Errorf("get %s: expected %q, got %q", tt.key, tt.want, got)
+		}
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runConfig([]string{"list"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("list: expected exit code 0, got %d", exitCode)
+	}
+	for _, line := range []string{
+		"default_journal = test",
+		"journals.test.path = " + filepath.Join(tmpDir, "test-journal"),
+		"journals.test.filename_template = {date}-{id}",
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("expected list output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestRunConfigSet_Invalid(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "")
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"missing default journal", "default_journal", "nope"},
+		{"invalid recipient", "default_recipients", "age1notakey"},
+		{"read-only path", "journals.test.path", tmpDir},
+		{"template without id", "journals.test.filename_template", "{date}"},
+		{"missing key file", "journals.test.key_files", filepath.Join(tmpDir, "missing.txt")},
+		{"relative journals dir", "journals_dir", "journals"},
+		{"unknown journal", "journals.nope.default_tags", "work"},
+		{"non-boolean use_index", "journals.test.use_index", "sometimes"},
+		{"non-boolean compress", "journals.test.compress", "gzip"},
+		{"unknown key", "auto_commit", "true"},
+		{"unknown journal key", "journals.test.color", "always"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runConfig([]string{"set", tt.key, tt.value}); exitCode == 0 {
+				t.Error("expected non-zero exit code")
+			}
+		})
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	journalCfg, err := cfg.GetJournal("test")
+	if err != nil {
+		t.Fatalf("failed to get journal: %v", err)
+	}
+	if journalCfg.FilenameTemplate != "" || cfg.DefaultJournal != "test" {
+		t.Errorf("expected rejected values not to be saved, got %+v", journalCfg)
+	}
+}
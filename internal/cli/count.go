@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func runCount(args []string) int {
+	fs := flag.NewFlagSet("count", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	tag := fs.String("tag", "", "Count entries with tag")
+	onDate := fs.String("on", "", "Count entries on specific date (YYYY-MM-DD)")
+	fromDate := fs.String("from", "", "Count entries from date (YYYY-MM-DD)")
+	toDate := fs.String("to", "", "Count entries to date (YYYY-MM-DD)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal count [flags]")
+		fmt.Println("\nPrint the number of entries, optionally filtered by tag or date")
+		fmt.Println("Counts come from the index, so no entries are decrypted")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	hasDateFilter := *onDate != "" || *fromDate != "" || *toDate != ""
+	if *tag != "" && hasDateFilter {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --tag cannot be combined with date filters\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	var count int
+
+	switch {
+	case *tag != "":
+		count = j.CountByTag(*tag)
+
+	case *onDate != "":
+		date, err := time.Parse("2006-01-02", *onDate)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid date format: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		count = j.CountByDateRange(date, date)
+
+	case *fromDate != "" || *toDate != "":
+		var start, end time.Time
+		if *fromDate != "" {
+			start, err = time.Parse("2006-01-02", *fromDate)
+			if err != nil {
+				if _, ferr := fmt.Fprintf(os.Stderr, "Invalid from date: %v\n", err); ferr != nil {
+					return 1
+				}
+				return 1
+			}
+		}
+		if *toDate != "" {
+			end, err = time.Parse("2006-01-02", *toDate)
+			if err != nil {
+				if _, ferr := fmt.Fprintf(os.Stderr, "Invalid to date: %v\n", err); ferr != nil {
+					return 1
+				}
+				return 1
+			}
+		} else {
+			end = time.Now()
+		}
+		count = j.CountByDateRange(start, end)
+
+	default:
+		count = j.Count()
+	}
+
+	if _, err := fmt.Println(count); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunCount(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Entry with tag1", []string{"tag1"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"all entries", []string{"-j", "test"}},
+		{"by tag", []string{"-j", "test", "--tag", "tag1"}},
+		{"on date", []string{"-j", "test", "--on", today}},
+		{"date range", []string{"-j", "test", "--from", today, "--to", today}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runCount(tt.args); exitCode != 0 {
+				t.Errorf("expected exit code 0, got %d", exitCode)
+			}
+		})
+	}
+}
+
+func TestRunCount_InvalidDate(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--on", "19-11-2024"}
+	exitCode := runCount(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for invalid date")
+	}
+}
+
+func TestRunCount_TagWithDateFilter(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--tag", "tag1", "--on", "2024-11-19"}
+	exitCode := runCount(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when combining --tag with a date filter")
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDateExpr parses a search date: YYYY-MM-DD or an expression relative to now
+// Relative expressions are today, yesterday, thismonth, or a count of days, weeks,
+// or months back such as 7d, 2w, or 1m; they resolve to midnight of that day in now's location
+func parseDateExpr(value string, now time.Time) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "thismonth":
+		return today.AddDate(0, 0, 1-today.Day()), nil
+	}
+
+	if len(value) >= 2 {
+		count, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && count >= 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				return today.AddDate(0, 0, -count), nil
+			case 'w':
+				return today.AddDate(0, 0, -7*count), nil
+			case 'm':
+				return today.AddDate(0, -count, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD, today, yesterday, thismonth, or a count back like 7d, 2w, or 1m", value)
+}
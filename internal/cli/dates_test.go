@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateExpr(t *testing.T) {
+	now := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"2024-01-02", "2024-01-02", false},
+		{"today", "2024-03-15", false},
+		{"Yesterday", "2024-03-14", false},
+		{"thismonth", "2024-03-01", false},
+		{"0d", "2024-03-15", false},
+		{"7d", "2024-03-08", false},
+		{"30d", "2024-02-14", false},
+		{"2w", "2024-03-01", false},
+		{"1m", "2024-02-15", false},
+		{"12m", "2023-03-15", false},
+		{"2024-13-01", "", true},
+		{"tomorrow", "", true},
+		{"7y", "", true},
+		{"-3d", "", true},
+		{"d", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDateExpr(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDateExpr(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("parseDateExpr(%q) = %s, want %s", tt.value, got.Format("2006-01-02"), tt.want)
+			}
+			if got.Hour() != 0 || got.Minute() != 0 {
+				t.Errorf("parseDateExpr(%q) = %v, want midnight", tt.value, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+// doctorCheck is a single item in the doctor checklist
+type doctorCheck struct {
+	name     string
+	err      error
+	critical bool
+}
+
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Only check this journal (default: all configured journals)")
+	fs.StringVar(journalName, "j", "", "Only check this journal (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal doctor [flags]")
+		fmt.Println("\nCheck the configuration, age key, and journals for common problems")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	failed := false
+	report := func(checks ...doctorCheck) bool {
+		for _, check := range checks {
+			status := "PASS"
+			detail := ""
+			if check.err != nil {
+				status = "WARN"
+				if check.critical {
+					status = "FAIL"
+					failed = true
+				}
+				detail = fmt.Sprintf(": %v", check.err)
+			}
+			if _, err := fmt.Printf("[%s] %s%s\n", status, check.name, detail); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	cfg, err := config.LoadConfig()
+	if !report(doctorCheck{name: "Config file parses", err: err, critical: true}) {
+		return 1
+	}
+
+	var names []string
+	if cfg != nil {
+		names = slices.Sorted(maps.Keys(cfg.Journals))
+		if *journalName != "" {
+			names = []string{*journalName}
+		}
+	}
+
+	// SOPS_AGE_KEY_FILE is only required by journals that do not configure their own key files
+	keyFileRequired := cfg == nil || len(names) == 0
+	for _, name := range names {
+		if journalCfg, err := cfg.GetJournal(name); err != nil || len(journalCfg.KeyFiles) == 0 {
+			keyFileRequired = true
+		}
+	}
+	if !report(checkAgeKeyFile(keyFileRequired)) {
+		return 1
+	}
+
+	if cfg != nil {
+		if len(names) == 0 {
+			if !report(doctorCheck{name: "Journals are configured", err: fmt.Errorf("no journals configured, run 'journal init' to create one")}) {
+				return 1
+			}
+		}
+
+		for _, name := range names {
+			if _, err := fmt.Printf("\nJournal '%s':\n", name); err != nil {
+				return 1
+			}
+			journalCfg, err := cfg.GetJournal(name)
+			if err != nil {
+				if !report(doctorCheck{name: "Journal is configured", err: err, critical: true}) {
+					return 1
+				}
+				continue
+			}
+			if !report(checkJournal(journalCfg)...) {
+				return 1
+			}
+		}
+	}
+
+	if failed {
+		if _, err := fmt.Println("\nSome critical checks failed"); err != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Println("\nAll critical checks passed"); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// checkAgeKeyFile verifies SOPS_AGE_KEY_FILE is set and points to a readable age identity file
+func checkAgeKeyFile(critical bool) doctorCheck {
+	check := doctorCheck{name: "Age key file is readable (SOPS_AGE_KEY_FILE)", critical: critical}
+
+	keyPath := os.Getenv("SOPS_AGE_KEY_FILE")
+	if keyPath == "" {
+		check.err = fmt.Errorf("SOPS_AGE_KEY_FILE is not set")
+		return check
+	}
+
+	f, err := os.Open(keyPath)
+	if err != nil {
+		check.err = fmt.Errorf("failed to open key file: %w", err)
+		return check
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err := age.ParseIdentities(f); err != nil {
+		check.err = fmt.Errorf("failed to parse key file %s: %w", keyPath, err)
+	}
+	return check
+}
+
+// checkJournal runs the per-journal checks, skipping checks that depend on a failed one
+func checkJournal(journalCfg *config.Journal) []doctorCheck {
+	var checks []doctorCheck
+
+	pathCheck := doctorCheck{name: fmt.Sprintf("Journal directory exists (%s)", journalCfg.Path), critical: true}
+	if info, err := os.Stat(journalCfg.Path); err != nil {
+		pathCheck.err = err
+	} else if !info.IsDir() {
+		pathCheck.err = fmt.Errorf("not a directory")
+	}
+	checks = append(checks, pathCheck)
+	if pathCheck.err != nil {
+		return checks
+	}
+
+	sopsCheck := doctorCheck{name: ".sops.yaml is present", critical: true}
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, ".sops.yaml")); err != nil {
+		sopsCheck.err = err
+	}
+	checks = append(checks, sopsCheck)
+	if sopsCheck.err != nil {
+		return checks
+	}
+
+	// Entry files that failed to load are left out of the index, so they would otherwise go unnoticed
+	quarantineCheck := doctorCheck{name: "No entry files failed to load in the last rebuild or verify"}
+	if quarantined, err := entry.QuarantinedFiles(journalCfg); err != nil {
+		quarantineCheck.err = err
+	} else if len(quarantined) > 0 {
+		paths := make([]string, 0, len(quarantined))
+		for _, file := range quarantined {
+			paths = append(paths, file.FilePath)
+		}
+		quarantineCheck.err = fmt.Errorf("%d listed in %s: %s; inspect them with 'journal cat <path>', then run 'journal rebuild -j %s'",
+			len(quarantined), storage.QuarantineLogFileName, strings.Join(paths, ", "), journalCfg.Name)
+	}
+	checks = append(checks, quarantineCheck)
+
+	indexCheck := doctorCheck{name: "Index decrypts", critical: true}
+	store, err := storage.NewStorage(journalCfg.Path)
+	if err != nil {
+		indexCheck.err = err
+		return append(checks, indexCheck)
+	}
+	if err := store.SetKeyFiles(journalCfg.KeyFiles); err != nil {
+		indexCheck.err = err
+		return append(checks, indexCheck)
+	}
+	if err := store.SetFilenameTemplate(journalCfg.FilenameTemplate); err != nil {
+		indexCheck.err = err
+		return append(checks, indexCheck)
+	}
+	// Journals without an index have nothing to decrypt or drift; entries are checked on every run
+	if !journalCfg.IndexEnabled() {
+		checks = append(checks, doctorCheck{name: "Index is disabled (use_index: false), entries are scanned instead"})
+		return append(checks, checkSOPSVersions(journalCfg, store))
+	}
+	// Inconsistencies get their own check below
+	index, _, err := store.LoadIndex()
+	if err != nil {
+		indexCheck.err = err
+		return append(checks, indexCheck)
+	}
+	// The span of entries makes a stale journal, or the wrong one, easy to spot
+	if first, ok := index.FirstEntry(); ok {
+		last, _ := index.LastEntry()
+		indexCheck.name = fmt.Sprintf("Index decrypts (%d entries, %s to %s)", len(index.Entries), first.Date.Format("2006-01-02"), last.Date.Format("2006-01-02"))
+	} else {
+		indexCheck.name = "Index decrypts (no entries)"
+	}
+	checks = append(checks, indexCheck)
+
+	// An inconsistent index only affects search results and can be rebuilt from the entries
+	validateCheck := doctorCheck{name: "Index is consistent"}
+	if errs := index.Validate(); len(errs) > 0 {
+		validateCheck.err = fmt.Errorf("%d inconsistencies (first: %v), run 'journal rebuild -j %s' to fix", len(errs), errs[0], journalCfg.Name)
+	}
+	checks = append(checks, validateCheck)
+
+	// Drift usually comes from a git merge that kept an index from another machine
+	driftCheck := doctorCheck{name: "Index matches entry files"}
+	added, removed, err := entry.FindIndexDrift(store, index)
+	switch {
+	case err != nil:
+		driftCheck.err = err
+	case len(added) > 0 || len(removed) > 0:
+		driftCheck.err = fmt.Errorf("%d entry files missing from the index, %d indexed entries without a file, run 'journal rebuild -j %s' to fix", len(added), len(removed), journalCfg.Name)
+	}
+	checks = append(checks, driftCheck)
+
+	return append(checks, checkSOPSVersions(journalCfg, store))
+}
+
+// checkSOPSVersions compares the SOPS version stamped into the index and entry files with the
+// SOPS library the binary is built against; files are only parsed, not decrypted
+func checkSOPSVersions(journalCfg *config.Journal, store *storage.Storage) doctorCheck {
+	check := doctorCheck{name: fmt.Sprintf("Files are stamped with the SOPS library version (%s)", crypto.SOPSVersion())}
+
+	entryFiles, err := store.ListAllEntries()
+	if err != nil {
+		check.err = err
+		return check
+	}
+	paths := []string{filepath.Join(journalCfg.Path, storage.IndexFileName)}
+	for _, file := range entryFiles {
+		paths = append(paths, filepath.Join(journalCfg.Path, storage.EntriesDir, file))
+	}
+
+	counts := make(map[string]int)
+	for _, path := range paths {
+		stamped, err := crypto.StampedSOPSVersion(path)
+		if err != nil {
+			// Unreadable files are reported by the quarantine and drift checks
+			continue
+		}
+		if stamped != crypto.SOPSVersion() {
+			counts[stamped]++
+		}
+	}
+	if len(counts) == 0 {
+		return check
+	}
+
+	newer := false
+	var parts []string
+	for _, stamped := range slices.Sorted(maps.Keys(counts)) {
+		parts = append(parts, fmt.Sprintf("%d stamped %s", counts[stamped], stamped))
+		if isNewer, err := crypto.IsNewerSOPSVersion(stamped); err != nil || isNewer {
+			newer = true
+		}
+	}
+	if newer {
+		check.err = fmt.Errorf("%s; some were written by a newer or unknown SOPS version, upgrade journal before editing them", strings.Join(parts, ", "))
+	} else {
+		check.err = fmt.Errorf("%s; older versions still decrypt, run 'journal re-encrypt -j %s' to restamp them", strings.Join(parts, ", "), journalCfg.Name)
+	}
+	return check
+}
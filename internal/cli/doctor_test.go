@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func TestRunDoctor_ConsistentIndex(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Entry", []string{"tag1"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDoctor([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	today := time.Now().Format("2006-01-02")
+	if !strings.Contains(output, "[PASS] Index decrypts (1 entries, "+today+" to "+today+")") {
+		t.Errorf("expected the index check to show the journal's date span, got %q", output)
+	}
+}
+
+func TestRunDoctor_NoIndex(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runConfig([]string{"set", "journals.test.use_index", "false"}); exitCode != 0 {
+		t.Fatalf("failed to disable the index, exit code %d", exitCode)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDoctor([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "[PASS] Index is disabled") {
+		t.Errorf("expected the disabled index to be reported, got %q", output)
+	}
+	if strings.Contains(output, "Index decrypts") {
+		t.Errorf("expected no index checks, got %q", output)
+	}
+}
+
+func TestRunDoctor_InconsistentIndex(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	store, err := storage.NewStorage(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+
+	index, _, err := store.LoadIndex()
+	if err != nil {
+		t.Fatalf("failed to load index: %v", err)
+	}
+
+	index.ByTag["tag1"] = []string{"missing-entry"}
+	if err := store.SaveIndex(index); err != nil {
+		t.Fatalf("failed to save index: %v", err)
+	}
+
+	args := []string{"-j", "test"}
+	exitCode := runDoctor(args)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 since an inconsistent index is only a warning, got %d", exitCode)
+	}
+}
+
+func TestRunDoctor_IndexDrift(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry lost from the index", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	store, err := storage.NewStorage(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	index, _, err := store.LoadIndex()
+	if err != nil {
+		t.Fatalf("failed to load index: %v", err)
+	}
+	index.Remove(ent.GetID())
+	if err := store.SaveIndex(index); err != nil {
+		t.Fatalf("failed to save index: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDoctor([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 since index drift is only a warning, got %d", exitCode)
+	}
+	if !strings.Contains(output, "[WARN] Index matches entry files") || !strings.Contains(output, "journal rebuild -j test") {
+		t.Errorf("expected drift warning suggesting rebuild, got %q", output)
+	}
+}
+
+func TestRunDoctor_QuarantinedEntry(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry to corrupt", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	if exitCode := runVerify([]string{"-j", "test"}); exitCode == 0 {
+		t.Fatal("expected verify to fail for the corrupted entry")
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDoctor([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 since quarantined entries are only a warning, got %d", exitCode)
+	}
+	if !strings.Contains(output, "[WARN] No entry files failed to load") || !strings.Contains(output, ent.GetFilePath()) {
+		t.Errorf("expected doctor to list the quarantined entry, got %q", output)
+	}
+}
+
+func TestRunDoctor_SOPSVersion(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry from an older release", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDoctor([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "[PASS] Files are stamped with the SOPS library version ("+crypto.SOPSVersion()+")") {
+		t.Errorf("expected the SOPS version check to pass, got %q", output)
+	}
+
+	// Releases before the version was taken from the library stamped 3.9.2
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	data, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+	data = []byte(strings.Replace(string(data), "version: "+crypto.SOPSVersion(), "version: 3.9.2", 1))
+	if err := os.WriteFile(entryPath, data, 0600); err != nil {
+		t.Fatalf("failed to write entry: %v", err)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runDoctor([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 since a version mismatch is only a warning, got %d", exitCode)
+	}
+	if !strings.Contains(output, "[WARN] Files are stamped") || !strings.Contains(output, "1 stamped 3.9.2") || !strings.Contains(output, "journal re-encrypt -j test") {
+		t.Errorf("expected a version warning suggesting re-encrypt, got %q", output)
+	}
+}
+
+func TestRunDoctor_MissingKeyFile(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+
+	args := []string{"-j", "test"}
+	exitCode := runDoctor(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when age key file is missing")
+	}
+}
+
+func TestRunDoctor_MissingSOPSConfig(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	if err := os.Remove(filepath.Join(journalCfg.Path, ".sops.yaml")); err != nil {
+		t.Fatalf("failed to remove .sops.yaml: %v", err)
+	}
+
+	args := []string{"-j", "test"}
+	exitCode := runDoctor(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when .sops.yaml is missing")
+	}
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func runEncrypt(args []string) int {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use (required)")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal encrypt <path> [flags]")
+		fmt.Println("\nEncrypt a plaintext YAML file inside the journal directory in place, for the journal's recipients")
+		fmt.Println("Files that are already encrypted are refused; run 'journal rebuild' after encrypting an entry file")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal decrypt -j work ~/work-journal/entries/2024/11/<id>.yaml -o fixed.yaml")
+		fmt.Println("  journal encrypt -j work ~/work-journal/entries/2024/11/<id>.yaml")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	// Allow flags after the path
+	path := fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	if path == "" || fs.NArg() > 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one file path is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	if *journalName == "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: -j is required, so the file is not encrypted for the wrong journal\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	journalCfg, err := resolveJournalConfig(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := entry.EncryptJournalFile(journalCfg, path); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to encrypt %s: %v\n", path, err); ferr != nil {
+			return 1
+		}
+		if errors.Is(err, crypto.ErrAlreadyEncrypted) {
+			if _, ferr := fmt.Fprintln(os.Stderr, "Use 'journal decrypt' to read it"); ferr != nil {
+				return 1
+			}
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Encrypted %s for journal '%s'\n", path, journalCfg.Name); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("If it is an entry file, run 'journal rebuild -j %s' so the index includes it\n", journalCfg.Name); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runDecrypt(args []string) int {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use (required)")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	output := fs.String("output", "", "Write the plaintext to this file instead of stdout")
+	fs.StringVar(output, "o", "", "Output file (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal decrypt <path> [-o out] [flags]")
+		fmt.Println("\nDecrypt a file inside the journal directory with the journal's keys")
+		fmt.Println("The file itself stays encrypted; the plaintext goes to stdout or the --output file")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	// Allow flags after the path
+	path := fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	if path == "" || fs.NArg() > 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one file path is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	if *journalName == "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: -j is required, so the file is not read from the wrong journal\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	journalCfg, err := resolveJournalConfig(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	data, err := entry.DecryptJournalFile(journalCfg, path)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to decrypt %s: %v\n", path, err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if *output == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if err := os.WriteFile(*output, data, 0600); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", *output, err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	if _, err := fmt.Printf("Decrypted %s to %s\n", path, *output); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunEncryptDecrypt(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	path := filepath.Join(journalCfg.Path, "notes.yaml")
+	if err := os.WriteFile(path, []byte("title: Notes\ncontent: Fixed by hand\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runEncrypt([]string{path, "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Encrypted "+path) {
+		t.Errorf("expected encrypt confirmation, got %q", output)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if strings.Contains(string(data), "Fixed by hand") {
+		t.Error("expected file to be encrypted in place")
+	}
+
+	if exitCode := runEncrypt([]string{path, "-j", "test"}); exitCode == 0 {
+		t.Error("expected encrypting an encrypted file to fail")
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runDecrypt([]string{path, "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "content: Fixed by hand") {
+		t.Errorf("expected plaintext on stdout, got %q", output)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "plain.yaml")
+	captureStdout(t, func() {
+		exitCode = runDecrypt([]string{path, "-j", "test", "-o", outPath})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	plain, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(plain), "title: Notes") {
+		t.Errorf("expected plaintext in output file, got %q", plain)
+	}
+	if info, err := os.Stat(outPath); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("expected output file mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestRunEncrypt_Invalid(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	outside := filepath.Join(tmpDir, "outside.yaml")
+	if err := os.WriteFile(outside, []byte("content: secret\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing path", []string{"-j", "test"}},
+		{"missing journal", []string{outside}},
+		{"outside journal", []string{outside, "-j", "test"}},
+		{"sops config", []string{filepath.Join(journalCfg.Path, ".sops.yaml"), "-j", "test"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runEncrypt(tt.args); exitCode == 0 {
+				t.Error("expected non-zero exit code")
+			}
+			if exitCode := runDecrypt(tt.args); exitCode == 0 {
+				t.Error("expected non-zero exit code")
+			}
+		})
+	}
+
+	data, err := os.ReadFile(outside)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "content: secret\n" {
+		t.Errorf("expected file outside the journal to be untouched, got %q", data)
+	}
+}
@@ -1,19 +1,312 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
 )
 
 func runShow(args []string) int {
 	fs := flag.NewFlagSet("show", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	onDate := fs.String("on", "", "Show the only entry on a date (YYYY-MM-DD) instead of giving an ID")
+	format := fs.String("format", "", "Output format: raw, yaml, or a Go text/template")
+	noStats := fs.Bool("no-stats", false, "Do not print the word count and reading time after the entry")
+	noPager := fs.Bool("no-pager", false, "Do not page long output through $PAGER")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal show <entry-id> [flags]")
+		fmt.Println("       journal show --on <date> [flags]")
+		fmt.Println("\nShow a specific journal entry, by ID or by date")
+		fmt.Println("--on and an entry ID cannot be combined; with --on the date must have exactly one entry")
+		fmt.Println("The entry is followed by its word count and reading time, except with --format")
+		fmt.Println("\nFormats:")
+		fmt.Println("  raw   Only the entry content, for piping to other tools")
+		fmt.Println("  yaml  The decrypted entry file")
+		fmt.Println("  Anything else is a Go text/template with .ID, .Date, .Modified, .Title,")
+		fmt.Println("  .Mood, .Tags, .Links, .Attachments, .Content, and .Version")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal show 1a2b3c4d --format raw | wc -w")
+		fmt.Println("  journal show 1a2b3c4d --format '{{.Date.Format \"2006-01-02\"}} {{.Title}}'")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	var id string
+	if fs.NArg() > 0 {
+		id = fs.Arg(0)
+		// Allow flags after the ID, as in the usage line
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+		if fs.NArg() != 0 {
+			if _, err := fmt.Fprintf(os.Stderr, "Error: only one entry ID can be shown\n\n"); err != nil {
+				return 1
+			}
+			fs.Usage()
+			return 1
+		}
+	}
+
+	// Long output goes through $PAGER on a terminal
+	defer startPager(*noPager)()
+
+	var tmpl *template.Template
+	if *format != "" && *format != showFormatRaw && *format != showFormatYAML {
+		var err error
+		tmpl, err = template.New("format").Parse(*format)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid --format template: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case *onDate != "" && id != "":
+		if _, err := fmt.Fprintf(os.Stderr, "Error: specify either an entry ID or --on, not both\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	case *onDate == "" && id == "":
+		if _, err := fmt.Fprintf(os.Stderr, "Error: entry ID or --on date is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	var date time.Time
+	if *onDate != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", *onDate)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid date format: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	var ent models.Entry
+	if *onDate != "" {
+		ent, err = j.GetByDate(date)
+	} else {
+		ent, err = j.Get(id)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, entry.ErrEntryNotFound) && *onDate != "":
+			if _, ferr := fmt.Fprintf(os.Stderr, "No entry on %s (use 'journal list' to see entry dates)\n", *onDate); ferr != nil {
+				return 1
+			}
+			return 1
+		case errors.Is(err, entry.ErrEntryNotFound):
+			if _, ferr := fmt.Fprintf(os.Stderr, "Entry %s not found (use 'journal list' to see entry IDs)\n", id); ferr != nil {
+				return 1
+			}
+			return 1
+		case errors.Is(err, entry.ErrAmbiguousDate):
+			if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get entry: %v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if *format != "" {
+		if err := printEntryFormat(ent, *format, tmpl); err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to format entry: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		return 0
+	}
+
+	backlinks, err := j.Backlinks(ent.GetID())
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to find backlinks: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := printEntry(ent, backlinks); err != nil {
+		return 1
+	}
+
+	if !*noStats {
+		if _, err := fmt.Printf("\n%s\n", readingStats(j.WordCount(ent))); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// wordsPerMinute is the reading speed assumed for show's reading time
+const wordsPerMinute = 200
+
+// readingStats formats a word count and its estimated reading time, rounded up to whole minutes
+func readingStats(words int) string {
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	return fmt.Sprintf("%d words, %d min read", words, minutes)
+}
+
+// printEntry writes the full human-readable layout of an entry used by show
+// backlinks are the IDs of entries that link to ent
+func printEntry(ent models.Entry, backlinks []string) error {
+	if _, err := fmt.Printf("ID: %s\n", colorID(ent.GetID())); err != nil {
+		return err
+	}
+	if _, err := fmt.Printf("Date: %s\n", colorDate(ent.GetDate().Format("2006-01-02 15:04:05"))); err != nil {
+		return err
+	}
+	if !ent.GetModified().IsZero() {
+		if _, err := fmt.Printf("Modified: %s\n", colorDate(ent.GetModified().Format("2006-01-02 15:04:05"))); err != nil {
+			return err
+		}
+	}
+	if ent.GetTitle() != "" {
+		if _, err := fmt.Printf("Title: %s\n", ent.GetTitle()); err != nil {
+			return err
+		}
+	}
+	if ent.GetMood() != 0 {
+		if _, err := fmt.Printf("Mood: %d/%d\n", ent.GetMood(), models.MaxMood); err != nil {
+			return err
+		}
+	}
+	if len(ent.GetTags()) > 0 {
+		if _, err := fmt.Printf("Tags: %s\n", colorTags(ent.GetTags())); err != nil {
+			return err
+		}
+	}
+	if len(ent.GetLinks()) > 0 {
+		if _, err := fmt.Printf("Links: %s\n", shortIDs(ent.GetLinks())); err != nil {
+			return err
+		}
+	}
+	if len(backlinks) > 0 {
+		if _, err := fmt.Printf("Linked from: %s\n", shortIDs(backlinks)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Printf("\n%s\n", ent.GetContent())
+	return err
+}
+
+// shortIDs renders entry IDs as the 8-character prefixes shown by list, separated by commas
+func shortIDs(ids []string) string {
+	short := make([]string, len(ids))
+	for i, id := range ids {
+		short[i] = colorID(id[:min(8, len(id))])
+	}
+	return strings.Join(short, ", ")
+}
+
+// Presets accepted by show --format in place of a template
+const (
+	showFormatRaw  = "raw"
+	showFormatYAML = "yaml"
+)
+
+// showTemplateData is the data available to show --format templates
+type showTemplateData struct {
+	ID          string
+	Date        time.Time
+	Modified    time.Time
+	Title       string
+	Mood        int
+	Tags        []string
+	Links       []string
+	Attachments []string
+	Content     string
+	Version     int
+}
+
+// printEntryFormat writes ent to stdout using a --format preset or the parsed template
+// Output that does not end in a newline gets one, so the shell prompt starts on its own line
+func printEntryFormat(ent models.Entry, format string, tmpl *template.Template) error {
+	var out string
+	switch format {
+	case showFormatRaw:
+		out = ent.GetContent()
+	case showFormatYAML:
+		data, err := ent.ToYaml()
+		if err != nil {
+			return err
+		}
+		out = string(data)
+	default:
+		var buf strings.Builder
+		err := tmpl.Execute(&buf, showTemplateData{
+			ID:          ent.GetID(),
+			Date:        ent.GetDate(),
+			Modified:    ent.GetModified(),
+			Title:       ent.GetTitle(),
+			Mood:        ent.GetMood(),
+			Tags:        ent.GetTags(),
+			Links:       ent.GetLinks(),
+			Attachments: ent.GetAttachments(),
+			Content:     ent.GetContent(),
+			Version:     ent.GetVersion(),
+		})
+		if err != nil {
+			return err
+		}
+		out = buf.String()
+	}
+
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := fmt.Print(out)
+	return err
+}
+
+func runDelete(args []string) int {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	permanent := fs.Bool("permanent", false, "Delete the entry file instead of moving it to the trash")
+	yes := fs.Bool("yes", false, "Delete without asking for confirmation")
+	fs.BoolVar(yes, "y", false, "Delete without asking for confirmation (shorthand)")
+	tag := fs.String("tag", "", "Delete every entry with this tag instead of a single entry")
+	fromDate := fs.String("from", "", "Delete every entry from this date (YYYY-MM-DD or relative, e.g. 30d)")
+	toDate := fs.String("to", "", "Delete every entry up to this date (YYYY-MM-DD or relative; default: today)")
 	fs.Usage = func() {
-		fmt.Println("Usage: journal show [entry-id] [flags]")
-		fmt.Println("\nShow a specific journal entry")
+		fmt.Println("Usage: journal delete [entry-id] [flags]")
+		fmt.Println("       journal delete --tag <tag> | --from <date> --to <date> [flags]")
+		fmt.Println("\nMove a journal entry to the trash (use 'journal restore' to undo)")
+		fmt.Println("The entry is shown and confirmation is asked first unless --yes is given")
+		fmt.Println("When stdin is not a terminal the delete is aborted unless --yes is given")
+		fmt.Println("\nWith --tag or --from/--to, every matching entry is moved to the trash after confirmation")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
 	}
@@ -21,6 +314,27 @@ func runShow(args []string) int {
 		return 1
 	}
 
+	hasDateRange := *fromDate != "" || *toDate != ""
+	if *tag != "" || hasDateRange {
+		var usageErr string
+		switch {
+		case fs.NArg() > 0:
+			usageErr = "give either an entry ID or --tag/--from/--to, not both"
+		case *tag != "" && hasDateRange:
+			usageErr = "--tag cannot be combined with --from/--to"
+		case *permanent:
+			usageErr = "--permanent only applies to a single entry"
+		}
+		if usageErr != "" {
+			if _, err := fmt.Fprintf(os.Stderr, "Error: %s\n\n", usageErr); err != nil {
+				return 1
+			}
+			fs.Usage()
+			return 1
+		}
+		return runDeleteBatch(*journalName, *tag, *fromDate, *toDate, *yes)
+	}
+
 	if fs.NArg() != 1 {
 		if _, err := fmt.Fprintf(os.Stderr, "Error: entry ID is required\n\n"); err != nil {
 			return 1
@@ -34,49 +348,256 @@ func runShow(args []string) int {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
+		return errorExitCode(err)
+	}
+
+	if !*yes {
+		ok, err := confirmDelete(j, fs.Arg(0))
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+				return 1
+			}
+			return errorExitCode(err)
+		}
+		if !ok {
+			if _, err := fmt.Println("Aborted; nothing was deleted"); err != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+
+	if *permanent {
+		if err := j.DeletePermanently(fs.Arg(0)); err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to delete entry: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+
+		if _, err := fmt.Printf("Entry %s permanently deleted\n", fs.Arg(0)); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if err := j.Delete(fs.Arg(0)); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to delete entry: %v\n", err); ferr != nil {
+			return 1
+		}
 		return 1
 	}
 
-	ent, err := j.Get(fs.Arg(0))
+	if _, err := fmt.Printf("Entry %s moved to trash (restore with 'journal restore %s')\n", fs.Arg(0), fs.Arg(0)); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// runDeleteBatch moves every entry with tag, or dated between fromDate and toDate, to the trash
+func runDeleteBatch(journalName, tag, fromDate, toDate string, yes bool) int {
+	now := time.Now()
+	var start time.Time
+	end := now
+	if fromDate != "" {
+		var err error
+		start, err = parseDateExpr(fromDate, now)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid from date: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+	if toDate != "" {
+		var err error
+		end, err = parseDateExpr(toDate, now)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid to date: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+
+	j, _, err := openJournal(journalName)
 	if err != nil {
-		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get entry: %v\n", err); ferr != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	var count int
+	var description string
+	if tag != "" {
+		count = j.CountByTag(tag)
+		description = fmt.Sprintf("tagged '%s'", tag)
+	} else {
+		count = j.CountByDateRange(start, end)
+		description = fmt.Sprintf("dated %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+		if start.IsZero() {
+			description = "dated up to " + end.Format("2006-01-02")
+		}
+	}
+
+	if count == 0 {
+		if _, err := fmt.Printf("No entries %s; nothing was deleted\n", description); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if !yes {
+		if !stdinIsTerminal() {
+			if _, err := fmt.Fprintln(os.Stderr, "stdin is not a terminal; pass --yes to delete without confirmation"); err != nil {
+				return 1
+			}
+			return 1
+		}
+		ok, err := confirm(fmt.Sprintf("Move %d entries %s to the trash?", count, description))
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if !ok {
+			if _, err := fmt.Println("Aborted; nothing was deleted"); err != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+
+	var deleted int
+	if tag != "" {
+		deleted, err = j.DeleteByTag(tag)
+	} else {
+		deleted, err = j.DeleteByDateRange(start, end)
+	}
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to delete entries: %v\n", err); ferr != nil {
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Moved %d entries to the trash before the failure\n", deleted); ferr != nil {
 			return 1
 		}
 		return 1
 	}
 
-	if _, err := fmt.Printf("ID: %s\n", ent.GetID()); err != nil {
+	if _, err := fmt.Printf("Moved %d entries to the trash (see 'journal trash list' to restore them)\n", deleted); err != nil {
 		return 1
 	}
+	return 0
+}
+
+// deletePreviewLength is how many characters of content the delete prompt shows
+const deletePreviewLength = 60
+
+// confirmDelete shows the entry about to be deleted and asks for confirmation
+// Without a terminal to answer on, it refuses rather than deleting unattended
+func confirmDelete(j *entry.Journal, id string) (bool, error) {
+	if !stdinIsTerminal() {
+		return false, errors.New("stdin is not a terminal; pass --yes to delete without confirmation")
+	}
+
+	ent, err := j.Get(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get entry: %w", err)
+	}
+
 	if _, err := fmt.Printf("Date: %s\n", ent.GetDate().Format("2006-01-02 15:04:05")); err != nil {
+		return false, err
+	}
+	if _, err := fmt.Printf("Content: %s\n", contentPreview(ent.GetContent(), deletePreviewLength)); err != nil {
+		return false, err
+	}
+	return confirm("Delete this entry?")
+}
+
+// contentPreview collapses content onto one line and cuts it to at most maxLen characters
+func contentPreview(content string, maxLen int) string {
+	preview := []rune(strings.Join(strings.Fields(content), " "))
+	if len(preview) <= maxLen {
+		return string(preview)
+	}
+	return string(preview[:maxLen]) + "..."
+}
+
+func runAppend(args []string) int {
+	fs := flag.NewFlagSet("append", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	timestamp := fs.Bool("timestamp", false, "Prefix the appended text with the current time")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal append [entry-id] [text] [flags]")
+		fmt.Println("\nAppend text to an existing entry on a new line")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal append 1a2b3c4d \"Follow-up: shipped the fix\"")
+		fmt.Println("  journal append --timestamp 1a2b3c4d \"Back from lunch\"")
+	}
+	if err := fs.Parse(args); err != nil {
 		return 1
 	}
-	if len(ent.GetTags()) > 0 {
-		if _, err := fmt.Printf("Tags: %s\n", strings.Join(ent.GetTags(), ", ")); err != nil {
+
+	if fs.NArg() < 2 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: entry ID and text are required\n\n"); err != nil {
 			return 1
 		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	text := strings.Join(fs.Args()[1:], " ")
+	if *timestamp {
+		text = fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04"), text)
+	}
+
+	ent, err := j.Append(fs.Arg(0), text)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to append to entry: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
 	}
-	if _, err := fmt.Printf("\n%s\n", ent.GetContent()); err != nil {
+
+	if _, err := fmt.Printf("Appended to entry %s\n", ent.GetID()[:8]); err != nil {
 		return 1
 	}
 	return 0
 }
 
-func runDelete(args []string) int {
-	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+func runTouch(args []string) int {
+	fs := flag.NewFlagSet("touch", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	dateFlag := fs.String("date", "", "New entry date as YYYY-MM-DD or RFC3339 (default: now)")
 	fs.Usage = func() {
-		fmt.Println("Usage: journal delete [entry-id] [flags]")
-		fmt.Println("\nDelete a journal entry")
+		fmt.Println("Usage: journal touch <entry-id> [flags]")
+		fmt.Println("\nChange an entry's date without changing its content")
+		fmt.Println("Without --date the entry moves to now, to the top of 'journal list'")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal touch 1a2b3c4d")
+		fmt.Println("  journal touch 1a2b3c4d --date 2024-06-01")
 	}
 	if err := fs.Parse(args); err != nil {
 		return 1
 	}
 
-	if fs.NArg() != 1 {
+	if fs.NArg() < 1 {
 		if _, err := fmt.Fprintf(os.Stderr, "Error: entry ID is required\n\n"); err != nil {
 			return 1
 		}
@@ -84,22 +605,41 @@ func runDelete(args []string) int {
 		return 1
 	}
 
+	// Allow flags after the ID, as in the usage line
+	id := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return 1
+	}
+
+	date := time.Now()
+	if *dateFlag != "" {
+		parsed, err := parseEntryDate(*dateFlag)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		date = parsed
+	}
+
 	j, _, err := openJournal(*journalName)
 	if err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
-		return 1
+		return errorExitCode(err)
 	}
 
-	if err := j.Delete(fs.Arg(0)); err != nil {
-		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to delete entry: %v\n", err); ferr != nil {
+	ent, err := j.Touch(id, date)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to touch entry: %v\n", err); ferr != nil {
 			return 1
 		}
 		return 1
 	}
 
-	if _, err := fmt.Printf("Entry %s deleted\n", fs.Arg(0)); err != nil {
+	if _, err := fmt.Printf("Entry %s now dated %s\n", ent.GetID()[:8], ent.GetDate().Format("2006-01-02 15:04")); err != nil {
 		return 1
 	}
 	return 0
@@ -109,9 +649,15 @@ func runRebuild(args []string) int {
 	fs := flag.NewFlagSet("rebuild", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	verbose := fs.Bool("verbose", false, "Print progress as each entry is read")
+	fs.BoolVar(verbose, "v", false, "Print progress (shorthand)")
+	limit := fs.Int("limit", 10, "Maximum number of skipped files to list in the summary (0 for all)")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal rebuild [flags]")
 		fmt.Println("\nRebuild the search index from all entries")
+		fmt.Println("Entries that cannot be read are skipped and listed in the summary; the command")
+		fmt.Println("then exits non-zero, since they are missing from the index")
+		fmt.Println("Entry files moved by hand are indexed where they are found, and the path recorded in them is corrected")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
 	}
@@ -124,21 +670,83 @@ func runRebuild(args []string) int {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
-		return 1
+		return errorExitCode(err)
 	}
 
 	if _, err := fmt.Println("Rebuilding index..."); err != nil {
 		return 1
 	}
-	if err := j.RebuildIndex(); err != nil {
+
+	var opts entry.RebuildOptions
+	// progressErr keeps the first failed progress write, so the command can still exit non-zero
+	var progressErr error
+	if *verbose {
+		opts.Progress = func(done, total int, filePath string) {
+			if progressErr == nil {
+				_, progressErr = fmt.Printf("[%d/%d] %s\n", done, total, filePath)
+			}
+		}
+	}
+
+	result, err := j.RebuildIndexWithOptions(opts)
+	if err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to rebuild index: %v\n", err); ferr != nil {
 			return 1
 		}
 		return 1
 	}
+	if progressErr != nil {
+		return 1
+	}
+
+	if result.Repaired > 0 {
+		if _, err := fmt.Printf("Corrected the recorded file path of %d moved entries\n", result.Repaired); err != nil {
+			return 1
+		}
+	}
+	if result.Backfilled > 0 {
+		if _, err := fmt.Printf("Recorded content hashes for %d older entries\n", result.Backfilled); err != nil {
+			return 1
+		}
+	}
+
+	if len(result.Skipped) == 0 {
+		if _, err := fmt.Printf("Indexed %d of %d entries\n", result.Indexed, result.TotalFiles); err != nil {
+			return 1
+		}
+		return 0
+	}
 
-	if _, err := fmt.Println("Index rebuilt successfully"); err != nil {
+	if _, err := fmt.Printf("Indexed %d of %d entries (%d skipped)\n", result.Indexed, result.TotalFiles, len(result.Skipped)); err != nil {
 		return 1
 	}
-	return 0
+	if err := printRebuildSkipped(result.Skipped, *limit); err != nil {
+		return 1
+	}
+	return 1
+}
+
+// printRebuildSkipped lists up to limit skipped files and why they were skipped on stderr
+func printRebuildSkipped(skipped []crypto.FileError, limit int) error {
+	if _, err := fmt.Fprintf(os.Stderr, "\nSkipped files:\n"); err != nil {
+		return err
+	}
+
+	shown := skipped
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, fe := range shown {
+		if _, err := fmt.Fprintf(os.Stderr, "  - %s: %v\n", fe.FilePath, fe.Error); err != nil {
+			return err
+		}
+	}
+	if len(shown) < len(skipped) {
+		if _, err := fmt.Fprintf(os.Stderr, "  ... and %d more (use --limit 0 to list all)\n", len(skipped)-len(shown)); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(os.Stderr, "\nRun 'journal verify' for details, or 'journal cat <path> -j <journal>' to inspect a file")
+	return err
 }
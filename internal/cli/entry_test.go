@@ -1,8 +1,15 @@
 package cli
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/data-castle/journal/internal/crypto"
 	"github.com/data-castle/journal/internal/entry"
 )
 
@@ -29,6 +36,101 @@ func TestRunShow_Success(t *testing.T) {
 	}
 }
 
+func TestRunShow_ReadingStats(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Four words in here", nil)
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		runShow([]string{"-j", "test", ent.GetID()})
+	})
+	if !strings.HasSuffix(output, "\n4 words, 1 min read\n") {
+		t.Errorf("expected reading stats footer, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		runShow([]string{"-j", "test", ent.GetID(), "--no-stats"})
+	})
+	if strings.Contains(output, "min read") {
+		t.Errorf("expected --no-stats to omit the footer, got %q", output)
+	}
+}
+
+func TestReadingStats(t *testing.T) {
+	tests := []struct {
+		words int
+		want  string
+	}{
+		{0, "0 words, 0 min read"},
+		{1, "1 words, 1 min read"},
+		{200, "200 words, 1 min read"},
+		{201, "201 words, 2 min read"},
+	}
+	for _, tt := range tests {
+		if got := readingStats(tt.words); got != tt.want {
+			t.Errorf("readingStats(%d) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestRunShow_Format(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Line one\nLine two", []string{"work", "notes"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"raw", "Line one\nLine two\n"},
+		{"{{.ID}} {{len .Tags}} v{{.Version}}", ent.GetID() + " 2 v2\n"},
+	}
+
+	for _, tt := range tests {
+		var exitCode int
+		output := captureStdout(t, func() {
+			exitCode = runShow([]string{"-j", "test", ent.GetID(), "--format", tt.format})
+		})
+		if exitCode != 0 {
+			t.Fatalf("--format %q: expected exit code 0, got %d", tt.format, exitCode)
+		}
+		if output != tt.want {
+			t.Errorf("--format %q: expected %q, got %q", tt.format, tt.want, output)
+		}
+	}
+
+	output := captureStdout(t, func() {
+		runShow([]string{"-j", "test", "--format=yaml", ent.GetID()})
+	})
+	if !strings.Contains(output, "id: "+ent.GetID()) || !strings.Contains(output, "Line two") {
+		t.Errorf("expected decrypted entry YAML, got %q", output)
+	}
+}
+
+func TestRunShow_InvalidFormat(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runShow([]string{"-j", "test", "--format", "{{.ID", "some-id"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an invalid template")
+	}
+}
+
 func TestRunShow_MissingID(t *testing.T) {
 	setupTestJournal(t, "", "")
 
@@ -51,6 +153,88 @@ func TestRunShow_InvalidID(t *testing.T) {
 	}
 }
 
+func TestRunShow_OnDate(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	date := time.Date(2024, 11, 19, 10, 0, 0, 0, time.Local)
+	if _, err := j.AddWithDate("Only entry that day", nil, date); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runShow([]string{"-j", "test", "--on", "2024-11-19"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Only entry that day") {
+		t.Errorf("expected entry content in output, got %q", output)
+	}
+
+	if _, err := j.AddWithDate("Second entry that day", nil, date.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	stderr := captureStderr(t, func() {
+		exitCode = runShow([]string{"-j", "test", "--on", "2024-11-19"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when the date has several entries")
+	}
+	if !strings.Contains(stderr, "specify ID") {
+		t.Errorf("expected hint to specify an ID, got %q", stderr)
+	}
+}
+
+func TestRunShow_OnDateWithID(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runShow([]string{"-j", "test", "--on", "2024-11-19", "some-id"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when combining --on with an entry ID")
+	}
+}
+
+func TestRunShow_MissingAgeKey(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Secret entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	// Keep SOPS from finding a key in its default locations
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	if err := os.Unsetenv("SOPS_AGE_KEY_FILE"); err != nil {
+		t.Fatalf("failed to unset SOPS_AGE_KEY_FILE: %v", err)
+	}
+
+	var exitCode int
+	stderr := captureStderr(t, func() {
+		exitCode = runShow([]string{"-j", "test", ent.GetID()})
+	})
+
+	if exitCode != exitDecryptionFailed {
+		t.Errorf("expected exit code %d, got %d", exitDecryptionFailed, exitCode)
+	}
+
+	if !strings.Contains(stderr, "SOPS_AGE_KEY_FILE") {
+		t.Errorf("expected a hint about SOPS_AGE_KEY_FILE, got: %s", stderr)
+	}
+}
+
 func TestRunDelete_Success(t *testing.T) {
 	_, journalCfg, _ := setupTestJournal(t, "", "")
 
@@ -66,7 +250,7 @@ func TestRunDelete_Success(t *testing.T) {
 
 	entryID := ent.GetID()
 
-	args := []string{"-j", "test", entryID}
+	args := []string{"-j", "test", "--yes", entryID}
 	exitCode := runDelete(args)
 
 	if exitCode != 0 {
@@ -74,6 +258,133 @@ func TestRunDelete_Success(t *testing.T) {
 	}
 }
 
+func TestRunDelete_Confirmation(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Entry that should survive", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	setStdin(t, "n\n")
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDelete([]string{"-j", "test", ent.GetID()})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when the prompt is declined")
+	}
+	if !strings.Contains(output, "Entry that should survive") {
+		t.Errorf("expected prompt to preview the entry, got %q", output)
+	}
+	if _, err := j.Get(ent.GetID()); err != nil {
+		t.Fatalf("expected entry to be kept after declining: %v", err)
+	}
+
+	setStdin(t, "y\n")
+	if exitCode := runDelete([]string{"-j", "test", ent.GetID()}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	if _, err := j.Get(ent.GetID()); err == nil {
+		t.Error("expected entry to be deleted after confirming")
+	}
+}
+
+func TestRunDelete_Batch(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	for _, tags := range [][]string{{"draft"}, {"draft"}, {"work"}} {
+		if _, err := j.Add("Entry", tags); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	setStdin(t, "n\n")
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runDelete([]string{"-j", "test", "--tag", "draft"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when the prompt is declined")
+	}
+	if !strings.Contains(output, "Move 2 entries tagged 'draft' to the trash?") {
+		t.Errorf("expected prompt with the number of entries, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runDelete([]string{"-j", "test", "--tag", "draft", "--yes"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Moved 2 entries to the trash") {
+		t.Errorf("expected deleted count, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runDelete([]string{"-j", "test", "--from", "1d", "-y"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Moved 1 entries to the trash") {
+		t.Errorf("expected the remaining entry to be deleted by date, got %q", output)
+	}
+}
+
+func TestRunDelete_BatchInvalid(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"with id", []string{"-j", "test", "--tag", "draft", "-y", "some-id"}},
+		{"tag and dates", []string{"-j", "test", "--tag", "draft", "--from", "2024-01-01", "-y"}},
+		{"permanent", []string{"-j", "test", "--tag", "draft", "--permanent", "-y"}},
+		{"invalid date", []string{"-j", "test", "--from", "someday", "-y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runDelete(tt.args); exitCode == 0 {
+				t.Error("expected non-zero exit code")
+			}
+		})
+	}
+}
+
+func TestContentPreview(t *testing.T) {
+	tests := []struct {
+		content string
+		maxLen  int
+		want    string
+	}{
+		{"short", 10, "short"},
+		{"line one\n\nline  two", 20, "line one line two"},
+		{"abcdefghij", 4, "abcd..."},
+	}
+
+	for _, tt := range tests {
+		if got := contentPreview(tt.content, tt.maxLen); got != tt.want {
+			t.Errorf("contentPreview(%q, %d) = %q, want %q", tt.content, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
 func TestRunDelete_MissingID(t *testing.T) {
 	setupTestJournal(t, "", "")
 
@@ -121,3 +432,175 @@ func TestRunRebuild_Success(t *testing.T) {
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
 }
+
+func TestRunRebuild_SkippedSummary(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	broken, err := j.Add("Entry to corrupt", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Healthy entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	brokenPath := filepath.Join(journalCfg.Path, "entries", broken.GetFilePath())
+	if err := os.WriteFile(brokenPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runRebuild([]string{"-j", "test", "--limit", "1"})
+	})
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when entries are skipped")
+	}
+	if !strings.Contains(output, "Indexed 1 of 2 entries (1 skipped)") {
+		t.Errorf("expected rebuild summary, got %q", output)
+	}
+}
+
+func TestPrintRebuildSkipped_Limit(t *testing.T) {
+	skipped := []crypto.FileError{
+		{FilePath: "a.yaml", Error: errors.New("bad")},
+		{FilePath: "b.yaml", Error: errors.New("bad")},
+		{FilePath: "c.yaml", Error: errors.New("bad")},
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	oldStderr := os.Stderr
+	os.Stderr = w
+	err = printRebuildSkipped(skipped, 2)
+	os.Stderr = oldStderr
+	if cerr := w.Close(); cerr != nil {
+		t.Fatalf("failed to close pipe: %v", cerr)
+	}
+	if err != nil {
+		t.Fatalf("printRebuildSkipped failed: %v", err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read stderr: %v", err)
+	}
+	output := string(data)
+	if !strings.Contains(output, "a.yaml: bad") || !strings.Contains(output, "b.yaml: bad") {
+		t.Errorf("expected the first two files to be listed, got %q", output)
+	}
+	if strings.Contains(output, "c.yaml") {
+		t.Errorf("expected the third file to be cut off by the limit, got %q", output)
+	}
+	if !strings.Contains(output, "... and 1 more") {
+		t.Errorf("expected a count of the files not listed, got %q", output)
+	}
+}
+
+func TestRunAppend_Success(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("First line", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	args := []string{"-j", "test", "--timestamp", ent.GetID(), "Second", "line"}
+	exitCode := runAppend(args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	got, err := j.Get(ent.GetID())
+	if err != nil {
+		t.Fatalf("failed to get entry: %v", err)
+	}
+
+	lines := strings.Split(got.GetContent(), "\n")
+	if len(lines) != 2 || lines[0] != "First line" {
+		t.Fatalf("expected two lines starting with the original content, got %q", got.GetContent())
+	}
+
+	if !strings.HasPrefix(lines[1], "[") || !strings.HasSuffix(lines[1], "] Second line") {
+		t.Errorf("expected timestamped appended line, got %q", lines[1])
+	}
+}
+
+func TestRunAppend_MissingText(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "some-id"}
+	exitCode := runAppend(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for missing text")
+	}
+}
+
+func TestRunTouch(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Misdated entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	args := []string{"-j", "test", ent.GetID()[:8], "--date", "2024-06-01"}
+	exitCode := runTouch(args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	got, err := j.Get(ent.GetID())
+	if err != nil {
+		t.Fatalf("failed to get entry: %v", err)
+	}
+
+	if got.GetDate().Format("2006-01-02") != "2024-06-01" {
+		t.Errorf("expected date 2024-06-01, got %v", got.GetDate())
+	}
+	if got.GetContent() != "Misdated entry" {
+		t.Errorf("expected content to be unchanged, got %q", got.GetContent())
+	}
+}
+
+func TestRunTouch_InvalidDate(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "some-id", "--date", "June 1st"}
+	exitCode := runTouch(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for an invalid date")
+	}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	format := fs.String("format", entry.ExportFormatJSON, "Export format (json or markdown)")
+	fs.StringVar(format, "f", entry.ExportFormatJSON, "Export format (shorthand)")
+	output := fs.String("output", "", "File to write the export to (default: stdout)")
+	fs.StringVar(output, "o", "", "File to write the export to (shorthand)")
+	newestFirst := fs.Bool("newest-first", false, "Write the newest entry first (default for json)")
+	oldestFirst := fs.Bool("oldest-first", false, "Write the oldest entry first (default for markdown)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal export [flags]")
+		fmt.Println("\nExport all entries, decrypted, to a single plaintext file")
+		fmt.Println("Markdown exports read like a diary, oldest entry first; JSON exports start with")
+		fmt.Println("the newest entry, like 'journal list'")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal export -j personal -o backup.json")
+		fmt.Println("  journal export -j work --format markdown -o work.md")
+		fmt.Println("  journal export -j work --format markdown --newest-first")
+		fmt.Println("\nWarning: the export is NOT encrypted. Store it somewhere safe.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *format != entry.ExportFormatJSON && *format != entry.ExportFormatMarkdown {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: unsupported format '%s' (use json or markdown)\n\n", *format); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	if *newestFirst && *oldestFirst {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --newest-first and --oldest-first cannot be combined\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	opts := entry.DefaultExportOptions(*format)
+	if *newestFirst || *oldestFirst {
+		opts.NewestFirst = *newestFirst
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	var w io.Writer = os.Stdout
+	var outFile *os.File
+	if *output != "" {
+		outFile, err = os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to create output file: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		w = outFile
+	}
+
+	exportErr := j.ExportWithOptions(w, *format, opts)
+
+	if outFile != nil {
+		if err := outFile.Close(); err != nil && exportErr == nil {
+			exportErr = fmt.Errorf("failed to close output file: %w", err)
+		}
+	}
+
+	if exportErr != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to export journal: %v\n", exportErr); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if *output != "" {
+		if _, err := fmt.Printf("Exported journal to %s\n", *output); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunExport_JSONToFile(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Exported entry", []string{"tag1"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "export.json")
+	args := []string{"-j", "test", "--format", "json", "--output", outPath}
+	exitCode := runExport(args)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+
+	if !strings.Contains(string(data), "Exported entry") {
+		t.Error("expected export to contain entry content")
+	}
+}
+
+func TestRunExport_Markdown(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Markdown entry", []string{"tag1"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "export.md")
+	args := []string{"-j", "test", "--format", "markdown", "-o", outPath}
+	exitCode := runExport(args)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+
+	if !strings.HasPrefix(string(data), "## ") {
+		t.Errorf("expected markdown export to start with a date heading, got:\n%s", data)
+	}
+}
+
+func TestRunExport_InvalidFormat(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--format", "csv"}
+	exitCode := runExport(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for unsupported format")
+	}
+}
+
+func TestRunExport_Order(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.AddWithDate("Older entry", nil, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.AddWithDate("Newer entry", nil, time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		olderFirst bool
+	}{
+		{"markdown default", []string{"--format", "markdown"}, true},
+		{"json default", []string{"--format", "json"}, false},
+		{"markdown newest first", []string{"--format", "markdown", "--newest-first"}, false},
+		{"json oldest first", []string{"--format", "json", "--oldest-first"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exitCode int
+			output := captureStdout(t, func() {
+				exitCode = runExport(append([]string{"-j", "test"}, tt.args...))
+			})
+			if exitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d", exitCode)
+			}
+
+			olderFirst := strings.Index(output, "Older entry") < strings.Index(output, "Newer entry")
+			if olderFirst != tt.olderFirst {
+				t.Errorf("expected older entry first: %v, got:\n%s", tt.olderFirst, output)
+			}
+		})
+	}
+}
+
+func TestRunExport_ConflictingOrder(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	exitCode := runExport([]string{"-j", "test", "--newest-first", "--oldest-first"})
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for conflicting order flags")
+	}
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+// importProgressInterval is the number of records between progress lines during import
+const importProgressInterval = 100
+
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	format := fs.String("format", "", "Import format (json or markdown; default: inferred from file extension)")
+	fs.StringVar(format, "f", "", "Import format (shorthand)")
+	preserveIDs := fs.Bool("preserve-ids", false, "Keep entry IDs from the archive instead of generating new ones; entries whose ID already exists are skipped, so a failed import can be re-run")
+	continueOnError := fs.Bool("continue-on-error", true, "Report and skip malformed records instead of aborting (use --continue-on-error=false to abort)")
+	dir := fs.String("dir", "", "Import each .md and .txt file in this directory as one entry instead of reading an archive")
+	dateLayout := fs.String("date-layout", entry.DefaultImportDateLayout, "Go time layout of the date file names start with in --dir; other files are dated by their modification time")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal import <file> [flags]")
+		fmt.Println("       journal import --dir <directory> [flags]")
+		fmt.Println("\nImport entries from a JSON or markdown archive created by 'journal export'")
+		fmt.Println("Use - as the file to read from stdin (requires --format)")
+		fmt.Println("With --dir, import a directory of notes with one entry per .md or .txt file")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal import backup.json -j personal")
+		fmt.Println("  journal import notes.md -j work --preserve-ids")
+		fmt.Println("  journal import backup.json --continue-on-error=false")
+		fmt.Println("  journal import --dir ~/old-diary -j personal")
+		fmt.Println("  journal import --dir notes --date-layout 20060102")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *dir != "" {
+		if fs.NArg() != 0 {
+			if _, err := fmt.Fprintf(os.Stderr, "Error: give either an import file or --dir\n\n"); err != nil {
+				return 1
+			}
+			fs.Usage()
+			return 1
+		}
+		return runImportDir(*journalName, *dir, *dateLayout)
+	}
+
+	if fs.NArg() != 1 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: import file is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	inputPath := fs.Arg(0)
+	importFormat := *format
+	if importFormat == "" {
+		importFormat = importFormatFromPath(inputPath)
+	}
+	if importFormat != entry.ExportFormatJSON && importFormat != entry.ExportFormatMarkdown {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: cannot determine import format for '%s' (use --format json or markdown)\n\n", inputPath); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	var r io.Reader = os.Stdin
+	if inputPath != "-" {
+		f, err := os.Open(inputPath)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to open import file: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		r = f
+	}
+
+	// progressErr keeps the first failed progress write, so the command can still exit non-zero
+	var progressErr error
+	opts := entry.ImportOptions{
+		PreserveIDs:      *preserveIDs,
+		AbortOnMalformed: !*continueOnError,
+		Progress: func(done, total int) {
+			if progressErr == nil {
+				_, progressErr = fmt.Printf("[%d/%d] records processed\n", done, total)
+			}
+		},
+		ProgressInterval: importProgressInterval,
+	}
+
+	result, err := j.ImportWithOptions(r, importFormat, opts)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to import entries: %v\n", err); ferr != nil {
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Imported %d entries before the failure\n", result.Imported); ferr != nil {
+			return 1
+		}
+		if result.Imported > 0 {
+			hint := "Re-running with --preserve-ids skips them"
+			if !*preserveIDs {
+				hint = "They were given new IDs, so re-running would import them again"
+			}
+			if _, ferr := fmt.Fprintln(os.Stderr, hint); ferr != nil {
+				return 1
+			}
+		}
+		return 1
+	}
+	if progressErr != nil {
+		return 1
+	}
+
+	if _, err := fmt.Printf("Imported %d entries\n", result.Imported); err != nil {
+		return 1
+	}
+	if result.Existing > 0 {
+		if _, err := fmt.Printf("Skipped %d entries that already exist\n", result.Existing); err != nil {
+			return 1
+		}
+	}
+	if result.Malformed > 0 {
+		if _, err := fmt.Printf("Skipped %d malformed records\n", result.Malformed); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// runImportDir imports a directory of notes, printing the outcome of every file
+func runImportDir(journalName, dir, dateLayout string) int {
+	info, err := os.Stat(dir)
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s is not a directory", dir)
+	}
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to open import directory: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	j, _, err := openJournal(journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	opts := entry.ImportDirOptions{
+		DateLayout: dateLayout,
+		Report: func(file entry.ImportedFile) error {
+			var err error
+			switch {
+			case file.Skipped:
+				_, err = fmt.Printf("Skipped %s (not .md or .txt)\n", file.Path)
+			case file.Err != nil:
+				_, err = fmt.Fprintf(os.Stderr, "Failed %s: %v\n", file.Path, file.Err)
+			default:
+				_, err = fmt.Printf("Imported %s as %s (%s)\n", file.Path, colorID(file.ID), colorDate(file.Date.Format("2006-01-02 15:04")))
+			}
+			return err
+		},
+	}
+
+	result, err := j.ImportDir(dir, opts)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to import entries: %v\n", err); ferr != nil {
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Imported %d entries before the failure\n", result.Imported); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Imported %d entries, skipped %d files\n", result.Imported, result.Skipped); err != nil {
+		return 1
+	}
+	if result.Failed > 0 {
+		if _, err := fmt.Printf("Could not import %d files\n", result.Failed); err != nil {
+			return 1
+		}
+		return 1
+	}
+	return 0
+}
+
+// importFormatFromPath infers the import format from a file extension
+func importFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return entry.ExportFormatJSON
+	case ".md", ".markdown":
+		return entry.ExportFormatMarkdown
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunImport_Markdown(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	archive := `## 2024-11-19T14:30:00Z
+
+Tags: work
+
+Imported entry
+`
+	archivePath := filepath.Join(tmpDir, "archive.md")
+	if err := os.WriteFile(archivePath, []byte(archive), 0600); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	args := []string{"-j", "test", archivePath}
+	exitCode := runImport(args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	entries, err := j.SearchByTag("work")
+	if err != nil {
+		t.Fatalf("failed to search entries: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].GetContent() != "Imported entry" {
+		t.Errorf("expected imported entry to be searchable, got %d entries", len(entries))
+	}
+}
+
+func TestRunImport_UnknownFormat(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "")
+
+	archivePath := filepath.Join(tmpDir, "archive.txt")
+	if err := os.WriteFile(archivePath, []byte("text"), 0600); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	args := []string{"-j", "test", archivePath}
+	exitCode := runImport(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when format cannot be inferred")
+	}
+}
+
+func TestRunImport_MissingFile(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test"}
+	exitCode := runImport(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for missing import file")
+	}
+}
+
+func TestRunImport_ResumeWithPreserveIDs(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "")
+
+	archive := `[
+  {"id": "11111111-1111-1111-1111-111111111111", "date": "2024-11-19T14:30:00Z", "content": "First"},
+  {"id": "22222222-2222-2222-2222-222222222222", "date": "2024-11-20T14:30:00Z", "content": "Second"}
+]`
+	archivePath := filepath.Join(tmpDir, "archive.json")
+	if err := os.WriteFile(archivePath, []byte(archive), 0600); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	args := []string{"-j", "test", "--preserve-ids", archivePath}
+	if exitCode := runImport(args); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runImport(args)
+	})
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 on re-run, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Imported 0 entries") || !strings.Contains(output, "Skipped 2 entries that already exist") {
+		t.Errorf("expected re-run to skip existing entries, got:\n%s", output)
+	}
+	if !strings.Contains(output, "[2/2] records processed") {
+		t.Errorf("expected a final progress line, got:\n%s", output)
+	}
+}
+
+func TestRunImport_AbortOnMalformed(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	archive := `[
+  {"date": "2024-11-19T14:30:00Z", "content": "Valid"},
+  {"content": "Missing date"}
+]`
+	archivePath := filepath.Join(tmpDir, "archive.json")
+	if err := os.WriteFile(archivePath, []byte(archive), 0600); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+
+	args := []string{"-j", "test", "--continue-on-error=false", archivePath}
+	if exitCode := runImport(args); exitCode == 0 {
+		t.Error("expected non-zero exit code for malformed record")
+	}
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if j.Count() != 0 {
+		t.Errorf("expected nothing imported, got %d entries", j.Count())
+	}
+}
+
+func TestRunImport_Dir(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	notesDir := filepath.Join(tmpDir, "notes")
+	if err := os.MkdirAll(notesDir, 0700); err != nil {
+		t.Fatalf("failed to create notes directory: %v", err)
+	}
+	for name, content := range map[string]string{
+		"2024-11-19.md":  "Monday notes",
+		"2024-11-20.txt": "Tuesday notes",
+		"cover.png":      "image",
+	} {
+		if err := os.WriteFile(filepath.Join(notesDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runImport([]string{"-j", "test", "--dir", notesDir})
+	})
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	for _, want := range []string{"Imported 2024-11-19.md as", "Imported 2024-11-20.txt as", "Skipped cover.png", "Imported 2 entries, skipped 1 files"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if count := j.Count(); count != 2 {
+		t.Errorf("expected 2 entries, got %d", count)
+	}
+}
+
+func TestRunImport_DirWithFile(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "")
+
+	exitCode := runImport([]string{"-j", "test", "--dir", tmpDir, "backup.json"})
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when both a file and --dir are given")
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/storage"
+	"github.com/data-castle/journal/pkg/models"
+)
+
+func runIndex(args []string) int {
+	if len(args) < 1 {
+		printIndexUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "dump":
+		return runIndexDump(args[1:])
+	case "stats":
+		return runIndexStats(args[1:])
+	case "help", "-h", "--help":
+		printIndexUsage()
+		return 0
+	default:
+		if _, err := fmt.Fprintf(os.Stderr, "Unknown index command: %s\n\n", args[0]); err != nil {
+			return 1
+		}
+		printIndexUsage()
+		return 1
+	}
+}
+
+func printIndexUsage() {
+	fmt.Println(`Usage: journal index <command> [flags]
+
+Inspect the encrypted search index, e.g. to troubleshoot drift from the entry files
+Use 'journal rebuild' to regenerate it
+
+Available Commands:
+  dump              Print the decrypted index as JSON
+  stats             Show the number of entries, date buckets, and tag buckets in the index`)
+}
+
+func runIndexDump(args []string) int {
+	fs := flag.NewFlagSet("index dump", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal index dump [flags]")
+		fmt.Printf("\nDecrypt %s and print it as JSON\n", storage.IndexFileName)
+		fmt.Println("The output holds every entry's metadata in plaintext, including titles and tags")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	index, _, err := loadIndex(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	data, err := index.ToJSON()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to encode index: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	if _, err := fmt.Println(string(data)); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runIndexStats(args []string) int {
+	fs := flag.NewFlagSet("index stats", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal index stats [flags]")
+		fmt.Println("\nShow the number of entries, date buckets, and tag buckets in the index")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	index, journalCfg, err := loadIndex(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if _, err := fmt.Printf("Index of journal '%s' (version %s)\n", journalCfg.Name, index.Version); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Entries:      %d\n", len(index.Entries)); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Date buckets: %d\n", len(index.ByDate)); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Tag buckets:  %d\n", len(index.ByTag)); err != nil {
+		return 1
+	}
+
+	if errs := index.Validate(); len(errs) > 0 {
+		if _, err := fmt.Printf("Inconsistencies: %d (run 'journal rebuild -j %s' to fix)\n", len(errs), journalCfg.Name); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// loadIndex decrypts the index of the specified (or default) journal without opening the journal,
+// so nothing but the index is read
+func loadIndex(journalName string) (*models.Index, *config.Journal, error) {
+	journalCfg, err := resolveJournalConfig(journalName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !journalCfg.IndexEnabled() {
+		return nil, nil, fmt.Errorf("journal '%s' keeps no index (use_index is false)", journalCfg.Name)
+	}
+
+	store, err := storage.NewStorage(journalCfg.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	if err := store.SetKeyFiles(journalCfg.KeyFiles); err != nil {
+		return nil, nil, fmt.Errorf("failed to load key files: %w", err)
+	}
+
+	// Inconsistencies are counted by the caller
+	index, _, err := store.LoadIndex()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load index: %w", err)
+	}
+	return index, journalCfg, nil
+}
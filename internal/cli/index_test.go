@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
+)
+
+func TestRunIndexDump(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Indexed entry", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runIndex([]string{"dump", "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	index, err := models.FromJSON([]byte(output))
+	if err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", output, err)
+	}
+	if _, ok := index.GetMetadata(ent.GetID()); !ok || len(index.ByTag["work"]) != 1 {
+		t.Errorf("expected the entry in the dumped index, got %q", output)
+	}
+}
+
+func TestRunIndexStats(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("First", []string{"work", "meeting"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Second", []string{"work"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runIndex([]string{"stats", "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	for _, want := range []string{"Entries:      2\n", "Date buckets: 1\n", "Tag buckets:  2\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "Inconsistencies") {
+		t.Errorf("expected a consistent index, got %q", output)
+	}
+}
+
+func TestRunIndexStats_NoIndex(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runConfig([]string{"set", "journals.test.use_index", "false"}); exitCode != 0 {
+		t.Fatalf("failed to disable the index, exit code %d", exitCode)
+	}
+
+	var exitCode int
+	output := captureStderr(t, func() {
+		exitCode = runIndex([]string{"stats", "-j", "test"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for a journal without an index")
+	}
+	if !strings.Contains(output, "keeps no index") {
+		t.Errorf("expected an explanation, got %q", output)
+	}
+}
+
+func TestRunIndex_UnknownCommand(t *testing.T) {
+	if exitCode := runIndex([]string{"compact"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an unknown index command")
+	}
+}
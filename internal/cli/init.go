@@ -1,31 +1,46 @@
 package cli
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/crypto"
 	"github.com/data-castle/journal/internal/entry"
 )
 
-func runInit(args []string) int {
+func runInit(s session, args []string) int {
 	fs := flag.NewFlagSet("init", flag.ExitOnError)
 	name := fs.String("name", "", "Journal name (required)")
 	fs.StringVar(name, "n", "", "Journal name (shorthand)")
-	path := fs.String("path", "", "Custom path for journal (required)")
+	path := fs.String("path", "", "Custom path for journal (default: <journals_dir>/<name>)")
 	fs.StringVar(path, "p", "", "Custom path for journal (shorthand)")
-	recipients := fs.String("recipients", "", "Age public keys (comma-separated, required)")
-	fs.StringVar(recipients, "r", "", "Age public keys (shorthand)")
+	recipients := fs.String("recipients", "", "Age or ssh public keys (comma-separated; default: the config's default recipients)")
+	fs.StringVar(recipients, "r", "", "Age or ssh public keys (shorthand)")
+	generateKey := fs.Bool("generate-key", false, "Generate a new age key and use it as the only recipient")
+	keyOut := fs.String("key-out", "", "Where --generate-key saves the private key (default: asked, or keys/<name>.txt next to the config)")
+	defaultTags := fs.String("default-tags", "", "Tags added to every new entry (comma-separated)")
+	noIndex := fs.Bool("no-index", false, "Keep no index and decrypt every entry file on each run instead; slower for large journals")
 	fs.Usage = func() {
-		fmt.Println("Usage: journal init --name <name> --path <path> --recipients <keys>")
+		fmt.Println("Usage: journal init --name <name> [--path <path>] --recipients <keys>")
+		fmt.Println("       journal init --name <name> [--path <path>] --generate-key [--key-out <file>]")
 		fmt.Println("\nInitialize a new journal with SOPS encryption")
+		fmt.Println("Without --path the journal is created in <journals_dir>/<name>; journals_dir defaults to")
+		fmt.Printf("~/.journal/%s and can be changed with 'journal config set journals_dir <dir>'\n", config.DefaultJournalsDirName)
+		fmt.Println("With --generate-key a new age key is created for the journal, so no separate")
+		fmt.Println("age-keygen step is needed; an existing key file is never overwritten")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
 		fmt.Println("\nExample:")
 		fmt.Println("  journal init -n work -p ~/work-journal -r age1key1...,age1key2...")
+		fmt.Println("  journal init -n notes -r age1key1...")
+		fmt.Println("  journal init -n work -p ~/work-journal -r age1key1... --default-tags work")
+		fmt.Println("  journal init -n personal -p ~/journal --generate-key --key-out ~/.config/sops/age/journal.txt")
 	}
 	if err := fs.Parse(args); err != nil {
 		return 1
@@ -38,52 +53,93 @@ func runInit(args []string) int {
 		fs.Usage()
 		return 1
 	}
-	if *path == "" {
-		if _, err := fmt.Fprintf(os.Stderr, "Error: --path is required\n\n"); err != nil {
+	switch {
+	case *generateKey && *recipients != "":
+		if _, err := fmt.Fprintf(os.Stderr, "Error: give either --recipients or --generate-key, not both\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	case *keyOut != "" && !*generateKey:
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --key-out requires --generate-key\n\n"); err != nil {
 			return 1
 		}
 		fs.Usage()
 		return 1
 	}
-	if *recipients == "" {
-		if _, err := fmt.Fprintf(os.Stderr, "Error: --recipients is required\n\n"); err != nil {
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if !*generateKey && *recipients == "" && len(cfg.DefaultRecipients) == 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --recipients is required (or use --generate-key to create a new key,\nor 'journal config set-recipients' to set default recipients)\n\n"); err != nil {
 			return 1
 		}
 		fs.Usage()
 		return 1
 	}
 
-	recipientKeys := strings.Split(*recipients, ",")
-	for i := range recipientKeys {
-		recipientKeys[i] = strings.TrimSpace(recipientKeys[i])
+	journalPath, err := initJournalPath(cfg, *name, *path)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
 	}
 
-	journalPath := *path
-	if strings.HasPrefix(journalPath, "~") {
-		homeDir, err := os.UserHomeDir()
+	var recipientKeys []string
+	var keyPath string
+	if *generateKey {
+		keyPath, err = generatedKeyPath(*name, *keyOut)
 		if err != nil {
-			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get home directory: %v\n", err); ferr != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 				return 1
 			}
 			return 1
 		}
-		journalPath = filepath.Join(homeDir, journalPath[1:])
-	}
 
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+		recipient, err := crypto.GenerateKeyFile(keyPath)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to generate key: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		recipientKeys = []string{recipient}
+	} else if *recipients != "" {
+		recipientKeys = strings.Split(*recipients, ",")
+		for i := range recipientKeys {
+			recipientKeys[i] = strings.TrimSpace(recipientKeys[i])
+		}
+	} else {
+		recipientKeys = cfg.DefaultRecipients
+		if err := s.infof("Using %d default recipients from the config\n", len(recipientKeys)); err != nil {
 			return 1
 		}
-		return 1
 	}
 
 	journalCfg := &config.Journal{
 		Name: *name,
 		Path: journalPath,
 	}
+	if *defaultTags != "" {
+		journalCfg.DefaultTags = uniqueTagList([]string{*defaultTags})
+	}
+	if *noIndex {
+		useIndex := false
+		journalCfg.UseIndex = &useIndex
+	}
 
 	if err := entry.InitializeJournal(journalCfg, recipientKeys); err != nil {
+		// Nothing was encrypted for the generated key, so it is removed to allow a clean retry
+		if keyPath != "" {
+			_ = os.Remove(keyPath)
+		}
 		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to initialize journal: %v\n", err); ferr != nil {
 			return 1
 		}
@@ -100,6 +156,12 @@ func runInit(args []string) int {
 		}
 		// Update the existing journal's path
 		existingJournal.Path = journalPath
+		if *defaultTags != "" {
+			existingJournal.DefaultTags = journalCfg.DefaultTags
+		}
+		if *noIndex {
+			existingJournal.UseIndex = journalCfg.UseIndex
+		}
 	} else {
 		// Add new journal
 		if err := cfg.AddJournal(journalCfg); err != nil {
@@ -117,26 +179,100 @@ func runInit(args []string) int {
 		return 1
 	}
 
-	if _, err := fmt.Printf("Journal '%s' initialized at %s\n", *name, journalPath); err != nil {
+	if err := s.infof("Journal '%s' initialized at %s\n", *name, journalPath); err != nil {
 		return 1
 	}
-	if _, err := fmt.Printf("Recipients: %d\n", len(recipientKeys)); err != nil {
+	if err := s.infof("Recipients: %d\n", len(recipientKeys)); err != nil {
 		return 1
 	}
-	if _, err := fmt.Println("\nNext steps:"); err != nil {
+	if keyPath != "" {
+		if err := s.infof("Generated age key: %s\n", keyPath); err != nil {
+			return 1
+		}
+		if err := s.infof("Public key: %s\n", recipientKeys[0]); err != nil {
+			return 1
+		}
+	}
+	if err := s.infoln("\nNext steps:"); err != nil {
 		return 1
 	}
-	if _, err := fmt.Println("1. Ensure SOPS_AGE_KEY_FILE environment variable is set"); err != nil {
+	if keyPath != "" {
+		if err := s.infoln("1. Point SOPS_AGE_KEY_FILE at the new key, e.g. in your shell profile:"); err != nil {
+			return 1
+		}
+		if err := s.infof("   export SOPS_AGE_KEY_FILE=%q\n", keyPath); err != nil {
+			return 1
+		}
+		if err := s.infoln("   Back up the key file: without it the journal cannot be decrypted"); err != nil {
+			return 1
+		}
+	} else if err := s.infoln("1. Ensure SOPS_AGE_KEY_FILE environment variable is set"); err != nil {
 		return 1
 	}
-	if _, err := fmt.Println("2. (Optional) Initialize git:"); err != nil {
+	if err := s.infoln("2. (Optional) Initialize git:"); err != nil {
 		return 1
 	}
-	if _, err := fmt.Printf("   cd %s && git init\n", journalPath); err != nil {
+	if err := s.infof("   cd %s && git init\n", journalPath); err != nil {
 		return 1
 	}
-	if _, err := fmt.Printf("3. Start adding entries: journal add \"Your first entry\"\n"); err != nil {
+	if err := s.infof("3. Start adding entries: journal add \"Your first entry\"\n"); err != nil {
 		return 1
 	}
 	return 0
 }
+
+// initJournalPath returns where init creates the journal name: path if given, else
+// <journals_dir>/<name>, with journals_dir defaulting to config.DefaultJournalsDirName next to the config
+func initJournalPath(cfg *config.Config, name, path string) (string, error) {
+	if path != "" {
+		return expandHome(path)
+	}
+
+	// The name becomes a directory, so it must not lead outside the journals directory
+	if !filepath.IsLocal(name) || filepath.Base(name) != name {
+		return "", fmt.Errorf("journal name %q cannot be used as a directory name, give --path", name)
+	}
+
+	journalsDir, err := expandHome(cfg.JournalsDir)
+	if err != nil {
+		return "", err
+	}
+	if journalsDir == "" {
+		configPath, err := config.GetConfigPath()
+		if err != nil {
+			return "", err
+		}
+		journalsDir = filepath.Join(filepath.Dir(configPath), config.DefaultJournalsDirName)
+	}
+	return filepath.Join(journalsDir, name), nil
+}
+
+// generatedKeyPath returns where init --generate-key saves the key for the journal name: keyOut if given,
+// else the path the user enters at a prompt, else keys/<name>.txt in the config directory
+func generatedKeyPath(name, keyOut string) (string, error) {
+	if keyOut != "" {
+		return expandHome(keyOut)
+	}
+
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	defaultPath := filepath.Join(filepath.Dir(configPath), "keys", name+".txt")
+
+	if !stdinIsTerminal() {
+		return defaultPath, nil
+	}
+
+	if _, err := fmt.Printf("Save the new age key to [%s]: ", defaultPath); err != nil {
+		return "", err
+	}
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultPath, nil
+	}
+	return expandHome(answer)
+}
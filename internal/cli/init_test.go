@@ -8,6 +8,8 @@ import (
 
 	"filippo.io/age"
 	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/storage"
 )
 
 func TestRunInit_Success(t *testing.T) {
@@ -26,7 +28,7 @@ func TestRunInit_Success(t *testing.T) {
 		"--recipients", publicKey,
 	}
 
-	exitCode := runInit(args)
+	exitCode := runInit(session{}, args)
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
@@ -55,6 +57,64 @@ func TestRunInit_Success(t *testing.T) {
 	}
 }
 
+func TestRunInit_DefaultTags(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	args := []string{
+		"--name", "work",
+		"--path", filepath.Join(tmpDir, "work-journal"),
+		"--recipients", identity.Recipient().String(),
+		"--default-tags", "work, ,work,office",
+	}
+	if exitCode := runInit(session{}, args); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if got := strings.Join(cfg.Journals["work"].DefaultTags, ","); got != "work,office" {
+		t.Errorf("expected default tags work,office, got %s", got)
+	}
+}
+
+func TestRunInit_NoIndex(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	journalPath := filepath.Join(tmpDir, "work-journal")
+	args := []string{
+		"--name", "work",
+		"--path", journalPath,
+		"--recipients", identity.Recipient().String(),
+		"--no-index",
+	}
+	if exitCode := runInit(session{}, args); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if cfg.Journals["work"].IndexEnabled() {
+		t.Error("expected the journal to be configured without an index")
+	}
+	if _, err := os.Stat(filepath.Join(journalPath, storage.IndexFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected no index file, got %v", err)
+	}
+}
+
 func TestRunInit_MissingName(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -69,44 +129,114 @@ func TestRunInit_MissingName(t *testing.T) {
 		"--recipients", publicKey,
 	}
 
-	exitCode := runInit(args)
+	exitCode := runInit(session{}, args)
 	if exitCode == 0 {
 		t.Error("expected non-zero exit code for missing name")
 	}
 }
 
-func TestRunInit_MissingPath(t *testing.T) {
+func TestRunInit_DefaultPath(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+
 	identity, err := age.GenerateX25519Identity()
 	if err != nil {
 		t.Fatalf("failed to generate identity: %v", err)
 	}
 	publicKey := identity.Recipient().String()
 
-	args := []string{
-		"--name", "test",
-		"--recipients", publicKey,
+	if exitCode := runInit(session{}, []string{"--name", "work", "--recipients", publicKey}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	defaultPath := filepath.Join(tmpDir, config.DefaultJournalsDirName, "work")
+	if _, err := os.Stat(filepath.Join(defaultPath, ".sops.yaml")); err != nil {
+		t.Errorf("expected journal in the default journals directory: %v", err)
 	}
 
-	exitCode := runInit(args)
-	if exitCode == 0 {
-		t.Error("expected non-zero exit code for missing path")
+	journalsDir := filepath.Join(tmpDir, "stores")
+	if exitCode := runConfig([]string{"set", "journals_dir", journalsDir}); exitCode != 0 {
+		t.Fatalf("expected exit code 0 from config set, got %d", exitCode)
+	}
+	if exitCode := runInit(session{}, []string{"--name", "personal", "--recipients", publicKey}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	for name, want := range map[string]string{"work": defaultPath, "personal": filepath.Join(journalsDir, "personal")} {
+		journalCfg, err := cfg.GetJournal(name)
+		if err != nil {
+			t.Fatalf("failed to get journal %s: %v", name, err)
+		}
+		if journalCfg.Path != want {
+			t.Errorf("expected journal %s at %s, got %s", name, want, journalCfg.Path)
+		}
+	}
+}
+
+func TestRunInit_DefaultPathInvalidName(t *testing.T) {
+	setupTestConfig(t)
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	for _, name := range []string{"../escape", "team/work"} {
+		if exitCode := runInit(session{}, []string{"--name", name, "--recipients", identity.Recipient().String()}); exitCode == 0 {
+			t.Errorf("expected non-zero exit code for journal name %q without --path", name)
+		}
 	}
 }
 
 func TestRunInit_MissingRecipients(t *testing.T) {
-	tmpDir := t.TempDir()
+	tmpDir, _ := setupTestConfig(t)
 
 	args := []string{
 		"--name", "test",
 		"--path", filepath.Join(tmpDir, "test-journal"),
 	}
 
-	exitCode := runInit(args)
+	exitCode := runInit(session{}, args)
 	if exitCode == 0 {
 		t.Error("expected non-zero exit code for missing recipients")
 	}
 }
 
+func TestRunInit_DefaultRecipients(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	publicKey := identity.Recipient().String()
+
+	if exitCode := runConfig([]string{"set-recipients", publicKey}); exitCode != 0 {
+		t.Fatalf("expected exit code 0 from config set-recipients, got %d", exitCode)
+	}
+
+	journalPath := filepath.Join(tmpDir, "test-journal")
+	args := []string{
+		"--name", "test",
+		"--path", journalPath,
+	}
+
+	exitCode := runInit(session{}, args)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	recipients, err := crypto.ReadSOPSConfig(journalPath)
+	if err != nil {
+		t.Fatalf("failed to read .sops.yaml: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != publicKey {
+		t.Errorf("expected default recipient %s, got %v", publicKey, recipients)
+	}
+}
+
 func TestRunInit_MultipleRecipients(t *testing.T) {
 	tmpDir, _ := setupTestConfig(t)
 
@@ -129,7 +259,7 @@ func TestRunInit_MultipleRecipients(t *testing.T) {
 		"--recipients", publicKey1 + "," + publicKey2,
 	}
 
-	exitCode := runInit(args)
+	exitCode := runInit(session{}, args)
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
@@ -147,3 +277,63 @@ func TestRunInit_MultipleRecipients(t *testing.T) {
 		t.Error("second recipient not found in .sops.yaml")
 	}
 }
+
+func TestRunInit_GenerateKey(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+
+	journalPath := filepath.Join(tmpDir, "test-journal")
+	keyPath := filepath.Join(tmpDir, "age", "key.txt")
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runInit(session{}, []string{"-n", "test", "-p", journalPath, "--generate-key", "--key-out", keyPath})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	own, err := crypto.IdentityRecipients([]string{keyPath})
+	if err != nil {
+		t.Fatalf("failed to read generated key: %v", err)
+	}
+	recipients, err := crypto.ReadSOPSConfig(journalPath)
+	if err != nil {
+		t.Fatalf("failed to read recipients: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != own[0] {
+		t.Errorf("expected the generated key to be the only recipient, got %v", recipients)
+	}
+	if !strings.Contains(output, "export SOPS_AGE_KEY_FILE=") || !strings.Contains(output, keyPath) {
+		t.Errorf("expected next steps to set SOPS_AGE_KEY_FILE, got %q", output)
+	}
+
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+	if exitCode := runAdd(session{}, []string{"-j", "test", "First entry"}); exitCode != 0 {
+		t.Errorf("expected the generated key to open the journal, got exit code %d", exitCode)
+	}
+}
+
+func TestRunInit_GenerateKeyDefaultPath(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+	setStdin(t, "\n")
+
+	journalPath := filepath.Join(tmpDir, "test-journal")
+	captureStdout(t, func() {
+		if exitCode := runInit(session{}, []string{"-n", "test", "-p", journalPath, "--generate-key"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "keys", "test.txt")); err != nil {
+		t.Errorf("expected the key in the config directory: %v", err)
+	}
+}
+
+func TestRunInit_GenerateKeyWithRecipients(t *testing.T) {
+	tmpDir, _ := setupTestConfig(t)
+
+	args := []string{"-n", "test", "-p", filepath.Join(tmpDir, "j"), "--generate-key", "-r", "age1abc"}
+	if exitCode := runInit(session{}, args); exitCode == 0 {
+		t.Error("expected non-zero exit code when combining --generate-key and --recipients")
+	}
+}
@@ -1,8 +1,14 @@
 package cli
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/data-castle/journal/internal/config"
 	"github.com/data-castle/journal/internal/crypto"
@@ -90,3 +96,315 @@ func runSetDefault(args []string) int {
 	}
 	return 0
 }
+
+func runRenameJournal(args []string) int {
+	fs := flag.NewFlagSet("rename", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: journal rename <old-name> <new-name>")
+		fmt.Println("\nRename a journal in the config; its files are not touched")
+		fmt.Println("If it was the default journal, it stays the default")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() != 2 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: old and new journal names are required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	oldName, newName := fs.Arg(0), fs.Arg(1)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := cfg.RenameJournal(oldName, newName); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to rename journal: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := cfg.Save(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Renamed journal '%s' to '%s'\n", oldName, newName); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runRelocateJournal(args []string) int {
+	fs := flag.NewFlagSet("relocate", flag.ExitOnError)
+	newPath := fs.String("path", "", "New directory for the journal (required, must not exist)")
+	fs.StringVar(newPath, "p", "", "New directory for the journal (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal relocate <name> --path <new-path>")
+		fmt.Println("\nMove a journal's directory on disk and update its path in the config")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() == 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: journal name is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	name := fs.Arg(0)
+
+	// Allow flags after the name, as in the usage line
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return 1
+	}
+	if fs.NArg() != 0 || *newPath == "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one journal name and --path are required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	dest, err := expandHome(*newPath)
+	if err == nil {
+		dest, err = filepath.Abs(dest)
+	}
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	journalCfg, err := cfg.GetJournal(name)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get journal: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	src := journalCfg.Path
+
+	if err := moveJournalDir(src, dest); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to move journal: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	journalCfg.Path = dest
+	if err := cfg.Save(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err); ferr != nil {
+			return 1
+		}
+		// Put the directory back so the unchanged config still points at it
+		if moveErr := moveJournalDir(dest, src); moveErr != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to move journal back to %s: %v\n", src, moveErr); ferr != nil {
+				return 1
+			}
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Moved journal '%s' from %s to %s\n", name, src, dest); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// moveJournalDir moves a journal directory to dest, which must not exist yet
+// Moves across file systems fall back to copying the tree and removing the original
+func moveJournalDir(src, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("destination already exists: %s", dest)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to check destination: %w", err)
+	}
+
+	if rel, err := filepath.Rel(src, dest); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("destination %s is inside the journal directory", dest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	} else if !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("failed to move directory: %w", err)
+	}
+
+	if err := os.CopyFS(dest, os.DirFS(src)); err != nil {
+		return errors.Join(fmt.Errorf("failed to copy journal: %w", err), os.RemoveAll(dest))
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("copied journal but failed to remove the original: %w", err)
+	}
+	return nil
+}
+
+func runRemoveJournal(args []string) int {
+	fs := flag.NewFlagSet("remove", flag.ExitOnError)
+	deleteFiles := fs.Bool("delete-files", false, "Also delete the journal directory and all its encrypted files")
+	yes := fs.Bool("yes", false, "Do not ask for confirmation before deleting files")
+	fs.BoolVar(yes, "y", false, "Do not ask for confirmation (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal remove <name> [flags]")
+		fmt.Println("\nRemove a journal from the config; its files are kept unless --delete-files is given")
+		fmt.Println("The default journal cannot be removed; use set-default to pick another one first")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() == 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: journal name is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	name := fs.Arg(0)
+
+	// Allow flags after the name, as in the usage line
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return 1
+	}
+	if fs.NArg() != 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one journal name is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	journalCfg, err := cfg.GetJournal(name)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get journal: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	path := journalCfg.Path
+
+	// Removed in memory first, so the default journal guard fails before any prompt
+	if err := cfg.RemoveJournal(name); err != nil {
+		if errors.Is(err, config.ErrRemoveDefault) {
+			if err := printRemoveDefaultHint(cfg, name); err != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to remove journal: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if *deleteFiles {
+		// Refuse to recursively delete a directory that does not look like a journal
+		if _, err := os.Stat(filepath.Join(path, ".sops.yaml")); err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Refusing to delete %s: it does not contain a .sops.yaml, so it may not be a journal\n", path); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+
+		if !*yes {
+			ok, err := confirm(fmt.Sprintf("Permanently delete journal '%s' and every file in %s?", name, path))
+			if err != nil {
+				if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+					return 1
+				}
+				return 1
+			}
+			if !ok {
+				if _, err := fmt.Println("Aborted; nothing was removed"); err != nil {
+					return 1
+				}
+				return 1
+			}
+		}
+	}
+
+	if err := cfg.Save(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Removed journal '%s' from config\n", name); err != nil {
+		return 1
+	}
+
+	if !*deleteFiles {
+		if _, err := fmt.Printf("Files were kept in %s\n", path); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to delete journal files: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Deleted %s\n", path); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// printRemoveDefaultHint explains on stderr how to remove the default journal
+func printRemoveDefaultHint(cfg *config.Config, name string) error {
+	if _, err := fmt.Fprintf(os.Stderr, "Cannot remove '%s' because it is the default journal\n", name); err != nil {
+		return err
+	}
+
+	others := slices.DeleteFunc(cfg.ListJournals(), func(other string) bool { return other == name })
+	if len(others) == 0 {
+		_, err := fmt.Fprintln(os.Stderr, "It is the only configured journal; initialize another one with 'journal init' first")
+		return err
+	}
+
+	slices.Sort(others)
+	_, err := fmt.Fprintf(os.Stderr, "Make another journal the default first, e.g. 'journal set-default %s'\n", others[0])
+	return err
+}
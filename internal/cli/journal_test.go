@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/entry"
 )
 
 func TestRunListJournals_Empty(t *testing.T) {
@@ -71,3 +75,172 @@ func TestRunSetDefault_InvalidName(t *testing.T) {
 		t.Error("expected non-zero exit code for invalid journal name")
 	}
 }
+
+func TestRunRenameJournal(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	setupTestJournal(t, tmpDir, "work")
+
+	if exitCode := runRenameJournal([]string{"personal", "diary"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if _, err := cfg.GetJournal("diary"); err != nil {
+		t.Errorf("expected renamed journal in config: %v", err)
+	}
+	if cfg.DefaultJournal != "diary" {
+		t.Errorf("expected default journal diary, got %s", cfg.DefaultJournal)
+	}
+
+	if exitCode := runRenameJournal([]string{"diary", "work"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when the new name is taken")
+	}
+}
+
+func TestRunRelocateJournal(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry that moves", nil)
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	newPath := filepath.Join(tmpDir, "moved", "journal")
+	if exitCode := runRelocateJournal([]string{"test", "--path", newPath}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	if _, err := os.Stat(journalCfg.Path); !os.IsNotExist(err) {
+		t.Error("expected old journal directory to be gone")
+	}
+
+	j, _, err = openJournal("test")
+	if err != nil {
+		t.Fatalf("failed to open relocated journal: %v", err)
+	}
+	if _, err := j.Get(ent.GetID()); err != nil {
+		t.Errorf("expected entry to be readable after relocating: %v", err)
+	}
+}
+
+func TestRunRelocateJournal_DestinationExists(t *testing.T) {
+	tmpDir, journalCfg, _ := setupTestJournal(t, "", "")
+
+	existing := filepath.Join(tmpDir, "existing")
+	if err := os.Mkdir(existing, 0700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	if exitCode := runRelocateJournal([]string{"test", "--path", existing}); exitCode == 0 {
+		t.Error("expected non-zero exit code when the destination exists")
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, ".sops.yaml")); err != nil {
+		t.Errorf("expected journal to stay in place: %v", err)
+	}
+}
+
+func TestRunRemoveJournal_KeepsFiles(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	_, workCfg, _ := setupTestJournal(t, tmpDir, "work")
+
+	if exitCode := runRemoveJournal([]string{"work"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if _, err := cfg.GetJournal("work"); err == nil {
+		t.Error("expected journal to be removed from config")
+	}
+	if _, err := os.Stat(workCfg.Path); err != nil {
+		t.Errorf("expected journal files to be kept: %v", err)
+	}
+}
+
+func TestRunRemoveJournal_DeleteFiles(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	_, workCfg, _ := setupTestJournal(t, tmpDir, "work")
+
+	setStdin(t, "n\n")
+	if exitCode := runRemoveJournal([]string{"work", "--delete-files"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when the prompt is declined")
+	}
+	if _, err := os.Stat(workCfg.Path); err != nil {
+		t.Fatalf("expected journal files to be kept after declining: %v", err)
+	}
+
+	setStdin(t, "y\n")
+	if exitCode := runRemoveJournal([]string{"work", "--delete-files"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if _, err := os.Stat(workCfg.Path); !os.IsNotExist(err) {
+		t.Error("expected journal directory to be deleted")
+	}
+}
+
+func TestRunRemoveJournal_DeleteFilesWithYes(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	_, workCfg, _ := setupTestJournal(t, tmpDir, "work")
+
+	if exitCode := runRemoveJournal([]string{"--delete-files", "-y", "work"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if _, err := os.Stat(workCfg.Path); !os.IsNotExist(err) {
+		t.Error("expected journal directory to be deleted")
+	}
+}
+
+func TestRunRemoveJournal_Default(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "personal")
+
+	if exitCode := runRemoveJournal([]string{"personal", "--delete-files", "--yes"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when removing the default journal")
+	}
+	if _, err := os.Stat(journalCfg.Path); err != nil {
+		t.Errorf("expected default journal files to be kept: %v", err)
+	}
+}
+
+func TestRunRemoveJournal_DefaultSuggestsSetDefault(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	setupTestJournal(t, tmpDir, "work")
+
+	var exitCode int
+	stderr := captureStderr(t, func() {
+		exitCode = runRemoveJournal([]string{"personal"})
+	})
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when removing the default journal")
+	}
+	if !strings.Contains(stderr, "journal set-default work") {
+		t.Errorf("expected set-default suggestion, got %q", stderr)
+	}
+}
+
+func TestRun_DeleteJournalAlias(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	setupTestJournal(t, tmpDir, "work")
+
+	if exitCode := Run([]string{"journal", "delete-journal", "work"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if _, err := cfg.GetJournal("work"); err == nil {
+		t.Error("expected journal to be removed from config")
+	}
+}
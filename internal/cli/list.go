@@ -4,18 +4,41 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// Sort orders accepted by list --sort
+const (
+	listSortDateDesc = "date-desc"
+	listSortDateAsc  = "date-asc"
+	listSortWords    = "words"
 )
 
+var listSortKeys = []string{listSortDateDesc, listSortDateAsc, listSortWords}
+
 func runList(args []string) int {
 	fs := flag.NewFlagSet("list", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
 	count := fs.Int("count", 10, "Number of entries to show")
 	fs.IntVar(count, "n", 10, "Number of entries to show (shorthand)")
+	offset := fs.Int("offset", 0, "Number of entries to skip in the chosen order")
+	sortKey := fs.String("sort", listSortDateDesc, "Sort order: date-desc, date-asc, or words")
+	tag := fs.String("tag", "", "Only list entries with this tag")
+	noPager := fs.Bool("no-pager", false, "Do not page long output through $PAGER")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal list [flags]")
-		fmt.Println("\nList recent journal entries")
+		fmt.Println("\nList journal entries, newest first by default")
+		fmt.Println("Combine --offset with --count to page through the list, e.g. --offset 10 -n 10 shows entries 11-20")
+		fmt.Println("--tag filters by a tag using only the index, without decrypting entries")
+		fmt.Println("\nSort orders:")
+		fmt.Println("  date-desc  Newest first (default)")
+		fmt.Println("  date-asc   Oldest first")
+		fmt.Println("  words      Longest first; uses the indexed word counts, decrypting only entries indexed without one")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
 	}
@@ -23,15 +46,54 @@ func runList(args []string) int {
 		return 1
 	}
 
-	j, _, err := openJournal(*journalName)
+	// Long output goes through $PAGER on a terminal
+	defer startPager(*noPager)()
+
+	if *offset < 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --offset must not be negative\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	if !slices.Contains(listSortKeys, *sortKey) {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: unknown sort order %q (valid: %s)\n\n", *sortKey, strings.Join(listSortKeys, ", ")); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
 	if err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
-		return 1
+		return errorExitCode(err)
+	}
+
+	var metas []models.Metadata
+	if *tag != "" {
+		metas = j.ListByTag(*tag)
+	} else {
+		metas = j.ListAll()
 	}
 
-	metas := j.ListAll()
+	switch *sortKey {
+	case listSortDateAsc:
+		slices.Reverse(metas)
+	case listSortWords:
+		if err := sortByWordCount(j, metas); err != nil {
+			return 1
+		}
+	}
+
+	if *offset >= len(metas) {
+		metas = nil
+	} else {
+		metas = metas[*offset:]
+	}
 
 	if *count > 0 && *count < len(metas) {
 		metas = metas[:*count]
@@ -45,14 +107,49 @@ func runList(args []string) int {
 	}
 
 	for _, meta := range metas {
-		if _, err := fmt.Printf("\n[%s] %s\n", meta.Date.Format("2006-01-02 15:04"), meta.Id[:8]); err != nil {
+		if _, err := fmt.Printf("\n[%s] %s%s\n", colorDate(meta.Date.Format("2006-01-02 15:04")), colorID(meta.Id[:8]), titleSuffix(meta.Title)); err != nil {
 			return 1
 		}
 		if len(meta.Tags) > 0 {
-			if _, err := fmt.Printf("Tags: %s\n", strings.Join(meta.Tags, ", ")); err != nil {
+			if _, err := fmt.Printf("Tags: %s\n", colorTags(meta.Tags)); err != nil {
 				return 1
 			}
 		}
 	}
 	return 0
 }
+
+// sortByWordCount orders metas by the word count of their entries, longest first
+// Counts come from the index; only entries indexed before word counts existed are decrypted,
+// and those that fail to load are warned about and counted as empty. Equal counts keep their newest-first order
+// The only error returned is a failure to print a warning
+func sortByWordCount(j *entry.Journal, metas []models.Metadata) error {
+	words := make(map[string]int, len(metas))
+	for _, meta := range metas {
+		if meta.Words > 0 {
+			words[meta.Id] = meta.Words
+			continue
+		}
+		ent, err := j.Get(meta.Id)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Warning: failed to load entry %s: %v\n", meta.Id, err); ferr != nil {
+				return ferr
+			}
+			continue
+		}
+		words[meta.Id] = models.CountWords(ent.GetContent())
+	}
+
+	slices.SortStableFunc(metas, func(a, b models.Metadata) int {
+		return words[b.Id] - words[a.Id]
+	})
+	return nil
+}
+
+// titleSuffix formats a title for the end of an entry heading line, or "" without a title
+func titleSuffix(title string) string {
+	if title == "" {
+		return ""
+	}
+	return "  " + title
+}
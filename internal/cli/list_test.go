@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
 )
 
 func TestRunList_Success(t *testing.T) {
@@ -56,6 +61,50 @@ func TestRunList_WithCount(t *testing.T) {
 	}
 }
 
+func TestRunList_WithOffset(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		_, err = j.Add("Entry", []string{})
+		if err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"offset with count", []string{"-j", "test", "--offset", "1", "-n", "1"}},
+		{"offset to last entry", []string{"-j", "test", "--offset", "2"}},
+		{"offset past end", []string{"-j", "test", "--offset", "10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if exitCode := runList(tt.args); exitCode != 0 {
+				t.Errorf("expected exit code 0, got %d", exitCode)
+			}
+		})
+	}
+}
+
+func TestRunList_NegativeOffset(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"-j", "test", "--offset", "-1"}
+	exitCode := runList(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for negative offset")
+	}
+}
+
 func TestRunList_Empty(t *testing.T) {
 	setupTestJournal(t, "", "")
 
@@ -66,3 +115,140 @@ func TestRunList_Empty(t *testing.T) {
 		t.Errorf("expected exit code 0, got %d", exitCode)
 	}
 }
+
+func TestRunList_Sort(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	for i, content := range []string{"Oldest short", "Middle entry with the most words", "Newest"} {
+		if _, err := j.AddWithDate(content, nil, base.AddDate(0, 0, i)); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sort  string
+		first string
+	}{
+		{"date-desc", "Newest"},
+		{"date-asc", "Oldest short"},
+		{"words", "Middle entry with the most words"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			var exitCode int
+			output := captureStdout(t, func() {
+				exitCode = runList([]string{"-j", "test", "--sort", tt.sort, "-n", "1"})
+			})
+			if exitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d", exitCode)
+			}
+			if !strings.Contains(output, tt.first) {
+				t.Errorf("expected %q first, got %q", tt.first, output)
+			}
+		})
+	}
+}
+
+func TestRunList_SortWordsUsesIndex(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	base := time.Date(2024, 6, 1, 10, 0, 0, 0, time.Local)
+	var longest models.Entry
+	for i, content := range []string{"Oldest short", "Middle entry with the most words", "Newest"} {
+		ent, err := j.AddWithDate(content, nil, base.AddDate(0, 0, i))
+		if err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+		if i == 1 {
+			longest = ent
+		}
+	}
+
+	// The word count is indexed, so the entry file is never read
+	entryPath := filepath.Join(journalCfg.Path, "entries", longest.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	var exitCode int
+	var output string
+	stderr := captureStderr(t, func() {
+		output = captureStdout(t, func() {
+			exitCode = runList([]string{"-j", "test", "--sort", "words", "-n", "1"})
+		})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, longest.GetID()[:8]) {
+		t.Errorf("expected the longest entry first, got %q", output)
+	}
+	if stderr != "" {
+		t.Errorf("expected no entry to be decrypted, got %q", stderr)
+	}
+}
+
+func TestRunList_UnknownSort(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runList([]string{"-j", "test", "--sort", "tags"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for unknown sort order")
+	}
+}
+
+func TestRunList_Tag(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	var workIDs []string
+	for _, tags := range [][]string{{"work"}, {"personal"}, {"work", "personal"}, {"work"}} {
+		ent, err := j.Add("Entry", tags)
+		if err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+		if tags[0] == "work" {
+			workIDs = append(workIDs, ent.GetID()[:8])
+		}
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runList([]string{"-j", "test", "--tag", "work", "-n", "2", "--offset", "1"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	// Newest first, so --offset 1 skips the last work entry added
+	if strings.Count(output, "Tags:") != 2 {
+		t.Errorf("expected 2 listed entries, got %q", output)
+	}
+	if !strings.Contains(output, workIDs[0]) || !strings.Contains(output, workIDs[1]) || strings.Contains(output, workIDs[2]) {
+		t.Errorf("expected the two older work entries, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runList([]string{"-j", "test", "--tag", "missing"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "No entries found") {
+		t.Errorf("expected no entries for an unknown tag, got %q", output)
+	}
+}
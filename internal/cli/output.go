@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+)
+
+// session holds the global settings of one run that commands take beyond their own flags
+type session struct {
+	quiet bool // --quiet: suppress informational output
+}
+
+// infof prints informational output to stdout unless the session is quiet
+func (s session) infof(format string, a ...any) error {
+	if s.quiet {
+		return nil
+	}
+	_, err := fmt.Printf(format, a...)
+	return err
+}
+
+// infoln prints an informational line to stdout unless the session is quiet
+func (s session) infoln(a ...any) error {
+	if s.quiet {
+		return nil
+	}
+	_, err := fmt.Println(a...)
+	return err
+}
+
+// extractQuietFlag removes the global -q/--quiet flag from args and reports whether it was given
+// Like --config, the flag may appear anywhere before a "--" terminator
+func extractQuietFlag(args []string) (bool, []string) {
+	quiet := false
+	rest := make([]string, 0, len(args))
+
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		switch arg {
+		case "-q", "--quiet", "-quiet":
+			quiet = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return quiet, rest
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractQuietFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantQuiet bool
+		wantRest  []string
+	}{
+		{"none", []string{"journal", "add", "text"}, false, []string{"journal", "add", "text"}},
+		{"long before command", []string{"journal", "--quiet", "add", "text"}, true, []string{"journal", "add", "text"}},
+		{"short after command", []string{"journal", "add", "-q", "text"}, true, []string{"journal", "add", "text"}},
+		{"after terminator", []string{"journal", "add", "--", "-q"}, false, []string{"journal", "add", "--", "-q"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiet, rest := extractQuietFlag(tt.args)
+			if quiet != tt.wantQuiet {
+				t.Errorf("expected quiet %v, got %v", tt.wantQuiet, quiet)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("expected args %v, got %v", tt.wantRest, rest)
+			}
+		})
+	}
+}
+
+func TestSessionInfo(t *testing.T) {
+	output := captureStdout(t, func() {
+		if err := (session{}).infof("shown %d\n", 1); err != nil {
+			t.Errorf("infof failed: %v", err)
+		}
+		if err := (session{quiet: true}).infoln("hidden"); err != nil {
+			t.Errorf("infoln failed: %v", err)
+		}
+	})
+	if output != "shown 1\n" {
+		t.Errorf("expected only non-quiet output, got %q", output)
+	}
+}
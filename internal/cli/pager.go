@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultPager is used when PAGER is unset or empty; -R lets less show colors
+const defaultPager = "less -R"
+
+// startPager collects what a command writes to stdout so it can be shown through $PAGER when it
+// is taller than the terminal; the returned function must be called once the command is done
+// Nothing is collected when disabled (--no-pager) or stdout is not a terminal, so piped output
+// streams as usual and never reaches a pager
+func startPager(disabled bool) func() {
+	if disabled || !isTerminal(os.Stdout) {
+		return func() {}
+	}
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return func() {}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+
+	// Color is decided per call from whether stdout is a terminal, so it is fixed before stdout becomes the pipe
+	origColorMode := colorMode
+	if colorEnabled() {
+		colorMode = colorAlways
+	}
+	terminal := os.Stdout
+	os.Stdout = w
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	return func() {
+		_ = w.Close()
+		os.Stdout = terminal
+		colorMode = origColorMode
+
+		data := <-done
+		_ = r.Close()
+		if !needsPaging(data, height) || runPager(data) != nil {
+			if _, err := terminal.Write(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// needsPaging reports whether output has more lines than fit on a terminal of the given height,
+// keeping one row for the shell prompt; lines wrapped by the terminal are not counted
+func needsPaging(output []byte, height int) bool {
+	return bytes.Count(output, []byte("\n")) >= height
+}
+
+// runPager shows output through $PAGER, or defaultPager if it is unset
+// Only a pager that cannot be started is an error; how it exits is up to the user
+func runPager(output []byte) error {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = strings.Fields(defaultPager)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	_ = cmd.Wait()
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestNeedsPaging(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		height int
+		want   bool
+	}{
+		{"empty", "", 24, false},
+		{"fits with a row for the prompt", strings.Repeat("line\n", 23), 24, false},
+		{"taller than the terminal", strings.Repeat("line\n", 24), 24, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsPaging([]byte(tt.output), tt.height); got != tt.want {
+				t.Errorf("needsPaging() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPager(t *testing.T) {
+	t.Setenv("PAGER", "cat")
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runPager([]byte("paged output\n"))
+	})
+	if err != nil {
+		t.Fatalf("runPager failed: %v", err)
+	}
+	if output != "paged output\n" {
+		t.Errorf("expected the pager to receive the output, got %q", output)
+	}
+
+	t.Setenv("PAGER", filepath.Join(t.TempDir(), "missing-pager"))
+	if err := runPager([]byte("paged output\n")); err == nil {
+		t.Error("expected error for a pager that cannot be started")
+	}
+}
+
+func TestPipedOutputIsNotPaged(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add(strings.Repeat("A long entry line\n", 200), []string{"long"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	// A pager that leaves a marker file, so any invocation is detected
+	marker := filepath.Join(t.TempDir(), "pager-ran")
+	t.Setenv("PAGER", "touch "+marker)
+
+	commands := map[string]func() int{
+		"show":   func() int { return runShow([]string{ent.GetID(), "-j", "test"}) },
+		"search": func() int { return runSearch([]string{"-j", "test", "--tag", "long"}) },
+		"list":   func() int { return runList([]string{"-j", "test", "-n", "0"}) },
+	}
+	for name, run := range commands {
+		t.Run(name, func(t *testing.T) {
+			var exitCode int
+			output := captureStdout(t, func() {
+				exitCode = run()
+			})
+			if exitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d", exitCode)
+			}
+			if !strings.Contains(output, ent.GetID()[:8]) {
+				t.Errorf("expected output to be written to the pipe, got %q", output)
+			}
+			if _, err := os.Stat(marker); !os.IsNotExist(err) {
+				t.Error("expected the pager not to run when stdout is not a terminal")
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdin is where confirmation prompts read answers from; tests replace it
+var stdin io.Reader = os.Stdin
+
+// stdinIsTerminal reports whether confirmation prompts can be answered interactively
+// A reader substituted for stdin (as tests do) always counts as interactive
+func stdinIsTerminal() bool {
+	file, ok := stdin.(*os.File)
+	if !ok {
+		return true
+	}
+	return isTerminal(file)
+}
+
+// confirm prints prompt followed by " [y/N] " and reports whether the answer was yes
+// Anything other than y or yes, including end of input, counts as no
+func confirm(prompt string) (bool, error) {
+	if _, err := fmt.Printf("%s [y/N] ", prompt); err != nil {
+		return false, err
+	}
+
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
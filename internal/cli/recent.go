@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+func runToday(args []string) int {
+	fs := flag.NewFlagSet("today", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal today [flags]")
+		fmt.Println("\nShow every entry dated today, oldest first")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	entries, err := j.SearchByDate(time.Now())
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load entries: %v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if len(entries) == 0 {
+		if _, err := fmt.Println("No entries today"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	slices.SortFunc(entries, func(a, b models.Entry) int {
+		return a.GetDate().Compare(b.GetDate())
+	})
+
+	if err := printEntries(entries, nil, 0); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runOnThisDay(args []string) int {
+	fs := flag.NewFlagSet("onthisday", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	dateFlag := fs.String("date", "", "Day to look back from instead of today (YYYY-MM-DD or an expression like yesterday)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal onthisday [flags]")
+		fmt.Println("\nShow the entries written on today's month and day in every year, newest first")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	date := time.Now()
+	if *dateFlag != "" {
+		var err error
+		date, err = parseDateExpr(*dateFlag, date)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Error: invalid --date: %v\n\n", err); ferr != nil {
+				return 1
+			}
+			fs.Usage()
+			return 1
+		}
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	entries, err := j.OnThisDay(date)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load entries: %v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if len(entries) == 0 {
+		if _, err := fmt.Printf("No entries on %s in any year\n", date.Format("January 2")); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if err := printEntries(entries, nil, 0); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runLast(args []string) int {
+	fs := flag.NewFlagSet("last", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal last [flags]")
+		fmt.Println("\nShow the most recent entry in full")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	entries, err := j.ListRecent(1)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load entries: %v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if len(entries) == 0 {
+		if _, err := fmt.Println("No entries found"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	backlinks, err := j.Backlinks(entries[0].GetID())
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to find backlinks: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := printEntry(entries[0], backlinks); err != nil {
+		return 1
+	}
+	return 0
+}
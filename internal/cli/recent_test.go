@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunToday(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Written today", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.AddWithDate("Written last year", []string{}, time.Now().AddDate(-1, 0, 0)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runToday([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Written today") || strings.Contains(output, "Written last year") {
+		t.Errorf("expected only today's entry, got %q", output)
+	}
+}
+
+func TestRunToday_NoEntries(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runToday([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "No entries today") {
+		t.Errorf("expected no entries message, got %q", output)
+	}
+}
+
+func TestRunOnThisDay(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	for content, date := range map[string]time.Time{
+		"Two years ago": time.Date(2022, 7, 4, 9, 0, 0, 0, time.Local),
+		"Last year":     time.Date(2023, 7, 4, 9, 0, 0, 0, time.Local),
+		"Day after":     time.Date(2023, 7, 5, 9, 0, 0, 0, time.Local),
+	} {
+		if _, err := j.AddWithDate(content, nil, date); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runOnThisDay([]string{"-j", "test", "--date", "2024-07-04"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	lastYear, twoYearsAgo := strings.Index(output, "Last year"), strings.Index(output, "Two years ago")
+	if lastYear < 0 || twoYearsAgo < 0 || lastYear > twoYearsAgo {
+		t.Errorf("expected July 4 entries newest year first, got %q", output)
+	}
+	if strings.Contains(output, "Day after") {
+		t.Errorf("expected only entries on July 4, got %q", output)
+	}
+}
+
+func TestRunOnThisDay_NoEntries(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runOnThisDay([]string{"-j", "test", "--date", "2024-02-29"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "No entries on February 29") {
+		t.Errorf("expected no-entries message, got %q", output)
+	}
+}
+
+func TestRunOnThisDay_InvalidDate(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runOnThisDay([]string{"-j", "test", "--date", "someday"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an invalid --date")
+	}
+}
+
+func TestRunLast(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.AddWithDate("Older entry", []string{}, time.Now().AddDate(0, 0, -2)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	latest, err := j.Add("Newest entry", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runLast([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "ID: "+latest.GetID()) || strings.Contains(output, "Older entry") {
+		t.Errorf("expected only the newest entry in full, got %q", output)
+	}
+}
+
+func TestRunLast_NoEntries(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runLast([]string{"-j", "test"}); exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+}
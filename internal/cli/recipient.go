@@ -1,20 +1,32 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/data-castle/journal/internal/config"
 	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/internal/storage"
 )
 
 func runAddRecipient(args []string) int {
 	fs := flag.NewFlagSet("add-recipient", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	label := fs.String("label", "", "Human-readable name for the recipient, e.g. laptop")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal add-recipient <public-key> [flags]")
 		fmt.Println("\nAdd a recipient to a journal")
+		fmt.Println("The key is an age public key or an ssh-ed25519 or ssh-rsa public key; quote ssh keys")
+		fmt.Printf("Labels are stored in %s in the journal directory and shown by list-recipients\n", crypto.RecipientLabelsFileName)
+		fmt.Println("Giving --label for an existing recipient only changes its label")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
 	}
@@ -22,7 +34,16 @@ func runAddRecipient(args []string) int {
 		return 1
 	}
 
-	if fs.NArg() != 1 {
+	// Allow flags after the public key, as in the usage line
+	var recipient string
+	if fs.NArg() > 0 {
+		recipient = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	if recipient == "" || fs.NArg() != 0 {
 		if _, err := fmt.Fprintf(os.Stderr, "Error: recipient public key is required\n\n"); err != nil {
 			return 1
 		}
@@ -30,18 +51,25 @@ func runAddRecipient(args []string) int {
 		return 1
 	}
 
-	recipient := fs.Arg(0)
-
 	j, journalCfg, err := openJournal(*journalName)
 	if err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
-		return 1
+		return errorExitCode(err)
 	}
 
 	newRecipients, err := crypto.PrepareAddRecipient(journalCfg.Path, recipient)
 	if err != nil {
+		if errors.Is(err, crypto.ErrRecipientExists) && *label != "" {
+			return setRecipientLabel(journalCfg, recipient, *label)
+		}
+		if errors.Is(err, crypto.ErrRecipientExists) {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Recipient is already in journal '%s'\n", journalCfg.Name); ferr != nil {
+				return 1
+			}
+			return 1
+		}
 		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to prepare recipient addition: %v\n", err); ferr != nil {
 			return 1
 		}
@@ -68,6 +96,26 @@ func runAddRecipient(args []string) int {
 	if _, err := fmt.Printf("Successfully added recipient to journal '%s'\n", journalCfg.Name); err != nil {
 		return 1
 	}
+
+	if *label != "" {
+		return setRecipientLabel(journalCfg, recipient, *label)
+	}
+	return 0
+}
+
+// setRecipientLabel labels recipient in the journal and reports the labeled recipient
+func setRecipientLabel(journalCfg *config.Journal, recipient, label string) int {
+	if err := crypto.SetRecipientLabel(journalCfg.Path, recipient, label); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to label recipient: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	labels := map[string]string{recipient: strings.TrimSpace(label)}
+	if _, err := fmt.Printf("Labeled recipient %s\n", crypto.FormatRecipient(recipient, labels)); err != nil {
+		return 1
+	}
 	return 0
 }
 
@@ -75,6 +123,7 @@ func runRemoveRecipient(args []string) int {
 	fs := flag.NewFlagSet("remove-recipient", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	force := fs.Bool("force", false, "Remove the recipient even if it revokes your own access")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal remove-recipient <public-key> [flags]")
 		fmt.Println("\nRemove a recipient from a journal")
@@ -100,17 +149,58 @@ func runRemoveRecipient(args []string) int {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
-		return 1
+		return errorExitCode(err)
 	}
 
 	newRecipients, err := crypto.PrepareRemoveRecipient(journalCfg.Path, recipient)
 	if err != nil {
-		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to prepare recipient removal: %v\n", err); ferr != nil {
-			return 1
+		switch {
+		case errors.Is(err, crypto.ErrRecipientNotFound):
+			if _, ferr := fmt.Fprintf(os.Stderr, "Recipient is not in journal '%s' (see .sops.yaml for current recipients)\n", journalCfg.Name); ferr != nil {
+				return 1
+			}
+		case errors.Is(err, crypto.ErrLastRecipient):
+			if _, ferr := fmt.Fprintln(os.Stderr, "Cannot remove the last recipient: nobody would be able to decrypt the journal"); ferr != nil {
+				return 1
+			}
+		default:
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to prepare recipient removal: %v\n", err); ferr != nil {
+				return 1
+			}
 		}
 		return 1
 	}
 
+	// Removing the user's own key revokes their access, and the re-encrypted files can
+	// then no longer be verified with it
+	losesAccess := false
+	own, err := ownRecipients(journalCfg)
+	if err != nil {
+		if !*force {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to read your age key: %v\nUse --force to remove the recipient without this check\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	} else if len(own) > 0 {
+		losesAccess = !slices.ContainsFunc(newRecipients, func(r string) bool { return slices.Contains(own, r) })
+	}
+
+	if losesAccess {
+		if !*force {
+			if _, ferr := fmt.Fprintln(os.Stderr, "Refusing to remove recipient: it is your own key, and you would no longer be able to decrypt this journal"); ferr != nil {
+				return 1
+			}
+			if _, ferr := fmt.Fprintln(os.Stderr, "Use --force if someone else will keep access and this is intended"); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, ferr := fmt.Fprintln(os.Stderr, "Warning: removing your own key; you will no longer be able to decrypt this journal"); ferr != nil {
+			return 1
+		}
+	}
+
 	if _, err := fmt.Printf("Removing recipient from journal '%s'\n", journalCfg.Name); err != nil {
 		return 1
 	}
@@ -118,7 +208,8 @@ func runRemoveRecipient(args []string) int {
 		return 1
 	}
 
-	if err := j.ReEncryptWithRecipients(newRecipients); err != nil {
+	opts := entry.ReEncryptOptions{Recipients: newRecipients, SkipVerify: losesAccess}
+	if err := j.ReEncryptWithOptions(opts); err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to remove recipient: %v\n", err); ferr != nil {
 			return 1
 		}
@@ -131,44 +222,278 @@ func runRemoveRecipient(args []string) int {
 	if _, err := fmt.Printf("Successfully removed recipient from journal '%s'\n", journalCfg.Name); err != nil {
 		return 1
 	}
+
+	if err := crypto.SetRecipientLabel(journalCfg.Path, recipient, ""); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Warning: failed to remove the recipient's label: %v\n", err); ferr != nil {
+			return 1
+		}
+	}
 	return 0
 }
 
-func runReEncrypt(args []string) int {
+// ownRecipients returns the public keys of the identities used to decrypt the journal,
+// read from the journal's key_files or else SOPS_AGE_KEY_FILE
+// Returns nil if neither is configured
+func ownRecipients(journalCfg *config.Journal) ([]string, error) {
+	keyFiles := journalCfg.KeyFiles
+	if len(keyFiles) == 0 {
+		keyPath := os.Getenv("SOPS_AGE_KEY_FILE")
+		if keyPath == "" {
+			return nil, nil
+		}
+		keyFiles = []string{keyPath}
+	}
+
+	return crypto.IdentityRecipients(keyFiles)
+}
+
+// recipientListing is one line of list-recipients output
+type recipientListing struct {
+	Recipient  string `json:"recipient"`
+	Label      string `json:"label,omitempty"`
+	CurrentKey bool   `json:"current_key"`
+}
+
+func runListRecipients(args []string) int {
+	fs := flag.NewFlagSet("list-recipients", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	jsonOutput := fs.Bool("json", false, "Print recipients as JSON")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal list-recipients [flags]")
+		fmt.Println("\nList the age public keys a journal is encrypted for")
+		fmt.Println("The key matching your own identity (key_files or SOPS_AGE_KEY_FILE) is marked")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	journalCfg, err := resolveJournalConfig(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	recipients, err := crypto.ReadSOPSConfig(journalCfg.Path)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to read recipients: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	own, err := ownRecipients(journalCfg)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Warning: cannot read your age key to mark it: %v\n", err); ferr != nil {
+			return 1
+		}
+	}
+
+	labels, err := crypto.ReadRecipientLabels(journalCfg.Path)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Warning: cannot read recipient labels: %v\n", err); ferr != nil {
+			return 1
+		}
+	}
+
+	listings := make([]recipientListing, 0, len(recipients))
+	for _, recipient := range recipients {
+		listings = append(listings, recipientListing{
+			Recipient:  recipient,
+			Label:      labels[recipient],
+			CurrentKey: slices.Contains(own, recipient),
+		})
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(listings, "", "  ")
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to encode recipients: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, err := fmt.Println(string(data)); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	for _, listing := range listings {
+		marker := ""
+		if listing.CurrentKey {
+			marker = " (your key)"
+		}
+		if _, err := fmt.Printf("%s%s\n", crypto.FormatRecipient(listing.Recipient, labels), marker); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+func runReEncrypt(s session, args []string) int {
 	fs := flag.NewFlagSet("re-encrypt", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	dryRun := fs.Bool("dry-run", false, "Check that all files decrypt without re-encrypting anything")
+	verbose := fs.Bool("verbose", false, "Print progress as each entry is re-encrypted")
+	fs.BoolVar(verbose, "v", false, "Print progress (shorthand)")
+	since := fs.String("since", "", "Only re-encrypt entry files changed since this git ref (all entries outside a git repository)")
+	indexOnly := fs.Bool("index-only", false, "Only re-encrypt the index, content index, and templates")
+	entriesOnly := fs.Bool("entries-only", false, "Only re-encrypt entries and their attachments, not the index")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal re-encrypt [flags]")
 		fmt.Println("\nRe-encrypt all entries with current recipient list from .sops.yaml")
 		fmt.Println("Use this after adding or removing recipients")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal re-encrypt --since ORIG_HEAD   # Entries pulled in by the last merge")
+		fmt.Println("  journal re-encrypt --index-only        # After fixing the index by hand")
 	}
 	if err := fs.Parse(args); err != nil {
 		return 1
 	}
 
+	if *indexOnly && (*entriesOnly || *since != "") {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --index-only cannot be combined with --entries-only or --since\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
 	j, journalCfg, err := openJournal(*journalName)
 	if err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
 			return 1
 		}
-		return 1
+		return errorExitCode(err)
+	}
+
+	if *dryRun {
+		return runReEncryptDryRun(j)
 	}
 
-	if _, err := fmt.Println("Re-encrypting all entries..."); err != nil {
+	opts := entry.ReEncryptOptions{SkipIndex: *entriesOnly}
+	if *indexOnly {
+		opts.Entries = []string{}
+	}
+	if *since != "" {
+		files, err := changedEntryFiles(journalCfg.Path, *since)
+		switch {
+		case errors.Is(err, errNotGitRepository):
+			if _, ferr := fmt.Fprintf(os.Stderr, "Warning: %s is not a git repository, re-encrypting all entries\n", journalCfg.Path); ferr != nil {
+				return 1
+			}
+		case err != nil:
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to list entries changed since %s: %v\n", *since, err); ferr != nil {
+				return 1
+			}
+			return 1
+		default:
+			opts.Entries = files
+		}
+	}
+
+	var scope string
+	switch {
+	case *indexOnly:
+		scope = "Re-encrypting the index..."
+	case opts.Entries != nil:
+		scope = fmt.Sprintf("Re-encrypting %d entry files changed since %s...", len(opts.Entries), *since)
+	case *entriesOnly:
+		scope = "Re-encrypting all entries, leaving the index as is..."
+	default:
+		scope = "Re-encrypting all entries..."
+	}
+	if err := s.infoln(scope); err != nil {
 		return 1
 	}
-	if err := j.ReEncrypt(); err != nil {
+
+	// progressErr keeps the first failed progress write, so the command can still exit non-zero
+	var progressErr error
+	if *verbose {
+		opts.Progress = func(done, total int, filePath string) {
+			if progressErr == nil {
+				_, progressErr = fmt.Printf("[%d/%d] %s\n", done, total, filePath)
+			}
+		}
+	}
+
+	if err := j.ReEncryptWithOptions(opts); err != nil {
 		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to re-encrypt: %v\n", err); ferr != nil {
 			return 1
 		}
 		return 1
 	}
+	if progressErr != nil {
+		return 1
+	}
 
-	if _, err := fmt.Printf("Re-encryption complete for journal '%s'\n", journalCfg.Name); err != nil {
+	if err := s.infof("Re-encryption complete for journal '%s'\n", journalCfg.Name); err != nil {
 		return 1
 	}
 	return 0
 }
+
+// errNotGitRepository is returned by changedEntryFiles when the journal is not in a git repository
+var errNotGitRepository = errors.New("not a git repository")
+
+// changedEntryFiles lists the entry files that differ between ref and the working tree of the journal,
+// relative to the entries directory as ReEncryptOptions.Entries expects
+func changedEntryFiles(dir, ref string) ([]string, error) {
+	if _, err := gitOutput(dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, errNotGitRepository
+	}
+
+	// --end-of-options keeps a ref starting with "-" from being read as a git option
+	out, err := gitOutput(dir, "diff", "--name-only", "-z", "--relative", "--end-of-options", ref, "--", storage.EntriesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for name := range strings.SplitSeq(out, "\x00") {
+		rel, ok := strings.CutPrefix(name, storage.EntriesDir+"/")
+		if ok && strings.HasSuffix(rel, ".yaml") {
+			files = append(files, filepath.FromSlash(rel))
+		}
+	}
+	return files, nil
+}
+
+// runReEncryptDryRun checks that every file re-encrypt would touch can be decrypted
+func runReEncryptDryRun(j *entry.Journal) int {
+	if _, err := fmt.Println("Dry run: checking that all entries and the index can be decrypted..."); err != nil {
+		return 1
+	}
+
+	result, err := j.Verify()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Dry run failed: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("%d entries and the index would be re-encrypted\n", result.TotalFiles); err != nil {
+		return 1
+	}
+
+	if result.OK() {
+		if _, err := fmt.Println("All files decrypted successfully; no files were modified"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if err := printVerifyFailures(result); err != nil {
+		return 1
+	}
+	return 1
+}
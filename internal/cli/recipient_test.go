@@ -1,14 +1,19 @@
 package cli
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"filippo.io/age"
 	"github.com/data-castle/journal/internal/config"
 	"github.com/data-castle/journal/internal/crypto"
 	"github.com/data-castle/journal/internal/entry"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestRunAddRecipient_WithAutoReencrypt(t *testing.T) {
@@ -356,7 +361,7 @@ func TestRunReEncrypt(t *testing.T) {
 
 	// Run re-encrypt
 	args := []string{"-j", "test"}
-	exitCode := runReEncrypt(args)
+	exitCode := runReEncrypt(session{}, args)
 
 	if exitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", exitCode)
@@ -377,3 +382,487 @@ func TestRunReEncrypt(t *testing.T) {
 		t.Errorf("expected 1 entry, got %d", len(entries))
 	}
 }
+
+func TestRunReEncrypt_DryRun(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Test entry", []string{"tag1"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	indexPath := filepath.Join(journalCfg.Path, "index.yaml")
+
+	entryBefore, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+	indexBefore, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+
+	args := []string{"-j", "test", "--dry-run"}
+	exitCode := runReEncrypt(session{}, args)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+
+	entryAfter, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+	indexAfter, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+
+	if string(entryBefore) != string(entryAfter) {
+		t.Error("dry run modified the entry file")
+	}
+	if string(indexBefore) != string(indexAfter) {
+		t.Error("dry run modified the index file")
+	}
+}
+
+func TestRunReEncrypt_DryRunReportsFailures(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Test entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	args := []string{"-j", "test", "--dry-run"}
+	exitCode := runReEncrypt(session{}, args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when an entry cannot be decrypted")
+	}
+}
+
+func TestRunReEncrypt_DryRunLeavesJournalUnchanged(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Test entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Second entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	// A failing entry is what a verify would record in the quarantine log
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	before := readJournalFiles(t, journalCfg.Path)
+
+	args := []string{"-j", "test", "--dry-run"}
+	if exitCode := runReEncrypt(session{}, args); exitCode == 0 {
+		t.Error("expected non-zero exit code when an entry cannot be decrypted")
+	}
+
+	after := readJournalFiles(t, journalCfg.Path)
+	for path, data := range after {
+		if prev, ok := before[path]; !ok {
+			t.Errorf("dry run created %s", path)
+		} else if prev != data {
+			t.Errorf("dry run modified %s", path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			t.Errorf("dry run removed %s", path)
+		}
+	}
+}
+
+// readJournalFiles returns the content of every file under dir, keyed by path
+func readJournalFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[path] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read journal files: %v", err)
+	}
+	return files
+}
+
+func TestRunReEncrypt_Verbose(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Test entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	args := []string{"-j", "test", "--verbose"}
+	exitCode := runReEncrypt(session{}, args)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+}
+
+func TestRunListRecipients(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	other := identity.Recipient().String()
+	if err := crypto.AddRecipient(journalCfg.Path, other); err != nil {
+		t.Fatalf("failed to add recipient: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runListRecipients([]string{"-j", "test"})
+	})
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 recipients, got %d lines: %q", len(lines), output)
+	}
+
+	if !strings.HasSuffix(lines[0], "(your key)") {
+		t.Errorf("expected the journal's own key to be marked, got %q", lines[0])
+	}
+	if lines[1] != other {
+		t.Errorf("expected the other recipient unmarked, got %q", lines[1])
+	}
+}
+
+func TestRunAddRecipient_Label(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	other := identity.Recipient().String()
+
+	captureStdout(t, func() {
+		if exitCode := runAddRecipient([]string{"-j", "test", other, "--label", "laptop"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+
+	output := captureStdout(t, func() {
+		runListRecipients([]string{"-j", "test"})
+	})
+	if !strings.Contains(output, other+" (laptop)\n") {
+		t.Errorf("expected the labeled recipient, got %q", output)
+	}
+
+	// Labeling an existing recipient does not re-encrypt or fail
+	captureStdout(t, func() {
+		if exitCode := runAddRecipient([]string{"-j", "test", other, "--label", "work laptop"}); exitCode != 0 {
+			t.Errorf("expected exit code 0 when relabeling, got %d", exitCode)
+		}
+	})
+	labels, err := crypto.ReadRecipientLabels(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to read labels: %v", err)
+	}
+	if labels[other] != "work laptop" {
+		t.Errorf("expected the label to be updated, got %v", labels)
+	}
+
+	captureStdout(t, func() {
+		if exitCode := runRemoveRecipient([]string{"-j", "test", other}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+	labels, err = crypto.ReadRecipientLabels(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to read labels: %v", err)
+	}
+	if _, ok := labels[other]; ok {
+		t.Errorf("expected the label to be removed with the recipient, got %v", labels)
+	}
+}
+
+func TestRunAddRecipient_SSHKey(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ed25519 key: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("failed to convert ssh public key: %v", err)
+	}
+	sshKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " user@laptop"
+
+	captureStdout(t, func() {
+		if exitCode := runAddRecipient([]string{"-j", "test", sshKey}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+
+	recipients, err := crypto.ReadSOPSConfig(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to read recipients: %v", err)
+	}
+	if len(recipients) != 2 || recipients[1] != sshKey {
+		t.Errorf("expected the ssh key to be added, got %v", recipients)
+	}
+
+	if exitCode := runAddRecipient([]string{"-j", "test", "ssh-ed25519 not-a-key"}); exitCode == 0 {
+		t.Error("expected a non-zero exit code for an invalid ssh key")
+	}
+}
+
+func TestRunListRecipients_JSON(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runListRecipients([]string{"-j", "test", "--json"})
+	})
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	var listings []recipientListing
+	if err := json.Unmarshal([]byte(output), &listings); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if len(listings) != 1 || !listings[0].CurrentKey {
+		t.Errorf("expected one recipient marked as the current key, got %+v", listings)
+	}
+}
+
+func TestRunRemoveRecipient_RefusesOwnKey(t *testing.T) {
+	_, journalCfg, keyPath := setupTestJournal(t, "", "")
+
+	own, err := crypto.IdentityRecipients([]string{keyPath})
+	if err != nil {
+		t.Fatalf("failed to read own recipient: %v", err)
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	if err := crypto.AddRecipient(journalCfg.Path, other.Recipient().String()); err != nil {
+		t.Fatalf("failed to add recipient: %v", err)
+	}
+
+	if exitCode := runRemoveRecipient([]string{"-j", "test", own[0]}); exitCode == 0 {
+		t.Fatal("expected non-zero exit code when removing own key")
+	}
+
+	recipients, err := crypto.ReadSOPSConfig(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to read SOPS config: %v", err)
+	}
+	if len(recipients) != 2 {
+		t.Errorf("expected recipients to be unchanged, got %d", len(recipients))
+	}
+
+	if exitCode := runRemoveRecipient([]string{"-j", "test", "--force", own[0]}); exitCode != 0 {
+		t.Fatalf("expected exit code 0 with --force, got %d", exitCode)
+	}
+
+	recipients, err = crypto.ReadSOPSConfig(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("failed to read SOPS config: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != other.Recipient().String() {
+		t.Errorf("expected only the other recipient to remain, got %v", recipients)
+	}
+}
+
+func TestRunReEncrypt_Since(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	old, err := j.Add("Old entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	setupGitJournal(t, journalCfg.Path)
+
+	pulled, err := j.Add("Pulled entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	mustGit(t, journalCfg.Path, "add", ".")
+	mustGit(t, journalCfg.Path, "commit", "-m", "Add entry")
+
+	oldPath := filepath.Join(journalCfg.Path, "entries", old.GetFilePath())
+	oldBefore, err := os.ReadFile(oldPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runReEncrypt(session{}, []string{"-j", "test", "-v", "--since", "HEAD~1"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	if !strings.Contains(output, "Re-encrypting 1 entry files changed since HEAD~1") {
+		t.Errorf("expected targeted re-encrypt, got:\n%s", output)
+	}
+	if !strings.Contains(output, "[1/1] "+pulled.GetFilePath()) {
+		t.Errorf("expected only the pulled entry to be re-encrypted, got:\n%s", output)
+	}
+
+	oldAfter, err := os.ReadFile(oldPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+	if string(oldBefore) != string(oldAfter) {
+		t.Error("expected unchanged entry to be left as is")
+	}
+}
+
+func TestRunReEncrypt_SinceOptionLikeRef(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	setupGitJournal(t, journalCfg.Path)
+
+	outPath := filepath.Join(t.TempDir(), "out")
+	var exitCode int
+	captureStderr(t, func() {
+		captureStdout(t, func() {
+			exitCode = runReEncrypt(session{}, []string{"-j", "test", "--since", "--output=" + outPath})
+		})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for a ref that is not a revision")
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("expected the ref not to be read as a git option, got %v", err)
+	}
+}
+
+func TestRunReEncrypt_SinceOutsideGit(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runReEncrypt(session{}, []string{"-j", "test", "--since", "HEAD~1"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Re-encrypting all entries") {
+		t.Errorf("expected fallback to a full re-encrypt, got:\n%s", output)
+	}
+}
+
+func TestRunReEncrypt_IndexOnly(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	before, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+
+	if exitCode := runReEncrypt(session{}, []string{"-j", "test", "--index-only"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	after, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("failed to read entry: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("expected --index-only to leave entries as they are")
+	}
+}
+
+func TestRunReEncrypt_ConflictingScopes(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	for _, args := range [][]string{
+		{"-j", "test", "--index-only", "--entries-only"},
+		{"-j", "test", "--index-only", "--since", "HEAD"},
+	} {
+		if exitCode := runReEncrypt(session{}, args); exitCode == 0 {
+			t.Errorf("expected non-zero exit code for %v", args)
+		}
+	}
+}
@@ -1,16 +1,55 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/crypto"
 	"github.com/data-castle/journal/internal/entry"
 )
 
 var Version = "1.0.0"
 
+// defaultJournalEnv names the environment variable that overrides the default journal
+const defaultJournalEnv = "JOURNAL_DEFAULT"
+
+// exitDecryptionFailed is the exit code for failures caused by a missing or wrong age key
+const exitDecryptionFailed = 3
+
 func Run(args []string) int {
+	configPath, args, err := extractConfigFlag(args)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	mode, args, err := extractColorFlag(args)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	quiet, args := extractQuietFlag(args)
+	s := session{quiet: quiet}
+
+	originalMode := colorMode
+	colorMode = mode
+	defer func() { colorMode = originalMode }()
+
+	if configPath != "" {
+		originalFunc := config.GetConfigPathFunc
+		config.GetConfigPathFunc = func() (string, error) {
+			return configPath, nil
+		}
+		defer func() { config.GetConfigPathFunc = originalFunc }()
+	}
+
 	if len(args) < 2 {
 		printUsage()
 		return 1
@@ -21,35 +60,88 @@ func Run(args []string) int {
 
 	switch cmd {
 	case "init":
-		return runInit(cmdArgs)
+		return runInit(s, cmdArgs)
 	case "add":
-		return runAdd(cmdArgs)
+		return runAdd(s, cmdArgs)
 	case "list":
 		return runList(cmdArgs)
 	case "search":
 		return runSearch(cmdArgs)
+	case "count":
+		return runCount(cmdArgs)
+	case "stats":
+		return runStats(cmdArgs)
+	case "browse":
+		return runBrowse(cmdArgs)
 	case "show":
 		return runShow(cmdArgs)
+	case "today":
+		return runToday(cmdArgs)
+	case "onthisday":
+		return runOnThisDay(cmdArgs)
+	case "last":
+		return runLast(cmdArgs)
+	case "append":
+		return runAppend(cmdArgs)
+	case "touch":
+		return runTouch(cmdArgs)
 	case "delete":
 		return runDelete(cmdArgs)
+	case "restore":
+		return runRestore(cmdArgs)
+	case "trash":
+		return runTrash(cmdArgs)
+	case "undo":
+		return runUndo(cmdArgs)
 	case "rebuild":
 		return runRebuild(cmdArgs)
+	case "index":
+		return runIndex(cmdArgs)
 	case "list-journals":
 		return runListJournals(cmdArgs)
 	case "set-default":
 		return runSetDefault(cmdArgs)
+	case "rename":
+		return runRenameJournal(cmdArgs)
+	case "relocate":
+		return runRelocateJournal(cmdArgs)
+	case "remove", "delete-journal":
+		return runRemoveJournal(cmdArgs)
 	case "add-recipient":
 		return runAddRecipient(cmdArgs)
 	case "remove-recipient":
 		return runRemoveRecipient(cmdArgs)
+	case "list-recipients":
+		return runListRecipients(cmdArgs)
 	case "re-encrypt":
-		return runReEncrypt(cmdArgs)
+		return runReEncrypt(s, cmdArgs)
+	case "export":
+		return runExport(cmdArgs)
+	case "import":
+		return runImport(cmdArgs)
+	case "tag":
+		return runTag(cmdArgs)
+	case "doctor":
+		return runDoctor(cmdArgs)
+	case "verify":
+		return runVerify(cmdArgs)
+	case "cat":
+		return runCat(cmdArgs)
+	case "encrypt":
+		return runEncrypt(cmdArgs)
+	case "decrypt":
+		return runDecrypt(cmdArgs)
+	case "sync":
+		return runSync(cmdArgs)
+	case "template":
+		return runTemplate(cmdArgs)
+	case "config":
+		return runConfig(cmdArgs)
 	case "help", "-h", "--help":
 		printUsage()
 		return 0
 	case "version", "-v", "--version":
-		fmt.Printf("journal version %s\n", Version)
-		return 0
+		return runVersion(cmdArgs)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", cmd)
 		printUsage()
@@ -69,45 +161,206 @@ Available Commands:
   add               Add a new journal entry
   list              List recent journal entries
   search            Search journal entries
+  count             Print the number of entries
+  stats             Show entry counts, words, tags, and average mood over time
+  browse            List months with entries, or the entries in one month
   show              Show a specific journal entry
-  delete            Delete a journal entry
+  today             Show today's entries
+  onthisday         Show entries from this day in every year
+  last              Show the most recent entry
+  append            Append text to an existing entry
+  touch             Change an entry's date without changing its content
+  delete            Move a journal entry to the trash
+  restore           Restore a deleted entry from the trash
+  trash             List or empty deleted entries
+  undo              Undo the most recent entry delete or update
   rebuild           Rebuild the search index from all entries
+  index             Inspect the search index (dump, stats)
   list-journals     List all configured journals
   set-default       Set the default journal
+  rename            Rename a journal in the config
+  relocate          Move a journal's directory and update the config
+  remove            Remove a journal from the config (--delete-files to delete it)
+  delete-journal    Alias for remove
   add-recipient     Add a recipient to a multi-recipient journal
   remove-recipient  Remove a recipient from a journal
+  list-recipients   List a journal's recipients and mark your own key
   re-encrypt        Re-encrypt journal after changing recipients
+  export            Export all entries to a plaintext JSON or markdown file
+  import            Import entries from a JSON or markdown archive
+  tag               Manage tags (add, remove, rename)
+  template          Manage entry templates (save, list, show, delete)
+  config            Get, set, or list config settings
+  doctor            Check config, age key, and journals for common problems
+  verify            Decrypt every entry and the index to detect corrupted files
+  encrypt           Encrypt a plaintext YAML file in the journal directory in place
+  decrypt           Decrypt a file in the journal directory to stdout or a file
+  sync              Pull and push the journal's git repository
   help              Show this help message
-  version           Show version information
+  version           Show version information (--json for bug reports)
 
 Global Flags:
-  -j, --journal     Journal name to use (default: configured default journal)`)
+  -c, --config      Config file to use (default: ~/.journal/config.yaml)
+  -j, --journal     Journal name to use (default: $JOURNAL_DEFAULT, then configured default journal)
+      --color       Color output: auto, always, or never (default: auto)
+      --no-color    Same as --color=never
+  -q, --quiet       Suppress informational output of add, init, and re-encrypt; errors still go to stderr
+
+Environment:
+  JOURNAL_CONFIG    Config file to use when --config is not given
+  JOURNAL_DEFAULT   Journal to use when -j is not given
+  NO_COLOR          Disable color in auto mode when set to any value
+  PAGER             Pager for long show, search, and list output on a terminal (default: less -R)
+
+Exit Codes:
+  0                 Success
+  1                 Error
+  3                 Decryption failed (check SOPS_AGE_KEY_FILE)`)
+}
+
+// extractConfigFlag removes the global -c/--config flag from args and returns its value
+// The flag may appear anywhere before a "--" terminator, so it is stripped before
+// the command's own flags are parsed
+func extractConfigFlag(args []string) (string, []string, error) {
+	var configPath string
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		switch {
+		case arg == "-c" || arg == "--config" || arg == "-config":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("flag %s requires a config file path", arg)
+			}
+			i++
+			configPath = args[i]
+		case strings.HasPrefix(arg, "--config="):
+			configPath = strings.TrimPrefix(arg, "--config=")
+		case strings.HasPrefix(arg, "-config="):
+			configPath = strings.TrimPrefix(arg, "-config=")
+		case strings.HasPrefix(arg, "-c="):
+			configPath = strings.TrimPrefix(arg, "-c=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if configPath == "" {
+		return "", rest, nil
+	}
+
+	expanded, err := expandHome(configPath)
+	if err != nil {
+		return "", nil, err
+	}
+	return expanded, rest, nil
+}
+
+// expandHome replaces a leading ~ in path with the user's home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
+
+// errorExitCode returns the exit code for a command that failed with err
+// For decryption failures it also prints a hint about the age key to stderr
+func errorExitCode(err error) int {
+	if !errors.Is(err, crypto.ErrDecryptionFailed) {
+		return 1
+	}
+
+	if _, ferr := fmt.Fprintln(os.Stderr, "Hint: check that SOPS_AGE_KEY_FILE is set and points to an age key that is a recipient of this journal"); ferr != nil {
+		return 1
+	}
+	return exitDecryptionFailed
 }
 
 // openJournal loads config and opens the specified (or default) journal
 func openJournal(journalName string) (*entry.Journal, *config.Journal, error) {
+	journalCfg, err := resolveJournalConfig(journalName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j.SetWarnFunc(printWarning)
+
+	return j, journalCfg, nil
+}
+
+// openJournalReadOnly loads config and opens the specified (or default) journal for commands that
+// only read, so they work on a read-only filesystem and cannot change the journal by accident
+func openJournalReadOnly(journalName string) (*entry.Journal, *config.Journal, error) {
+	journalCfg, err := resolveJournalConfig(journalName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j, err := entry.OpenJournalReadOnly(journalCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j.SetWarnFunc(printWarning)
+
+	return j, journalCfg, nil
+}
+
+// printWarning prints a problem the journal reports without failing the command to stderr
+func printWarning(err error) {
+	if _, ferr := fmt.Fprintf(os.Stderr, "Warning: %v\n", err); ferr != nil {
+		// With stderr unwritable there is nowhere left to report the warning
+		return
+	}
+}
+
+// resolveJournalConfig loads config and returns the specified (or default) journal's settings
+// without opening the journal, so nothing needs to be decrypted
+// Without a name, JOURNAL_DEFAULT is used before the configured default journal
+func resolveJournalConfig(journalName string) (*config.Journal, error) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	return resolveJournal(cfg, journalName)
+}
 
-	var journalCfg *config.Journal
+// resolveJournal returns the named journal from cfg, or the default journal when journalName is empty
+// The returned journal belongs to cfg, so changes to it are written by cfg.Save
+func resolveJournal(cfg *config.Config, journalName string) (*config.Journal, error) {
 	if journalName == "" {
-		journalCfg, err = cfg.GetDefaultJournal()
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get default journal: %w\nHint: Use -j flag to specify a journal, or set a default with 'journal set-default <name>'", err)
+		if envName := os.Getenv(defaultJournalEnv); envName != "" {
+			journalCfg, err := cfg.GetJournal(envName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get journal from %s: %w", defaultJournalEnv, err)
+			}
+			return journalCfg, nil
 		}
-	} else {
-		journalCfg, err = cfg.GetJournal(journalName)
+
+		journalCfg, err := cfg.GetDefaultJournal()
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get journal: %w", err)
+			return nil, fmt.Errorf("failed to get default journal: %w\nHint: Use -j flag to specify a journal, or set a default with 'journal set-default <name>'", err)
 		}
+		return journalCfg, nil
 	}
 
-	j, err := entry.NewJournalFromConfig(journalCfg)
+	journalCfg, err := cfg.GetJournal(journalName)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+		return nil, fmt.Errorf("failed to get journal: %w", err)
 	}
-
-	return j, journalCfg, nil
+	return journalCfg, nil
 }
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func TestExtractConfigFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantPath string
+		wantRest []string
+	}{
+		{"none", []string{"journal", "list", "-n", "5"}, "", []string{"journal", "list", "-n", "5"}},
+		{"long before command", []string{"journal", "--config", "/tmp/c.yaml", "list"}, "/tmp/c.yaml", []string{"journal", "list"}},
+		{"short after command", []string{"journal", "list", "-c", "/tmp/c.yaml", "-n", "5"}, "/tmp/c.yaml", []string{"journal", "list", "-n", "5"}},
+		{"equals form", []string{"journal", "--config=/tmp/c.yaml", "list"}, "/tmp/c.yaml", []string{"journal", "list"}},
+		{"after terminator", []string{"journal", "add", "--", "-c", "text"}, "", []string{"journal", "add", "--", "-c", "text"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, rest, err := extractConfigFlag(tt.args)
+			if err != nil {
+				t.Fatalf("extractConfigFlag failed: %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("expected path %q, got %q", tt.wantPath, path)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("expected args %v, got %v", tt.wantRest, rest)
+			}
+		})
+	}
+}
+
+func TestExtractConfigFlag_MissingValue(t *testing.T) {
+	if _, _, err := extractConfigFlag([]string{"journal", "list", "--config"}); err == nil {
+		t.Error("expected error for --config without a path")
+	}
+}
+
+func TestRun_ConfigFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "work", "config.yaml")
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	originalFunc := config.GetConfigPathFunc
+	args := []string{"journal", "--config", configPath, "init",
+		"-n", "work", "-p", filepath.Join(tmpDir, "work-journal"), "-r", identity.Recipient().String()}
+	if exitCode := Run(args); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	if _, err := os.Stat(configPath); err != nil {
+		t.Errorf("expected config to be written to %s: %v", configPath, err)
+	}
+
+	path, err := config.GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath failed: %v", err)
+	}
+	if originalPath, _ := originalFunc(); path != originalPath {
+		t.Errorf("expected config path to be restored after Run, got %s", path)
+	}
+}
+
+func TestResolveJournalConfig_Precedence(t *testing.T) {
+	tmpDir, _, _ := setupTestJournal(t, "", "personal")
+	setupTestJournal(t, tmpDir, "work")
+	setupTestJournal(t, tmpDir, "travel")
+
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{name: "config default", want: "personal"},
+		{name: "env over config default", env: "work", want: "work"},
+		{name: "flag over env", flag: "travel", env: "work", want: "travel"},
+		{name: "unknown env journal", env: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(defaultJournalEnv, tt.env)
+
+			journalCfg, err := resolveJournalConfig(tt.flag)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveJournalConfig failed: %v", err)
+			}
+			if journalCfg.Name != tt.want {
+				t.Errorf("expected journal %s, got %s", tt.want, journalCfg.Name)
+			}
+		})
+	}
+}
+
+func TestOpenJournal_PrintsWarnings(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	useIndex := false
+	cfg.Journals[journalCfg.Name].UseIndex = &useIndex
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	corrupt := filepath.Join(journalCfg.Path, storage.EntriesDir, "corrupt.yaml")
+	if err := os.WriteFile(corrupt, []byte("not: encrypted"), 0600); err != nil {
+		t.Fatalf("failed to write corrupt entry: %v", err)
+	}
+
+	var exitCode int
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			exitCode = runList([]string{"-j", "test"})
+		})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "Warning: failed to load entry corrupt.yaml") {
+		t.Errorf("expected a warning about the unreadable entry, got %q", stderr)
+	}
+}
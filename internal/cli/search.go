@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,15 +16,39 @@ func runSearch(args []string) int {
 	fs := flag.NewFlagSet("search", flag.ExitOnError)
 	journalName := fs.String("journal", "", "Journal to use")
 	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
-	onDate := fs.String("on", "", "Search entries on specific date (YYYY-MM-DD)")
-	fromDate := fs.String("from", "", "Search entries from date (YYYY-MM-DD)")
-	toDate := fs.String("to", "", "Search entries to date (YYYY-MM-DD)")
+	onDate := fs.String("on", "", "Search entries on specific date (YYYY-MM-DD or relative, e.g. yesterday)")
+	fromDate := fs.String("from", "", "Search entries from date (YYYY-MM-DD or relative, e.g. 30d)")
+	toDate := fs.String("to", "", "Search entries to date (YYYY-MM-DD or relative, e.g. 1w)")
 	tag := fs.String("tag", "", "Search entries with tag")
 	tags := fs.String("tags", "", "Search entries with all tags (comma-separated)")
+	anyTags := fs.String("any-tags", "", "Search entries with any of the tags (comma-separated)")
+	tagPrefix := fs.String("tag-prefix", "", "Search entries with any tag starting with the prefix (e.g. project/)")
+	text := fs.String("text", "", "Search entry titles and content, ignoring case (decrypts every entry)")
+	pattern := fs.String("regex", "", "Search entry titles and content with a regular expression (decrypts every entry)")
+	contextLines := fs.Int("context", 0, "With --text or --regex, show only N lines around each match instead of the whole entry")
 	lastDays := fs.Int("last", 0, "Search entries from last N days")
+	after := fs.String("after", "", "Only entries written at or after this time of day (HH:MM)")
+	before := fs.String("before", "", "Only entries written before this time of day (HH:MM)")
+	minMood := fs.Int("min-mood", 0, "Only entries rated at least this mood (1-5); unrated entries are excluded")
+	fs.IntVar(minMood, "min-rating", 0, "Only entries rated at least this mood (alias for --min-mood)")
+	idsOnly := fs.Bool("ids-only", false, "Print only the full ID of each matching entry, one per line")
+	countOnly := fs.Bool("count-only", false, "Print only the number of matching entries")
+	reverse := fs.Bool("reverse", false, "Print the oldest matching entry first instead of the newest")
+	noPager := fs.Bool("no-pager", false, "Do not page long output through $PAGER")
 	fs.Usage = func() {
 		fmt.Println("Usage: journal search [flags]")
 		fmt.Println("\nSearch journal entries by date, date range, or tags")
+		fmt.Println("\nDates are YYYY-MM-DD or relative to today:")
+		fmt.Println("  today, yesterday, thismonth (the first of this month)")
+		fmt.Println("  Nd, Nw, Nm for N days, weeks, or months ago, e.g. --from 30d for the last 30 days")
+		fmt.Println("\n--after and --before narrow any search to a time of day, e.g. --before 09:00 for")
+		fmt.Println("morning pages; alone they search every entry. If --after is later than --before,")
+		fmt.Println("the window wraps past midnight (--after 22:00 --before 06:00)")
+		fmt.Println("\n--min-mood likewise narrows any search, or alone finds every entry rated that high")
+		fmt.Println("\nResults are printed newest first; --reverse reads them in order, e.g. a tag's history")
+		fmt.Println("\nMatches of --text and --regex are highlighted when color is enabled")
+		fmt.Println("\n--ids-only and --count-only print nothing else, for use in scripts:")
+		fmt.Println("  journal search --tag work --ids-only | xargs -n1 journal show")
 		fmt.Println("\nFlags:")
 		fs.PrintDefaults()
 	}
@@ -30,22 +56,95 @@ func runSearch(args []string) int {
 		return 1
 	}
 
-	j, _, err := openJournal(*journalName)
+	// Long output goes through $PAGER on a terminal
+	defer startPager(*noPager)()
+
+	if *text != "" && *pattern != "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --text and --regex cannot be used together\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	if *idsOnly && *countOnly {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --ids-only and --count-only cannot be used together\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	var match *regexp.Regexp
+	switch {
+	case *text != "":
+		match = regexp.MustCompile("(?i)" + regexp.QuoteMeta(*text))
+	case *pattern != "":
+		var err error
+		match, err = regexp.Compile(*pattern)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid --regex pattern: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+	if *contextLines != 0 && match == nil {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --context requires --text or --regex\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	if *contextLines < 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: --context must not be negative\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	afterMinute, err := parseTimeOfDay(*after)
 	if err != nil {
-		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Invalid --after time: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	beforeMinute, err := parseTimeOfDay(*before)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Invalid --before time: %v\n", err); ferr != nil {
 			return 1
 		}
 		return 1
 	}
+	filterTime := *after != "" || *before != ""
+
+	if *minMood != 0 {
+		if err := models.ValidateMood(*minMood); err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid --min-mood: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
 
 	var entries []models.Entry
 	var searchErr error
+	now := time.Now()
 
 	switch {
 	case *onDate != "":
-		date, err := time.Parse("2006-01-02", *onDate)
+		date, err := parseDateExpr(*onDate, now)
 		if err != nil {
-			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid date format: %v\n", err); ferr != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Invalid --on date: %v\n", err); ferr != nil {
 				return 1
 			}
 			return 1
@@ -55,7 +154,7 @@ func runSearch(args []string) int {
 	case *fromDate != "" || *toDate != "":
 		var start, end time.Time
 		if *fromDate != "" {
-			start, err = time.Parse("2006-01-02", *fromDate)
+			start, err = parseDateExpr(*fromDate, now)
 			if err != nil {
 				if _, ferr := fmt.Fprintf(os.Stderr, "Invalid from date: %v\n", err); ferr != nil {
 					return 1
@@ -64,7 +163,7 @@ func runSearch(args []string) int {
 			}
 		}
 		if *toDate != "" {
-			end, err = time.Parse("2006-01-02", *toDate)
+			end, err = parseDateExpr(*toDate, now)
 			if err != nil {
 				if _, ferr := fmt.Fprintf(os.Stderr, "Invalid to date: %v\n", err); ferr != nil {
 					return 1
@@ -72,7 +171,7 @@ func runSearch(args []string) int {
 				return 1
 			}
 		} else {
-			end = time.Now()
+			end = now
 		}
 		entries, searchErr = j.SearchByDateRange(start, end)
 
@@ -85,11 +184,25 @@ func runSearch(args []string) int {
 		entries, searchErr = j.SearchByTag(*tag)
 
 	case *tags != "":
-		tagList := strings.Split(*tags, ",")
-		for i := range tagList {
-			tagList[i] = strings.TrimSpace(tagList[i])
-		}
-		entries, searchErr = j.SearchByTags(tagList)
+		entries, searchErr = j.SearchByTags(splitTagList(*tags))
+
+	case *anyTags != "":
+		entries, searchErr = j.SearchByAnyTag(splitTagList(*anyTags))
+
+	case *tagPrefix != "":
+		entries, searchErr = j.SearchByTagPrefix(*tagPrefix)
+
+	case *text != "":
+		entries, searchErr = j.SearchByContent(*text)
+
+	case *pattern != "":
+		entries, searchErr = j.SearchByRegex(*pattern)
+
+	case *minMood != 0:
+		entries, searchErr = j.SearchByMinMood(*minMood)
+
+	case filterTime:
+		entries, searchErr = j.ListRecent(len(j.ListAll()))
 
 	default:
 		if _, err := fmt.Println("Please specify search criteria"); err != nil {
@@ -106,6 +219,32 @@ func runSearch(args []string) int {
 		return 1
 	}
 
+	if filterTime {
+		entries = filterByTimeOfDay(entries, afterMinute, beforeMinute)
+	}
+	if *minMood != 0 {
+		entries = filterByMinMood(entries, *minMood)
+	}
+	if *reverse {
+		slices.Reverse(entries)
+	}
+
+	if *countOnly {
+		if _, err := fmt.Println(len(entries)); err != nil {
+			return 1
+		}
+		return 0
+	}
+	if *idsOnly {
+		// Full IDs, unlike the 8-character prefixes shown otherwise, so they can be passed on reliably
+		for _, ent := range entries {
+			if _, err := fmt.Println(ent.GetID()); err != nil {
+				return 1
+			}
+		}
+		return 0
+	}
+
 	if len(entries) == 0 {
 		if _, err := fmt.Println("No entries found"); err != nil {
 			return 1
@@ -116,18 +255,126 @@ func runSearch(args []string) int {
 	if _, err := fmt.Printf("Found %d entries:\n", len(entries)); err != nil {
 		return 1
 	}
+	if err := printEntries(entries, match, *contextLines); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// printEntries writes each entry as a heading line, its tags, and its content
+// Matches of match, if not nil, are highlighted; a positive contextLines shows only the
+// lines around each match instead of the whole content
+func printEntries(entries []models.Entry, match *regexp.Regexp, contextLines int) error {
 	for _, ent := range entries {
-		if _, err := fmt.Printf("\n[%s] %s\n", ent.GetDate().Format("2006-01-02 15:04"), ent.GetID()[:8]); err != nil {
-			return 1
+		if _, err := fmt.Printf("\n[%s] %s%s\n", colorDate(ent.GetDate().Format("2006-01-02 15:04")), colorID(ent.GetID()[:8]), highlightMatches(titleSuffix(ent.GetTitle()), match)); err != nil {
+			return err
 		}
 		if len(ent.GetTags()) > 0 {
-			if _, err := fmt.Printf("Tags: %s\n", strings.Join(ent.GetTags(), ", ")); err != nil {
-				return 1
+			if _, err := fmt.Printf("Tags: %s\n", colorTags(ent.GetTags())); err != nil {
+				return err
 			}
 		}
-		if _, err := fmt.Printf("%s\n", ent.GetContent()); err != nil {
-			return 1
+
+		content := highlightMatches(ent.GetContent(), match)
+		if match != nil && contextLines > 0 {
+			content = matchContext(ent.GetContent(), match, contextLines)
+		}
+		if _, err := fmt.Printf("%s\n", content); err != nil {
+			return err
 		}
 	}
-	return 0
+	return nil
+}
+
+// matchContextSeparator separates groups of lines that are not next to each other in matchContext output
+const matchContextSeparator = "--"
+
+// matchContext returns the lines of content within n lines of a line matching match, with
+// matches highlighted; the whole content is returned if no single line matches, e.g. when
+// only the title matched or the match spans lines
+func matchContext(content string, match *regexp.Regexp, n int) string {
+	lines := strings.Split(content, "\n")
+
+	keep := make([]bool, len(lines))
+	found := false
+	for i, line := range lines {
+		if !match.MatchString(line) {
+			continue
+		}
+		found = true
+		for k := max(0, i-n); k <= min(len(lines)-1, i+n); k++ {
+			keep[k] = true
+		}
+	}
+	if !found {
+		return highlightMatches(content, match)
+	}
+
+	var out []string
+	for i, line := range lines {
+		if !keep[i] {
+			continue
+		}
+		if len(out) > 0 && !keep[i-1] {
+			out = append(out, matchContextSeparator)
+		}
+		out = append(out, highlightMatches(line, match))
+	}
+	return strings.Join(out, "\n")
+}
+
+// parseTimeOfDay parses an HH:MM time into minutes after midnight, or -1 for an empty value
+func parseTimeOfDay(value string) (int, error) {
+	if value == "" {
+		return -1, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// filterByTimeOfDay keeps entries written at or after afterMinute and before beforeMinute
+// Either bound may be -1 for no limit; when after is later than before the window wraps past midnight
+func filterByTimeOfDay(entries []models.Entry, afterMinute, beforeMinute int) []models.Entry {
+	var filtered []models.Entry
+	for _, ent := range entries {
+		hour, minute, _ := ent.GetDate().Clock()
+		m := hour*60 + minute
+
+		isAfter := afterMinute < 0 || m >= afterMinute
+		isBefore := beforeMinute < 0 || m < beforeMinute
+
+		var keep bool
+		if afterMinute >= 0 && beforeMinute >= 0 && afterMinute > beforeMinute {
+			keep = isAfter || isBefore
+		} else {
+			keep = isAfter && isBefore
+		}
+		if keep {
+			filtered = append(filtered, ent)
+		}
+	}
+	return filtered
+}
+
+// filterByMinMood keeps entries rated at least minMood, dropping unrated ones
+func filterByMinMood(entries []models.Entry, minMood int) []models.Entry {
+	var filtered []models.Entry
+	for _, ent := range entries {
+		if ent.GetMood() != 0 && ent.GetMood() >= minMood {
+			filtered = append(filtered, ent)
+		}
+	}
+	return filtered
+}
+
+// splitTagList splits a comma-separated tag list and trims surrounding whitespace
+func splitTagList(value string) []string {
+	tagList := strings.Split(value, ",")
+	for i := range tagList {
+		tagList[i] = strings.TrimSpace(tagList[i])
+	}
+	return tagList
 }
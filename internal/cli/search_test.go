@@ -1,10 +1,14 @@
 package cli
 
 import (
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/pkg/models"
 )
 
 func TestRunSearch_ByDate(t *testing.T) {
@@ -50,6 +54,27 @@ func TestRunSearch_ByTag(t *testing.T) {
 	}
 }
 
+func TestRunSearch_ByAnyTags(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	_, err = j.Add("Entry with tag1", []string{"tag1"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	args := []string{"-j", "test", "--any-tags", "tag1, tag2"}
+	exitCode := runSearch(args)
+
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+}
+
 func TestRunSearch_ByLastDays(t *testing.T) {
 	_, journalCfg, _ := setupTestJournal(t, "", "")
 
@@ -92,3 +117,427 @@ func TestRunSearch_NoCriteria(t *testing.T) {
 		t.Error("expected non-zero exit code for missing search criteria")
 	}
 }
+
+func TestRunSearch_RelativeDates(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Recent entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--from", "30d"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Recent entry") {
+		t.Errorf("expected entry in the last 30 days, got %q", output)
+	}
+
+	if exitCode := runSearch([]string{"-j", "test", "--on", "someday"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an unrecognized date")
+	}
+}
+
+func TestFilterByTimeOfDay(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	entries := []models.Entry{
+		models.NewEntryV2("early", day.Add(6*time.Hour+30*time.Minute), "", nil, ""),
+		models.NewEntryV2("nine", day.Add(9*time.Hour), "", nil, ""),
+		models.NewEntryV2("late", day.Add(23*time.Hour), "", nil, ""),
+	}
+
+	tests := []struct {
+		name          string
+		after, before string
+		want          string
+	}{
+		{"before only", "", "09:00", "early"},
+		{"after only", "09:00", "", "nine,late"},
+		{"window", "06:00", "10:00", "early,nine"},
+		{"wraps midnight", "22:00", "07:00", "early,late"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			afterMinute, err := parseTimeOfDay(tt.after)
+			if err != nil {
+				t.Fatalf("failed to parse --after: %v", err)
+			}
+			beforeMinute, err := parseTimeOfDay(tt.before)
+			if err != nil {
+				t.Fatalf("failed to parse --before: %v", err)
+			}
+
+			var ids []string
+			for _, ent := range filterByTimeOfDay(entries, afterMinute, beforeMinute) {
+				ids = append(ids, ent.GetID())
+			}
+			if strings.Join(ids, ",") != tt.want {
+				t.Errorf("expected %s, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestRunSearch_TimeOfDay(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	if _, err := j.AddWithDate("Morning pages", []string{}, day.Add(7*time.Hour)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.AddWithDate("Evening notes", []string{}, day.Add(20*time.Hour)); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--on", "2024-06-01", "--before", "09:00"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Morning pages") || strings.Contains(output, "Evening notes") {
+		t.Errorf("expected only the morning entry, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--after", "18:00"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Evening notes") || strings.Contains(output, "Morning pages") {
+		t.Errorf("expected only the evening entry, got %q", output)
+	}
+
+	if exitCode := runSearch([]string{"-j", "test", "--after", "25:00"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an invalid time")
+	}
+}
+
+func TestRunSearch_ByText(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Finished the quarterly report", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Went hiking", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--text", "REPORT"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Found 1 entries") || strings.Contains(output, "hiking") {
+		t.Errorf("expected only the matching entry, got %q", output)
+	}
+}
+
+func TestRunSearch_MinMood(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	for content, mood := range map[string]int{"Great day": 5, "Rough day": 2, "Unrated day": 0} {
+		if _, err := j.AddWithOptions(content, []string{"daily"}, entry.AddOptions{Mood: mood}); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-j", "test", "--min-rating", "4"},
+		{"-j", "test", "--tag", "daily", "--min-mood", "4"},
+	} {
+		var exitCode int
+		output := captureStdout(t, func() {
+			exitCode = runSearch(args)
+		})
+		if exitCode != 0 {
+			t.Fatalf("expected exit code 0, got %d", exitCode)
+		}
+		if !strings.Contains(output, "Found 1 entries") || !strings.Contains(output, "Great day") {
+			t.Errorf("expected only the highly rated entry for %v, got %q", args, output)
+		}
+	}
+
+	if exitCode := runSearch([]string{"-j", "test", "--min-mood", "9"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for a mood outside 1-5")
+	}
+}
+
+func TestRunSearch_ByTagPrefix(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Foo kickoff", []string{"project/foo", "project/foo/planning"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Bar review", []string{"project/bar"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Went hiking", []string{"personal"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--tag-prefix", "project/"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Found 2 entries") || strings.Contains(output, "hiking") {
+		t.Errorf("expected the two project entries, got %q", output)
+	}
+}
+
+func TestRunSearch_IDsOnlyAndCountOnly(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	first, err := j.Add("Standup notes", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	second, err := j.Add("Retro notes", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Went hiking", []string{"personal"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--tag", "work", "--ids-only"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	ids := strings.Fields(output)
+	if len(ids) != 2 || !slices.Contains(ids, first.GetID()) || !slices.Contains(ids, second.GetID()) {
+		t.Errorf("expected the two full work entry IDs, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--tag", "work", "--count-only"})
+	})
+	if exitCode != 0 || output != "2\n" {
+		t.Errorf("expected count 2, got %q (exit code %d)", output, exitCode)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--tag", "missing", "--ids-only"})
+	})
+	if exitCode != 0 || output != "" {
+		t.Errorf("expected no output without matches, got %q (exit code %d)", output, exitCode)
+	}
+
+	captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--tag", "work", "--ids-only", "--count-only"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for --ids-only with --count-only")
+	}
+}
+
+func TestRunSearch_ByRegex(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Call 555-1234 about the lease", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if _, err := j.Add("Went hiking", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--regex", `\d{3}-\d{4}`})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Found 1 entries") || strings.Contains(output, "hiking") {
+		t.Errorf("expected only the matching entry, got %q", output)
+	}
+
+	if exitCode := runSearch([]string{"-j", "test", "--regex", "[a-"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an invalid pattern")
+	}
+	if exitCode := runSearch([]string{"-j", "test", "--regex", "a", "--text", "a"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when combining --regex and --text")
+	}
+}
+
+func TestMatchContext(t *testing.T) {
+	content := "one\ntwo\nthree match\nfour\nfive\nsix\nseven match\neight"
+	match := regexp.MustCompile("match")
+
+	want := "two\nthree match\nfour\n--\nsix\nseven match\neight"
+	if got := matchContext(content, match, 1); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	want = "one\ntwo\nthree match\nfour\nfive\nsix\nseven match\neight"
+	if got := matchContext(content, match, 2); got != want {
+		t.Errorf("expected overlapping context to merge, got %q", got)
+	}
+
+	if got := matchContext(content, regexp.MustCompile("title only"), 1); got != content {
+		t.Errorf("expected whole content when no line matches, got %q", got)
+	}
+}
+
+func TestRunSearch_Context(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Breakfast\nLunch with Sam\nDinner\nBed early", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runSearch([]string{"-j", "test", "--text", "dinner", "--context", "1"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Lunch with Sam\nDinner\nBed early") || strings.Count(output, "Breakfast") != 1 {
+		t.Errorf("expected only the lines around the match, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = Run([]string{"journal", "--color=always", "search", "-j", "test", "--regex", "Din+er"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, ansiMatch+"Dinner"+ansiReset) {
+		t.Errorf("expected highlighted match, got %q", output)
+	}
+
+	if exitCode := runSearch([]string{"-j", "test", "--tag", "work", "--context", "2"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for --context without --text or --regex")
+	}
+}
+
+func TestRunSearch_Reverse(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	var ids []string
+	for i, content := range []string{"Project kickoff", "Project review", "Project wrap-up"} {
+		added, err := j.AddWithDate(content, []string{"project"}, time.Date(2024, 3, 1+i, 9, 0, 0, 0, time.Local))
+		if err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+		ids = append(ids, added.GetID())
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"tag", []string{"--tag", "project"}},
+		{"date range", []string{"--from", "2024-03-01", "--to", "2024-03-31"}},
+		{"text", []string{"--text", "project"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exitCode int
+			output := captureStdout(t, func() {
+				exitCode = runSearch(append([]string{"-j", "test", "--reverse", "--ids-only"}, tt.args...))
+			})
+			if exitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d", exitCode)
+			}
+			if got := strings.Fields(output); !slices.Equal(got, ids) {
+				t.Errorf("expected IDs oldest first %v, got %v", ids, got)
+			}
+
+			output = captureStdout(t, func() {
+				exitCode = runSearch(append([]string{"-j", "test", "--reverse"}, tt.args...))
+			})
+			if exitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d", exitCode)
+			}
+			if strings.Index(output, "Project kickoff") > strings.Index(output, "Project wrap-up") {
+				t.Errorf("expected the oldest entry first, got:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestRunSearch_TagIgnoresCase(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	lower, err := j.Add("Lowercase tag", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	mixed, err := j.Add("Mixed-case tag", []string{"Work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	for _, args := range [][]string{{"--tag", "Work"}, {"--tags", "WORK"}, {"--any-tags", "work"}} {
+		var exitCode int
+		output := captureStdout(t, func() {
+			exitCode = runSearch(append([]string{"-j", "test", "--ids-only"}, args...))
+		})
+		if exitCode != 0 {
+			t.Fatalf("%v: expected exit code 0, got %d", args, exitCode)
+		}
+		ids := strings.Fields(output)
+		if len(ids) != 2 || !slices.Contains(ids, lower.GetID()) || !slices.Contains(ids, mixed.GetID()) {
+			t.Errorf("%v: expected both spellings to match, got %q", args, output)
+		}
+	}
+}
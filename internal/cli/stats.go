@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+func runStats(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal stats [flags]")
+		fmt.Println("\nShow entry counts, dates, words, the most used tags, and the average mood over time")
+		fmt.Println("Entries without a mood (see 'journal add --mood') are left out of the averages")
+		fmt.Println("Statistics come from the index; only entries indexed by an older version are")
+		fmt.Println("decrypted to count their words, until 'journal rebuild' records the counts")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, journalCfg, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	stats, err := j.Stats()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to compute statistics: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Journal: %s\n", journalCfg.Name); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Entries: %d\n", stats.Entries); err != nil {
+		return 1
+	}
+	if stats.Entries == 0 {
+		return 0
+	}
+
+	if _, err := fmt.Printf("First entry: %s\n", stats.First.Format("2006-01-02")); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Last entry: %s\n", stats.Last.Format("2006-01-02")); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Words: %d\n", stats.Words); err != nil {
+		return 1
+	}
+
+	if len(stats.Tags) > 0 {
+		if _, err := fmt.Println("\nTop tags:"); err != nil {
+			return 1
+		}
+		for _, tag := range topTags(stats.Tags, statsTopTags) {
+			if _, err := fmt.Printf("  %-20s %d\n", tag, stats.Tags[tag]); err != nil {
+				return 1
+			}
+		}
+	}
+
+	if _, err := fmt.Println("\nEntries by month:"); err != nil {
+		return 1
+	}
+	for _, month := range stats.Months {
+		if _, err := fmt.Printf("  %s  %d\n", month.Month, month.Entries); err != nil {
+			return 1
+		}
+	}
+
+	mood := stats.Mood
+	if mood.Rated == 0 {
+		if _, err := fmt.Println("\nNo entries have a mood rating; add one with 'journal add --mood N'"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if _, err := fmt.Printf("\nRated entries: %d\n", mood.Rated); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Average mood: %.2f\n", mood.Average); err != nil {
+		return 1
+	}
+
+	if _, err := fmt.Println("\nAverage mood by month:"); err != nil {
+		return 1
+	}
+	for _, month := range mood.Months {
+		if _, err := fmt.Printf("  %s  %.2f  (%d rated)\n", month.Month, month.Average, month.Rated); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// statsTopTags is the number of tags listed by the stats command
+const statsTopTags = 10
+
+// topTags returns up to n tags from counts, most used first and then alphabetically
+func topTags(counts map[string]int, n int) []string {
+	tags := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(tags, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunStats(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	for _, opts := range []entry.AddOptions{
+		{Date: time.Date(2024, 5, 2, 9, 0, 0, 0, time.Local), Mood: 3},
+		{Date: time.Date(2024, 6, 1, 9, 0, 0, 0, time.Local), Mood: 5},
+		{Date: time.Date(2024, 6, 2, 9, 0, 0, 0, time.Local), Mood: 4},
+		{Date: time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local)},
+	} {
+		if _, err := j.AddWithOptions("Entry", []string{"daily"}, opts); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runStats([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	for _, want := range []string{"Entries: 4", "First entry: 2024-05-02", "Last entry: 2024-06-03", "Words: 4", "daily", "2024-06  3\n", "Rated entries: 3", "Average mood: 4.00", "2024-05  3.00  (1 rated)", "2024-06  4.50  (2 rated)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got %q", want, output)
+		}
+	}
+}
+
+func TestRunStats_NoRatings(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Unrated entry", nil); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runStats([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "No entries have a mood rating") {
+		t.Errorf("expected a hint about mood ratings, got %q", output)
+	}
+}
+
+func TestRunStats_Empty(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runStats([]string{"-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Entries: 0") || strings.Contains(output, "First entry") {
+		t.Errorf("expected only the entry count for an empty journal, got %q", output)
+	}
+}
+
+func TestTopTags(t *testing.T) {
+	counts := map[string]int{"work": 3, "home": 1, "health": 3, "travel": 2}
+
+	got := topTags(counts, 3)
+	if strings.Join(got, ",") != "health,work,travel" {
+		t.Errorf("expected most used tags first, ties alphabetical, got %v", got)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/data-castle/journal/internal/storage"
+)
+
+// gitCommand is the git executable used by sync
+const gitCommand = "git"
+
+func runSync(args []string) int {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal sync [flags]")
+		fmt.Println("\nRun 'git pull --rebase' and then 'git push' in the journal directory")
+		fmt.Println("The journal directory must be a git repository with an upstream branch")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	journalCfg, err := resolveJournalConfig(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+	dir := journalCfg.Path
+
+	if _, err := gitOutput(dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Error: %s is not a git repository (%v)\n", dir, err); ferr != nil {
+			return 1
+		}
+		if _, ferr := fmt.Fprintln(os.Stderr, "Run 'git init' there and add a remote to sync this journal"); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := gitRun(dir, "pull", "--rebase"); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to pull: %v\n", err); ferr != nil {
+			return 1
+		}
+		if err := printSyncConflicts(dir); err != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if err := gitRun(dir, "push"); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to push: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Journal '%s' synced\n", journalCfg.Name); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// printSyncConflicts explains how to recover from a failed pull, if it left conflicted files
+// A conflicted index or content index can be regenerated from the entry files, so rebuilding is suggested for them
+func printSyncConflicts(dir string) error {
+	out, err := gitOutput(dir, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil
+	}
+	conflicted := strings.Fields(out)
+	if len(conflicted) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(os.Stderr, "\nConflicted files:\n  %s\n", strings.Join(conflicted, "\n  ")); err != nil {
+		return err
+	}
+
+	var indexes []string
+	for _, name := range []string{storage.IndexFileName, storage.ContentIndexFileName} {
+		if slices.Contains(conflicted, name) {
+			indexes = append(indexes, name)
+		}
+	}
+	if len(indexes) > 0 {
+		_, err := fmt.Fprintf(os.Stderr, `
+The index is encrypted and cannot be merged by hand. To recover:
+  1. Keep either version: git checkout --ours %[1]s && git add %[1]s
+  2. Resolve any other conflicted files and run: git rebase --continue
+  3. Regenerate the index from the entry files: journal rebuild
+  4. Commit the rebuilt index and run journal sync again
+`, strings.Join(indexes, " "))
+		return err
+	}
+
+	_, err = fmt.Fprintln(os.Stderr, "\nResolve the conflicts, run 'git rebase --continue', then run journal sync again")
+	return err
+}
+
+// gitRun runs a git command in dir, passing its output through to the terminal
+func gitRun(dir string, args ...string) error {
+	cmd := exec.Command(gitCommand, append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// gitOutput runs a git command in dir and returns its standard output
+func gitOutput(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(gitCommand, append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
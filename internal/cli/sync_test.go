@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+// setupGitJournal turns the test journal into a clone of a new bare remote and returns the remote path
+func setupGitJournal(t *testing.T, journalPath string) string {
+	t.Helper()
+	if _, err := exec.LookPath(gitCommand); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	mustGit(t, "", "init", "--bare", "--initial-branch=main", remote)
+	mustGit(t, journalPath, "init", "--initial-branch=main")
+	mustGit(t, journalPath, "remote", "add", "origin", remote)
+	mustGit(t, journalPath, "add", ".")
+	mustGit(t, journalPath, "commit", "-m", "Initial journal")
+	mustGit(t, journalPath, "push", "-u", "origin", "main")
+	return remote
+}
+
+func mustGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	if out, err := exec.Command(gitCommand, args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestRunSync(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+	remote := setupGitJournal(t, journalCfg.Path)
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Synced entry", []string{}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	mustGit(t, journalCfg.Path, "add", ".")
+	mustGit(t, journalCfg.Path, "commit", "-m", "Add entry")
+
+	if exitCode := runSync([]string{"-j", "test"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	local, err := exec.Command(gitCommand, "-C", journalCfg.Path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("failed to read local HEAD: %v", err)
+	}
+	pushed, err := exec.Command(gitCommand, "-C", remote, "rev-parse", "main").Output()
+	if err != nil {
+		t.Fatalf("failed to read remote main: %v", err)
+	}
+	if string(local) != string(pushed) {
+		t.Errorf("expected remote main %s to match local HEAD %s", pushed, local)
+	}
+}
+
+func TestRunSync_NotGitRepo(t *testing.T) {
+	setupTestJournal(t, "", "")
+	if _, err := exec.LookPath(gitCommand); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+
+	if exitCode := runSync([]string{"-j", "test"}); exitCode == 0 {
+		t.Error("expected non-zero exit code outside a git repository")
+	}
+}
+
+func TestRunSync_IndexConflict(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+	remote := setupGitJournal(t, journalCfg.Path)
+
+	other := filepath.Join(t.TempDir(), "other")
+	mustGit(t, "", "clone", remote, other)
+	if err := os.WriteFile(filepath.Join(other, storage.IndexFileName), []byte("theirs\n"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+	mustGit(t, other, "commit", "-am", "Change index elsewhere")
+	mustGit(t, other, "push")
+
+	if err := os.WriteFile(filepath.Join(journalCfg.Path, storage.IndexFileName), []byte("ours\n"), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+	mustGit(t, journalCfg.Path, "commit", "-am", "Change index here")
+
+	var exitCode int
+	stderr := captureStderr(t, func() {
+		exitCode = runSync([]string{"-j", "test"})
+	})
+	if exitCode == 0 {
+		t.Fatal("expected non-zero exit code for a conflicted pull")
+	}
+	if !strings.Contains(stderr, "journal rebuild") {
+		t.Errorf("expected advice to rebuild the index, got %q", stderr)
+	}
+}
@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/pkg/models"
+)
+
+func runTag(args []string) int {
+	if len(args) < 1 {
+		printTagUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "rename":
+		return runTagRename(args[1:])
+	case "add":
+		return runTagModify("add", args[1:])
+	case "remove":
+		return runTagModify("remove", args[1:])
+	case "defaults":
+		return runTagDefaults(args[1:])
+	case "help", "-h", "--help":
+		printTagUsage()
+		return 0
+	default:
+		if _, err := fmt.Fprintf(os.Stderr, "Unknown tag command: %s\n\n", args[0]); err != nil {
+			return 1
+		}
+		printTagUsage()
+		return 1
+	}
+}
+
+func printTagUsage() {
+	fmt.Println(`Usage: journal tag <command> [flags]
+
+Manage tags across the journal
+
+Available Commands:
+  add               Add tags to an entry (--stdin for entry IDs read from stdin)
+  remove            Remove tags from an entry (--stdin for entry IDs read from stdin)
+  rename            Rename a tag on every entry (merges into an existing tag)
+  defaults          Show or set the tags added to every new entry`)
+}
+
+func runTagRename(args []string) int {
+	fs := flag.NewFlagSet("tag rename", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal tag rename <old> <new> [flags]")
+		fmt.Println("\nRename a tag on every entry that carries it")
+		fmt.Println("If entries already have the new tag, the two tags are merged")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() != 2 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: old and new tag names are required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	oldTag, newTag := fs.Arg(0), fs.Arg(1)
+	changed, err := j.RenameTag(oldTag, newTag)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to rename tag: %v\n", err); ferr != nil {
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Updated %d entries before the failure\n", changed); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Renamed tag '%s' to '%s' on %d entries\n", oldTag, newTag, changed); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// runTagModify handles "tag add" and "tag remove", which differ only in the journal method called
+func runTagModify(action string, args []string) int {
+	fs := flag.NewFlagSet("tag "+action, flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fromStdin := fs.Bool("stdin", false, "Read entry IDs from stdin, one per line, and change the tags of each")
+	fs.Usage = func() {
+		fmt.Printf("Usage: journal tag %s <entry-id> <tags...> [flags]\n", action)
+		fmt.Printf("       journal tag %s --stdin <tags...> [flags]\n", action)
+		fmt.Printf("\n%s tags without changing the entry content\n", strings.ToUpper(action[:1])+action[1:])
+		fmt.Println("With --stdin every entry whose ID is read from stdin is changed, continuing past")
+		fmt.Println("entries that fail, e.g. to re-tag search results:")
+		fmt.Printf("  journal search --tag meeting --ids-only | journal tag %s --stdin standup\n", action)
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *fromStdin && fs.NArg() < 1 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: at least one tag is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	if !*fromStdin && fs.NArg() < 2 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: entry ID and at least one tag are required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	modify := j.AddTags
+	if action == "remove" {
+		modify = j.RemoveTags
+	}
+
+	if *fromStdin {
+		var tagList []string
+		for _, arg := range fs.Args() {
+			tagList = append(tagList, splitTagList(arg)...)
+		}
+		return modifyTagsFromStdin(action, modify, tagList)
+	}
+
+	id := fs.Arg(0)
+	var tagList []string
+	for _, arg := range fs.Args()[1:] {
+		tagList = append(tagList, splitTagList(arg)...)
+	}
+
+	ent, err := modify(id, tagList)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to %s tags: %v\n", action, err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Tags for %s: %s\n", ent.GetID()[:8], strings.Join(ent.GetTags(), ", ")); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// modifyTagsFromStdin applies modify with tagList to every entry ID read from stdin, one per line
+// Blank lines are skipped; a failing entry is reported and the rest are still changed
+func modifyTagsFromStdin(action string, modify func(id string, tags []string) (models.Entry, error), tagList []string) int {
+	scanner := bufio.NewScanner(stdin)
+	total, failed := 0, 0
+	for scanner.Scan() {
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" {
+			continue
+		}
+		total++
+
+		ent, err := modify(id, tagList)
+		if err != nil {
+			failed++
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to %s tags for %s: %v\n", action, id, err); ferr != nil {
+				return 1
+			}
+			continue
+		}
+		if _, err := fmt.Printf("Tags for %s: %s\n", ent.GetID()[:8], strings.Join(ent.GetTags(), ", ")); err != nil {
+			return 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to read entry IDs from stdin: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Updated %d of %d entries (%d failed)\n", total-failed, total, failed); err != nil {
+		return 1
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func runTagDefaults(args []string) int {
+	fs := flag.NewFlagSet("tag defaults", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	clearTags := fs.Bool("clear", false, "Remove all default tags")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal tag defaults [tags...] [flags]")
+		fmt.Println("\nShow the journal's default tags, or replace them with the given tags")
+		fmt.Println("Default tags are added to every new entry along with tags given with -t")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if *clearTags && fs.NArg() > 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: give either tags or --clear, not both\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	journalCfg, err := resolveJournal(cfg, *journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if !*clearTags && fs.NArg() == 0 {
+		if len(journalCfg.DefaultTags) == 0 {
+			if _, err := fmt.Printf("Journal '%s' has no default tags\n", journalCfg.Name); err != nil {
+				return 1
+			}
+			return 0
+		}
+		if _, err := fmt.Printf("Default tags for '%s': %s\n", journalCfg.Name, strings.Join(journalCfg.DefaultTags, ", ")); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	tagList := uniqueTagList(fs.Args())
+	journalCfg.DefaultTags = tagList
+
+	if err := cfg.Save(); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if len(tagList) == 0 {
+		if _, err := fmt.Printf("Cleared default tags for '%s'\n", journalCfg.Name); err != nil {
+			return 1
+		}
+		return 0
+	}
+	if _, err := fmt.Printf("Default tags for '%s': %s\n", journalCfg.Name, strings.Join(tagList, ", ")); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// uniqueTagList splits each comma-separated value into tags, dropping empty and repeated ones
+func uniqueTagList(values []string) []string {
+	var tagList []string
+	for _, value := range values {
+		for _, tag := range splitTagList(value) {
+			if tag != "" && !slices.Contains(tagList, tag) {
+				tagList = append(tagList, tag)
+			}
+		}
+	}
+	return tagList
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunTagRename(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	if _, err := j.Add("Entry with typo", []string{"wrok"}); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	args := []string{"rename", "-j", "test", "wrok", "work"}
+	exitCode := runTag(args)
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	entries, err := j.SearchByTag("work")
+	if err != nil {
+		t.Fatalf("failed to search entries: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry with renamed tag, got %d", len(entries))
+	}
+}
+
+func TestRunTagRename_MissingArgs(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	args := []string{"rename", "-j", "test", "wrok"}
+	exitCode := runTag(args)
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when new tag is missing")
+	}
+}
+
+func TestRunTag_UnknownCommand(t *testing.T) {
+	exitCode := runTag([]string{"frobnicate"})
+
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for unknown tag command")
+	}
+}
+
+func TestRunTagAddAndRemove(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Entry", []string{"work"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	if exitCode := runTag([]string{"add", "-j", "test", ent.GetID(), "meeting", "notes"}); exitCode != 0 {
+		t.Fatalf("expected tag add exit code 0, got %d", exitCode)
+	}
+
+	if exitCode := runTag([]string{"remove", "-j", "test", ent.GetID(), "work"}); exitCode != 0 {
+		t.Fatalf("expected tag remove exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	got, err := j.Get(ent.GetID())
+	if err != nil {
+		t.Fatalf("failed to get entry: %v", err)
+	}
+
+	if strings.Join(got.GetTags(), ",") != "meeting,notes" {
+		t.Errorf("expected tags [meeting notes], got %v", got.GetTags())
+	}
+}
+
+func TestRunTagAdd_MissingTags(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runTag([]string{"add", "-j", "test", "some-id"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when no tags are given")
+	}
+}
+
+func TestRunTagAdd_StdinFromSearch(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	first, err := j.Add("Standup", []string{"meeting"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	second, err := j.Add("Planning", []string{"meeting"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	other, err := j.Add("Went hiking", []string{"personal"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	ids := captureStdout(t, func() {
+		if exitCode := runSearch([]string{"-j", "test", "--tag", "meeting", "--ids-only"}); exitCode != 0 {
+			t.Errorf("expected search exit code 0, got %d", exitCode)
+		}
+	})
+
+	// An unknown ID is reported without stopping the others
+	setStdin(t, ids+"\nmissing-id\n")
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runTag([]string{"add", "-j", "test", "--stdin", "work,team"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code when an entry fails")
+	}
+	if !strings.Contains(output, "Updated 2 of 3 entries (1 failed)") {
+		t.Errorf("expected a summary, got %q", output)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	for _, id := range []string{first.GetID(), second.GetID()} {
+		got, err := j.Get(id)
+		if err != nil {
+			t.Fatalf("failed to get entry: %v", err)
+		}
+		if strings.Join(got.GetTags(), ",") != "meeting,work,team" {
+			t.Errorf("expected tags [meeting work team], got %v", got.GetTags())
+		}
+	}
+	got, err := j.Get(other.GetID())
+	if err != nil {
+		t.Fatalf("failed to get entry: %v", err)
+	}
+	if strings.Join(got.GetTags(), ",") != "personal" {
+		t.Errorf("expected the unmatched entry to be untouched, got %v", got.GetTags())
+	}
+
+	setStdin(t, first.GetID()+"\n"+second.GetID()+"\n")
+	captureStdout(t, func() {
+		exitCode = runTag([]string{"remove", "-j", "test", "--stdin", "team"})
+	})
+	if exitCode != 0 {
+		t.Errorf("expected tag remove exit code 0, got %d", exitCode)
+	}
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	if got, err := j.Get(first.GetID()); err != nil || strings.Join(got.GetTags(), ",") != "meeting,work" {
+		t.Errorf("expected team to be removed, got %v (%v)", got, err)
+	}
+}
+
+func TestRunTagAdd_StdinMissingTags(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runTag([]string{"add", "-j", "test", "--stdin"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when no tags are given")
+	}
+}
+
+func TestRunTagDefaults(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	if exitCode := runTag([]string{"defaults", "-j", "test", "work,daily", "work"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	saved, err := cfg.GetJournal("test")
+	if err != nil {
+		t.Fatalf("failed to get journal: %v", err)
+	}
+	if strings.Join(saved.DefaultTags, ",") != "work,daily" {
+		t.Errorf("expected default tags [work daily], got %v", saved.DefaultTags)
+	}
+
+	j, err := entry.NewJournalFromConfig(saved)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry", []string{"extra"})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if strings.Join(ent.GetTags(), ",") != "work,daily,extra" {
+		t.Errorf("expected default and explicit tags, got %v", ent.GetTags())
+	}
+
+	if exitCode := runTag([]string{"defaults", "-j", "test", "--clear"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if saved := cfg.Journals[journalCfg.Name]; len(saved.DefaultTags) != 0 {
+		t.Errorf("expected default tags to be cleared, got %v", saved.DefaultTags)
+	}
+}
@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func runTemplate(args []string) int {
+	if len(args) < 1 {
+		printTemplateUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "save":
+		return runTemplateSave(args[1:])
+	case "list":
+		return runTemplateList(args[1:])
+	case "show":
+		return runTemplateShow(args[1:])
+	case "delete":
+		return runTemplateDelete(args[1:])
+	case "help", "-h", "--help":
+		printTemplateUsage()
+		return 0
+	default:
+		if _, err := fmt.Fprintf(os.Stderr, "Unknown template command: %s\n\n", args[0]); err != nil {
+			return 1
+		}
+		printTemplateUsage()
+		return 1
+	}
+}
+
+func printTemplateUsage() {
+	fmt.Println(`Usage: journal template <command> [flags]
+
+Manage entry templates, stored encrypted in the journal directory
+Use a template with 'journal add --template <name>'
+
+Available Commands:
+  save              Save a template from text or stdin
+  list              List saved templates
+  show              Print a template
+  delete            Delete a template`)
+}
+
+// parseTemplateArgs parses flags that may come before or after the template name
+// and returns the name followed by any further positional arguments
+func parseTemplateArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		return nil, nil
+	}
+
+	name := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return nil, err
+	}
+	return append([]string{name}, fs.Args()...), nil
+}
+
+func runTemplateSave(args []string) int {
+	fs := flag.NewFlagSet("template save", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal template save <name> [text] [flags]")
+		fmt.Println("\nSave a template, replacing any template with the same name")
+		fmt.Println("Without text, the template is read from stdin")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+		fmt.Println("\nExamples:")
+		fmt.Println("  journal template save gratitude \"Grateful for:\"")
+		fmt.Println("  journal template save daily < daily-template.md")
+	}
+	positional, err := parseTemplateArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if len(positional) == 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: template name is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+	name := positional[0]
+
+	content := strings.Join(positional[1:], " ")
+	if len(positional) == 1 {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to read template from stdin: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		content = strings.TrimRight(string(data), "\n")
+	}
+	if strings.TrimSpace(content) == "" {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: template text is empty\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if err := j.SaveTemplate(name, content); err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to save template: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Template '%s' saved\n", name); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runTemplateList(args []string) int {
+	fs := flag.NewFlagSet("template list", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal template list [flags]")
+		fmt.Println("\nList the journal's saved templates")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	names, err := j.ListTemplates()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to list templates: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if len(names) == 0 {
+		if _, err := fmt.Println("No templates saved (add one with 'journal template save <name>')"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	for _, name := range names {
+		if _, err := fmt.Println(name); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+func runTemplateShow(args []string) int {
+	fs := flag.NewFlagSet("template show", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal template show <name> [flags]")
+		fmt.Println("\nPrint a saved template")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	positional, err := parseTemplateArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if len(positional) != 1 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one template name is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	content, err := j.GetTemplate(positional[0])
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to get template: %v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if _, err := fmt.Println(content); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runTemplateDelete(args []string) int {
+	fs := flag.NewFlagSet("template delete", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal template delete <name> [flags]")
+		fmt.Println("\nDelete a saved template; entries created from it are not changed")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	positional, err := parseTemplateArgs(fs, args)
+	if err != nil {
+		return 1
+	}
+	if len(positional) != 1 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: exactly one template name is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	if err := j.DeleteTemplate(positional[0]); err != nil {
+		if errors.Is(err, entry.ErrTemplateNotFound) {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Template '%s' not found (use 'journal template list' to see templates)\n", positional[0]); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to delete template: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Template '%s' deleted\n", positional[0]); err != nil {
+		return 1
+	}
+	return 0
+}
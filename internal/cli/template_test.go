@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunTemplate_SaveListShowDelete(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runTemplate([]string{"save", "gratitude", "-j", "test", "Grateful for:"}); exitCode != 0 {
+		t.Fatalf("expected save exit code 0, got %d", exitCode)
+	}
+
+	setStdin(t, "Mood:\nTodo:\n")
+	if exitCode := runTemplate([]string{"save", "-j", "test", "daily"}); exitCode != 0 {
+		t.Fatalf("expected save from stdin exit code 0, got %d", exitCode)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runTemplate([]string{"list", "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected list exit code 0, got %d", exitCode)
+	}
+	if output != "daily\ngratitude\n" {
+		t.Errorf("expected both templates listed, got %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		exitCode = runTemplate([]string{"show", "daily", "-j", "test"})
+	})
+	if exitCode != 0 {
+		t.Fatalf("expected show exit code 0, got %d", exitCode)
+	}
+	if output != "Mood:\nTodo:\n" {
+		t.Errorf("expected template read from stdin, got %q", output)
+	}
+
+	if exitCode := runTemplate([]string{"delete", "daily", "-j", "test"}); exitCode != 0 {
+		t.Fatalf("expected delete exit code 0, got %d", exitCode)
+	}
+	if exitCode := runTemplate([]string{"show", "daily", "-j", "test"}); exitCode == 0 {
+		t.Error("expected non-zero exit code showing a deleted template")
+	}
+}
+
+func TestRunAdd_Template(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	if exitCode := runTemplate([]string{"save", "gratitude", "-j", "test", "Grateful for:"}); exitCode != 0 {
+		t.Fatalf("expected save exit code 0, got %d", exitCode)
+	}
+
+	if exitCode := runAdd(session{}, []string{"-j", "test", "--template", "gratitude", "a sunny walk"}); exitCode != 0 {
+		t.Fatalf("expected add exit code 0, got %d", exitCode)
+	}
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	entries, err := j.ListRecent(1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("failed to load entry: %v", err)
+	}
+	if entries[0].GetContent() != "Grateful for:\na sunny walk" {
+		t.Errorf("expected template followed by text, got %q", entries[0].GetContent())
+	}
+
+	if exitCode := runAdd(session{}, []string{"-j", "test", "--template", "missing"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for an unknown template")
+	}
+}
+
+func TestRunTemplate_UnknownCommand(t *testing.T) {
+	if exitCode := runTemplate([]string{"frobnicate"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for unknown template command")
+	}
+	if exitCode := runTemplate([]string{"save"}); exitCode == 0 {
+		t.Error("expected non-zero exit code without a template name")
+	}
+}
+
+func TestRunTemplate_EmptyStdin(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	setStdin(t, "\n")
+	var exitCode int
+	captureStderr(t, func() {
+		exitCode = runTemplate([]string{"save", "blank", "-j", "test"})
+	})
+	if exitCode == 0 {
+		t.Error("expected non-zero exit code for an empty template")
+	}
+	if !strings.Contains(captureStdout(t, func() { runTemplate([]string{"list", "-j", "test"}) }), "No templates") {
+		t.Error("expected no template to be saved")
+	}
+}
@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"filippo.io/age"
@@ -103,3 +105,50 @@ func setupTestConfig(t *testing.T) (string, string) {
 
 	return tmpDir, configPath
 }
+
+// captureStdout runs fn and returns everything it wrote to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureOutput(t, &os.Stdout, fn)
+}
+
+// captureStderr runs fn and returns everything it wrote to stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureOutput(t, &os.Stderr, fn)
+}
+
+// captureOutput temporarily replaces *target with a pipe while fn runs and returns what was written
+func captureOutput(t *testing.T, target **os.File, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	orig := *target
+	*target = w
+	defer func() { *target = orig }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe: %v", err)
+	}
+	return string(<-done)
+}
+
+// setStdin makes confirmation prompts read input instead of the real stdin
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+	original := stdin
+	stdin = strings.NewReader(input)
+	t.Cleanup(func() { stdin = original })
+}
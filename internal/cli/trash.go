@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func runTrash(args []string) int {
+	if len(args) < 1 {
+		printTrashUsage()
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		return runTrashList(args[1:])
+	case "empty":
+		return runTrashEmpty(args[1:])
+	case "help", "-h", "--help":
+		printTrashUsage()
+		return 0
+	default:
+		if _, err := fmt.Fprintf(os.Stderr, "Unknown trash command: %s\n\n", args[0]); err != nil {
+			return 1
+		}
+		printTrashUsage()
+		return 1
+	}
+}
+
+func printTrashUsage() {
+	fmt.Println(`Usage: journal trash <command> [flags]
+
+Manage deleted entries
+
+Available Commands:
+  list              List entries in the trash
+  empty             Permanently delete all entries in the trash`)
+}
+
+func runTrashList(args []string) int {
+	fs := flag.NewFlagSet("trash list", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal trash list [flags]")
+		fmt.Println("\nList entries in the trash")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, _, err := openJournalReadOnly(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	entries, err := j.ListTrash()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to list trash: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if len(entries) == 0 {
+		if _, err := fmt.Println("Trash is empty"); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	for _, ent := range entries {
+		if _, err := fmt.Printf("\n[%s] %s\n", ent.GetDate().Format("2006-01-02 15:04"), ent.GetID()); err != nil {
+			return 1
+		}
+		if len(ent.GetTags()) > 0 {
+			if _, err := fmt.Printf("Tags: %s\n", strings.Join(ent.GetTags(), ", ")); err != nil {
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+func runTrashEmpty(args []string) int {
+	fs := flag.NewFlagSet("trash empty", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal trash empty [flags]")
+		fmt.Println("\nPermanently delete all entries in the trash")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	count, err := j.EmptyTrash()
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to empty trash: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Permanently deleted %d entries\n", count); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runRestore(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal restore [entry-id] [flags]")
+		fmt.Println("\nRestore a deleted entry from the trash")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	if fs.NArg() != 1 {
+		if _, err := fmt.Fprintf(os.Stderr, "Error: entry ID is required\n\n"); err != nil {
+			return 1
+		}
+		fs.Usage()
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	ent, err := j.Restore(fs.Arg(0))
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to restore entry: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Entry %s restored\n", ent.GetID()); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunDeleteAndRestore(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Entry to restore", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	if exitCode := runDelete([]string{"-j", "test", "-y", ent.GetID()}); exitCode != 0 {
+		t.Fatalf("expected delete exit code 0, got %d", exitCode)
+	}
+
+	if exitCode := runTrash([]string{"list", "-j", "test"}); exitCode != 0 {
+		t.Errorf("expected trash list exit code 0, got %d", exitCode)
+	}
+
+	if exitCode := runRestore([]string{"-j", "test", ent.GetID()}); exitCode != 0 {
+		t.Fatalf("expected restore exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	if _, err := j.Get(ent.GetID()); err != nil {
+		t.Errorf("expected restored entry to be readable: %v", err)
+	}
+}
+
+func TestRunDelete_Permanent(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Entry to purge", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	if exitCode := runDelete([]string{"-j", "test", "--permanent", "-y", ent.GetID()}); exitCode != 0 {
+		t.Fatalf("expected delete exit code 0, got %d", exitCode)
+	}
+
+	if exitCode := runRestore([]string{"-j", "test", ent.GetID()}); exitCode == 0 {
+		t.Error("expected non-zero exit code when restoring a permanently deleted entry")
+	}
+}
+
+func TestRunTrashEmpty(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Entry to trash", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	if err := j.Delete(ent.GetID()); err != nil {
+		t.Fatalf("failed to delete entry: %v", err)
+	}
+
+	if exitCode := runTrash([]string{"empty", "-j", "test"}); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+
+	trashed, err := j.ListTrash()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+
+	if len(trashed) != 0 {
+		t.Errorf("expected empty trash, got %d entries", len(trashed))
+	}
+}
+
+func TestRunRestore_MissingID(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runRestore([]string{"-j", "test"}); exitCode == 0 {
+		t.Error("expected non-zero exit code for missing entry ID")
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func runUndo(args []string) int {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal undo [flags]")
+		fmt.Println("\nUndo the most recent entry delete or update")
+		fmt.Println("Only the single most recent operation can be undone")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	j, _, err := openJournal(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return errorExitCode(err)
+	}
+
+	operation, ent, err := j.Undo()
+	if err != nil {
+		if errors.Is(err, entry.ErrNothingToUndo) {
+			if _, ferr := fmt.Fprintln(os.Stderr, "Nothing to undo"); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to undo: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	if _, err := fmt.Printf("Undid %s of entry %s\n", operation, ent.GetID()); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunUndo_Delete(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	ent, err := j.Add("Entry to undelete", []string{})
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	if exitCode := runDelete([]string{"-j", "test", "-y", ent.GetID()}); exitCode != 0 {
+		t.Fatalf("expected delete exit code 0, got %d", exitCode)
+	}
+
+	if exitCode := runUndo([]string{"-j", "test"}); exitCode != 0 {
+		t.Fatalf("expected undo exit code 0, got %d", exitCode)
+	}
+
+	j, err = entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	if _, err := j.Get(ent.GetID()); err != nil {
+		t.Errorf("expected undeleted entry to be readable: %v", err)
+	}
+}
+
+func TestRunUndo_NothingToUndo(t *testing.T) {
+	setupTestJournal(t, "", "")
+
+	if exitCode := runUndo([]string{"-j", "test"}); exitCode == 0 {
+		t.Error("expected non-zero exit code when there is nothing to undo")
+	}
+}
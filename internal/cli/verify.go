@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/data-castle/journal/internal/entry"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	journalName := fs.String("journal", "", "Journal to use")
+	fs.StringVar(journalName, "j", "", "Journal to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal verify [flags]")
+		fmt.Println("\nDecrypt every entry file and the index to detect corrupted or unreadable files")
+		fmt.Println("Each entry's content is also checked against the hash recorded when it was saved")
+		fmt.Println("No entry is modified; the command exits non-zero if any file fails")
+		fmt.Printf("Failed entry files are listed in %s for 'journal doctor'\n", storage.QuarantineLogFileName)
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	// The journal is not opened, so a corrupted index is reported rather than aborting the scan
+	journalCfg, err := resolveJournalConfig(*journalName)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "%v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	result, err := entry.VerifyJournal(journalCfg)
+	if err != nil {
+		if _, ferr := fmt.Fprintf(os.Stderr, "Failed to verify journal: %v\n", err); ferr != nil {
+			return 1
+		}
+		return 1
+	}
+
+	indexStatus := "OK"
+	if result.IndexError != nil {
+		indexStatus = "FAILED"
+	}
+	if _, err := fmt.Printf("Entries: %d OK, %d failed\n", result.ReadableFiles, len(result.FailedFiles)+len(result.CorruptFiles)); err != nil {
+		return 1
+	}
+	if _, err := fmt.Printf("Index: %s\n", indexStatus); err != nil {
+		return 1
+	}
+	if result.QuarantineError != nil {
+		if _, err := fmt.Fprintf(os.Stderr, "Warning: %v\n", result.QuarantineError); err != nil {
+			return 1
+		}
+	}
+
+	if result.OK() {
+		return 0
+	}
+
+	if err := printVerifyFailures(result); err != nil {
+		return 1
+	}
+	return 1
+}
+
+// printVerifyFailures lists every file that failed to decrypt or is corrupted on stderr
+func printVerifyFailures(result *entry.VerifyResult) error {
+	if len(result.FailedFiles) > 0 || result.IndexError != nil {
+		if _, err := fmt.Fprintf(os.Stderr, "\nFiles that failed to decrypt:\n"); err != nil {
+			return err
+		}
+		for _, fe := range result.FailedFiles {
+			if _, err := fmt.Fprintf(os.Stderr, "  - %s: %v\n", fe.FilePath, fe.Error); err != nil {
+				return err
+			}
+		}
+		if result.IndexError != nil {
+			if _, err := fmt.Fprintf(os.Stderr, "  - %s: %v\n", storage.IndexFileName, result.IndexError); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(result.CorruptFiles) > 0 {
+		if _, err := fmt.Fprintf(os.Stderr, "\nEntries that decrypt but are corrupted:\n"); err != nil {
+			return err
+		}
+		for _, fe := range result.CorruptFiles {
+			if _, err := fmt.Fprintf(os.Stderr, "  - %s: %v\n", fe.FilePath, fe.Error); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/entry"
+)
+
+func TestRunVerify(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	if _, err := j.Add("Entry 1", nil); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = runVerify([]string{"-j", "test"})
+	})
+
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(output, "Entries: 1 OK, 0 failed") {
+		t.Errorf("expected summary in output, got %q", output)
+	}
+}
+
+func TestRunVerify_CorruptedFiles(t *testing.T) {
+	_, journalCfg, _ := setupTestJournal(t, "", "")
+
+	j, err := entry.NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	ent, err := j.Add("Entry to corrupt", nil)
+	if err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", ent.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+	indexPath := filepath.Join(journalCfg.Path, "index.yaml")
+	if err := os.WriteFile(indexPath, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("failed to corrupt index: %v", err)
+	}
+
+	var exitCode int
+	stderr := captureStderr(t, func() {
+		exitCode = runVerify([]string{"-j", "test"})
+	})
+
+	if exitCode == 0 {
+		t.Fatal("expected non-zero exit code for corrupted files")
+	}
+	if !strings.Contains(stderr, ent.GetFilePath()) || !strings.Contains(stderr, "index.yaml") {
+		t.Errorf("expected failed file paths in stderr, got %q", stderr)
+	}
+}
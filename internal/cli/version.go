@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/data-castle/journal/internal/crypto"
+)
+
+// versionInfo is the output of version --json
+type versionInfo struct {
+	Version string `json:"version"`
+	// SOPS is the SOPS library version, which is stamped into every file the journal encrypts
+	SOPS string `json:"sops"`
+	Go   string `json:"go"`
+}
+
+func runVersion(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the journal, SOPS, and Go versions as JSON, e.g. for bug reports")
+	fs.Usage = func() {
+		fmt.Println("Usage: journal version [flags]")
+		fmt.Println("\nShow the journal version; --json adds the SOPS and Go versions")
+		fmt.Println("\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	info := versionInfo{
+		Version: Version,
+		SOPS:    crypto.SOPSVersion(),
+		Go:      runtime.Version(),
+	}
+
+	if *jsonOutput {
+		data, err := json.Marshal(info)
+		if err != nil {
+			if _, ferr := fmt.Fprintf(os.Stderr, "Failed to encode version: %v\n", err); ferr != nil {
+				return 1
+			}
+			return 1
+		}
+		if _, err := fmt.Println(string(data)); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	if _, err := fmt.Printf("journal version %s\n", info.Version); err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/data-castle/journal/internal/crypto"
+)
+
+func TestRunVersion(t *testing.T) {
+	output := captureStdout(t, func() {
+		if exitCode := runVersion(nil); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+	if output != "journal version "+Version+"\n" {
+		t.Errorf("unexpected version output %q", output)
+	}
+}
+
+func TestRunVersion_JSON(t *testing.T) {
+	output := captureStdout(t, func() {
+		if exitCode := runVersion([]string{"--json"}); exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+	})
+
+	var info map[string]string
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("failed to decode output %q: %v", output, err)
+	}
+	want := map[string]string{
+		"version": Version,
+		"sops":    crypto.SOPSVersion(),
+		"go":      runtime.Version(),
+	}
+	if len(info) != len(want) {
+		t.Errorf("expected fields %v, got %v", want, info)
+	}
+	for key, value := range want {
+		if info[key] != value {
+			t.Errorf("expected %s %q, got %q", key, value, info[key])
+		}
+	}
+}
@@ -1,23 +1,99 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
+	"github.com/data-castle/journal/internal/atomicfile"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrRemoveDefault is returned by RemoveJournal for the default journal
+var ErrRemoveDefault = errors.New("cannot remove default journal")
+
+// CurrentVersion is the config schema version written by Save
+const CurrentVersion = "1"
+
+// versionHistory lists every config schema version in order; "" is the legacy versionless format
+var versionHistory = []string{"", "1"}
+
+// migrations upgrade a config from the keyed version to the next one in versionHistory
+var migrations = map[string]func(*Config) error{
+	// Versionless configs have the same fields as version 1
+	"": func(c *Config) error { return nil },
+}
+
 // Config represents the global journal configuration
 type Config struct {
+	Version        string              `yaml:"version"`
 	DefaultJournal string              `yaml:"default_journal"`
 	Journals       map[string]*Journal `yaml:"journals"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+	// DefaultRecipients are the public keys 'journal init' encrypts to when no recipients are given
+	DefaultRecipients []string `yaml:"default_recipients,omitempty"`
+	// JournalsDir is where 'journal init' creates journals when no path is given; empty means
+	// DefaultJournalsDirName next to the config file. It may start with ~
+	JournalsDir string `yaml:"journals_dir,omitempty"`
 }
 
+// DefaultJournalsDirName is the directory next to the config file that holds journals created without a path
+const DefaultJournalsDirName = "journals"
+
 // Journal represents a single journal configuration
 type Journal struct {
 	Name string `yaml:"name"`
 	Path string `yaml:"path"`
+	// FilenameTemplate names new entry files, e.g. "{date}-{id}"; empty means "{id}"
+	FilenameTemplate string `yaml:"filename_template,omitempty"`
+	// KeyFiles lists age key files or directories to decrypt with; empty means SOPS_AGE_KEY_FILE
+	KeyFiles []string `yaml:"key_files,omitempty"`
+	// DefaultTags are added to every new entry along with the tags given for it
+	DefaultTags []string `yaml:"default_tags,omitempty"`
+	// UseIndex keeps the encrypted index of entry metadata; nil means true. Without it every open
+	// decrypts all entry files, which avoids index drift but gets slow for large journals
+	UseIndex *bool `yaml:"use_index,omitempty"`
+	// Compress gzips entries before encrypting them, which shrinks long entries on disk and in git
+	Compress bool `yaml:"compress,omitempty"`
+}
+
+// IndexEnabled reports whether the journal keeps an index, which it does unless UseIndex is false
+func (j *Journal) IndexEnabled() bool {
+	return j.UseIndex == nil || *j.UseIndex
+}
+
+// Profile bundles entry defaults that can be selected per entry with --profile
+type Profile struct {
+	Tags       []string `yaml:"tags,omitempty"`
+	Category   string   `yaml:"category,omitempty"`
+	Template   string   `yaml:"template,omitempty"`
+	DateFormat string   `yaml:"date_format,omitempty"`
+}
+
+// Merge returns the profile's defaults with any non-empty field in overrides taking precedence
+// A nil profile yields the overrides unchanged
+func (p *Profile) Merge(overrides Profile) Profile {
+	if p == nil {
+		return overrides
+	}
+
+	merged := *p
+	if len(overrides.Tags) > 0 {
+		merged.Tags = overrides.Tags
+	}
+	if overrides.Category != "" {
+		merged.Category = overrides.Category
+	}
+	if overrides.Template != "" {
+		merged.Template = overrides.Template
+	}
+	if overrides.DateFormat != "" {
+		merged.DateFormat = overrides.DateFormat
+	}
+
+	return merged
 }
 
 // GetConfigPathFunc is the function used to get the config path
@@ -29,8 +105,16 @@ func GetConfigPath() (string, error) {
 	return GetConfigPathFunc()
 }
 
+// ConfigPathEnv names the environment variable that overrides the default config path
+const ConfigPathEnv = "JOURNAL_CONFIG"
+
 // getConfigPathDefault is the default implementation
+// JOURNAL_CONFIG takes precedence over ~/.journal/config.yaml
 func getConfigPathDefault() (string, error) {
+	if path := os.Getenv(ConfigPathEnv); path != "" {
+		return path, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -41,6 +125,7 @@ func getConfigPathDefault() (string, error) {
 // NewConfig creates a new empty configuration
 func NewConfig() *Config {
 	return &Config{
+		Version:  CurrentVersion,
 		Journals: make(map[string]*Journal),
 	}
 }
@@ -53,9 +138,7 @@ func LoadConfig() (*Config, error) {
 	}
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return &Config{
-			Journals: make(map[string]*Journal),
-		}, nil
+		return NewConfig(), nil
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -76,9 +159,31 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("config file is corrupted: 'journals' field is null")
 	}
 
+	if err := config.migrate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// migrate upgrades the config in memory from its version to CurrentVersion
+// The upgraded config is written on the next Save
+func (c *Config) migrate() error {
+	start := slices.Index(versionHistory, c.Version)
+	if start < 0 {
+		return fmt.Errorf("config version %q is not supported by this version of journal (latest is %q); please upgrade journal", c.Version, CurrentVersion)
+	}
+
+	for _, version := range versionHistory[start : len(versionHistory)-1] {
+		if err := migrations[version](c); err != nil {
+			return fmt.Errorf("failed to migrate config from version %q: %w", version, err)
+		}
+	}
+	c.Version = CurrentVersion
+
+	return nil
+}
+
 // Save saves the configuration file
 func (c *Config) Save() error {
 	configPath, err := GetConfigPath()
@@ -91,12 +196,13 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	c.Version = CurrentVersion
 	data, err := yaml.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := atomicfile.WriteFile(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
@@ -167,7 +273,7 @@ func (c *Config) RemoveJournal(name string) error {
 
 	// Prevent deletion of the default journal
 	if c.DefaultJournal == name {
-		return fmt.Errorf("cannot remove default journal %s; use set-default to change default first", name)
+		return fmt.Errorf("%w %s; use set-default to change default first", ErrRemoveDefault, name)
 	}
 
 	delete(c.Journals, name)
@@ -175,6 +281,42 @@ func (c *Config) RemoveJournal(name string) error {
 	return nil
 }
 
+// RenameJournal changes a journal's name, keeping it the default journal if it was
+func (c *Config) RenameJournal(oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("journal name is required")
+	}
+	journal, err := c.GetJournal(oldName)
+	if err != nil {
+		return err
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, exists := c.Journals[newName]; exists {
+		return fmt.Errorf("journal %s already exists", newName)
+	}
+
+	delete(c.Journals, oldName)
+	journal.Name = newName
+	c.Journals[newName] = journal
+
+	if c.DefaultJournal == oldName {
+		c.DefaultJournal = newName
+	}
+
+	return nil
+}
+
+// GetProfile returns a profile by name
+func (c *Config) GetProfile(name string) (*Profile, error) {
+	profile, exists := c.Profiles[name]
+	if !exists || profile == nil {
+		return nil, fmt.Errorf("profile %s not found", name)
+	}
+	return profile, nil
+}
+
 // ListJournals returns all journal names
 func (c *Config) ListJournals() []string {
 	var names []string
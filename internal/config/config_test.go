@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -221,6 +223,113 @@ func TestConfig_Save(t *testing.T) {
 	}
 }
 
+func TestConfig_SaveConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	origFunc := GetConfigPathFunc
+	GetConfigPathFunc = func() (string, error) {
+		return configPath, nil
+	}
+	defer func() { GetConfigPathFunc = origFunc }()
+
+	// Two configs of different sizes, so a torn write would leave invalid YAML behind
+	small := NewConfig()
+	small.DefaultJournal = "small"
+	small.Journals["small"] = &Journal{Name: "small", Path: "/small"}
+
+	large := NewConfig()
+	large.DefaultJournal = "large"
+	for i := range 50 {
+		name := fmt.Sprintf("journal-%d", i)
+		large.Journals[name] = &Journal{Name: name, Path: "/large/" + name, DefaultTags: []string{"work", "notes"}}
+	}
+	large.Journals["large"] = &Journal{Name: "large", Path: "/large"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, cfg := range []*Config{small, large} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if err := cfg.Save(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// Read while the saves interleave; every read must see a complete config
+	for range 50 {
+		if _, err := LoadConfig(); err != nil {
+			t.Fatalf("LoadConfig() during concurrent saves failed: %v", err)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() after concurrent saves failed: %v", err)
+	}
+	wantJournals := map[string]int{"small": 1, "large": 51}[loaded.DefaultJournal]
+	if len(loaded.Journals) != wantJournals {
+		t.Errorf("config mixes both saves: default %q with %d journals", loaded.DefaultJournal, len(loaded.Journals))
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read config dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only config.yaml to remain, got %d files", len(entries))
+	}
+}
+
+func TestConfig_SaveKeepsSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "dotfiles-config.yaml")
+	if err := os.WriteFile(target, []byte("version: \"1\"\njournals: {}\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.Symlink(target, configPath); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	origFunc := GetConfigPathFunc
+	GetConfigPathFunc = func() (string, error) {
+		return configPath, nil
+	}
+	defer func() { GetConfigPathFunc = origFunc }()
+
+	cfg := NewConfig()
+	cfg.DefaultRecipients = []string{"age1example"}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	info, err := os.Lstat(configPath)
+	if err != nil {
+		t.Fatalf("failed to stat config: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("expected config.yaml to remain a symlink")
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("failed to read symlink target: %v", err)
+	}
+	if !strings.Contains(string(data), "age1example") {
+		t.Errorf("expected the symlink target to be updated, got %q", data)
+	}
+}
+
 func TestConfig_AddJournal(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -617,3 +726,256 @@ func TestConfig_ListJournals(t *testing.T) {
 		})
 	}
 }
+
+func TestProfile_Merge(t *testing.T) {
+	daily := &Profile{
+		Tags:       []string{"daily", "reflection"},
+		Category:   "personal",
+		Template:   "Gratitude:",
+		DateFormat: "Jan 2",
+	}
+
+	tests := []struct {
+		name      string
+		profile   *Profile
+		overrides Profile
+		want      Profile
+	}{
+		{
+			name:      "profile defaults applied",
+			profile:   daily,
+			overrides: Profile{},
+			want:      *daily,
+		},
+		{
+			name:      "explicit tags and category override profile",
+			profile:   daily,
+			overrides: Profile{Tags: []string{"work"}, Category: "worklog"},
+			want: Profile{
+				Tags:       []string{"work"},
+				Category:   "worklog",
+				Template:   "Gratitude:",
+				DateFormat: "Jan 2",
+			},
+		},
+		{
+			name:      "nil profile uses overrides",
+			profile:   nil,
+			overrides: Profile{Tags: []string{"work"}},
+			want:      Profile{Tags: []string{"work"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.profile.Merge(tt.overrides)
+
+			if strings.Join(got.Tags, ",") != strings.Join(tt.want.Tags, ",") {
+				t.Errorf("Tags = %v, want %v", got.Tags, tt.want.Tags)
+			}
+			if got.Category != tt.want.Category {
+				t.Errorf("Category = %q, want %q", got.Category, tt.want.Category)
+			}
+			if got.Template != tt.want.Template {
+				t.Errorf("Template = %q, want %q", got.Template, tt.want.Template)
+			}
+			if got.DateFormat != tt.want.DateFormat {
+				t.Errorf("DateFormat = %q, want %q", got.DateFormat, tt.want.DateFormat)
+			}
+		})
+	}
+
+	if len(daily.Tags) != 2 || daily.Category != "personal" {
+		t.Error("Merge should not modify the original profile")
+	}
+}
+
+func TestConfig_GetProfile(t *testing.T) {
+	cfg := &Config{
+		Journals: make(map[string]*Journal),
+		Profiles: map[string]*Profile{
+			"daily": {Tags: []string{"daily"}},
+		},
+	}
+
+	profile, err := cfg.GetProfile("daily")
+	if err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+	if len(profile.Tags) != 1 || profile.Tags[0] != "daily" {
+		t.Errorf("unexpected profile tags: %v", profile.Tags)
+	}
+
+	if _, err := cfg.GetProfile("missing"); err == nil {
+		t.Error("GetProfile() should return error for unknown profile")
+	}
+}
+
+func TestGetConfigPath_Env(t *testing.T) {
+	t.Setenv(ConfigPathEnv, "")
+	defaultPath, err := getConfigPathDefault()
+	if err != nil {
+		t.Fatalf("getConfigPathDefault failed: %v", err)
+	}
+	if !strings.HasSuffix(defaultPath, filepath.Join(".journal", "config.yaml")) {
+		t.Errorf("expected default path under ~/.journal, got %s", defaultPath)
+	}
+
+	envPath := filepath.Join(t.TempDir(), "work.yaml")
+	t.Setenv(ConfigPathEnv, envPath)
+	path, err := getConfigPathDefault()
+	if err != nil {
+		t.Fatalf("getConfigPathDefault failed: %v", err)
+	}
+	if path != envPath {
+		t.Errorf("expected %s from %s, got %s", envPath, ConfigPathEnv, path)
+	}
+}
+
+func TestConfig_RenameJournal(t *testing.T) {
+	cfg := &Config{
+		DefaultJournal: "personal",
+		Journals: map[string]*Journal{
+			"personal": {Name: "personal", Path: "/personal"},
+			"work":     {Name: "work", Path: "/work"},
+		},
+	}
+
+	if err := cfg.RenameJournal("personal", "diary"); err != nil {
+		t.Fatalf("RenameJournal failed: %v", err)
+	}
+
+	journal, err := cfg.GetJournal("diary")
+	if err != nil {
+		t.Fatalf("expected renamed journal: %v", err)
+	}
+	if journal.Name != "diary" || journal.Path != "/personal" {
+		t.Errorf("expected name diary at /personal, got %s at %s", journal.Name, journal.Path)
+	}
+	if _, exists := cfg.Journals["personal"]; exists {
+		t.Error("expected old name to be removed")
+	}
+	if cfg.DefaultJournal != "diary" {
+		t.Errorf("expected default to follow the rename, got %s", cfg.DefaultJournal)
+	}
+
+	if err := cfg.RenameJournal("diary", "work"); err == nil {
+		t.Error("expected error when renaming onto an existing journal")
+	}
+	if err := cfg.RenameJournal("missing", "other"); err == nil {
+		t.Error("expected error when renaming a missing journal")
+	}
+	if err := cfg.RenameJournal("work", ""); err == nil {
+		t.Error("expected error for an empty name")
+	}
+}
+
+func TestLoadConfig_MigratesLegacyConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	legacyYAML := `default_journal: work
+journals:
+  work:
+    name: work
+    path: /home/user/work-journal
+    filename_template: "{date}-{id}"
+    key_files:
+      - /home/user/.config/age/work.txt
+profiles:
+  worklog:
+    tags: [work]
+`
+	if err := os.WriteFile(configPath, []byte(legacyYAML), 0600); err != nil {
+		t.Fatalf("failed to write legacy config: %v", err)
+	}
+
+	origFunc := GetConfigPathFunc
+	GetConfigPathFunc = func() (string, error) {
+		return configPath, nil
+	}
+	defer func() { GetConfigPathFunc = origFunc }()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %q, want %q", cfg.Version, CurrentVersion)
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	reloaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() after save failed: %v", err)
+	}
+
+	work, err := reloaded.GetJournal("work")
+	if err != nil {
+		t.Fatalf("work journal lost in migration: %v", err)
+	}
+	if reloaded.DefaultJournal != "work" || work.Path != "/home/user/work-journal" ||
+		work.FilenameTemplate != "{date}-{id}" || len(work.KeyFiles) != 1 {
+		t.Errorf("journal settings changed in migration: default %q, %+v", reloaded.DefaultJournal, work)
+	}
+	if profile, err := reloaded.GetProfile("worklog"); err != nil || len(profile.Tags) != 1 {
+		t.Errorf("profile lost in migration: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read saved config: %v", err)
+	}
+	if !strings.Contains(string(data), "version: \""+CurrentVersion+"\"") {
+		t.Errorf("expected saved config to record version %s, got:\n%s", CurrentVersion, data)
+	}
+}
+
+func TestLoadConfig_FutureVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	futureYAML := `version: "99"
+default_journal: ""
+journals: {}
+`
+	if err := os.WriteFile(configPath, []byte(futureYAML), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	origFunc := GetConfigPathFunc
+	GetConfigPathFunc = func() (string, error) {
+		return configPath, nil
+	}
+	defer func() { GetConfigPathFunc = origFunc }()
+
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("expected error for a config from a newer version")
+	}
+	if !strings.Contains(err.Error(), "upgrade") {
+		t.Errorf("expected error to suggest upgrading, got: %v", err)
+	}
+}
+
+func TestJournalIndexEnabled(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name     string
+		useIndex *bool
+		want     bool
+	}{
+		{"unset", nil, true},
+		{"true", &enabled, true},
+		{"false", &disabled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &Journal{Name: "test", UseIndex: tt.useIndex}
+			if got := j.IndexEnabled(); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
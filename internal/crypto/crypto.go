@@ -1,25 +1,53 @@
 package crypto
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"github.com/data-castle/journal/internal/atomicfile"
 	"github.com/getsops/sops/v3"
 	"github.com/getsops/sops/v3/aes"
 	sopsage "github.com/getsops/sops/v3/age"
 	"github.com/getsops/sops/v3/decrypt"
 	"github.com/getsops/sops/v3/keyservice"
 	sopsyaml "github.com/getsops/sops/v3/stores/yaml"
+	"github.com/getsops/sops/v3/version"
 	"gopkg.in/yaml.v3"
 )
 
+// Errors returned by the recipient functions, for use with errors.Is
+var (
+	ErrRecipientExists   = errors.New("recipient already exists")
+	ErrRecipientNotFound = errors.New("recipient not found")
+	ErrLastRecipient     = errors.New("cannot remove last recipient")
+)
+
+// ErrDecryptionFailed is returned when an encrypted file cannot be decrypted,
+// typically because no age key is available or the key is not a recipient
+var ErrDecryptionFailed = errors.New("failed to decrypt file")
+
+// ErrAlreadyEncrypted is returned by EncryptFile for a file that already has SOPS metadata
+var ErrAlreadyEncrypted = errors.New("file is already encrypted")
+
 // Encryptor handles encryption and decryption using SOPS
 type Encryptor struct {
 	journalPath string   // Path to journal directory (contains .sops.yaml)
 	recipients  []string // Age public keys for encryption
+
+	// masterKeys caches the parsed recipients; cachedRecipients records what they were parsed from
+	masterKeys       []*sopsage.MasterKey
+	cachedRecipients []string
+
+	// identities are tried in turn when decrypting; if empty, SOPS finds keys itself (SOPS_AGE_KEY_FILE)
+	identities sopsage.ParsedIdentities
 }
 
 // NewEncryptor creates a SOPS-based encryptor
@@ -36,13 +64,26 @@ func NewEncryptor(journalPath string) (*Encryptor, error) {
 	}, nil
 }
 
+// NewEncryptorWithRecipients creates an encryptor for the given recipients without reading .sops.yaml
+// Useful when re-encrypting for recipients that are not yet, or no longer, in .sops.yaml
+func NewEncryptorWithRecipients(journalPath string, recipients []string) *Encryptor {
+	return &Encryptor{
+		journalPath: journalPath,
+		recipients:  slices.Clone(recipients),
+	}
+}
+
 // EncryptFile encrypts a YAML file using SOPS
 // filePath: absolute path to the file to encrypt
+// A file that is already encrypted is refused with ErrAlreadyEncrypted rather than encrypted twice
 func (e *Encryptor) EncryptFile(filePath string) error {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
+	if IsEncrypted(data) {
+		return fmt.Errorf("%w: %s", ErrAlreadyEncrypted, filePath)
+	}
 
 	store := sopsyaml.Store{}
 
@@ -60,7 +101,7 @@ func (e *Encryptor) EncryptFile(filePath string) error {
 		Branches: branches,
 		Metadata: sops.Metadata{
 			KeyGroups: keyGroups,
-			Version:   "3.9.2",
+			Version:   version.Version,
 		},
 	}
 
@@ -87,25 +128,266 @@ func (e *Encryptor) EncryptFile(filePath string) error {
 		return fmt.Errorf("failed to emit encrypted YAML: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, encryptedData, 0600); err != nil {
+	if err := atomicfile.WriteFile(filePath, encryptedData, 0600); err != nil {
 		return fmt.Errorf("failed to write encrypted file: %w", err)
 	}
 
 	return nil
 }
 
+// IsEncrypted reports whether data is a YAML document with a top-level sops metadata block
+func IsEncrypted(data []byte) bool {
+	var doc map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["sops"]
+	return ok
+}
+
+// SOPSVersion returns the version of the SOPS library this binary is built against,
+// which is stamped into the metadata of every file it encrypts
+func SOPSVersion() string {
+	return version.Version
+}
+
+// StampedSOPSVersion returns the SOPS version recorded in the metadata of an encrypted file
+// The file is only parsed, not decrypted, so no key is needed
+func StampedSOPSVersion(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var doc struct {
+		Sops *struct {
+			Version string `yaml:"version"`
+		} `yaml:"sops"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if doc.Sops == nil {
+		return "", fmt.Errorf("%s has no SOPS metadata", filePath)
+	}
+	return doc.Sops.Version, nil
+}
+
+// IsNewerSOPSVersion reports whether a stamped version is newer than the SOPS library,
+// meaning the file was written by a newer SOPS that this binary may not fully understand
+func IsNewerSOPSVersion(stamped string) (bool, error) {
+	return version.AIsNewerThanB(stamped, version.Version)
+}
+
 // DecryptFile decrypts a SOPS-encrypted file and returns the content
 // filePath: absolute path to the encrypted file
 func (e *Encryptor) DecryptFile(filePath string) ([]byte, error) {
-	cleartext, err := decrypt.File(filePath, "yaml")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	cleartext, err := e.decryptData(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt file: %w", err)
+		// A file without SOPS metadata is malformed rather than unreadable with the current key
+		if errors.Is(err, sops.MetadataNotFound) {
+			return nil, fmt.Errorf("failed to decrypt file: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
 	}
 
 	return cleartext, nil
 }
 
-// EncryptYAMLInMemory encrypts YAML data in memory and writes only the encrypted result
+// SetKeyFiles loads the age identities used for decryption from files or directories
+// Every file in a directory is read; all identities found are tried in turn
+// With no paths, SOPS looks up keys itself, e.g. from SOPS_AGE_KEY_FILE
+func (e *Encryptor) SetKeyFiles(paths []string) error {
+	identities, err := loadIdentities(paths)
+	if err != nil {
+		return err
+	}
+
+	e.identities = identities
+	return nil
+}
+
+// IdentityRecipients returns the public keys of the age identities in the given key files or directories
+// ssh identities are left out, since age does not expose their public keys
+func IdentityRecipients(paths []string) ([]string, error) {
+	identities, err := loadIdentities(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x25519.Recipient().String())
+		}
+	}
+	return recipients, nil
+}
+
+// GenerateKeyFile generates a new age identity and writes it to path in the format of age-keygen,
+// readable only by the owner, and returns its public key
+// An existing file at path is never overwritten, since it may hold the only copy of another key
+func GenerateKeyFile(path string) (string, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate age identity: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create key file: %w", err)
+	}
+
+	recipient := identity.Recipient().String()
+	_, err = fmt.Fprintf(file, "# created: %s\n# public key: %s\n%s\n",
+		time.Now().Format(time.RFC3339), recipient, identity.String())
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	return recipient, nil
+}
+
+// loadIdentities parses every age identity in the given key files or directories
+func loadIdentities(paths []string) (sopsage.ParsedIdentities, error) {
+	var identities sopsage.ParsedIdentities
+
+	for _, path := range paths {
+		files, err := keyFilesIn(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read key file: %w", err)
+			}
+
+			// ssh private keys can decrypt files encrypted to their ssh public keys
+			if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+				identity, err := agessh.ParseIdentity(data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse ssh key file %s (passphrase-protected keys are not supported): %w", file, err)
+				}
+				identities = append(identities, identity)
+				continue
+			}
+
+			parsed, err := age.ParseIdentities(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse key file %s: %w", file, err)
+			}
+			identities = append(identities, parsed...)
+		}
+	}
+
+	if len(paths) > 0 && len(identities) == 0 {
+		return nil, fmt.Errorf("no age identities found in key files")
+	}
+
+	return identities, nil
+}
+
+// keyFilesIn returns path itself, or the regular files in it if path is a directory
+func keyFilesIn(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// decryptData decrypts SOPS-encrypted YAML, using the configured identities if there are any
+func (e *Encryptor) decryptData(data []byte) ([]byte, error) {
+	if len(e.identities) == 0 {
+		return decrypt.Data(data, "yaml")
+	}
+
+	store := sopsyaml.Store{}
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := e.decryptDataKey(tree.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(dataKey, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt tree: %w", err)
+	}
+
+	originalMac, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt MAC: %w", err)
+	}
+	if originalMac != mac {
+		return nil, fmt.Errorf("failed to verify data integrity")
+	}
+
+	return store.EmitPlainFile(tree.Branches)
+}
+
+// decryptDataKey returns the file's data key from the first age key the identities can decrypt
+func (e *Encryptor) decryptDataKey(metadata sops.Metadata) ([]byte, error) {
+	var errs []error
+	for _, group := range metadata.KeyGroups {
+		for _, key := range group {
+			ageKey, ok := key.(*sopsage.MasterKey)
+			if !ok {
+				continue
+			}
+
+			e.identities.ApplyToMasterKey(ageKey)
+			dataKey, err := ageKey.Decrypt()
+			if err == nil {
+				return dataKey, nil
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("file has no age recipients")
+	}
+	return nil, fmt.Errorf("none of the configured age identities can decrypt this file: %w", errors.Join(errs...))
+}
+
+// EncryptYAMLInMemory encrypts YAML data in memory and writes only the encrypted result,
+// replacing filePath atomically so an interrupted save leaves the previous file intact
 // data: the data structure to encrypt
 // filePath: where to write the encrypted file
 func (e *Encryptor) EncryptYAMLInMemory(data any, filePath string) error {
@@ -130,7 +412,7 @@ func (e *Encryptor) EncryptYAMLInMemory(data any, filePath string) error {
 		Branches: branches,
 		Metadata: sops.Metadata{
 			KeyGroups: keyGroups,
-			Version:   "3.9.2",
+			Version:   version.Version,
 		},
 	}
 
@@ -157,7 +439,7 @@ func (e *Encryptor) EncryptYAMLInMemory(data any, filePath string) error {
 		return fmt.Errorf("failed to emit encrypted YAML: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, encryptedData, 0600); err != nil {
+	if err := atomicfile.WriteFile(filePath, encryptedData, 0600); err != nil {
 		return fmt.Errorf("failed to write encrypted file: %w", err)
 	}
 
@@ -191,25 +473,49 @@ func (e *Encryptor) DecryptYAML(filePath string, target any) error {
 }
 
 // createKeyGroups creates SOPS key groups from age recipients
+// Recipients are parsed once and cached until they change
 func (e *Encryptor) createKeyGroups() ([]sops.KeyGroup, error) {
-	var keyGroup sops.KeyGroup
-
-	for _, recipient := range e.recipients {
-		ageRecipient, err := age.ParseX25519Recipient(recipient)
+	if e.masterKeys == nil || !slices.Equal(e.cachedRecipients, e.recipients) {
+		masterKeys, err := parseMasterKeys(e.recipients)
 		if err != nil {
+			return nil, err
+		}
+		e.masterKeys = masterKeys
+		e.cachedRecipients = slices.Clone(e.recipients)
+	}
+
+	// Encryption stores the encrypted data key on each master key, so every tree gets its own copies
+	keyGroup := make(sops.KeyGroup, 0, len(e.masterKeys))
+	for _, cached := range e.masterKeys {
+		key := *cached
+		keyGroup = append(keyGroup, &key)
+	}
+
+	return []sops.KeyGroup{keyGroup}, nil
+}
+
+// parseMasterKeys parses age recipients into SOPS master keys
+func parseMasterKeys(recipients []string) ([]*sopsage.MasterKey, error) {
+	var masterKeys []*sopsage.MasterKey
+
+	for _, recipient := range recipients {
+		if err := ValidateRecipient(recipient); err != nil {
 			return nil, fmt.Errorf("invalid age recipient %s: %w", recipient, err)
 		}
 
-		keyGroup = append(keyGroup, &sopsage.MasterKey{
-			Recipient: ageRecipient.String(),
-		})
+		// SOPS parses both age and ssh public keys itself
+		masterKey, err := sopsage.MasterKeyFromRecipient(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %s: %w", recipient, err)
+		}
+		masterKeys = append(masterKeys, masterKey)
 	}
 
-	if len(keyGroup) == 0 {
+	if len(masterKeys) == 0 {
 		return nil, fmt.Errorf("no valid recipients found")
 	}
 
-	return []sops.KeyGroup{keyGroup}, nil
+	return masterKeys, nil
 }
 
 // SOPSConfig represents the .sops.yaml configuration file
@@ -223,15 +529,31 @@ type CreationRule struct {
 	Age       string `yaml:"age"`
 }
 
-// ValidateRecipient validates that a recipient is a valid age public key
+// ValidateRecipient validates that a recipient is a valid age public key, or an ssh-ed25519 or
+// ssh-rsa public key in authorized_keys format, which age encrypts to as well
 func ValidateRecipient(recipient string) error {
-	_, err := age.ParseX25519Recipient(recipient)
-	if err != nil {
-		return fmt.Errorf("invalid age public key: %w", err)
+	if !IsSSHRecipient(recipient) {
+		if _, err := age.ParseX25519Recipient(recipient); err != nil {
+			return fmt.Errorf("invalid age public key: %w", err)
+		}
+		return nil
+	}
+
+	// .sops.yaml joins recipients with commas, so a comma in the key's comment would split it
+	if strings.Contains(recipient, ",") {
+		return fmt.Errorf("invalid ssh public key: must not contain a comma")
+	}
+	if _, err := agessh.ParseRecipient(recipient); err != nil {
+		return fmt.Errorf("invalid ssh public key: %w", err)
 	}
 	return nil
 }
 
+// IsSSHRecipient reports whether recipient is an ssh public key rather than an age public key
+func IsSSHRecipient(recipient string) bool {
+	return strings.HasPrefix(recipient, "ssh-ed25519 ") || strings.HasPrefix(recipient, "ssh-rsa ")
+}
+
 // CreateSOPSConfig creates or updates a .sops.yaml file with age recipients
 // journalPath: path to journal directory
 // recipients: list of age public keys
@@ -313,7 +635,7 @@ func AddRecipient(journalPath string, newRecipient string) error {
 
 	for _, r := range recipients {
 		if r == newRecipient {
-			return fmt.Errorf("recipient already exists")
+			return ErrRecipientExists
 		}
 	}
 
@@ -339,11 +661,11 @@ func RemoveRecipient(journalPath string, recipientToRemove string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("recipient not found")
+		return ErrRecipientNotFound
 	}
 
 	if len(newRecipients) == 0 {
-		return fmt.Errorf("cannot remove last recipient")
+		return ErrLastRecipient
 	}
 
 	return CreateSOPSConfig(journalPath, newRecipients)
@@ -419,7 +741,7 @@ func PrepareAddRecipient(journalPath string, newRecipient string) ([]string, err
 
 	for _, r := range recipients {
 		if r == newRecipient {
-			return nil, fmt.Errorf("recipient already exists")
+			return nil, ErrRecipientExists
 		}
 	}
 
@@ -445,11 +767,11 @@ func PrepareRemoveRecipient(journalPath string, recipientToRemove string) ([]str
 	}
 
 	if !found {
-		return nil, fmt.Errorf("recipient not found")
+		return nil, ErrRecipientNotFound
 	}
 
 	if len(newRecipients) == 0 {
-		return nil, fmt.Errorf("cannot remove last recipient")
+		return nil, ErrLastRecipient
 	}
 
 	return newRecipients, nil
@@ -1,12 +1,18 @@
 package crypto
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
 	"filippo.io/age"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestNewEncryptor(t *testing.T) {
@@ -232,8 +238,8 @@ func TestAddRecipient_Duplicate(t *testing.T) {
 		t.Error("expected error when adding duplicate recipient")
 	}
 
-	if !strings.Contains(err.Error(), "recipient already exists") {
-		t.Errorf("expected 'recipient already exists' error, got: %v", err)
+	if !errors.Is(err, ErrRecipientExists) {
+		t.Errorf("expected ErrRecipientExists, got: %v", err)
 	}
 }
 
@@ -289,8 +295,8 @@ func TestRemoveRecipient_NotFound(t *testing.T) {
 		t.Error("expected error when removing non-existent recipient")
 	}
 
-	if !strings.Contains(err.Error(), "recipient not found") {
-		t.Errorf("expected 'recipient not found' error, got: %v", err)
+	if !errors.Is(err, ErrRecipientNotFound) {
+		t.Errorf("expected ErrRecipientNotFound, got: %v", err)
 	}
 }
 
@@ -310,8 +316,8 @@ func TestRemoveRecipient_LastRecipient(t *testing.T) {
 		t.Error("expected error when removing last recipient")
 	}
 
-	if !strings.Contains(err.Error(), "cannot remove last recipient") {
-		t.Errorf("expected 'cannot remove last recipient' error, got: %v", err)
+	if !errors.Is(err, ErrLastRecipient) {
+		t.Errorf("expected ErrLastRecipient, got: %v", err)
 	}
 }
 
@@ -464,6 +470,87 @@ func TestEncryptFile(t *testing.T) {
 	if strings.Contains(string(encryptedContent), "secret data") {
 		t.Error("encrypted file contains plaintext data")
 	}
+
+	if err := enc.EncryptFile(testFile); !errors.Is(err, ErrAlreadyEncrypted) {
+		t.Errorf("expected ErrAlreadyEncrypted when encrypting twice, got %v", err)
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"sops metadata", "content: ENC[AES256_GCM,data:abc]\nsops:\n  version: 3.9.2\n", true},
+		{"plain yaml", "content: hello\n", false},
+		{"nested sops key", "meta:\n  sops: true\n", false},
+		{"not yaml", "- [unclosed", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEncrypted([]byte(tt.data)); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStampedSOPSVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+	enc := NewEncryptorWithRecipients(tmpDir, []string{identity.Recipient().String()})
+
+	encPath := filepath.Join(tmpDir, "encrypted.yaml")
+	if err := enc.EncryptYAMLInMemory(map[string]string{"content": "secret"}, encPath); err != nil {
+		t.Fatalf("EncryptYAMLInMemory failed: %v", err)
+	}
+
+	stamped, err := StampedSOPSVersion(encPath)
+	if err != nil {
+		t.Fatalf("StampedSOPSVersion failed: %v", err)
+	}
+	if stamped != SOPSVersion() {
+		t.Errorf("expected files to be stamped with the library version %s, got %s", SOPSVersion(), stamped)
+	}
+
+	plainPath := filepath.Join(tmpDir, "plain.yaml")
+	if err := os.WriteFile(plainPath, []byte("content: plain\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := StampedSOPSVersion(plainPath); err == nil {
+		t.Error("expected error for a file without SOPS metadata")
+	}
+}
+
+func TestIsNewerSOPSVersion(t *testing.T) {
+	tests := []struct {
+		stamped string
+		want    bool
+	}{
+		{"3.9.2", false},
+		{SOPSVersion(), false},
+		{"99.0.0", true},
+	}
+
+	for _, tt := range tests {
+		got, err := IsNewerSOPSVersion(tt.stamped)
+		if err != nil {
+			t.Fatalf("IsNewerSOPSVersion(%q) failed: %v", tt.stamped, err)
+		}
+		if got != tt.want {
+			t.Errorf("IsNewerSOPSVersion(%q) = %v, want %v", tt.stamped, got, tt.want)
+		}
+	}
+
+	if _, err := IsNewerSOPSVersion("not-a-version"); err == nil {
+		t.Error("expected error for an invalid version")
+	}
 }
 
 // TestDecryptFile tests decrypting a file
@@ -528,3 +615,363 @@ func TestDecryptFile(t *testing.T) {
 		t.Errorf("expected content %q, got %q", expectedContent, string(decryptedContent))
 	}
 }
+
+func TestDecryptFile_WrongKey(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	recipient, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+
+	keyPath := filepath.Join(tmpDir, "key.txt")
+	if err := os.WriteFile(keyPath, []byte(other.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+
+	enc := NewEncryptorWithRecipients(tmpDir, []string{recipient.Recipient().String()})
+
+	encryptedFile := filepath.Join(tmpDir, "encrypted.yaml")
+	if err := enc.EncryptYAMLInMemory(map[string]string{"message": "secret"}, encryptedFile); err != nil {
+		t.Fatalf("EncryptYAMLInMemory failed: %v", err)
+	}
+
+	if _, err := enc.DecryptFile(encryptedFile); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("expected ErrDecryptionFailed, got: %v", err)
+	}
+
+	plainFile := filepath.Join(tmpDir, "plain.yaml")
+	if err := os.WriteFile(plainFile, []byte("message: not encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to write plain file: %v", err)
+	}
+
+	_, err = enc.DecryptFile(plainFile)
+	if err == nil || errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("expected a non-key error for an unencrypted file, got: %v", err)
+	}
+}
+
+// writeIdentityFile writes an age identity to a key file in dir
+func writeIdentityFile(t *testing.T, dir, name string, identity *age.X25519Identity) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	return path
+}
+
+// writeSSHKeyFile generates an ssh-ed25519 key, writes the private key to a key file in dir
+// and returns its path with the public key in authorized_keys format
+func writeSSHKeyFile(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ed25519 key: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("failed to convert ssh public key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("failed to marshal ssh private key: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	return path, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " user@host"
+}
+
+func TestValidateRecipient(t *testing.T) {
+	_, sshRecipient := writeSSHKeyFile(t, t.TempDir(), "id_ed25519")
+
+	tests := []struct {
+		name      string
+		recipient string
+		wantErr   bool
+	}{
+		{"age key", generateRecipients(1)[0], false},
+		{"ssh-ed25519 key", sshRecipient, false},
+		{"ssh key with comma in comment", sshRecipient + ",other", true},
+		{"truncated ssh key", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5", true},
+		{"ssh-rsa without key", "ssh-rsa ", true},
+		{"unsupported ssh key type", "ssh-dss AAAAB3NzaC1kc3M=", true},
+		{"garbage", "not-a-key", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRecipient(tt.recipient)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRecipient(%q) error = %v, wantErr %v", tt.recipient, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSSHRecipient_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	keyPath, sshRecipient := writeSSHKeyFile(t, tmpDir, "id_ed25519")
+	recipients := []string{generateRecipients(1)[0], sshRecipient}
+
+	if err := CreateSOPSConfig(tmpDir, recipients); err != nil {
+		t.Fatalf("CreateSOPSConfig failed: %v", err)
+	}
+	read, err := ReadSOPSConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadSOPSConfig failed: %v", err)
+	}
+	if len(read) != 2 || read[1] != sshRecipient {
+		t.Fatalf("expected the ssh recipient to survive .sops.yaml, got %v", read)
+	}
+
+	enc, err := NewEncryptor(tmpDir)
+	if err != nil {
+		t.Fatalf("NewEncryptor failed: %v", err)
+	}
+
+	encryptedFile := filepath.Join(tmpDir, "encrypted.yaml")
+	if err := enc.EncryptYAMLInMemory(map[string]string{"message": "secret"}, encryptedFile); err != nil {
+		t.Fatalf("EncryptYAMLInMemory failed: %v", err)
+	}
+
+	if err := enc.SetKeyFiles([]string{keyPath}); err != nil {
+		t.Fatalf("SetKeyFiles failed: %v", err)
+	}
+	decrypted, err := enc.DecryptFile(encryptedFile)
+	if err != nil {
+		t.Fatalf("DecryptFile with the ssh key failed: %v", err)
+	}
+	if !strings.Contains(string(decrypted), "secret") {
+		t.Errorf("expected decrypted content, got %q", decrypted)
+	}
+}
+
+func TestSetKeyFiles_SecondIdentityDecrypts(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	personal, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+	work, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+
+	keyDir := filepath.Join(tmpDir, "keys")
+	if err := os.MkdirAll(keyDir, 0700); err != nil {
+		t.Fatalf("failed to create key directory: %v", err)
+	}
+	personalKey := writeIdentityFile(t, keyDir, "personal.txt", personal)
+	workKey := writeIdentityFile(t, keyDir, "work.txt", work)
+
+	// Only the work identity is a recipient
+	enc := NewEncryptorWithRecipients(tmpDir, []string{work.Recipient().String()})
+
+	encryptedFile := filepath.Join(tmpDir, "encrypted.yaml")
+	if err := enc.EncryptYAMLInMemory(map[string]string{"message": "secret"}, encryptedFile); err != nil {
+		t.Fatalf("EncryptYAMLInMemory failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{"list of files", []string{personalKey, workKey}},
+		{"directory", []string{keyDir}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := enc.SetKeyFiles(tt.paths); err != nil {
+				t.Fatalf("SetKeyFiles failed: %v", err)
+			}
+
+			decrypted, err := enc.DecryptFile(encryptedFile)
+			if err != nil {
+				t.Fatalf("DecryptFile failed: %v", err)
+			}
+
+			if !strings.Contains(string(decrypted), "secret") {
+				t.Errorf("expected decrypted content, got %q", decrypted)
+			}
+		})
+	}
+
+	if err := enc.SetKeyFiles([]string{personalKey}); err != nil {
+		t.Fatalf("SetKeyFiles failed: %v", err)
+	}
+	if _, err := enc.DecryptFile(encryptedFile); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("expected ErrDecryptionFailed with only the wrong identity, got: %v", err)
+	}
+}
+
+func TestIdentityRecipients(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate age identity: %v", err)
+	}
+	keyPath := writeIdentityFile(t, tmpDir, "key.txt", identity)
+
+	recipients, err := IdentityRecipients([]string{keyPath})
+	if err != nil {
+		t.Fatalf("IdentityRecipients failed: %v", err)
+	}
+
+	if len(recipients) != 1 || recipients[0] != identity.Recipient().String() {
+		t.Errorf("expected [%s], got %v", identity.Recipient().String(), recipients)
+	}
+}
+
+func TestGenerateKeyFile(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "keys", "journal.txt")
+
+	recipient, err := GenerateKeyFile(keyPath)
+	if err != nil {
+		t.Fatalf("GenerateKeyFile failed: %v", err)
+	}
+
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatalf("key file was not created: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected key file mode 0600, got %v", info.Mode().Perm())
+	}
+
+	recipients, err := IdentityRecipients([]string{keyPath})
+	if err != nil {
+		t.Fatalf("IdentityRecipients failed: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != recipient {
+		t.Errorf("expected the key file to hold the identity for %s, got %v", recipient, recipients)
+	}
+
+	if _, err := GenerateKeyFile(keyPath); err == nil {
+		t.Error("expected an error instead of overwriting an existing key file")
+	}
+	if again, err := IdentityRecipients([]string{keyPath}); err != nil || again[0] != recipient {
+		t.Errorf("expected the existing key to be kept, got %v (%v)", again, err)
+	}
+}
+
+func TestSetKeyFiles_Invalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	enc := NewEncryptorWithRecipients(tmpDir, generateRecipients(1))
+
+	if err := enc.SetKeyFiles([]string{filepath.Join(tmpDir, "missing.txt")}); err == nil {
+		t.Error("expected error for a missing key file")
+	}
+
+	emptyDir := filepath.Join(tmpDir, "empty")
+	if err := os.MkdirAll(emptyDir, 0700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := enc.SetKeyFiles([]string{emptyDir}); err == nil {
+		t.Error("expected error for a directory without identities")
+	}
+}
+
+func TestCreateKeyGroups_CachesUntilRecipientsChange(t *testing.T) {
+	enc := &Encryptor{recipients: generateRecipients(2)}
+
+	first, err := enc.createKeyGroups()
+	if err != nil {
+		t.Fatalf("createKeyGroups failed: %v", err)
+	}
+	cached := enc.masterKeys
+
+	second, err := enc.createKeyGroups()
+	if err != nil {
+		t.Fatalf("createKeyGroups failed: %v", err)
+	}
+
+	if &enc.masterKeys[0] != &cached[0] {
+		t.Error("expected parsed recipients to be reused")
+	}
+
+	if first[0][0] == second[0][0] {
+		t.Error("expected each call to return its own master key copies")
+	}
+
+	enc.recipients = generateRecipients(3)
+	third, err := enc.createKeyGroups()
+	if err != nil {
+		t.Fatalf("createKeyGroups failed: %v", err)
+	}
+
+	if len(third[0]) != 3 {
+		t.Errorf("expected 3 keys after recipients changed, got %d", len(third[0]))
+	}
+}
+
+func TestCreateKeyGroups_InvalidRecipient(t *testing.T) {
+	enc := &Encryptor{recipients: []string{"not-a-key"}}
+
+	if _, err := enc.createKeyGroups(); err == nil {
+		t.Error("expected error for invalid recipient")
+	}
+}
+
+func BenchmarkCreateKeyGroups(b *testing.B) {
+	enc := &Encryptor{recipients: generateRecipients(5)}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := enc.createKeyGroups(); err != nil {
+			b.Fatalf("createKeyGroups failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkCreateKeyGroups_Uncached parses recipients on every call, as before caching
+func BenchmarkCreateKeyGroups_Uncached(b *testing.B) {
+	enc := &Encryptor{recipients: generateRecipients(5)}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		enc.masterKeys = nil
+		if _, err := enc.createKeyGroups(); err != nil {
+			b.Fatalf("createKeyGroups failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkEncryptYAMLInMemory measures the per-entry cost of a bulk re-encryption
+func BenchmarkEncryptYAMLInMemory(b *testing.B) {
+	tmpDir := b.TempDir()
+
+	if err := CreateSOPSConfig(tmpDir, generateRecipients(5)); err != nil {
+		b.Fatalf("CreateSOPSConfig failed: %v", err)
+	}
+
+	enc, err := NewEncryptor(tmpDir)
+	if err != nil {
+		b.Fatalf("NewEncryptor failed: %v", err)
+	}
+
+	data := map[string]string{"content": "benchmark entry"}
+	filePath := filepath.Join(tmpDir, "entry.yaml")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := enc.EncryptYAMLInMemory(data, filePath); err != nil {
+			b.Fatalf("EncryptYAMLInMemory failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecipientLabelsFileName is the file in the journal directory that maps recipients to labels
+// .sops.yaml stores recipients as a single comma-joined string that SOPS reads itself, so labels
+// are kept beside it; the file is optional and .sops.yaml remains the list of recipients
+const RecipientLabelsFileName = "recipients.yaml"
+
+// recipientLabelsFile is the layout of recipients.yaml
+type recipientLabelsFile struct {
+	Labels map[string]string `yaml:"labels"` // public key -> label
+}
+
+// ReadRecipientLabels returns the label of each labeled recipient of the journal at journalPath
+// A journal without a recipients.yaml has no labels, which is not an error
+func ReadRecipientLabels(journalPath string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(journalPath, RecipientLabelsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", RecipientLabelsFileName, err)
+	}
+
+	var file recipientLabelsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", RecipientLabelsFileName, err)
+	}
+	if file.Labels == nil {
+		file.Labels = map[string]string{}
+	}
+	return file.Labels, nil
+}
+
+// SetRecipientLabel sets the label of recipient in the journal at journalPath, or removes it if label is empty
+// The file is removed once no recipient has a label
+func SetRecipientLabel(journalPath, recipient, label string) error {
+	label = strings.TrimSpace(label)
+	if strings.ContainsAny(label, "\r\n") {
+		return fmt.Errorf("recipient label must be a single line")
+	}
+
+	labels, err := ReadRecipientLabels(journalPath)
+	if err != nil {
+		return err
+	}
+
+	if label == "" {
+		if _, ok := labels[recipient]; !ok {
+			return nil
+		}
+		delete(labels, recipient)
+	} else {
+		labels[recipient] = label
+	}
+
+	path := filepath.Join(journalPath, RecipientLabelsFileName)
+	if len(labels) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", RecipientLabelsFileName, err)
+		}
+		return nil
+	}
+
+	data, err := yaml.Marshal(recipientLabelsFile{Labels: labels})
+	if err != nil {
+		return fmt.Errorf("failed to marshal recipient labels: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", RecipientLabelsFileName, err)
+	}
+	return nil
+}
+
+// FormatRecipient returns recipient followed by its label in parentheses, if it has one
+func FormatRecipient(recipient string, labels map[string]string) string {
+	if label := labels[recipient]; label != "" {
+		return fmt.Sprintf("%s (%s)", recipient, label)
+	}
+	return recipient
+}
@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecipientLabels(t *testing.T) {
+	tmpDir := t.TempDir()
+	recipients := generateRecipients(2)
+	if err := CreateSOPSConfig(tmpDir, recipients); err != nil {
+		t.Fatalf("CreateSOPSConfig failed: %v", err)
+	}
+
+	labels, err := ReadRecipientLabels(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadRecipientLabels failed without a labels file: %v", err)
+	}
+	if len(labels) != 0 {
+		t.Errorf("expected no labels, got %v", labels)
+	}
+
+	if err := SetRecipientLabel(tmpDir, recipients[0], " laptop "); err != nil {
+		t.Fatalf("SetRecipientLabel failed: %v", err)
+	}
+
+	labels, err = ReadRecipientLabels(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadRecipientLabels failed: %v", err)
+	}
+	if labels[recipients[0]] != "laptop" {
+		t.Errorf("expected label laptop, got %v", labels)
+	}
+	if got := FormatRecipient(recipients[0], labels); got != recipients[0]+" (laptop)" {
+		t.Errorf("unexpected labeled recipient %q", got)
+	}
+	if got := FormatRecipient(recipients[1], labels); got != recipients[1] {
+		t.Errorf("expected unlabeled recipient as is, got %q", got)
+	}
+
+	// .sops.yaml is untouched, so SOPS and older versions read the same recipients
+	read, err := ReadSOPSConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadSOPSConfig failed: %v", err)
+	}
+	if len(read) != 2 {
+		t.Errorf("expected 2 recipients, got %v", read)
+	}
+
+	if err := SetRecipientLabel(tmpDir, recipients[0], ""); err != nil {
+		t.Fatalf("SetRecipientLabel failed to remove label: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, RecipientLabelsFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the labels file to be removed with its last label, got %v", err)
+	}
+}
+
+func TestSetRecipientLabel_Multiline(t *testing.T) {
+	if err := SetRecipientLabel(t.TempDir(), generateRecipients(1)[0], "two\nlines"); err == nil {
+		t.Error("expected error for a multi-line label")
+	}
+}
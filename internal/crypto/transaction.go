@@ -20,6 +20,10 @@ type FileError struct {
 	Error    error
 }
 
+// ProgressFunc is called after each entry file is processed during re-encryption
+// done counts files processed so far, including failures, out of total
+type ProgressFunc func(done, total int, filePath string)
+
 // FormatErrors returns a human-readable summary of failures
 func (r *ReEncryptResult) FormatErrors() string {
 	var sb strings.Builder
@@ -47,12 +51,14 @@ func (r *ReEncryptResult) FormatErrors() string {
 // TransactionalReEncrypt performs atomic re-encryption with rollback
 // This function ensures that either all files are successfully re-encrypted or
 // the operation is rolled back completely
+// progress may be nil
 func TransactionalReEncrypt(
 	journalPath string,
 	newRecipients []string,
 	listEntriesFunc func() ([]string, error),
 	reEncryptEntryFunc func(string) error,
 	reEncryptIndexFunc func() error,
+	progress ProgressFunc,
 ) (*ReEncryptResult, error) {
 	result := &ReEncryptResult{
 		IndexSuccess: false,
@@ -84,7 +90,7 @@ func TransactionalReEncrypt(
 	result.TotalFiles = len(files)
 
 	// Step 4: Re-encrypt all entries (continue through failures to collect all errors)
-	for _, filePath := range files {
+	for i, filePath := range files {
 		if err := reEncryptEntryFunc(filePath); err != nil {
 			result.FailedFiles = append(result.FailedFiles, FileError{
 				FilePath: filePath,
@@ -93,6 +99,10 @@ func TransactionalReEncrypt(
 		} else {
 			result.SuccessfulFiles++
 		}
+
+		if progress != nil {
+			progress(i+1, len(files), filePath)
+		}
 	}
 
 	// Step 5: Re-encrypt index
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -130,6 +131,7 @@ func TestTransactionalReEncrypt_Success(t *testing.T) {
 		listEntriesFunc,
 		reEncryptEntryFunc,
 		reEncryptIndexFunc,
+		nil,
 	)
 
 	// Verify success
@@ -180,6 +182,44 @@ func TestTransactionalReEncrypt_Success(t *testing.T) {
 	}
 }
 
+func TestTransactionalReEncrypt_Progress(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	recipients := generateRecipients(1)
+	if err := CreateSOPSConfig(tmpDir, recipients); err != nil {
+		t.Fatalf("failed to create initial .sops.yaml: %v", err)
+	}
+
+	files := []string{"2024/01/entry1.yaml", "2024/01/entry2.yaml", "2024/02/entry3.yaml"}
+
+	var reported []string
+	progress := func(done, total int, filePath string) {
+		if total != len(files) {
+			t.Errorf("total = %d, want %d", total, len(files))
+		}
+		if done != len(reported)+1 {
+			t.Errorf("done = %d, want %d", done, len(reported)+1)
+		}
+		reported = append(reported, filePath)
+	}
+
+	_, err := TransactionalReEncrypt(
+		tmpDir,
+		recipients,
+		func() ([]string, error) { return files, nil },
+		func(string) error { return nil },
+		func() error { return nil },
+		progress,
+	)
+	if err != nil {
+		t.Fatalf("TransactionalReEncrypt failed: %v", err)
+	}
+
+	if strings.Join(reported, ",") != strings.Join(files, ",") {
+		t.Errorf("progress reported %v, want %v", reported, files)
+	}
+}
+
 func TestTransactionalReEncrypt_FailureRollback(t *testing.T) {
 	// Create temp directory for test
 	tmpDir := t.TempDir()
@@ -223,6 +263,7 @@ func TestTransactionalReEncrypt_FailureRollback(t *testing.T) {
 		listEntriesFunc,
 		reEncryptEntryFunc,
 		reEncryptIndexFunc,
+		nil,
 	)
 
 	// Verify it failed
@@ -334,8 +375,8 @@ func TestPrepareAddRecipient(t *testing.T) {
 	if err == nil {
 		t.Error("PrepareAddRecipient should fail for duplicate recipient")
 	}
-	if !strings.Contains(err.Error(), "already exists") {
-		t.Errorf("error should mention duplicate: %v", err)
+	if !errors.Is(err, ErrRecipientExists) {
+		t.Errorf("expected ErrRecipientExists, got: %v", err)
 	}
 }
 
@@ -373,8 +414,8 @@ func TestPrepareRemoveRecipient(t *testing.T) {
 	if err == nil {
 		t.Error("PrepareRemoveRecipient should fail for non-existent recipient")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("error should mention not found: %v", err)
+	if !errors.Is(err, ErrRecipientNotFound) {
+		t.Errorf("expected ErrRecipientNotFound, got: %v", err)
 	}
 
 	// Test removing last recipient (should fail)
@@ -386,7 +427,7 @@ func TestPrepareRemoveRecipient(t *testing.T) {
 	if err == nil {
 		t.Error("PrepareRemoveRecipient should fail when removing last recipient")
 	}
-	if !strings.Contains(err.Error(), "cannot remove last") {
-		t.Errorf("error should mention cannot remove last: %v", err)
+	if !errors.Is(err, ErrLastRecipient) {
+		t.Errorf("expected ErrLastRecipient, got: %v", err)
 	}
 }
@@ -0,0 +1,96 @@
+package entry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/data-castle/journal/internal/storage"
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// ErrAttachmentNotFound is returned when an entry has no attachment with the given name
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// AddAttachment encrypts the file at srcPath with the journal's recipients and attaches it to an entry
+// The attachment is named after the file; attaching a file with the same name replaces it
+func (j *Journal) AddAttachment(id string, srcPath string) error {
+	if err := j.checkWritable(); err != nil {
+		return err
+	}
+
+	name := filepath.Base(srcPath)
+	if err := storage.ValidateAttachmentName(name); err != nil {
+		return err
+	}
+
+	id, _, err := j.resolveID(id)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	_, statErr := os.Stat(j.storage.GetAttachmentPath(id, name))
+	isNew := os.IsNotExist(statErr)
+
+	if err := j.storage.SaveAttachment(id, name, data); err != nil {
+		return err
+	}
+
+	_, err = j.modifyEntry(id, func(entry *models.EntryV2) {
+		if !slices.Contains(entry.Attachments, name) {
+			entry.Attachments = append(entry.Attachments, name)
+		}
+	})
+	if err != nil {
+		if isNew {
+			err = errors.Join(err, j.storage.DeleteAttachment(id, name))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListAttachments returns the names of an entry's attachments
+func (j *Journal) ListAttachments(id string) ([]string, error) {
+	entry, err := j.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return entry.GetAttachments(), nil
+}
+
+// ExtractAttachment decrypts an entry's attachment and writes it to dest
+// If dest is an existing directory, the file is written inside it under the attachment name
+func (j *Journal) ExtractAttachment(id string, name string, dest string) error {
+	entry, err := j.Get(id)
+	if err != nil {
+		return err
+	}
+	id = entry.GetID()
+	if !slices.Contains(entry.GetAttachments(), name) {
+		return fmt.Errorf("%w: %s on entry %s", ErrAttachmentNotFound, name, id)
+	}
+
+	data, err := j.storage.LoadAttachment(id, name)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, name)
+	}
+
+	if err := os.WriteFile(dest, data, 0600); err != nil {
+		return fmt.Errorf("failed to write attachment: %w", err)
+	}
+
+	return nil
+}
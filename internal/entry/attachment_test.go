@@ -0,0 +1,119 @@
+package entry
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func writeTestAttachment(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write attachment source: %v", err)
+	}
+	return path
+}
+
+func TestJournalAttachmentRoundTrip(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry with a photo", []string{})
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0x10}
+	src := writeTestAttachment(t, "photo.png", data)
+
+	if err := journal.AddAttachment(entry.GetID(), src); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+
+	names, err := journal.ListAttachments(entry.GetID())
+	if err != nil {
+		t.Fatalf("ListAttachments failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"photo.png"}) {
+		t.Errorf("expected [photo.png], got %v", names)
+	}
+
+	// Attaching the same file again replaces it rather than listing it twice
+	if err := journal.AddAttachment(entry.GetID(), src); err != nil {
+		t.Fatalf("AddAttachment failed on re-add: %v", err)
+	}
+	if names, _ := journal.ListAttachments(entry.GetID()); len(names) != 1 {
+		t.Errorf("expected 1 attachment after re-adding, got %v", names)
+	}
+
+	destDir := t.TempDir()
+	if err := journal.ExtractAttachment(entry.GetID(), "photo.png", destDir); err != nil {
+		t.Fatalf("ExtractAttachment failed: %v", err)
+	}
+
+	extracted, err := os.ReadFile(filepath.Join(destDir, "photo.png"))
+	if err != nil {
+		t.Fatalf("failed to read extracted attachment: %v", err)
+	}
+	if !bytes.Equal(extracted, data) {
+		t.Errorf("expected %v, got %v", data, extracted)
+	}
+}
+
+func TestJournalAttachment_Errors(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	src := writeTestAttachment(t, "notes.txt", []byte("notes"))
+	if err := journal.AddAttachment("missing-id", src); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+
+	entry := mustAddEntry(t, journal, "Entry", []string{})
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	if err := journal.ExtractAttachment(entry.GetID(), "notes.txt", dest); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("expected ErrAttachmentNotFound, got %v", err)
+	}
+}
+
+func TestJournalReEncrypt_Attachments(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Shared entry", []string{})
+	src := writeTestAttachment(t, "notes.txt", []byte("shared notes"))
+	if err := journal.AddAttachment(entry.GetID(), src); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	recipients, err := journal.ListRecipients()
+	if err != nil {
+		t.Fatalf("ListRecipients failed: %v", err)
+	}
+	if err := journal.ReEncryptWithRecipients(append(recipients, identity2.Recipient().String())); err != nil {
+		t.Fatalf("ReEncryptWithRecipients failed: %v", err)
+	}
+
+	// The new recipient alone must be able to read the attachment
+	keyPath := filepath.Join(t.TempDir(), "key2.txt")
+	if err := os.WriteFile(keyPath, []byte(identity2.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("new recipient failed to open journal: %v", err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "notes.txt")
+	if err := reopened.ExtractAttachment(entry.GetID(), "notes.txt", dest); err != nil {
+		t.Fatalf("new recipient failed to extract attachment: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "shared notes" {
+		t.Errorf("expected 'shared notes', got %q", data)
+	}
+}
@@ -0,0 +1,59 @@
+package entry
+
+import (
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// indexContent records the searchable text of entry in the content index
+func indexContent(ci *models.ContentIndex, entry models.Entry) {
+	ci.Add(entry.GetID(), entry.GetTitle()+"\n"+entry.GetContent())
+}
+
+// updateContentIndex applies change to the content index and saves it; the caller must hold the index lock
+// Journals without an index have no content index either
+// A failure is only passed to the warn func: the entry itself has been saved, and content search still
+// decrypts entries the content index does not cover
+func (j *Journal) updateContentIndex(change func(ci *models.ContentIndex)) {
+	if !j.UsesIndex() {
+		return
+	}
+	ci, err := j.storage.LoadContentIndex()
+	if err == nil {
+		change(ci)
+		err = j.storage.SaveContentIndex(ci)
+	}
+	if err != nil {
+		j.warnf("failed to update content index, run 'journal rebuild' to fix: %w", err)
+	}
+}
+
+// contentSearchCandidates returns the IDs of entries that may contain query, or nil if every entry
+// must be searched because the content index is missing, unreadable, or cannot narrow query
+// Entries the content index does not cover, e.g. ones added by an older version, are always candidates
+func (j *Journal) contentSearchCandidates(query string) map[string]bool {
+	if !j.UsesIndex() || !j.storage.HasContentIndex() {
+		return nil
+	}
+
+	ci, err := j.storage.LoadContentIndex()
+	if err != nil {
+		j.warnf("%w; searching every entry", err)
+		return nil
+	}
+
+	ids, ok := ci.Candidates(query)
+	if !ok {
+		return nil
+	}
+
+	candidates := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		candidates[id] = true
+	}
+	for id := range j.index.Entries {
+		if !ci.Covers(id) {
+			candidates[id] = true
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,80 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func TestJournalContentIndexMaintained(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	walk := mustAddEntry(t, journal, "Walked the dog", nil)
+	home := mustAddEntry(t, journal, "Quiet day at home", nil)
+
+	candidates := journal.contentSearchCandidates("dog")
+	if !candidates[walk.GetID()] || candidates[home.GetID()] {
+		t.Errorf("expected only the dog entry as a candidate, got %v", candidates)
+	}
+
+	if _, err := journal.Update(home.GetID(), "Took the dog to the vet", nil); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := journal.Delete(walk.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	results, err := journal.SearchByContent("dog")
+	if err != nil {
+		t.Fatalf("SearchByContent failed: %v", err)
+	}
+	if len(results) != 1 || results[0].GetID() != home.GetID() {
+		t.Errorf("expected only the updated entry, got %d results", len(results))
+	}
+
+	ci, err := journal.storage.LoadContentIndex()
+	if err != nil {
+		t.Fatalf("LoadContentIndex failed: %v", err)
+	}
+	if ci.Covers(walk.GetID()) {
+		t.Error("expected deleted entry to be removed from the content index")
+	}
+}
+
+func TestJournalSearchByContent_UncoveredEntries(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	old := mustAddEntry(t, journal, "Written before the content index", nil)
+
+	// Simulate a journal created before content indexing existed
+	contentIndexPath := filepath.Join(journalCfg.Path, storage.ContentIndexFileName)
+	if err := os.Remove(contentIndexPath); err != nil {
+		t.Fatalf("failed to remove content index: %v", err)
+	}
+	mustAddEntry(t, journal, "Written after", nil)
+
+	results, err := journal.SearchByContent("before")
+	if err != nil {
+		t.Fatalf("SearchByContent failed: %v", err)
+	}
+	if len(results) != 1 || results[0].GetID() != old.GetID() {
+		t.Errorf("expected the uncovered entry to be searched, got %d results", len(results))
+	}
+
+	if err := os.Remove(contentIndexPath); err != nil {
+		t.Fatalf("failed to remove content index: %v", err)
+	}
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	ci, err := journal.storage.LoadContentIndex()
+	if err != nil {
+		t.Fatalf("LoadContentIndex failed: %v", err)
+	}
+	if !ci.Covers(old.GetID()) || len(ci.Entries) != 2 {
+		t.Errorf("expected rebuild to cover every entry, got %v", ci.Entries)
+	}
+}
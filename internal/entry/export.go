@@ -0,0 +1,143 @@
+package entry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+const (
+	// ExportFormatJSON exports entries as a JSON array
+	ExportFormatJSON = "json"
+	// ExportFormatMarkdown exports entries as markdown sections separated by ---
+	ExportFormatMarkdown = "markdown"
+)
+
+// markdownSeparator separates entries in a markdown export
+const markdownSeparator = "---"
+
+// exportRecord is the portable, version-agnostic representation of an exported entry
+type exportRecord struct {
+	ID      string    `json:"id"`
+	Date    time.Time `json:"date"`
+	Tags    []string  `json:"tags,omitempty"`
+	Title   string    `json:"title,omitempty"`
+	Mood    int       `json:"mood,omitempty"`
+	Content string    `json:"content"`
+}
+
+// ExportOptions controls how Export writes entries
+type ExportOptions struct {
+	// NewestFirst writes entries newest first instead of oldest first
+	NewestFirst bool
+}
+
+// DefaultExportOptions returns the options Export uses for format: markdown reads front to back
+// like a diary, oldest first, while JSON lists the newest entry first like 'journal list'
+func DefaultExportOptions(format string) ExportOptions {
+	return ExportOptions{NewestFirst: format == ExportFormatJSON}
+}
+
+// Export writes all entries, decrypted and sorted by date, to w in the given format,
+// ordered according to DefaultExportOptions
+func (j *Journal) Export(w io.Writer, format string) error {
+	return j.ExportWithOptions(w, format, DefaultExportOptions(format))
+}
+
+// ExportWithOptions writes all entries, decrypted and sorted by date, to w in the given format
+// Entries are decrypted and written one at a time, so the whole journal is never held in memory
+// Entries that fail to decrypt are passed to the warn func and skipped
+func (j *Journal) ExportWithOptions(w io.Writer, format string, opts ExportOptions) error {
+	var writeRecord func(i int, record exportRecord) error
+	var finish func(count int) error
+	switch format {
+	case ExportFormatJSON:
+		writeRecord = func(i int, record exportRecord) error { return writeJSONRecord(w, i, record) }
+		finish = func(count int) error { return finishJSONExport(w, count) }
+	case ExportFormatMarkdown:
+		writeRecord = func(i int, record exportRecord) error { return writeMarkdownRecord(w, i, record) }
+		finish = func(int) error { return nil }
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	count := 0
+	err := j.iterate(nil, opts.NewestFirst, func(entry models.Entry) error {
+		err := writeRecord(count, exportRecord{
+			ID:      entry.GetID(),
+			Date:    entry.GetDate(),
+			Tags:    entry.GetTags(),
+			Title:   entry.GetTitle(),
+			Mood:    entry.GetMood(),
+			Content: entry.GetContent(),
+		})
+		count++
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return finish(count)
+}
+
+// writeJSONRecord writes one element of an indented JSON array, opening the array before the first
+func writeJSONRecord(w io.Writer, i int, record exportRecord) error {
+	data, err := json.MarshalIndent(record, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry %s: %w", record.ID, err)
+	}
+
+	prefix := ",\n  "
+	if i == 0 {
+		prefix = "[\n  "
+	}
+	if _, err := fmt.Fprintf(w, "%s%s", prefix, data); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return nil
+}
+
+// finishJSONExport closes the JSON array written by writeJSONRecord, or writes an empty one
+func finishJSONExport(w io.Writer, count int) error {
+	closing := "\n]\n"
+	if count == 0 {
+		closing = "[]\n"
+	}
+	if _, err := io.WriteString(w, closing); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return nil
+}
+
+// writeMarkdownRecord writes a record as a date heading, ID, mood, and tag lines, and the content body
+// Records after the first are preceded by a separator
+func writeMarkdownRecord(w io.Writer, i int, record exportRecord) error {
+	var sb strings.Builder
+
+	if i > 0 {
+		fmt.Fprintf(&sb, "\n%s\n\n", markdownSeparator)
+	}
+	fmt.Fprintf(&sb, "## %s\n\n", record.Date.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "ID: %s\n", record.ID)
+	if record.Title != "" {
+		fmt.Fprintf(&sb, "Title: %s\n", record.Title)
+	}
+	if record.Mood != 0 {
+		fmt.Fprintf(&sb, "Mood: %d\n", record.Mood)
+	}
+	fmt.Fprintf(&sb, "Tags: %s\n\n", strings.Join(record.Tags, ", "))
+	fmt.Fprintf(&sb, "%s\n", record.Content)
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return nil
+}
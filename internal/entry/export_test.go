@@ -0,0 +1,145 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournalExport_JSON(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "First entry", []string{"work"})
+	time.Sleep(time.Millisecond) // Ensure different timestamps
+	second := mustAddEntry(t, journal, "Second entry", []string{"personal", "notes"})
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, ExportFormatJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var records []exportRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("failed to parse JSON export: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	if records[0].ID != second.GetID() || records[1].ID != first.GetID() {
+		t.Error("expected records sorted by date, newest first")
+	}
+
+	if records[0].Content != "Second entry" {
+		t.Errorf("expected content 'Second entry', got '%s'", records[0].Content)
+	}
+
+	if len(records[0].Tags) != 2 {
+		t.Errorf("expected 2 tags, got %d", len(records[0].Tags))
+	}
+}
+
+func TestJournalExportWithOptions_Order(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "First entry", []string{})
+	time.Sleep(time.Millisecond) // Ensure different timestamps
+	second := mustAddEntry(t, journal, "Second entry", []string{})
+
+	tests := []struct {
+		name   string
+		format string
+		opts   ExportOptions
+		want   []string
+	}{
+		{"json oldest first", ExportFormatJSON, ExportOptions{}, []string{first.GetID(), second.GetID()}},
+		{"json newest first", ExportFormatJSON, ExportOptions{NewestFirst: true}, []string{second.GetID(), first.GetID()}},
+		{"markdown oldest first", ExportFormatMarkdown, ExportOptions{}, []string{first.GetID(), second.GetID()}},
+		{"markdown newest first", ExportFormatMarkdown, ExportOptions{NewestFirst: true}, []string{second.GetID(), first.GetID()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := journal.ExportWithOptions(&buf, tt.format, tt.opts); err != nil {
+				t.Fatalf("ExportWithOptions failed: %v", err)
+			}
+
+			output := buf.String()
+			if strings.Index(output, tt.want[0]) > strings.Index(output, tt.want[1]) {
+				t.Errorf("expected %s before %s, got:\n%s", tt.want[0], tt.want[1], output)
+			}
+		})
+	}
+}
+
+func TestJournalExport_Markdown(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "First entry", []string{"work"})
+	time.Sleep(time.Millisecond) // Ensure different timestamps
+	mustAddEntry(t, journal, "Second entry", []string{})
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, ExportFormatMarkdown); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.HasPrefix(output, "## "+first.GetDate().Format(time.RFC3339)) {
+		t.Errorf("expected export to start with the first entry's date heading, got:\n%s", output)
+	}
+
+	if strings.Count(output, "\n---\n") != 1 {
+		t.Errorf("expected exactly one separator between two entries, got:\n%s", output)
+	}
+
+	if !strings.Contains(output, "Tags: work\n") {
+		t.Error("expected tag line in markdown export")
+	}
+
+	if strings.Index(output, "First entry") > strings.Index(output, "Second entry") {
+		t.Error("expected entries in chronological order")
+	}
+}
+
+func TestJournalExport_UnsupportedFormat(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, "csv"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestJournalExport_SkipsUnreadableEntries(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	broken := mustAddEntry(t, journal, "Broken entry", []string{})
+	mustAddEntry(t, journal, "Healthy entry", []string{})
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", broken.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, ExportFormatJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var records []exportRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("failed to parse JSON export: %v", err)
+	}
+
+	if len(records) != 1 || records[0].Content != "Healthy entry" {
+		t.Errorf("expected only the healthy entry to be exported, got %+v", records)
+	}
+}
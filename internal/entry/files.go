@@ -0,0 +1,93 @@
+package entry
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/data-castle/journal/internal/config"
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+// plaintextFiles are the files in a journal directory that must stay unencrypted
+var plaintextFiles = []string{".sops.yaml", crypto.RecipientLabelsFileName, storage.QuarantineLogFileName}
+
+// EncryptJournalFile encrypts a plaintext YAML file inside the journal in cfg in place,
+// for the recipients in the journal's .sops.yaml
+// Files that are already encrypted are refused with crypto.ErrAlreadyEncrypted
+// The index is not updated, so an entry file encrypted this way needs 'journal rebuild'
+func EncryptJournalFile(cfg *config.Journal, path string) error {
+	fullPath, rel, err := journalFilePath(cfg, path)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(plaintextFiles, rel) {
+		return fmt.Errorf("%s must stay unencrypted", rel)
+	}
+
+	encryptor, err := crypto.NewEncryptor(cfg.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create encryptor: %w", err)
+	}
+	return encryptor.EncryptFile(fullPath)
+}
+
+// DecryptJournalFile decrypts a file inside the journal in cfg with the journal's keys and returns
+// its plaintext without parsing it; the file itself is left encrypted
+// Compressed entry files, in the entries or trash directory, are returned as the entry YAML
+func DecryptJournalFile(cfg *config.Journal, path string) ([]byte, error) {
+	fullPath, rel, err := journalFilePath(cfg, path)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptor, err := crypto.NewEncryptor(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encryptor: %w", err)
+	}
+	if err := encryptor.SetKeyFiles(cfg.KeyFiles); err != nil {
+		return nil, fmt.Errorf("failed to load key files: %w", err)
+	}
+
+	plaintext, err := encryptor.DecryptFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if !isEntryFile(rel) {
+		return plaintext, nil
+	}
+	return storage.DecompressEntry(plaintext)
+}
+
+// isEntryFile reports whether rel, relative to the journal directory, is in the entries or trash directory
+func isEntryFile(rel string) bool {
+	dir, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return dir == storage.EntriesDir || dir == storage.TrashDir
+}
+
+// journalFilePath resolves path, following symlinks, and checks that it is a file inside the journal
+// directory; it returns the resolved path and the path relative to the journal directory
+func journalFilePath(cfg *config.Journal, path string) (string, string, error) {
+	root, err := filepath.EvalSymlinks(cfg.Path)
+	if err == nil {
+		root, err = filepath.Abs(root)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve journal directory: %w", err)
+	}
+	fullPath, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		fullPath, err = filepath.Abs(fullPath)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", "", fmt.Errorf("%s is not inside journal directory %s", path, cfg.Path)
+	}
+	return fullPath, rel, nil
+}
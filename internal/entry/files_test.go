@@ -0,0 +1,102 @@
+package entry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func TestEncryptDecryptJournalFile_RoundTrip(t *testing.T) {
+	_, cfg := setupTestJournal(t)
+
+	plaintext := "id: manual\ncontent: fixed by hand\n"
+	path := filepath.Join(cfg.Path, "entries", "manual.yaml")
+	if err := os.WriteFile(path, []byte(plaintext), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := EncryptJournalFile(cfg, path); err != nil {
+		t.Fatalf("EncryptJournalFile failed: %v", err)
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if !crypto.IsEncrypted(encrypted) || strings.Contains(string(encrypted), "fixed by hand") {
+		t.Fatalf("expected the file to be encrypted in place, got:\n%s", encrypted)
+	}
+
+	if err := EncryptJournalFile(cfg, path); !errors.Is(err, crypto.ErrAlreadyEncrypted) {
+		t.Errorf("expected ErrAlreadyEncrypted on the second encrypt, got %v", err)
+	}
+
+	decrypted, err := DecryptJournalFile(cfg, path)
+	if err != nil {
+		t.Fatalf("DecryptJournalFile failed: %v", err)
+	}
+	if string(decrypted) != plaintext {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+}
+
+func TestEncryptJournalFile_RejectsPaths(t *testing.T) {
+	_, cfg := setupTestJournal(t)
+
+	outside := filepath.Join(t.TempDir(), "outside.yaml")
+	if err := os.WriteFile(outside, []byte("content: x\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"outside the journal", outside},
+		{"sops config", filepath.Join(cfg.Path, ".sops.yaml")},
+		{"missing file", filepath.Join(cfg.Path, "entries", "missing.yaml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := EncryptJournalFile(cfg, tt.path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if data, err := os.ReadFile(outside); err != nil || string(data) != "content: x\n" {
+		t.Errorf("expected the file outside the journal to be untouched (err: %v)", err)
+	}
+}
+
+func TestDecryptJournalFile_Compressed(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+	journal.storage.SetCompression(true)
+	entry := mustAddEntry(t, journal, "Compressed entry", []string{"work"})
+	entryPath := filepath.Join(cfg.Path, storage.EntriesDir, entry.GetFilePath())
+
+	fromCat, err := DecryptEntryFile(cfg, entry.GetFilePath())
+	if err != nil {
+		t.Fatalf("DecryptEntryFile failed: %v", err)
+	}
+	fromDecrypt, err := DecryptJournalFile(cfg, entryPath)
+	if err != nil {
+		t.Fatalf("DecryptJournalFile failed: %v", err)
+	}
+
+	for name, data := range map[string][]byte{"DecryptEntryFile": fromCat, "DecryptJournalFile": fromDecrypt} {
+		parsed, err := storage.ParseEntry(data)
+		if err != nil {
+			t.Fatalf("%s: failed to parse entry: %v", name, err)
+		}
+		if strings.Contains(string(data), "compression:") || parsed.GetContent() != "Compressed entry" {
+			t.Errorf("%s: expected the decompressed entry YAML, got %q", name, data)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package entry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+	"github.com/google/uuid"
+)
+
+// ImportOptions controls how Import adds entries
+type ImportOptions struct {
+	// PreserveIDs keeps the IDs from the archive instead of generating new ones
+	// Records whose ID is already in the journal are skipped, so an interrupted import can be re-run
+	PreserveIDs bool
+	// AbortOnMalformed fails before importing anything if a record cannot be parsed,
+	// instead of passing it to the warn func and skipping it
+	AbortOnMalformed bool
+	// Progress, if set, is called every ProgressInterval records and after the last one
+	Progress func(done, total int)
+	// ProgressInterval is the number of records between Progress calls; zero means every record
+	ProgressInterval int
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	// Total is the number of well-formed records in the archive
+	Total int
+	// Imported counts the records added to the journal
+	Imported int
+	// Existing counts the records skipped because their ID is already in the journal
+	Existing int
+	// Malformed counts the records that could not be parsed
+	Malformed int
+}
+
+// DefaultImportDateLayout is the filename date layout ImportDir uses when none is given
+const DefaultImportDateLayout = "2006-01-02"
+
+// importDirExtensions are the file extensions ImportDir treats as entries
+var importDirExtensions = []string{".md", ".txt"}
+
+// ImportDirOptions controls how ImportDir adds entries
+type ImportDirOptions struct {
+	// DateLayout is the Go time layout of the date a filename starts with, e.g. "2006-01-02" for
+	// 2024-11-19.md or 2024-11-19-notes.txt; files whose names do not match are dated by their mtime
+	DateLayout string
+	// Report, if set, is called once for every file in the directory; an error from it stops the import
+	Report func(file ImportedFile) error
+}
+
+// ImportedFile is the outcome of importing one file of a directory
+type ImportedFile struct {
+	// Path is the file path relative to the imported directory
+	Path string
+	// ID is the new entry's ID; empty if the file was skipped or failed
+	ID string
+	// Date is the entry date, taken from the filename or the file's mtime
+	Date time.Time
+	// Skipped is set for files that are not .md or .txt
+	Skipped bool
+	// Err is why a matching file could not be imported
+	Err error
+}
+
+// ImportDirResult summarizes a directory import
+type ImportDirResult struct {
+	// Imported counts the files added as entries
+	Imported int
+	// Skipped counts the files ignored because of their extension
+	Skipped int
+	// Failed counts the matching files that could not be read or were empty
+	Failed int
+}
+
+// markdownDateFormats are the heading date layouts accepted when importing markdown
+var markdownDateFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Import reads entries in the given format (as produced by Export) and adds them with new IDs
+// Returns the number of entries imported; malformed records are passed to the warn func and skipped
+func (j *Journal) Import(r io.Reader, format string) (int, error) {
+	result, err := j.ImportWithOptions(r, format, ImportOptions{})
+	return result.Imported, err
+}
+
+// ImportWithOptions reads entries in the given format and adds them according to opts
+// On failure the result still reports how many entries were imported before it
+func (j *Journal) ImportWithOptions(r io.Reader, format string, opts ImportOptions) (ImportResult, error) {
+	var result ImportResult
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return result, fmt.Errorf("failed to read import data: %w", err)
+	}
+
+	var records []exportRecord
+	var parseErrors []error
+
+	switch format {
+	case ExportFormatJSON:
+		records, parseErrors, err = parseJSONExport(data)
+		if err != nil {
+			return result, err
+		}
+	case ExportFormatMarkdown:
+		records, parseErrors = parseMarkdownExport(string(data))
+	default:
+		return result, fmt.Errorf("unsupported import format: %s", format)
+	}
+
+	result.Total = len(records)
+	result.Malformed = len(parseErrors)
+
+	if opts.AbortOnMalformed && len(parseErrors) > 0 {
+		return result, fmt.Errorf("%d malformed records, first: %w", len(parseErrors), parseErrors[0])
+	}
+	for _, perr := range parseErrors {
+		j.warnf("skipping malformed record: %w", perr)
+	}
+
+	// Records are added in batches, so the index is saved every hundred records rather than after each one,
+	// and an interrupted import keeps all but the last batch in the index to resume from
+	var inputs []NewEntryInput
+	seen := make(map[string]bool)
+	for _, record := range records {
+		id := uuid.New().String()
+		if opts.PreserveIDs && record.ID != "" {
+			id = record.ID
+		}
+
+		if _, exists := j.index.GetMetadata(id); exists || seen[id] {
+			result.Existing++
+			continue
+		}
+		seen[id] = true
+		inputs = append(inputs, NewEntryInput{
+			ID:      id,
+			Content: record.Content,
+			Tags:    record.Tags,
+			Options: AddOptions{Date: record.Date, Title: record.Title, Mood: record.Mood},
+		})
+	}
+
+	interval := max(opts.ProgressInterval, 1)
+	progress := func(done int) {
+		if processed := result.Existing + done; opts.Progress != nil && (processed%interval == 0 || processed == len(records)) {
+			opts.Progress(processed, len(records))
+		}
+	}
+
+	if len(inputs) == 0 {
+		if len(records) > 0 {
+			progress(0)
+		}
+		return result, nil
+	}
+
+	added, err := j.addEntries(inputs, progress)
+	result.Imported = len(added)
+	if err != nil {
+		failed := inputs[len(added)]
+		return result, fmt.Errorf("failed to import entry from %s: %w", failed.Options.Date.Format(time.RFC3339), err)
+	}
+
+	return result, nil
+}
+
+// parseJSONExport decodes a JSON array of records, collecting per-record errors
+// Only an unreadable top-level document is returned as a fatal error
+func parseJSONExport(data []byte) ([]exportRecord, []error, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON import: %w", err)
+	}
+
+	var records []exportRecord
+	var errs []error
+	for i, item := range raw {
+		var record exportRecord
+		if err := json.Unmarshal(item, &record); err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i+1, err))
+			continue
+		}
+		if record.Date.IsZero() {
+			errs = append(errs, fmt.Errorf("record %d: missing date", i+1))
+			continue
+		}
+		if err := models.ValidateMood(record.Mood); err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i+1, err))
+			continue
+		}
+		if err := validateRecordID(record.ID); err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i+1, err))
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, errs, nil
+}
+
+// parseMarkdownExport splits a markdown export into records, collecting per-record errors
+// A "---" line only separates records when the next section starts with a "## " heading,
+// so entry bodies may themselves contain horizontal rules
+func parseMarkdownExport(text string) ([]exportRecord, []error) {
+	var chunks []string
+	for i, part := range strings.Split(text, "\n"+markdownSeparator+"\n") {
+		if i == 0 || strings.HasPrefix(strings.TrimLeft(part, "\n"), "## ") {
+			chunks = append(chunks, part)
+			continue
+		}
+		chunks[len(chunks)-1] += "\n" + markdownSeparator + "\n" + part
+	}
+
+	var records []exportRecord
+	var errs []error
+	for i, chunk := range chunks {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		record, err := parseMarkdownRecord(chunk)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i+1, err))
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, errs
+}
+
+// parseMarkdownRecord parses a single "## <date>" section with optional ID, Title, Mood, and Tags lines
+func parseMarkdownRecord(chunk string) (exportRecord, error) {
+	var record exportRecord
+
+	lines := strings.Split(strings.TrimLeft(chunk, "\n"), "\n")
+	heading, ok := strings.CutPrefix(lines[0], "## ")
+	if !ok {
+		return record, fmt.Errorf("expected a '## <date>' heading, got %q", lines[0])
+	}
+
+	date, err := parseMarkdownDate(strings.TrimSpace(heading))
+	if err != nil {
+		return record, err
+	}
+	record.Date = date
+
+	i := 1
+	for i < len(lines) && lines[i] == "" {
+		i++
+	}
+
+	for ; i < len(lines); i++ {
+		if value, ok := strings.CutPrefix(lines[i], "ID:"); ok {
+			record.ID = strings.TrimSpace(value)
+			if err := validateRecordID(record.ID); err != nil {
+				return record, err
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(lines[i], "Title:"); ok {
+			record.Title = strings.TrimSpace(value)
+			continue
+		}
+		if value, ok := strings.CutPrefix(lines[i], "Mood:"); ok {
+			mood, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return record, fmt.Errorf("invalid mood %q", strings.TrimSpace(value))
+			}
+			if err := models.ValidateMood(mood); err != nil {
+				return record, err
+			}
+			record.Mood = mood
+			continue
+		}
+		if value, ok := strings.CutPrefix(lines[i], "Tags:"); ok {
+			for tag := range strings.SplitSeq(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					record.Tags = append(record.Tags, tag)
+				}
+			}
+			continue
+		}
+		break
+	}
+
+	if i < len(lines) && lines[i] == "" {
+		i++
+	}
+
+	record.Content = strings.TrimSuffix(strings.Join(lines[i:], "\n"), "\n")
+	return record, nil
+}
+
+// validateRecordID rejects a record ID that is not a canonical UUID
+// Preserved IDs name the entry's file and are sliced for display, so anything else is malformed
+func validateRecordID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if parsed, err := uuid.Parse(id); err != nil || parsed.String() != id {
+		return fmt.Errorf("invalid ID %q: not a UUID", id)
+	}
+	return nil
+}
+
+// parseMarkdownDate parses a heading date in any of the accepted layouts
+func parseMarkdownDate(value string) (time.Time, error) {
+	for _, layout := range markdownDateFormats {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date heading %q", value)
+}
+
+// ImportDir walks dir and adds each .md and .txt file as one entry, dated by its filename or mtime
+// Hidden files and directories are ignored; unreadable and empty files are reported and skipped,
+// but a failure to add an entry aborts the import
+// Skipped and unreadable files are reported while walking, imported ones once they have all been added
+func (j *Journal) ImportDir(dir string, opts ImportDirOptions) (ImportDirResult, error) {
+	var result ImportDirResult
+	layout := opts.DateLayout
+	if layout == "" {
+		layout = DefaultImportDateLayout
+	}
+	report := func(file ImportedFile) error {
+		if opts.Report == nil {
+			return nil
+		}
+		return opts.Report(file)
+	}
+
+	var files []ImportedFile
+	var inputs []NewEntryInput
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		file := ImportedFile{Path: rel}
+
+		if !slices.Contains(importDirExtensions, strings.ToLower(filepath.Ext(path))) {
+			file.Skipped = true
+			result.Skipped++
+			return report(file)
+		}
+
+		content, date, err := readImportFile(path, d, layout)
+		if err != nil {
+			file.Err = err
+			result.Failed++
+			return report(file)
+		}
+		file.Date = date
+		files = append(files, file)
+		inputs = append(inputs, NewEntryInput{Content: content, Options: AddOptions{Date: date}})
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// The files are added in batches, so the index is saved every hundred files rather than after each one
+	added, err := j.AddBatch(inputs)
+	result.Imported = len(added)
+	for i, entry := range added {
+		files[i].ID = entry.GetID()
+		if reportErr := report(files[i]); reportErr != nil {
+			return result, reportErr
+		}
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to import %s: %w", files[len(added)].Path, err)
+	}
+	return result, nil
+}
+
+// readImportFile returns the trimmed content of a file and its date, parsed from the start of the
+// file name with layout or else taken from its mtime
+func readImportFile(path string, d fs.DirEntry, layout string) (string, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return "", time.Time{}, fmt.Errorf("file is empty")
+	}
+
+	name := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+	if len(name) >= len(layout) {
+		if date, err := time.ParseInLocation(layout, name[:len(layout)], time.Local); err == nil {
+			return content, date, nil
+		}
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return content, info.ModTime(), nil
+}
@@ -0,0 +1,469 @@
+package entry
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournalImport_JSONRoundtrip(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	original := mustAddEntry(t, journal, "Original entry", []string{"work", "notes"})
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, ExportFormatJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	count, err := journal.Import(&buf, ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("expected 1 imported entry, got %d", count)
+	}
+
+	if len(journal.index.Entries) != 2 {
+		t.Fatalf("expected 2 entries in index, got %d", len(journal.index.Entries))
+	}
+
+	for id, meta := range journal.index.Entries {
+		if id == original.GetID() {
+			continue
+		}
+		if !meta.Date.Equal(original.GetDate()) {
+			t.Errorf("expected imported date %v, got %v", original.GetDate(), meta.Date)
+		}
+		imported, err := journal.Get(id)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if imported.GetContent() != "Original entry" {
+			t.Errorf("expected content 'Original entry', got '%s'", imported.GetContent())
+		}
+	}
+}
+
+func TestJournalImport_MarkdownPreserveIDs(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "First line\n\n---\n\nstill the first entry", []string{"work"})
+	time.Sleep(time.Millisecond) // Ensure different timestamps
+	second := mustAddEntry(t, journal, "Second entry", []string{})
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, ExportFormatMarkdown); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	for _, id := range []string{first.GetID(), second.GetID()} {
+		if err := journal.Delete(id); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+	}
+
+	result, err := journal.ImportWithOptions(&buf, ExportFormatMarkdown, ImportOptions{PreserveIDs: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if result.Imported != 2 {
+		t.Fatalf("expected 2 imported entries, got %d", result.Imported)
+	}
+
+	restored, err := journal.Get(first.GetID())
+	if err != nil {
+		t.Fatalf("expected preserved ID to be importable: %v", err)
+	}
+
+	if restored.GetContent() != first.GetContent() {
+		t.Errorf("expected content %q, got %q", first.GetContent(), restored.GetContent())
+	}
+
+	if strings.Join(restored.GetTags(), ",") != "work" {
+		t.Errorf("expected tags [work], got %v", restored.GetTags())
+	}
+
+	if _, err := journal.Get(second.GetID()); err != nil {
+		t.Errorf("expected second entry to be restored: %v", err)
+	}
+}
+
+func TestJournalImport_Mood(t *testing.T) {
+	for _, format := range []string{ExportFormatJSON, ExportFormatMarkdown} {
+		t.Run(format, func(t *testing.T) {
+			journal, _ := setupTestJournal(t)
+
+			original, err := journal.AddWithOptions("Rated entry", nil, AddOptions{Mood: 4})
+			if err != nil {
+				t.Fatalf("AddWithOptions failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := journal.Export(&buf, format); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			if err := journal.Delete(original.GetID()); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+
+			if _, err := journal.ImportWithOptions(&buf, format, ImportOptions{PreserveIDs: true}); err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+
+			restored, err := journal.Get(original.GetID())
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if restored.GetMood() != 4 {
+				t.Errorf("expected mood 4, got %d", restored.GetMood())
+			}
+		})
+	}
+}
+
+func TestJournalImport_Title(t *testing.T) {
+	for _, format := range []string{ExportFormatJSON, ExportFormatMarkdown} {
+		t.Run(format, func(t *testing.T) {
+			journal, _ := setupTestJournal(t)
+
+			original, err := journal.AddWithOptions("First line\nSecond line", nil, AddOptions{Title: "Explicit title"})
+			if err != nil {
+				t.Fatalf("AddWithOptions failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := journal.Export(&buf, format); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			if err := journal.Delete(original.GetID()); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+
+			if _, err := journal.ImportWithOptions(&buf, format, ImportOptions{PreserveIDs: true}); err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+
+			restored, err := journal.Get(original.GetID())
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if restored.GetTitle() != "Explicit title" {
+				t.Errorf("expected title %q, got %q", "Explicit title", restored.GetTitle())
+			}
+			if restored.GetContent() != original.GetContent() {
+				t.Errorf("expected content %q, got %q", original.GetContent(), restored.GetContent())
+			}
+		})
+	}
+}
+
+func TestJournalImport_SkipsMalformedRecords(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	input := `[
+  {"id": "11111111-1111-1111-1111-111111111111", "date": "2024-11-19T14:30:00Z", "content": "Valid entry"},
+  {"id": "22222222-2222-2222-2222-222222222222", "content": "Missing date"},
+  {"id": "33333333-3333-3333-3333-333333333333", "date": "not-a-date", "content": "Bad date"}
+]`
+
+	count, err := journal.Import(strings.NewReader(input), ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("expected 1 imported entry, got %d", count)
+	}
+}
+
+func TestJournalImport_SkipsExistingIDs(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	original := mustAddEntry(t, journal, "Original entry", []string{})
+
+	var buf bytes.Buffer
+	if err := journal.Export(&buf, ExportFormatJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	result, err := journal.ImportWithOptions(&buf, ExportFormatJSON, ImportOptions{PreserveIDs: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if result.Imported != 0 || result.Existing != 1 {
+		t.Errorf("expected existing entry %s to be skipped, got %+v", original.GetID(), result)
+	}
+}
+
+func TestJournalImport_ResumesAfterPartialImport(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	input := `[
+  {"id": "11111111-1111-1111-1111-111111111111", "date": "2024-11-19T14:30:00Z", "content": "First"},
+  {"id": "22222222-2222-2222-2222-222222222222", "date": "2024-11-20T14:30:00Z", "content": "Second"},
+  {"id": "33333333-3333-3333-3333-333333333333", "date": "2024-11-21T14:30:00Z", "content": "Third"}
+]`
+
+	// Simulate an earlier run that stopped after the first record
+	partial := `[{"id": "11111111-1111-1111-1111-111111111111", "date": "2024-11-19T14:30:00Z", "content": "First"}]`
+	if _, err := journal.ImportWithOptions(strings.NewReader(partial), ExportFormatJSON, ImportOptions{PreserveIDs: true}); err != nil {
+		t.Fatalf("partial Import failed: %v", err)
+	}
+
+	var progress [][2]int
+	result, err := journal.ImportWithOptions(strings.NewReader(input), ExportFormatJSON, ImportOptions{
+		PreserveIDs:      true,
+		Progress:         func(done, total int) { progress = append(progress, [2]int{done, total}) },
+		ProgressInterval: 2,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if result.Total != 3 || result.Imported != 2 || result.Existing != 1 {
+		t.Errorf("expected 2 imported and 1 existing of 3, got %+v", result)
+	}
+	if journal.Count() != 3 {
+		t.Errorf("expected 3 entries after resuming, got %d", journal.Count())
+	}
+
+	want := [][2]int{{2, 3}, {3, 3}}
+	if !slices.Equal(progress, want) {
+		t.Errorf("expected progress calls %v, got %v", want, progress)
+	}
+}
+
+func TestJournalImport_AbortOnMalformed(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	input := `[
+  {"id": "11111111-1111-1111-1111-111111111111", "date": "2024-11-19T14:30:00Z", "content": "Valid entry"},
+  {"id": "22222222-2222-2222-2222-222222222222", "content": "Missing date"}
+]`
+
+	result, err := journal.ImportWithOptions(strings.NewReader(input), ExportFormatJSON, ImportOptions{AbortOnMalformed: true})
+	if err == nil {
+		t.Fatal("expected error for malformed record")
+	}
+	if !strings.Contains(err.Error(), "missing date") {
+		t.Errorf("expected error to name the malformed record, got %v", err)
+	}
+	if result.Imported != 0 || result.Malformed != 1 {
+		t.Errorf("expected nothing imported and 1 malformed, got %+v", result)
+	}
+	if journal.Count() != 0 {
+		t.Errorf("expected no entries after aborted import, got %d", journal.Count())
+	}
+}
+
+func TestJournalImport_RejectsTraversalID(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+
+	input := `[
+  {"id": "../../../x", "date": "2024-11-19T14:30:00Z", "content": "Escaping entry"},
+  {"id": "11111111-1111-1111-1111-111111111111", "date": "2024-11-20T14:30:00Z", "content": "Valid entry"}
+]`
+
+	result, err := journal.ImportWithOptions(strings.NewReader(input), ExportFormatJSON, ImportOptions{PreserveIDs: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if result.Imported != 1 || result.Malformed != 1 {
+		t.Errorf("expected 1 imported and 1 malformed, got %+v", result)
+	}
+	if _, exists := journal.index.GetMetadata("../../../x"); exists {
+		t.Error("expected the traversal ID to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Path, "x.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no file outside the journal, got %v", err)
+	}
+}
+
+func TestJournalImport_AbortOnShortID(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	input := `## 2024-11-19
+
+ID: abc
+
+Short ID entry
+`
+
+	result, err := journal.ImportWithOptions(strings.NewReader(input), ExportFormatMarkdown, ImportOptions{
+		PreserveIDs:      true,
+		AbortOnMalformed: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "not a UUID") {
+		t.Fatalf("expected a malformed ID error, got %v", err)
+	}
+	if result.Malformed != 1 || journal.Count() != 0 {
+		t.Errorf("expected 1 malformed record and no entries, got %+v with %d entries", result, journal.Count())
+	}
+}
+
+func TestParseMarkdownExport(t *testing.T) {
+	input := `## 2024-11-19
+
+Tags: work, meeting
+
+Hand-written entry
+
+---
+
+not a heading
+
+---
+
+## 2024-11-20 09:15
+Plain body without headers
+`
+
+	records, errs := parseMarkdownExport(input)
+
+	if len(errs) != 0 {
+		t.Fatalf("expected no parse errors, got %v", errs)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	if records[0].Content != "Hand-written entry\n\n---\n\nnot a heading" {
+		t.Errorf("unexpected first body: %q", records[0].Content)
+	}
+
+	if strings.Join(records[0].Tags, ",") != "work,meeting" {
+		t.Errorf("unexpected first tags: %v", records[0].Tags)
+	}
+
+	if records[1].Content != "Plain body without headers" {
+		t.Errorf("unexpected second body: %q", records[1].Content)
+	}
+
+	if records[1].Date.Hour() != 9 || records[1].Date.Minute() != 15 {
+		t.Errorf("unexpected second date: %v", records[1].Date)
+	}
+}
+
+func TestParseMarkdownExport_MalformedHeading(t *testing.T) {
+	input := `## yesterday-ish
+
+Body
+
+---
+
+## 2024-11-20
+
+Good body
+`
+
+	records, errs := parseMarkdownExport(input)
+
+	if len(errs) != 1 {
+		t.Errorf("expected 1 parse error, got %d", len(errs))
+	}
+
+	if len(records) != 1 || records[0].Content != "Good body" {
+		t.Errorf("expected only the valid record, got %+v", records)
+	}
+}
+
+func TestJournalImportDir(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	dir := t.TempDir()
+	mtime := time.Date(2023, 5, 6, 7, 8, 0, 0, time.Local)
+	files := map[string]string{
+		"2024-11-19.md":            "Dated by name",
+		"2024/2024-11-20-walk.txt": "In a subdirectory",
+		"undated.md":               "Dated by mtime",
+		"photo.jpg":                "not an entry",
+		"empty.txt":                "  \n",
+		".hidden/2024-11-21.md":    "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("failed to set mtime: %v", err)
+		}
+	}
+
+	var reported []ImportedFile
+	result, err := journal.ImportDir(dir, ImportDirOptions{Report: func(file ImportedFile) error {
+		reported = append(reported, file)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("ImportDir failed: %v", err)
+	}
+
+	if result.Imported != 3 || result.Skipped != 1 || result.Failed != 1 {
+		t.Errorf("expected 3 imported, 1 skipped, 1 failed, got %+v", result)
+	}
+	if len(reported) != 5 {
+		t.Errorf("expected 5 reported files, got %d", len(reported))
+	}
+
+	wantDates := map[string]time.Time{
+		"2024-11-19.md": time.Date(2024, 11, 19, 0, 0, 0, 0, time.Local),
+		filepath.Join("2024", "2024-11-20-walk.txt"): time.Date(2024, 11, 20, 0, 0, 0, 0, time.Local),
+		"undated.md": mtime,
+	}
+	for _, file := range reported {
+		want, ok := wantDates[file.Path]
+		if !ok {
+			continue
+		}
+		if file.ID == "" || file.Err != nil {
+			t.Errorf("%s: expected an imported entry, got %+v", file.Path, file)
+			continue
+		}
+		if !file.Date.Equal(want) {
+			t.Errorf("%s: expected date %v, got %v", file.Path, want, file.Date)
+		}
+		imported, err := journal.Get(file.ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if imported.GetContent() != files[filepath.ToSlash(file.Path)] {
+			t.Errorf("%s: unexpected content %q", file.Path, imported.GetContent())
+		}
+	}
+}
+
+func TestJournalImportDir_DateLayout(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "20240102 notes.md"), []byte("Compact date"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var date time.Time
+	_, err := journal.ImportDir(dir, ImportDirOptions{DateLayout: "20060102", Report: func(file ImportedFile) error {
+		date = file.Date
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("ImportDir failed: %v", err)
+	}
+
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local); !date.Equal(want) {
+		t.Errorf("expected date %v, got %v", want, date)
+	}
+}
@@ -0,0 +1,65 @@
+package entry
+
+import (
+	"fmt"
+
+	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/storage"
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// UsesIndex reports whether the journal keeps its index on disk; see config.Journal.UseIndex
+// Without one, the index is rebuilt in memory from the entry files whenever it is loaded
+func (j *Journal) UsesIndex() bool {
+	return j.config.IndexEnabled()
+}
+
+// loadIndex reads the index from disk, or builds it by decrypting every entry file if the
+// journal does not use an index
+// Problems that leave the index usable, i.e. inconsistencies or unreadable entry files, are returned as warnings
+func loadIndex(store *storage.Storage, useIndex bool) (*models.Index, []error, error) {
+	if useIndex {
+		index, inconsistencies, err := store.LoadIndex()
+		if err != nil || len(inconsistencies) == 0 {
+			return index, nil, err
+		}
+		return index, []error{fmt.Errorf("index has %d inconsistencies, run 'journal rebuild' to fix", len(inconsistencies))}, nil
+	}
+
+	index, skipped, err := scanIndex(store)
+	var warnings []error
+	for _, file := range skipped {
+		warnings = append(warnings, fmt.Errorf("failed to load entry %s: %w", file.FilePath, file.Error))
+	}
+	return index, warnings, err
+}
+
+// scanIndex builds an index from the entry files; unreadable files are returned and left out,
+// like a rebuild does, so one corrupt entry does not make the journal unusable
+func scanIndex(store *storage.Storage) (*models.Index, []crypto.FileError, error) {
+	files, err := store.ListAllEntries()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	index := models.NewIndex()
+	var skipped []crypto.FileError
+	for _, relFilePath := range files {
+		entry, err := store.LoadEntry(store.EntryIDFromPath(relFilePath), relFilePath)
+		if err != nil {
+			skipped = append(skipped, crypto.FileError{FilePath: relFilePath, Error: err})
+			continue
+		}
+		repairFilePath(entry, relFilePath)
+		index.Add(entry)
+	}
+	return index, skipped, nil
+}
+
+// saveIndex writes the in-memory index to disk; it does nothing if the journal does not use an index
+func (j *Journal) saveIndex() error {
+	if !j.UsesIndex() {
+		return nil
+	}
+	return j.storage.SaveIndex(j.index)
+}
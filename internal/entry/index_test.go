@@ -0,0 +1,188 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/data-castle/journal/internal/storage"
+)
+
+func TestJournal_IndexModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		useIndex bool
+	}{
+		{"index", true},
+		{"no index", false},
+	}
+
+	for _, tt := range tests {
+		useIndex := tt.useIndex
+		t.Run(tt.name, func(t *testing.T) {
+			_, cfg := setupTestJournal(t)
+			indexPath := filepath.Join(cfg.Path, storage.IndexFileName)
+			if !useIndex {
+				cfg.UseIndex = &useIndex
+				if err := os.Remove(indexPath); err != nil {
+					t.Fatalf("failed to remove index: %v", err)
+				}
+			}
+
+			journal, err := NewJournalFromConfig(cfg)
+			if err != nil {
+				t.Fatalf("failed to open journal: %v", err)
+			}
+			if journal.UsesIndex() != useIndex {
+				t.Errorf("expected UsesIndex %v", useIndex)
+			}
+
+			kept := mustAddEntry(t, journal, "Kept entry", []string{"work"})
+			deleted := mustAddEntry(t, journal, "Deleted entry", []string{"work"})
+			if _, err := journal.Update(kept.GetID(), "Updated entry", []string{"work", "notes"}); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			if err := journal.Delete(deleted.GetID()); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+
+			if _, err := os.Stat(indexPath); os.IsNotExist(err) == useIndex {
+				t.Errorf("expected index file to exist: %v, stat error: %v", useIndex, err)
+			}
+
+			// A fresh open sees the changes, whether read from the index or scanned from the entries
+			reopened, err := NewJournalFromConfig(cfg)
+			if err != nil {
+				t.Fatalf("failed to reopen journal: %v", err)
+			}
+			entries, err := reopened.SearchByTag("notes")
+			if err != nil {
+				t.Fatalf("SearchByTag failed: %v", err)
+			}
+			if len(entries) != 1 || entries[0].GetContent() != "Updated entry" {
+				t.Fatalf("expected the updated entry, got %d entries", len(entries))
+			}
+			if count := reopened.Count(); count != 1 {
+				t.Errorf("expected 1 entry, got %d", count)
+			}
+			if _, err := reopened.Get(deleted.GetID()); err == nil {
+				t.Error("expected deleted entry to be gone")
+			}
+		})
+	}
+}
+
+func TestScanIndex_SkipsUnreadableEntries(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+	entry := mustAddEntry(t, journal, "Readable entry", []string{"work"})
+
+	corrupt := filepath.Join(journal.storage.GetBasePath(), storage.EntriesDir, "corrupt.yaml")
+	if err := os.WriteFile(corrupt, []byte("not: encrypted"), 0600); err != nil {
+		t.Fatalf("failed to write corrupt entry: %v", err)
+	}
+
+	index, skipped, err := scanIndex(journal.storage)
+	if err != nil {
+		t.Fatalf("scanIndex failed: %v", err)
+	}
+	if len(index.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(index.Entries))
+	}
+	if len(skipped) != 1 || skipped[0].FilePath != "corrupt.yaml" {
+		t.Errorf("expected the corrupt file to be returned as skipped, got %v", skipped)
+	}
+	if _, ok := index.GetMetadata(entry.GetID()); !ok {
+		t.Error("expected readable entry in the scanned index")
+	}
+}
+
+func TestJournalSetWarnFunc(t *testing.T) {
+	_, cfg := setupTestJournal(t)
+	useIndex := false
+	cfg.UseIndex = &useIndex
+
+	corrupt := filepath.Join(cfg.Path, storage.EntriesDir, "corrupt.yaml")
+	if err := os.WriteFile(corrupt, []byte("not: encrypted"), 0600); err != nil {
+		t.Fatalf("failed to write corrupt entry: %v", err)
+	}
+
+	journal, err := NewJournalFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+
+	// The unreadable file found while opening is reported once a warn func is set
+	var warnings []error
+	journal.SetWarnFunc(func(err error) { warnings = append(warnings, err) })
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "corrupt.yaml") {
+		t.Fatalf("expected a warning about the corrupt file, got %v", warnings)
+	}
+
+	// Later problems that do not fail an operation are reported too
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[1].Error(), "corrupt.yaml") {
+		t.Errorf("expected rebuild to warn about the corrupt file, got %v", warnings)
+	}
+}
+
+func TestJournalSetWarnFunc_IndexInconsistencies(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+	mustAddEntry(t, journal, "Entry", nil)
+
+	index, _, err := journal.storage.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	index.ByTag["ghost"] = []string{"missing-id"}
+	if err := journal.storage.SaveIndex(index); err != nil {
+		t.Fatalf("SaveIndex failed: %v", err)
+	}
+
+	reopened, err := NewJournalFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	var warnings []error
+	reopened.SetWarnFunc(func(err error) { warnings = append(warnings, err) })
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "1 inconsistencies") {
+		t.Fatalf("expected one inconsistency warning on open, got %v", warnings)
+	}
+
+	// Every chunk of a batch reloads the index, but the warning is not repeated
+	defer func(size int) { addBatchSize = size }(addBatchSize)
+	addBatchSize = 1
+	inputs := []NewEntryInput{{Content: "First"}, {Content: "Second"}, {Content: "Third"}}
+	if _, err := reopened.AddBatch(inputs); err != nil {
+		t.Fatalf("AddBatch failed: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected no further warnings, got %v", warnings)
+	}
+}
+
+func TestJournalListRecent_WarnsAboutUnreadableEntries(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+	broken := mustAddEntry(t, journal, "Broken", nil)
+	mustAddEntry(t, journal, "Fine", nil)
+
+	path := filepath.Join(cfg.Path, storage.EntriesDir, broken.GetFilePath())
+	if err := os.WriteFile(path, []byte("not: encrypted"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	var warnings []error
+	journal.SetWarnFunc(func(err error) { warnings = append(warnings, err) })
+	entries, err := journal.ListRecent(10)
+	if err != nil {
+		t.Fatalf("ListRecent failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected the readable entry only, got %d", len(entries))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), broken.GetID()) {
+		t.Errorf("expected a warning about %s, got %v", broken.GetID(), warnings)
+	}
+}
@@ -1,10 +1,14 @@
 package entry
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/data-castle/journal/internal/config"
@@ -14,36 +18,104 @@ import (
 	"github.com/google/uuid"
 )
 
+// Errors returned by journal operations, for use with errors.Is
+var (
+	ErrEntryNotFound = errors.New("entry not found")
+	ErrEntryExists   = errors.New("entry already exists")
+	ErrAmbiguousDate = errors.New("multiple entries on that date, specify ID")
+	ErrReadOnly      = errors.New("journal is opened read-only")
+)
+
 // Journal is the main entry point for journal operations using SOPS encryption
 type Journal struct {
 	config  *config.Journal
 	storage *storage.Storage
 	index   *models.Index
+	// readOnly makes every change fail with ErrReadOnly; see OpenJournalReadOnly
+	readOnly bool
+	// warn receives problems that do not fail an operation; see SetWarnFunc
+	warn func(err error)
+	// openWarnings are the problems found while opening the journal, kept until SetWarnFunc is called
+	openWarnings []error
 }
 
 // NewJournalFromConfig creates a SOPS-based journal instance from config
 func NewJournalFromConfig(cfg *config.Journal) (*Journal, error) {
+	return openJournal(cfg, false)
+}
+
+// OpenJournalReadOnly opens the journal for reading only: no directories are created and
+// every method that would change the journal returns ErrReadOnly
+// Use it for commands that only read, so they also work on a read-only filesystem
+func OpenJournalReadOnly(cfg *config.Journal) (*Journal, error) {
+	return openJournal(cfg, true)
+}
+
+// openJournal opens the journal described by cfg, creating its entries directory unless readOnly
+func openJournal(cfg *config.Journal, readOnly bool) (*Journal, error) {
 	store, err := storage.NewStorage(cfg.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage: %w", err)
 	}
 
-	if err := store.Initialize(); err != nil {
+	if err := store.SetFilenameTemplate(cfg.FilenameTemplate); err != nil {
+		return nil, fmt.Errorf("invalid journal config: %w", err)
+	}
+
+	if err := store.SetKeyFiles(cfg.KeyFiles); err != nil {
+		return nil, fmt.Errorf("failed to load key files: %w", err)
+	}
+	store.SetCompression(cfg.Compress)
+
+	if readOnly {
+		err = store.CheckInitialized()
+	} else {
+		err = store.Initialize()
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
-	index, err := store.LoadIndex()
+	index, warnings, err := loadIndex(store, cfg.IndexEnabled())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load index: %w", err)
 	}
 
 	return &Journal{
-		config:  cfg,
-		storage: store,
-		index:   index,
+		config:       cfg,
+		storage:      store,
+		index:        index,
+		readOnly:     readOnly,
+		openWarnings: warnings,
 	}, nil
 }
 
+// checkWritable returns ErrReadOnly if the journal was opened with OpenJournalReadOnly
+func (j *Journal) checkWritable() error {
+	if j.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// SetWarnFunc sets the function that receives problems that do not fail an operation, such as an
+// undo record or content index that could not be saved; without one they are dropped
+// Problems found while opening the journal are passed to warn right away
+func (j *Journal) SetWarnFunc(warn func(err error)) {
+	j.warn = warn
+	for _, err := range j.openWarnings {
+		j.warnf("%w", err)
+	}
+	j.openWarnings = nil
+}
+
+// warnf reports a problem that does not fail the current operation to the warn func, if there is one
+func (j *Journal) warnf(format string, a ...any) {
+	if j.warn != nil {
+		j.warn(fmt.Errorf(format, a...))
+	}
+}
+
 // InitializeJournal creates a new journal with specified recipients
 func InitializeJournal(cfg *config.Journal, recipients []string) error {
 	if err := os.MkdirAll(cfg.Path, 0700); err != nil {
@@ -63,6 +135,9 @@ func InitializeJournal(cfg *config.Journal, recipients []string) error {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
+	if !cfg.IndexEnabled() {
+		return nil
+	}
 	index := models.NewIndex()
 	if err := store.SaveIndex(index); err != nil {
 		return fmt.Errorf("failed to save initial index: %w", err)
@@ -71,36 +146,218 @@ func InitializeJournal(cfg *config.Journal, recipients []string) error {
 	return nil
 }
 
+// AddOptions sets optional fields of a new entry
+type AddOptions struct {
+	// Date backdates the entry; zero means now
+	Date time.Time
+	// Title summarizes the entry; empty means the first line of the content
+	Title string
+	// Mood rates the entry from models.MinMood to models.MaxMood; zero leaves it unrated
+	Mood int
+	// Links are IDs or unique ID prefixes of existing entries the new entry refers to
+	Links []string
+}
+
 // Add adds a new entry to the journal
 func (j *Journal) Add(content string, tags []string) (models.Entry, error) {
-	entry := models.NewEntryV1(
-		uuid.New().String(),
-		time.Now(),
-		content,
-		tags,
+	return j.AddWithOptions(content, tags, AddOptions{})
+}
+
+// AddWithDate adds a new entry dated at the given time, e.g. to backdate an entry
+func (j *Journal) AddWithDate(content string, tags []string, date time.Time) (models.Entry, error) {
+	if date.IsZero() {
+		return nil, fmt.Errorf("entry date is required")
+	}
+	return j.AddWithOptions(content, tags, AddOptions{Date: date})
+}
+
+// AddWithOptions adds a new entry with the optional fields in opts
+// The journal's default tags are added before tags, and duplicates are dropped
+func (j *Journal) AddWithOptions(content string, tags []string, opts AddOptions) (models.Entry, error) {
+	if opts.Date.IsZero() {
+		opts.Date = time.Now()
+	}
+	tags = dedupeTags(append(append([]string(nil), j.config.DefaultTags...), tags...))
+	return j.addEntry(uuid.New().String(), content, tags, opts)
+}
+
+// NewEntryInput describes one entry for AddBatch
+type NewEntryInput struct {
+	// ID keeps an existing ID, e.g. one from an archive; empty means a new one is generated
+	ID      string
+	Content string
+	Tags    []string
+	// Options sets the date, title, mood, and links; a zero date means now
+	Options AddOptions
+}
+
+// AddBatch adds several entries, saving the index once per hundred entries instead of
+// re-encrypting it after every entry as repeated calls to Add would
+// Default tags are added as with Add. If an entry cannot be saved, the entries saved before it stay
+// in the journal and are returned along with the error
+func (j *Journal) AddBatch(inputs []NewEntryInput) ([]models.Entry, error) {
+	prepared := make([]NewEntryInput, len(inputs))
+	for i, input := range inputs {
+		input.Tags = dedupeTags(append(append([]string(nil), j.config.DefaultTags...), input.Tags...))
+		prepared[i] = input
+	}
+	return j.addEntries(prepared, nil)
+}
+
+// addEntry saves a new entry with the given ID and records it in the index
+// opts.Date must be set
+func (j *Journal) addEntry(id string, content string, tags []string, opts AddOptions) (models.Entry, error) {
+	entries, err := j.addEntries([]NewEntryInput{{ID: id, Content: content, Tags: tags, Options: opts}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// addBatchSize is how many entries addEntries saves under one index lock before saving the index,
+// so an interrupted batch leaves at most that many entry files out of the index, and other
+// processes get the lock in between
+var addBatchSize = 100
+
+// addEntries saves new entries with their tags as given and records them in the index, which is
+// saved after every addBatchSize entries and after the last one saved if a later one fails
+// progress, if set, is called with the number of entries saved so far after each one
+func (j *Journal) addEntries(inputs []NewEntryInput, progress func(done int)) ([]models.Entry, error) {
+	now := time.Now()
+	var entries []models.Entry
+	for start := 0; start < len(inputs); start += addBatchSize {
+		chunk := inputs[start:min(start+addBatchSize, len(inputs))]
+		added, err := j.addEntryChunk(chunk, now, func(done int) {
+			if progress != nil {
+				progress(start + done)
+			}
+		})
+		entries = append(entries, added...)
+		if err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+// addEntryChunk adds inputs for addEntries under one index lock, dating those without a date at now,
+// and saves the index once after the last entry, or after the last one saved if a later one fails
+func (j *Journal) addEntryChunk(inputs []NewEntryInput, now time.Time, progress func(done int)) ([]models.Entry, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries := make([]models.Entry, 0, len(inputs))
+	var addErr error
+	for _, input := range inputs {
+		if input.ID == "" {
+			input.ID = uuid.New().String()
+		}
+		if input.Options.Date.IsZero() {
+			input.Options.Date = now
+		}
+
+		entry, err := j.saveNewEntry(input)
+		if err != nil {
+			addErr = err
+			break
+		}
+		j.index.Add(entry)
+		entries = append(entries, entry)
+
+		progress(len(entries))
+	}
+
+	if len(entries) == 0 {
+		return nil, addErr
+	}
+
+	if err := j.saveIndex(); err != nil {
+		return nil, fmt.Errorf("failed to save index: %w", err)
+	}
+
+	j.updateContentIndex(func(ci *models.ContentIndex) {
+		for _, entry := range entries {
+			indexContent(ci, entry)
+		}
+	})
+
+	return entries, addErr
+}
+
+// saveNewEntry builds the entry described by input, which must have an ID and date, and saves its
+// file; the caller must hold the index lock and add the entry to the index
+func (j *Journal) saveNewEntry(input NewEntryInput) (*models.EntryV2, error) {
+	if err := models.ValidateMood(input.Options.Mood); err != nil {
+		return nil, err
+	}
+	if _, exists := j.index.GetMetadata(input.ID); exists {
+		return nil, fmt.Errorf("%w: %s", ErrEntryExists, input.ID)
+	}
+
+	links, err := j.resolveLinks(input.Options.Links)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.NewEntryV2(
+		input.ID,
+		input.Options.Date,
+		input.Content,
+		input.Tags,
 		"", // filepath will be determined by storage path
 	)
 
+	entry.Title = input.Options.Title
+	if entry.Title == "" {
+		entry.Title = models.DefaultTitle(input.Content)
+	}
+	entry.Mood = input.Options.Mood
+	entry.Links = links
+	entry.ContentHash = models.HashContent(input.Content)
+
 	entry.FilePath = j.storage.GetEntryPath(entry.GetDate(), entry.GetID())
 
 	if err := j.storage.SaveEntry(entry); err != nil {
 		return nil, fmt.Errorf("failed to save entry: %w", err)
 	}
+	return entry, nil
+}
 
-	j.index.Add(&entry.MetadataV1)
-
-	if err := j.storage.SaveIndex(j.index); err != nil {
-		return nil, fmt.Errorf("failed to save index: %w", err)
+// resolveLinks resolves each link to the full ID of an indexed entry, dropping repeated links
+// Returns an error wrapping ErrEntryNotFound if a link matches no entry
+func (j *Journal) resolveLinks(links []string) ([]string, error) {
+	var resolved []string
+	for _, link := range links {
+		id, _, err := j.resolveID(link)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link %s: %w", link, err)
+		}
+		if !slices.Contains(resolved, id) {
+			resolved = append(resolved, id)
+		}
 	}
+	return resolved, nil
+}
 
-	return entry, nil
+// Backlinks returns the IDs of entries that link to the entry with the given ID or unique ID prefix,
+// oldest first
+// Links are kept in the index, so no entries are decrypted
+func (j *Journal) Backlinks(id string) ([]string, error) {
+	id, _, err := j.resolveID(id)
+	if err != nil {
+		return nil, err
+	}
+	return j.index.FindLinkingTo(id), nil
 }
 
-// Get retrieves a single entry by ID
+// Get retrieves a single entry by ID or unique ID prefix
 func (j *Journal) Get(id string) (models.Entry, error) {
-	meta, exists := j.index.GetMetadata(id)
-	if !exists {
-		return nil, fmt.Errorf("entry not found: %s", id)
+	id, meta, err := j.resolveID(id)
+	if err != nil {
+		return nil, err
 	}
 
 	entry, err := j.storage.LoadEntry(id, meta.FilePath)
@@ -111,30 +368,147 @@ func (j *Journal) Get(id string) (models.Entry, error) {
 	return entry, nil
 }
 
+// GetByDate retrieves the only entry on a date
+// Returns ErrEntryNotFound if there is none and ErrAmbiguousDate, listing the IDs, if there are several
+func (j *Journal) GetByDate(date time.Time) (models.Entry, error) {
+	day := models.DateKey(date)
+	ids := j.index.FindByDate(date)
+
+	switch len(ids) {
+	case 0:
+		return nil, fmt.Errorf("%w: no entry on %s", ErrEntryNotFound, day)
+	case 1:
+		return j.Get(ids[0])
+	default:
+		return nil, fmt.Errorf("%w (%s): %s", ErrAmbiguousDate, day, strings.Join(ids, ", "))
+	}
+}
+
 // SearchByDate finds entries for a specific date
 func (j *Journal) SearchByDate(date time.Time) ([]models.Entry, error) {
 	ids := j.index.FindByDate(date)
 	return j.loadEntries(ids)
 }
 
+// OnThisDay finds entries written on the month and day of date in any year, newest first
+func (j *Journal) OnThisDay(date time.Time) ([]models.Entry, error) {
+	ids := j.index.FindByMonthDay(date)
+	return j.loadEntries(ids)
+}
+
 // SearchByDateRange finds entries within a date range
 func (j *Journal) SearchByDateRange(start, end time.Time) ([]models.Entry, error) {
 	ids := j.index.FindByDateRange(start, end)
 	return j.loadEntries(ids)
 }
 
-// SearchByTag finds entries with a specific tag
+// SearchByTag finds entries with a specific tag, ignoring case
 func (j *Journal) SearchByTag(tag string) ([]models.Entry, error) {
-	ids := j.index.FindByTag(tag)
+	ids := j.index.FindByTagCaseInsensitive(tag)
 	return j.loadEntries(ids)
 }
 
-// SearchByTags finds entries with all specified tags (AND operation)
+// SearchByTags finds entries with all specified tags (AND operation), ignoring case
 func (j *Journal) SearchByTags(tags []string) ([]models.Entry, error) {
 	ids := j.index.FindByTags(tags)
 	return j.loadEntries(ids)
 }
 
+// SearchByAnyTag finds entries with at least one of the specified tags (OR operation), ignoring case
+func (j *Journal) SearchByAnyTag(tags []string) ([]models.Entry, error) {
+	ids := j.index.FindByAnyTag(tags)
+	return j.loadEntries(ids)
+}
+
+// SearchByTagPrefix finds entries with any tag starting with prefix, e.g. "project/", ignoring case
+// Entries are returned newest first, and those matching several tags appear once
+func (j *Journal) SearchByTagPrefix(prefix string) ([]models.Entry, error) {
+	ids := j.index.FindByTagPrefix(prefix)
+	return j.loadEntries(ids)
+}
+
+// SearchByMinMood finds entries rated at least minMood; unrated entries are excluded
+func (j *Journal) SearchByMinMood(minMood int) ([]models.Entry, error) {
+	ids := j.index.FindByMinMood(minMood)
+	return j.loadEntries(ids)
+}
+
+// Iterate decrypts entries one at a time in date order, oldest first, and calls fn with each
+// Only the entry being visited is held in memory, so it suits large journals at the cost of
+// decrypting every entry on each pass; prefer the index-backed searches when they suffice
+// Entries that fail to decrypt are passed to the warn func and skipped; an error from fn stops
+// the iteration and is returned
+func (j *Journal) Iterate(fn func(models.Entry) error) error {
+	return j.iterate(nil, false, fn)
+}
+
+// iterate is Iterate restricted to the entries whose IDs are in ids, or to every entry if ids is nil,
+// visiting the newest entry first if newestFirst is set
+func (j *Journal) iterate(ids map[string]bool, newestFirst bool, fn func(models.Entry) error) error {
+	metas := j.ListAll()
+	if !newestFirst {
+		slices.Reverse(metas)
+	}
+	for _, meta := range metas {
+		if ids != nil && !ids[meta.Id] {
+			continue
+		}
+
+		entry, err := j.storage.LoadEntry(meta.Id, meta.FilePath)
+		if err != nil {
+			j.warnf("failed to load entry %s: %w", meta.Id, err)
+			continue
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchByContent returns entries whose title or content contains query, ignoring case, newest first
+// Content is encrypted, so entries are decrypted to check them; the encrypted content index
+// narrows which entries are decrypted, and without it every entry is checked
+func (j *Journal) SearchByContent(query string) ([]models.Entry, error) {
+	candidates := j.contentSearchCandidates(query)
+	query = strings.ToLower(query)
+
+	var matches []models.Entry
+	err := j.iterate(candidates, true, func(entry models.Entry) error {
+		if strings.Contains(strings.ToLower(entry.GetContent()), query) || strings.Contains(strings.ToLower(entry.GetTitle()), query) {
+			matches = append(matches, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// SearchByRegex returns entries whose title or content matches the regular expression pattern,
+// newest first; an invalid pattern is reported before any entry is decrypted, otherwise every
+// entry is decrypted, like SearchByContent
+func (j *Journal) SearchByRegex(pattern string) ([]models.Entry, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+
+	var matches []models.Entry
+	err = j.iterate(nil, true, func(entry models.Entry) error {
+		if re.MatchString(entry.GetContent()) || re.MatchString(entry.GetTitle()) {
+			matches = append(matches, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
 // ListRecent lists the most recent N entries
 func (j *Journal) ListRecent(count int) ([]models.Entry, error) {
 	var metas []models.Metadata
@@ -151,27 +525,17 @@ func (j *Journal) ListRecent(count int) ([]models.Entry, error) {
 	}
 	metas = metas[:count]
 
-	// Load entries
+	// Load entries, passing the ones that fail to the warn func
 	var entries []models.Entry
-	var loadErrors []error
 	for _, meta := range metas {
 		entry, err := j.storage.LoadEntry(meta.Id, meta.FilePath)
 		if err != nil {
-			loadErrors = append(loadErrors, fmt.Errorf("failed to load entry %s: %w", meta.Id, err))
+			j.warnf("failed to load entry %s: %w", meta.Id, err)
 			continue
 		}
 		entries = append(entries, entry)
 	}
 
-	// Log warnings for failed entries to stderr
-	if len(loadErrors) > 0 {
-		for _, err := range loadErrors {
-			if _, ferr := fmt.Fprintf(os.Stderr, "Warning: %v\n", err); ferr != nil {
-				return nil, ferr
-			}
-		}
-	}
-
 	return entries, nil
 }
 
@@ -189,31 +553,233 @@ func (j *Journal) ListAll() []models.Metadata {
 	return metas
 }
 
-// Delete removes an entry
+// ListByTag returns metadata for the entries with a specific tag, ignoring case, newest first,
+// without loading them
+func (j *Journal) ListByTag(tag string) []models.Metadata {
+	var metas []models.Metadata
+	for _, id := range j.index.FindByTagCaseInsensitive(tag) {
+		if meta, ok := j.index.Entries[id]; ok {
+			metas = append(metas, meta)
+		}
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].Date.After(metas[j].Date)
+	})
+
+	return metas
+}
+
+// Count returns the total number of entries
+func (j *Journal) Count() int {
+	return len(j.index.Entries)
+}
+
+// CountByTag returns the number of entries with a specific tag, ignoring case
+func (j *Journal) CountByTag(tag string) int {
+	return len(j.index.FindByTagCaseInsensitive(tag))
+}
+
+// CountByDateRange returns the number of entries within a date range
+func (j *Journal) CountByDateRange(start, end time.Time) int {
+	return len(j.index.FindByDateRange(start, end))
+}
+
+// IndexSnapshot returns a deep copy of the current index
+// Callers may freely modify the result without affecting the journal
+func (j *Journal) IndexSnapshot() models.Index {
+	return *j.index.Clone()
+}
+
+// ValidateIndex checks the loaded index for inconsistencies between entries and lookup maps
+func (j *Journal) ValidateIndex() []error {
+	return j.index.Validate()
+}
+
+// Delete moves an entry to the trash and removes it from the index
+// Its attachments are kept until it is deleted permanently
+// Use Restore to bring it back or DeletePermanently to skip the trash
 func (j *Journal) Delete(id string) error {
-	meta, exists := j.index.GetMetadata(id)
-	if !exists {
-		return fmt.Errorf("entry not found: %s", id)
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return err
 	}
+	defer unlock()
+
+	id, meta, err := j.resolveID(id)
+	if err != nil {
+		return err
+	}
+
+	prior, loadErr := j.storage.LoadEntry(id, meta.FilePath)
+
+	if err := j.storage.MoveToTrash(meta.FilePath); err != nil {
+		return fmt.Errorf("failed to move entry to trash: %w", err)
+	}
+
+	j.index.Remove(id)
+
+	if err := j.saveIndex(); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+
+	j.updateContentIndex(func(ci *models.ContentIndex) { ci.Remove(id) })
+
+	if loadErr == nil {
+		j.recordOperation(OperationDelete, prior)
+	}
+
+	return nil
+}
+
+// DeletePermanently removes an entry file, its attachments, and its index entry without using the trash
+func (j *Journal) DeletePermanently(id string) error {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	id, meta, err := j.resolveID(id)
+	if err != nil {
+		return err
+	}
+
+	prior, loadErr := j.storage.LoadEntry(id, meta.FilePath)
 
 	if err := j.storage.DeleteEntry(meta.FilePath); err != nil {
 		return fmt.Errorf("failed to delete entry: %w", err)
 	}
+	if err := j.storage.DeleteAttachments(id); err != nil {
+		return err
+	}
 
 	j.index.Remove(id)
 
-	if err := j.storage.SaveIndex(j.index); err != nil {
+	if err := j.saveIndex(); err != nil {
 		return fmt.Errorf("failed to save index: %w", err)
 	}
 
+	j.updateContentIndex(func(ci *models.ContentIndex) { ci.Remove(id) })
+
+	if loadErr == nil {
+		j.recordOperation(OperationDelete, prior)
+	}
+
 	return nil
 }
 
+// DeleteByTag moves every entry with tag, ignoring case, to the trash and returns how many were deleted
+func (j *Journal) DeleteByTag(tag string) (int, error) {
+	return j.deleteMatching(func() []string { return j.index.FindByTagCaseInsensitive(tag) })
+}
+
+// DeleteByDateRange moves every entry dated within start and end to the trash and returns how many were deleted
+func (j *Journal) DeleteByDateRange(start, end time.Time) (int, error) {
+	return j.deleteMatching(func() []string { return j.index.FindByDateRange(start, end) })
+}
+
+// deleteMatching moves the entries whose IDs find returns to the trash, saving the index once
+// find is called after the index lock is taken, so it sees the latest index
+// Batch deletes are not recorded for undo; the undo record is cleared instead, since it may refer
+// to a deleted entry, and the entries can be brought back with Restore
+func (j *Journal) deleteMatching(find func() []string) (int, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Copy the IDs since the index buckets are modified while deleting
+	ids := append([]string(nil), find()...)
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var deleted []string
+	var deleteErr error
+	for _, id := range ids {
+		meta, exists := j.index.GetMetadata(id)
+		if !exists {
+			continue
+		}
+
+		if err := j.storage.MoveToTrash(meta.FilePath); err != nil {
+			deleteErr = fmt.Errorf("failed to move entry %s to trash: %w", id, err)
+			break
+		}
+
+		j.index.Remove(id)
+		deleted = append(deleted, id)
+	}
+
+	if len(deleted) > 0 {
+		j.updateContentIndex(func(ci *models.ContentIndex) {
+			for _, id := range deleted {
+				ci.Remove(id)
+			}
+		})
+
+		if err := j.storage.ClearLastOperation(); err != nil {
+			j.warnf("%w", err)
+		}
+	}
+
+	if deleteErr != nil {
+		return len(deleted), j.saveIndexAfterError(deleteErr)
+	}
+
+	if err := j.saveIndex(); err != nil {
+		return len(deleted), fmt.Errorf("failed to save index: %w", err)
+	}
+
+	return len(deleted), nil
+}
+
 // Update updates an existing entry
 func (j *Journal) Update(id string, content string, tags []string) (models.Entry, error) {
-	meta, exists := j.index.GetMetadata(id)
-	if !exists {
-		return nil, fmt.Errorf("entry not found: %s", id)
+	return j.modifyEntry(id, func(entry *models.EntryV2) {
+		entry.Content = content
+		entry.Tags = tags
+	})
+}
+
+// Append adds text to the end of an entry's content on a new line, keeping its date and tags
+func (j *Journal) Append(id string, text string) (models.Entry, error) {
+	return j.modifyEntry(id, func(entry *models.EntryV2) {
+		if entry.Content == "" {
+			entry.Content = text
+			return
+		}
+		entry.Content = strings.TrimRight(entry.Content, "\n") + "\n" + text
+	})
+}
+
+// Touch changes the date of an entry without changing its content
+// An entry moved to another day gets a new file under the matching year/month directory
+func (j *Journal) Touch(id string, newDate time.Time) (models.Entry, error) {
+	return j.modifyEntry(id, func(entry *models.EntryV2) {
+		if models.DateKey(newDate) != models.DateKey(entry.Date) {
+			entry.FilePath = j.storage.GetEntryPath(newDate, entry.Id)
+		}
+		entry.Date = newDate
+	})
+}
+
+// modifyEntry loads an entry, applies change to it, and saves it along with the index
+// The prior state is recorded so the change can be undone
+// If change moves the entry to another file, the old file is deleted only after the index points to
+// the new one, so an interrupted move leaves a duplicate file rather than a lost entry
+func (j *Journal) modifyEntry(id string, change func(entry *models.EntryV2)) (models.Entry, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	id, meta, err := j.resolveID(id)
+	if err != nil {
+		return nil, err
 	}
 
 	entry, err := j.storage.LoadEntry(id, meta.FilePath)
@@ -221,119 +787,486 @@ func (j *Journal) Update(id string, content string, tags []string) (models.Entry
 		return nil, fmt.Errorf("failed to load entry: %w", err)
 	}
 
-	// Type assert to V1 to update fields
-	// Note: When adding new entry versions, add a type switch here to handle each version
-	entryV1, ok := entry.(*models.EntryV1)
-	if !ok {
-		return nil, fmt.Errorf("unsupported entry version for update")
+	// Older entry versions are upgraded, so every modified entry is saved in the current version
+	updated, err := models.Upgrade(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update entry: %w", err)
 	}
 
-	entryV1.Content = content
-	entryV1.Tags = tags
+	change(updated)
+	updated.Modified = time.Now()
+	updated.ContentHash = models.HashContent(updated.Content)
 
-	if err := j.storage.SaveEntry(entryV1); err != nil {
+	if err := j.storage.SaveEntry(updated); err != nil {
 		return nil, fmt.Errorf("failed to save entry: %w", err)
 	}
 
-	// Update index
 	j.index.Remove(id)
-	j.index.Add(&entryV1.MetadataV1)
+	j.index.Add(updated)
 
-	if err := j.storage.SaveIndex(j.index); err != nil {
+	if err := j.saveIndex(); err != nil {
 		return nil, fmt.Errorf("failed to save index: %w", err)
 	}
 
-	return entryV1, nil
+	if updated.FilePath != meta.FilePath {
+		if err := j.storage.DeleteEntry(meta.FilePath); err != nil {
+			return nil, err
+		}
+	}
+
+	j.updateContentIndex(func(ci *models.ContentIndex) { indexContent(ci, updated) })
+
+	j.recordOperation(OperationUpdate, entry)
+
+	return updated, nil
+}
+
+// IndexDrift compares the index with the entry files on disk, as can differ after an external git merge
+// added lists IDs of entry files missing from the index; removed lists indexed IDs without a file
+func (j *Journal) IndexDrift() (added, removed []string, err error) {
+	return FindIndexDrift(j.storage, j.index)
+}
+
+// FindIndexDrift compares index with the entry files in store; see IndexDrift
+// Both lists are sorted, and both are empty when the index matches the files
+func FindIndexDrift(store *storage.Storage, index *models.Index) (added, removed []string, err error) {
+	files, err := store.ListAllEntries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	onDisk := make(map[string]bool, len(files))
+	for _, relFilePath := range files {
+		id := store.EntryIDFromPath(relFilePath)
+		onDisk[id] = true
+		if _, exists := index.GetMetadata(id); !exists {
+			added = append(added, id)
+		}
+	}
+
+	for id := range index.Entries {
+		if !onDisk[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed, nil
+}
+
+// RebuildOptions controls how RebuildIndexWithOptions rebuilds the index
+type RebuildOptions struct {
+	// Progress, if set, is called after each entry file is read
+	Progress crypto.ProgressFunc
+}
+
+// RebuildResult summarizes an index rebuild
+type RebuildResult struct {
+	TotalFiles int
+	Indexed    int
+	// Backfilled counts entries saved before content hashes existed that had their hash recorded
+	Backfilled int
+	// Repaired counts entries whose recorded file path did not match the file they were found in,
+	// e.g. after the file was moved by hand; the recorded path is corrected to the actual one
+	Repaired int
+	// Skipped lists the entry files that could not be read, which are left out of the index
+	Skipped []crypto.FileError
 }
 
-// RebuildIndex rebuilds the index from all entry files
+// RebuildIndex rebuilds the index and the content index from all entry files
+// Unreadable entry files are passed to the warn func and skipped; use RebuildIndexWithOptions to collect them
 func (j *Journal) RebuildIndex() error {
+	result, err := j.RebuildIndexWithOptions(RebuildOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, skipped := range result.Skipped {
+		j.warnf("failed to load entry %s: %w", skipped.FilePath, skipped.Error)
+	}
+	return nil
+}
+
+// RebuildIndexWithOptions rebuilds the index and the content index from all entry files
+// Entries without a content hash have it recorded, so later corruption of their content can be detected
+// Unreadable entry files are skipped, listed in the result, and recorded in the quarantine log;
+// only failures that stop the rebuild, such as being unable to save the index, are returned as errors
+func (j *Journal) RebuildIndexWithOptions(opts RebuildOptions) (*RebuildResult, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	newIndex := models.NewIndex()
+	contentIndex := models.NewContentIndex()
 
 	files, err := j.storage.ListAllEntries()
 	if err != nil {
-		return fmt.Errorf("failed to list entries: %w", err)
+		return nil, fmt.Errorf("failed to list entries: %w", err)
 	}
 
+	result := &RebuildResult{TotalFiles: len(files)}
+
 	// Load each entry and add to index
-	for _, relFilePath := range files {
-		filename := filepath.Base(relFilePath)
-		id := filename[:len(filename)-len(".yaml")]
+	for i, relFilePath := range files {
+		id := j.storage.EntryIDFromPath(relFilePath)
 
 		entry, err := j.storage.LoadEntry(id, relFilePath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to load entry %s: %v\n", relFilePath, err)
-			continue
+			result.Skipped = append(result.Skipped, crypto.FileError{FilePath: relFilePath, Error: err})
+		} else {
+			// The file's actual location wins over the path recorded in it, so the index always
+			// points at files that exist under the entries directory
+			repaired := repairFilePath(entry, relFilePath)
+
+			// Entries saved without a title get one from their content via GetTitle
+			newIndex.Add(entry)
+			indexContent(contentIndex, entry)
+			result.Indexed++
+
+			backfilled := backfillContentHash(entry)
+			if repaired || backfilled {
+				if err := j.storage.SaveEntryAt(entry, relFilePath); err != nil {
+					j.warnf("failed to update %s: %w", relFilePath, err)
+				} else {
+					if repaired {
+						result.Repaired++
+					}
+					if backfilled {
+						result.Backfilled++
+					}
+				}
+			}
 		}
 
-		newIndex.Add(entry)
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(files), relFilePath)
+		}
 	}
 
 	j.index = newIndex
 
-	if err := j.storage.SaveIndex(j.index); err != nil {
-		return fmt.Errorf("failed to save index: %w", err)
+	if err := j.saveIndex(); err != nil {
+		return nil, fmt.Errorf("failed to save index: %w", err)
 	}
 
-	return nil
+	if j.UsesIndex() {
+		if err := j.storage.SaveContentIndex(contentIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordQuarantine(j.storage, result.Skipped); err != nil {
+		j.warnf("%w", err)
+	}
+
+	return result, nil
+}
+
+// repairFilePath sets the file path recorded in entry to relFilePath, where it was actually found
+// It reports whether entry was changed and needs to be saved
+func repairFilePath(entry models.Entry, relFilePath string) bool {
+	if entry.GetFilePath() == relFilePath {
+		return false
+	}
+
+	switch e := entry.(type) {
+	case *models.EntryV2:
+		e.FilePath = relFilePath
+	case *models.EntryV1:
+		e.FilePath = relFilePath
+	default:
+		return false
+	}
+	return true
+}
+
+// backfillContentHash records the content hash of an entry saved before hashes existed
+// It reports whether entry was changed and needs to be saved
+func backfillContentHash(entry models.Entry) bool {
+	if entry.GetContentHash() != "" {
+		return false
+	}
+
+	hash := models.HashContent(entry.GetContent())
+	switch e := entry.(type) {
+	case *models.EntryV2:
+		e.ContentHash = hash
+	case *models.EntryV1:
+		e.ContentHash = hash
+	default:
+		return false
+	}
+	return true
+}
+
+// ReEncryptOptions controls how ReEncryptWithOptions re-encrypts the journal
+type ReEncryptOptions struct {
+	// Recipients replaces the recipients in .sops.yaml; empty keeps the current ones
+	Recipients []string
+	// Progress, if set, is called after each entry file is re-encrypted
+	Progress crypto.ProgressFunc
+	// SkipVerify skips decrypting each rewritten file with the current keys,
+	// which is required when the new recipients no longer include them
+	SkipVerify bool
+	// Entries limits re-encryption to these entry files, relative to the entries directory;
+	// nil re-encrypts every entry and an empty list none
+	Entries []string
+	// SkipIndex leaves the index, content index, templates, and undo record as they are
+	SkipIndex bool
 }
 
 // ReEncrypt re-encrypts all entries and index with current recipients from .sops.yaml
-// Uses transactional approach with automatic rollback on failure
 // This is useful after manually editing .sops.yaml to apply the changes to all entries
 func (j *Journal) ReEncrypt() error {
-	recipients, err := crypto.ReadSOPSConfig(j.config.Path)
+	return j.ReEncryptWithOptions(ReEncryptOptions{})
+}
+
+// ReEncryptWithRecipients re-encrypts all entries and index with new recipients
+// This is the method to use when programmatically adding/removing recipients
+func (j *Journal) ReEncryptWithRecipients(newRecipients []string) error {
+	return j.ReEncryptWithOptions(ReEncryptOptions{Recipients: newRecipients})
+}
+
+// ReEncryptIndex re-encrypts only the index, content index, templates, and undo record, e.g. after fixing the index by hand
+func (j *Journal) ReEncryptIndex() error {
+	return j.ReEncryptWithOptions(ReEncryptOptions{Entries: []string{}})
+}
+
+// ReEncryptEntries re-encrypts only the entry files and their attachments, leaving the index as it is
+func (j *Journal) ReEncryptEntries() error {
+	return j.ReEncryptWithOptions(ReEncryptOptions{SkipIndex: true})
+}
+
+// ReEncryptWithOptions re-encrypts all entries and index according to opts
+// Trashed entries are re-encrypted with the entries unless opts.Entries limits the entry files,
+// so removed recipients cannot read them and new ones can after a restore
+// Uses crypto.TransactionalReEncrypt, which writes .sops.yaml first and restores it on failure
+// Files re-encrypted before a failure are restored from their original contents,
+// so the journal stays readable by the original recipients
+func (j *Journal) ReEncryptWithOptions(opts ReEncryptOptions) error {
+	unlock, err := j.lockIndex()
 	if err != nil {
-		return fmt.Errorf("failed to read recipients: %w", err)
+		return err
+	}
+	defer unlock()
+
+	newRecipients := opts.Recipients
+	if len(newRecipients) == 0 {
+		recipients, err := crypto.ReadSOPSConfig(j.config.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read recipients: %w", err)
+		}
+		newRecipients = recipients
+	}
+
+	newEncryptor := crypto.NewEncryptorWithRecipients(j.config.Path, newRecipients)
+	if err := newEncryptor.SetKeyFiles(j.config.KeyFiles); err != nil {
+		return fmt.Errorf("failed to load key files: %w", err)
+	}
+	newStorage := storage.NewStorageWithEncryptor(j.config.Path, newEncryptor)
+	if err := newStorage.SetFilenameTemplate(j.storage.FilenameTemplate()); err != nil {
+		return fmt.Errorf("failed to create storage for new recipients: %w", err)
+	}
+	// Rewritten entries follow the journal's current compress setting
+	newStorage.SetCompression(j.config.Compress)
+
+	// Original ciphertext of every file rewritten so far, keyed by absolute path
+	originals := make(map[string][]byte)
+	backup := func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to back up: %w", err)
+		}
+		originals[path] = data
+		return nil
 	}
 
-	// Define wrapper functions for transaction manager
+	// Trashed entries are listed by their path relative to the entries directory too, through
+	// trashPrefix, so they are re-encrypted and reported like the other entry files
+	trashPrefix := filepath.Join("..", storage.TrashDir) + string(filepath.Separator)
+
 	listEntriesFunc := func() ([]string, error) {
-		return j.storage.ListAllEntries()
+		files, err := j.storage.ListAllEntries()
+		if err != nil {
+			return nil, err
+		}
+		if opts.Entries != nil {
+			// Only files that still exist are re-encrypted, so deleted or non-entry paths are ignored
+			return slices.DeleteFunc(files, func(file string) bool {
+				return !slices.Contains(opts.Entries, file)
+			}), nil
+		}
+
+		trashed, err := j.storage.ListTrash()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list trash: %w", err)
+		}
+		for _, file := range trashed {
+			files = append(files, trashPrefix+file)
+		}
+		return files, nil
 	}
 
 	reEncryptEntryFunc := func(relFilePath string) error {
-		filename := filepath.Base(relFilePath)
-		id := filename[:len(filename)-len(".yaml")]
-
-		entry, err := j.storage.LoadEntry(id, relFilePath)
+		var (
+			entry     models.Entry
+			entryPath string
+			save      func(models.Entry) error
+			err       error
+		)
+		if trashRelPath, ok := strings.CutPrefix(relFilePath, trashPrefix); ok {
+			entry, err = j.storage.LoadTrashedEntry(trashRelPath)
+			entryPath = filepath.Join(j.storage.GetBasePath(), storage.TrashDir, trashRelPath)
+			save = func(upgraded models.Entry) error { return newStorage.SaveTrashedEntry(upgraded, trashRelPath) }
+		} else {
+			entry, err = j.storage.LoadEntry(j.storage.EntryIDFromPath(relFilePath), relFilePath)
+			entryPath = filepath.Join(j.storage.GetBasePath(), storage.EntriesDir, relFilePath)
+			save = func(upgraded models.Entry) error { return newStorage.SaveEntryAt(upgraded, relFilePath) }
+		}
 		if err != nil {
 			return fmt.Errorf("failed to load: %w", err)
 		}
 
-		if err := j.storage.SaveEntry(entry); err != nil {
+		// Rewriting the file anyway, so older entry versions are migrated to the current one
+		upgraded, err := models.Upgrade(entry)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade: %w", err)
+		}
+
+		if err := backup(entryPath); err != nil {
+			return err
+		}
+
+		if err := save(upgraded); err != nil {
 			return fmt.Errorf("failed to save: %w", err)
 		}
 
-		// Verify the entry can be decrypted
-		// Note: We need to create a new encryptor with updated recipients
-		encryptor, err := crypto.NewEncryptor(j.config.Path)
-		if err != nil {
-			return fmt.Errorf("failed to create encryptor for verification: %w", err)
+		if !opts.SkipVerify {
+			if err := newEncryptor.VerifyEncryptedFile(entryPath); err != nil {
+				return fmt.Errorf("verification failed: %w", err)
+			}
 		}
 
-		entryPath := filepath.Join(j.storage.GetBasePath(), storage.EntriesDir, relFilePath)
-		if err := encryptor.VerifyEncryptedFile(entryPath); err != nil {
-			return fmt.Errorf("verification failed: %w", err)
+		// Attachments are encrypted for the same recipients, so they are rewritten with their entry
+		for _, name := range upgraded.GetAttachments() {
+			data, err := j.storage.LoadAttachment(upgraded.GetID(), name)
+			if err != nil {
+				return fmt.Errorf("failed to load attachment %s: %w", name, err)
+			}
+
+			attachmentPath := j.storage.GetAttachmentPath(upgraded.GetID(), name)
+			if err := backup(attachmentPath); err != nil {
+				return err
+			}
+
+			if err := newStorage.SaveAttachment(upgraded.GetID(), name, data); err != nil {
+				return fmt.Errorf("failed to save attachment %s: %w", name, err)
+			}
+
+			if !opts.SkipVerify {
+				if err := newEncryptor.VerifyEncryptedFile(attachmentPath); err != nil {
+					return fmt.Errorf("verification failed for attachment %s: %w", name, err)
+				}
+			}
 		}
 
 		return nil
 	}
 
 	reEncryptIndexFunc := func() error {
-		if err := j.storage.SaveIndex(j.index); err != nil {
-			return fmt.Errorf("failed to save: %w", err)
+		if opts.SkipIndex {
+			return nil
 		}
 
-		// Verify index can be decrypted
-		encryptor, err := crypto.NewEncryptor(j.config.Path)
+		// Templates are encrypted for the same recipients, so they are rewritten along with the index
+		templates, err := j.storage.ListTemplates()
 		if err != nil {
-			return fmt.Errorf("failed to create encryptor for verification: %w", err)
+			return err
 		}
+		for _, name := range templates {
+			content, err := j.storage.LoadTemplate(name)
+			if err != nil {
+				return fmt.Errorf("failed to load template %s: %w", name, err)
+			}
+
+			templatePath := j.storage.GetTemplatePath(name)
+			if err := backup(templatePath); err != nil {
+				return err
+			}
 
+			if err := newStorage.SaveTemplate(name, content); err != nil {
+				return fmt.Errorf("failed to save template %s: %w", name, err)
+			}
+
+			if !opts.SkipVerify {
+				if err := newEncryptor.VerifyEncryptedFile(templatePath); err != nil {
+					return fmt.Errorf("verification failed for template %s: %w", name, err)
+				}
+			}
+		}
+
+		// The undo record holds a full copy of an entry, so it must not stay readable by removed recipients
+		var op lastOperation
+		if err := j.storage.LoadLastOperation(&op); err == nil {
+			lastOpPath := filepath.Join(j.storage.GetBasePath(), storage.LastOpFileName)
+			if err := backup(lastOpPath); err != nil {
+				return err
+			}
+
+			if err := newStorage.SaveLastOperation(&op); err != nil {
+				return fmt.Errorf("failed to save undo record: %w", err)
+			}
+
+			if !opts.SkipVerify {
+				if err := newEncryptor.VerifyEncryptedFile(lastOpPath); err != nil {
+					return fmt.Errorf("verification failed for undo record: %w", err)
+				}
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to load undo record: %w", err)
+		}
+
+		if j.storage.HasContentIndex() {
+			contentIndex, err := j.storage.LoadContentIndex()
+			if err != nil {
+				return err
+			}
+
+			contentIndexPath := filepath.Join(j.storage.GetBasePath(), storage.ContentIndexFileName)
+			if err := backup(contentIndexPath); err != nil {
+				return err
+			}
+
+			if err := newStorage.SaveContentIndex(contentIndex); err != nil {
+				return err
+			}
+
+			if !opts.SkipVerify {
+				if err := newEncryptor.VerifyEncryptedFile(contentIndexPath); err != nil {
+					return fmt.Errorf("verification failed for content index: %w", err)
+				}
+			}
+		}
+
+		if !j.UsesIndex() {
+			return nil
+		}
 		indexPath := filepath.Join(j.storage.GetBasePath(), storage.IndexFileName)
-		if err := encryptor.VerifyEncryptedFile(indexPath); err != nil {
-			return fmt.Errorf("verification failed: %w", err)
+		if err := backup(indexPath); err != nil {
+			return err
+		}
+
+		if err := newStorage.SaveIndex(j.index); err != nil {
+			return fmt.Errorf("failed to save: %w", err)
+		}
+
+		if !opts.SkipVerify {
+			if err := newEncryptor.VerifyEncryptedFile(indexPath); err != nil {
+				return fmt.Errorf("verification failed: %w", err)
+			}
 		}
 
 		return nil
@@ -341,114 +1274,196 @@ func (j *Journal) ReEncrypt() error {
 
 	result, err := crypto.TransactionalReEncrypt(
 		j.config.Path,
-		recipients,
+		newRecipients,
 		listEntriesFunc,
 		reEncryptEntryFunc,
 		reEncryptIndexFunc,
+		opts.Progress,
 	)
 
 	if err != nil {
+		if restoreErr := restoreFiles(originals); restoreErr != nil {
+			return fmt.Errorf("re-encryption failed: %w\nDetails: %s\nFailed to restore original files: %v",
+				err, result.FormatErrors(), restoreErr)
+		}
 		return fmt.Errorf("re-encryption failed: %w\nDetails: %s",
 			err, result.FormatErrors())
 	}
 
-	// Re-load encryptor with new recipients
-	newEncryptor, err := crypto.NewEncryptor(j.config.Path)
-	if err != nil {
-		return fmt.Errorf("failed to reload encryptor: %w", err)
+	j.storage = newStorage
+
+	return nil
+}
+
+// restoreFiles writes back the original contents of files changed during a failed re-encryption
+func restoreFiles(originals map[string][]byte) error {
+	var errs []error
+	for path, data := range originals {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	j.storage = storage.NewStorageWithEncryptor(j.config.Path, newEncryptor)
+// VerifyResult summarizes a decryption check over all journal files
+type VerifyResult struct {
+	TotalFiles    int
+	ReadableFiles int
+	FailedFiles   []crypto.FileError
+	// CorruptFiles lists entry files that decrypt but cannot be parsed or whose content does not match its hash
+	CorruptFiles []crypto.FileError
+	IndexError   error
+	// QuarantineError is set by VerifyJournal if the quarantine log could not be written; the check itself is complete
+	QuarantineError error
+}
 
-	return nil
+// OK reports whether every entry file and the index could be decrypted and every entry is intact
+func (r *VerifyResult) OK() bool {
+	return len(r.FailedFiles) == 0 && len(r.CorruptFiles) == 0 && r.IndexError == nil
 }
 
-// ReEncryptWithRecipients re-encrypts all entries and index with new recipients
-// Uses transactional approach with automatic rollback on failure
-// Updates .sops.yaml first, then re-encrypts all data with the new recipients
-// This is the method to use when programmatically adding/removing recipients
-func (j *Journal) ReEncryptWithRecipients(newRecipients []string) error {
-	// Define wrapper functions for transaction manager
-	listEntriesFunc := func() ([]string, error) {
-		return j.storage.ListAllEntries()
+// Verify decrypts every entry file and the index with the current keys, checking each entry's content hash
+// Failures are collected in the result rather than returned; no file is written, so it is safe for dry runs
+func (j *Journal) Verify() (*VerifyResult, error) {
+	return verifyFiles(j.config, j.storage)
+}
+
+// VerifyJournal decrypts every entry file and the index of the journal in cfg, recording failed
+// entry files in the quarantine log
+// Unlike Verify it does not open the journal first, so it also works when the index is unreadable
+func VerifyJournal(cfg *config.Journal) (*VerifyResult, error) {
+	store, err := storage.NewStorage(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	if err := store.SetFilenameTemplate(cfg.FilenameTemplate); err != nil {
+		return nil, fmt.Errorf("invalid journal config: %w", err)
 	}
 
-	reEncryptEntryFunc := func(relFilePath string) error {
-		filename := filepath.Base(relFilePath)
-		id := filename[:len(filename)-len(".yaml")]
+	result, err := verifyFiles(cfg, store)
+	if err == nil {
+		result.QuarantineError = recordQuarantine(store, slices.Concat(result.FailedFiles, result.CorruptFiles))
+	}
+	return result, err
+}
 
-		entry, err := j.storage.LoadEntry(id, relFilePath)
-		if err != nil {
-			return fmt.Errorf("failed to load: %w", err)
-		}
+// recordQuarantine replaces the quarantine log with the entry files that failed to load in a full scan
+// Failing to write it should not fail the scan, so callers only report the error
+func recordQuarantine(store *storage.Storage, failures []crypto.FileError) error {
+	files := make([]storage.QuarantinedFile, 0, len(failures))
+	for _, failure := range failures {
+		files = append(files, storage.QuarantinedFile{FilePath: failure.FilePath, Reason: failure.Error.Error()})
+	}
+	return store.WriteQuarantineLog(files)
+}
 
-		if err := j.storage.SaveEntry(entry); err != nil {
-			return fmt.Errorf("failed to save: %w", err)
-		}
+// QuarantinedFiles returns the entry files that failed to load in the last rebuild or verify
+func QuarantinedFiles(cfg *config.Journal) ([]storage.QuarantinedFile, error) {
+	store, err := storage.NewStorage(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	return store.ReadQuarantineLog()
+}
 
-		// Verify the entry can be decrypted
-		// Note: We need to create a new encryptor with updated recipients
-		encryptor, err := crypto.NewEncryptor(j.config.Path)
-		if err != nil {
-			return fmt.Errorf("failed to create encryptor for verification: %w", err)
-		}
+// DecryptEntryFile decrypts the entry file at relFilePath, relative to the entries directory of the
+// journal in cfg, and returns the raw YAML without parsing it, for debugging unreadable entries
+// Compressed entries are decompressed, so the YAML is the same whether or not compress is set
+// Like VerifyJournal it does not open the journal, so it works when the index is unreadable
+func DecryptEntryFile(cfg *config.Journal, relFilePath string) ([]byte, error) {
+	store, err := storage.NewStorage(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
 
-		entryPath := filepath.Join(j.storage.GetBasePath(), storage.EntriesDir, relFilePath)
-		if err := encryptor.VerifyEncryptedFile(entryPath); err != nil {
-			return fmt.Errorf("verification failed: %w", err)
-		}
+	fullPath, err := store.EntryFilePath(relFilePath)
+	if err != nil {
+		return nil, err
+	}
 
-		return nil
+	encryptor, err := crypto.NewEncryptor(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encryptor: %w", err)
+	}
+	if err := encryptor.SetKeyFiles(cfg.KeyFiles); err != nil {
+		return nil, fmt.Errorf("failed to load key files: %w", err)
 	}
 
-	reEncryptIndexFunc := func() error {
-		if err := j.storage.SaveIndex(j.index); err != nil {
-			return fmt.Errorf("failed to save: %w", err)
-		}
+	plaintext, err := encryptor.DecryptFile(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	return storage.DecompressEntry(plaintext)
+}
+
+// verifyFiles decrypts every entry file listed by store and the index, using cfg's keys
+func verifyFiles(cfg *config.Journal, store *storage.Storage) (*VerifyResult, error) {
+	files, err := store.ListAllEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
 
-		// Verify index can be decrypted
-		encryptor, err := crypto.NewEncryptor(j.config.Path)
+	encryptor, err := crypto.NewEncryptor(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encryptor for verification: %w", err)
+	}
+	if err := encryptor.SetKeyFiles(cfg.KeyFiles); err != nil {
+		return nil, fmt.Errorf("failed to load key files: %w", err)
+	}
+
+	result := &VerifyResult{TotalFiles: len(files)}
+	for _, relFilePath := range files {
+		entryPath := filepath.Join(store.GetBasePath(), storage.EntriesDir, relFilePath)
+		data, err := encryptor.DecryptFile(entryPath)
 		if err != nil {
-			return fmt.Errorf("failed to create encryptor for verification: %w", err)
+			result.FailedFiles = append(result.FailedFiles, crypto.FileError{
+				FilePath: relFilePath,
+				Error:    fmt.Errorf("verification failed: %w", err),
+			})
+			continue
 		}
 
-		indexPath := filepath.Join(j.storage.GetBasePath(), storage.IndexFileName)
-		if err := encryptor.VerifyEncryptedFile(indexPath); err != nil {
-			return fmt.Errorf("verification failed: %w", err)
+		entry, err := storage.ParseEntry(data)
+		if err == nil {
+			err = models.CheckContentHash(entry)
 		}
+		if err != nil {
+			result.CorruptFiles = append(result.CorruptFiles, crypto.FileError{
+				FilePath: relFilePath,
+				Error:    err,
+			})
+			continue
+		}
+		result.ReadableFiles++
+	}
 
-		return nil
+	indexPath := filepath.Join(store.GetBasePath(), storage.IndexFileName)
+	if err := encryptor.VerifyEncryptedFile(indexPath); err != nil {
+		result.IndexError = err
 	}
 
-	result, err := crypto.TransactionalReEncrypt(
-		j.config.Path,
-		newRecipients,
-		listEntriesFunc,
-		reEncryptEntryFunc,
-		reEncryptIndexFunc,
-	)
+	return result, nil
+}
 
-	if err != nil {
-		return fmt.Errorf("re-encryption failed: %w\nDetails: %s",
-			err, result.FormatErrors())
+// resolveID expands an ID or unique ID prefix to the full entry ID and returns its metadata
+// An ambiguous prefix is an error wrapping models.ErrAmbiguousPrefix that lists every match
+func (j *Journal) resolveID(prefix string) (string, models.Metadata, error) {
+	id, err := j.index.ResolvePrefix(prefix)
+	if errors.Is(err, models.ErrPrefixNotFound) {
+		return "", models.Metadata{}, fmt.Errorf("%w: %s", ErrEntryNotFound, prefix)
 	}
-
-	// Re-load encryptor with new recipients
-	newEncryptor, err := crypto.NewEncryptor(j.config.Path)
 	if err != nil {
-		return fmt.Errorf("failed to reload encryptor: %w", err)
+		return "", models.Metadata{}, err
 	}
 
-	// Update storage with new encryptor
-	j.storage = storage.NewStorageWithEncryptor(j.config.Path, newEncryptor)
-
-	return nil
+	meta, _ := j.index.GetMetadata(id)
+	return id, meta, nil
 }
 
 // Helper function to load multiple entries
 func (j *Journal) loadEntries(ids []string) ([]models.Entry, error) {
 	var entries []models.Entry
-	var loadErrors []error
 
 	for _, id := range ids {
 		meta, exists := j.index.GetMetadata(id)
@@ -456,25 +1471,18 @@ func (j *Journal) loadEntries(ids []string) ([]models.Entry, error) {
 			continue
 		}
 
+		// Entries that fail to load are passed to the warn func and left out
 		entry, err := j.storage.LoadEntry(id, meta.FilePath)
 		if err != nil {
-			loadErrors = append(loadErrors, fmt.Errorf("failed to load entry %s: %w", id, err))
+			j.warnf("failed to load entry %s: %w", id, err)
 			continue
 		}
 
 		entries = append(entries, entry)
 	}
 
-	// Log warnings for failed entries to stderr
-	if len(loadErrors) > 0 {
-		for _, err := range loadErrors {
-			if _, ferr := fmt.Fprintf(os.Stderr, "Warning: %v\n", err); ferr != nil {
-				return nil, ferr
-			}
-		}
-	}
-
-	sort.Slice(entries, func(i, j int) bool {
+	// Stable, so entries with the same date keep the order of ids
+	sort.SliceStable(entries, func(i, j int) bool {
 		return entries[i].GetDate().After(entries[j].GetDate())
 	})
 
@@ -483,6 +1491,9 @@ func (j *Journal) loadEntries(ids []string) ([]models.Entry, error) {
 
 // AddRecipient adds a new recipient to the journal's .sops.yaml
 func (j *Journal) AddRecipient(publicKey string) error {
+	if err := j.checkWritable(); err != nil {
+		return err
+	}
 	if err := crypto.AddRecipient(j.config.Path, publicKey); err != nil {
 		return fmt.Errorf("failed to add recipient: %w", err)
 	}
@@ -491,6 +1502,9 @@ func (j *Journal) AddRecipient(publicKey string) error {
 
 // RemoveRecipient removes a recipient from the journal's .sops.yaml
 func (j *Journal) RemoveRecipient(publicKey string) error {
+	if err := j.checkWritable(); err != nil {
+		return err
+	}
 	if err := crypto.RemoveRecipient(j.config.Path, publicKey); err != nil {
 		return fmt.Errorf("failed to remove recipient: %w", err)
 	}
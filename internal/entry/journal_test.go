@@ -1,18 +1,23 @@
 package entry
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"filippo.io/age"
 	"github.com/data-castle/journal/internal/config"
 	"github.com/data-castle/journal/internal/crypto"
+	"github.com/data-castle/journal/internal/storage"
 	"github.com/data-castle/journal/pkg/models"
 )
 
-func setupTestJournal(t *testing.T) (*Journal, *config.Journal) {
+func setupTestJournal(t testing.TB) (*Journal, *config.Journal) {
 	t.Helper()
 	tmpDir := t.TempDir()
 
@@ -129,313 +134,1954 @@ func TestJournalAdd(t *testing.T) {
 	}
 }
 
+func TestJournalAddWithDate(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	date := time.Date(2023, 3, 14, 9, 30, 0, 0, time.UTC)
+	entry, err := journal.AddWithDate("Backdated entry", []string{"old"}, date)
+	if err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+
+	if !entry.GetDate().Equal(date) {
+		t.Errorf("expected date %v, got %v", date, entry.GetDate())
+	}
+
+	expectedPath := filepath.Join("2023", "03", entry.GetID()+".yaml")
+	if entry.GetFilePath() != expectedPath {
+		t.Errorf("expected file path %s, got %s", expectedPath, entry.GetFilePath())
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, "entries", expectedPath)); err != nil {
+		t.Errorf("expected entry file in backdated directory: %v", err)
+	}
+
+	entries, err := journal.SearchByDate(date)
+	if err != nil {
+		t.Fatalf("SearchByDate failed: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry on backdated date, got %d", len(entries))
+	}
+}
+
+func TestJournalAddWithDate_ZeroDate(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, err := journal.AddWithDate("No date", []string{}, time.Time{}); err == nil {
+		t.Error("expected error for zero date")
+	}
+}
+
+func TestJournalAddWithOptions_Title(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	titled, err := journal.AddWithOptions("Body text", nil, AddOptions{Title: "My title"})
+	if err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+	if titled.GetTitle() != "My title" {
+		t.Errorf("expected title 'My title', got %q", titled.GetTitle())
+	}
+
+	untitled := mustAddEntry(t, journal, "\nFirst line\nSecond line", nil)
+	if untitled.GetTitle() != "First line" {
+		t.Errorf("expected title from first line, got %q", untitled.GetTitle())
+	}
+
+	meta, _ := journal.index.GetMetadata(titled.GetID())
+	if meta.Title != "My title" {
+		t.Errorf("expected title in index, got %q", meta.Title)
+	}
+}
+
+func TestJournalAddWithOptions_Mood(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	rated, err := journal.AddWithOptions("Great day", nil, AddOptions{Mood: 5})
+	if err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+	if rated.GetMood() != 5 {
+		t.Errorf("expected mood 5, got %d", rated.GetMood())
+	}
+	if meta, _ := journal.index.GetMetadata(rated.GetID()); meta.Mood != 5 {
+		t.Errorf("expected mood in index, got %d", meta.Mood)
+	}
+
+	mustAddEntry(t, journal, "Unrated day", nil)
+	if _, err := journal.AddWithOptions("Bad day", nil, AddOptions{Mood: 2}); err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+
+	if _, err := journal.AddWithOptions("Too good", nil, AddOptions{Mood: 6}); err == nil {
+		t.Error("expected error for a mood above the range")
+	}
+
+	entries, err := journal.SearchByMinMood(3)
+	if err != nil {
+		t.Fatalf("SearchByMinMood failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].GetID() != rated.GetID() {
+		t.Errorf("expected only the rated entry, got %d entries", len(entries))
+	}
+}
+
+func TestJournalAddWithOptions_Links(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	original := mustAddEntry(t, journal, "Started the migration", nil)
+	other := mustAddEntry(t, journal, "Unrelated", nil)
+
+	// Links may be prefixes and are stored as full IDs, without repeats
+	followUp, err := journal.AddWithOptions("Finished the migration", nil, AddOptions{Links: []string{original.GetID()[:8], original.GetID()}})
+	if err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+	if !slices.Equal(followUp.GetLinks(), []string{original.GetID()}) {
+		t.Errorf("expected links [%s], got %v", original.GetID(), followUp.GetLinks())
+	}
+
+	loaded, err := journal.Get(followUp.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !slices.Equal(loaded.GetLinks(), []string{original.GetID()}) {
+		t.Errorf("expected links to be saved, got %v", loaded.GetLinks())
+	}
+
+	backlinks, err := journal.Backlinks(original.GetID()[:8])
+	if err != nil {
+		t.Fatalf("Backlinks failed: %v", err)
+	}
+	if !slices.Equal(backlinks, []string{followUp.GetID()}) {
+		t.Errorf("expected backlinks [%s], got %v", followUp.GetID(), backlinks)
+	}
+	if backlinks, err := journal.Backlinks(other.GetID()); err != nil || len(backlinks) != 0 {
+		t.Errorf("expected no backlinks, got %v (%v)", backlinks, err)
+	}
+
+	// Backlinks survive a rebuild, since links are read back from the entry files
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	if backlinks, err := journal.Backlinks(original.GetID()); err != nil || len(backlinks) != 1 {
+		t.Errorf("expected backlinks after rebuild, got %v (%v)", backlinks, err)
+	}
+
+	if _, err := journal.AddWithOptions("Dangling", nil, AddOptions{Links: []string{"does-not-exist"}}); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound for an unknown link, got %v", err)
+	}
+	if _, err := journal.Backlinks("does-not-exist"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestJournalAddWithOptions_NonUTCMonthBoundary(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	// 23:30 on January 31 in New York is already February 1 in UTC
+	newYork := time.FixedZone("EST", -5*60*60)
+	date := time.Date(2024, 1, 31, 23, 30, 0, 0, newYork)
+	added, err := journal.AddWithOptions("Late night entry", nil, AddOptions{Date: date})
+	if err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+
+	if dir := filepath.Dir(added.GetFilePath()); dir != filepath.Join("2024", "01") {
+		t.Errorf("expected entry in the January directory, got %s", dir)
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	entries, err := reopened.SearchByDate(time.Date(2024, 1, 31, 0, 0, 0, 0, newYork))
+	if err != nil {
+		t.Fatalf("SearchByDate failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected the entry on January 31, got %d entries", len(entries))
+	}
+}
+
 func TestJournalGet(t *testing.T) {
 	journal, _ := setupTestJournal(t)
 
 	addedEntry := mustAddEntry(t, journal, "Test content", []string{"tag1"})
 	entryID := addedEntry.GetID()
 
-	retrievedEntry, err := journal.Get(entryID)
+	retrievedEntry, err := journal.Get(entryID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if retrievedEntry.GetID() != entryID {
+		t.Errorf("expected ID %s, got %s", entryID, retrievedEntry.GetID())
+	}
+
+	if retrievedEntry.GetContent() != "Test content" {
+		t.Errorf("expected content 'Test content', got '%s'", retrievedEntry.GetContent())
+	}
+}
+
+func TestJournalGet_NotFound(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	_, err := journal.Get("nonexistent-id")
+	if err == nil {
+		t.Fatal("expected error when getting nonexistent entry")
+	}
+
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got: %v", err)
+	}
+}
+
+func TestJournalGet_Prefix(t *testing.T) {
+	j, _ := setupTestJournal(t)
+
+	date := time.Date(2024, 11, 19, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"abcd1234-0000-4000-8000-000000000001", "abcd1234-0000-4000-8000-000000000002"} {
+		if _, err := j.addEntry(id, "Entry "+id, nil, AddOptions{Date: date}); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	if _, err := j.Get("abcd1234"); !errors.Is(err, models.ErrAmbiguousPrefix) {
+		t.Errorf("expected ErrAmbiguousPrefix, got %v", err)
+	}
+	if err := j.Delete("abcd1234"); !errors.Is(err, models.ErrAmbiguousPrefix) {
+		t.Errorf("expected Delete to refuse an ambiguous prefix, got %v", err)
+	}
+
+	got, err := j.Get("abcd1234-0000-4000-8000-000000000002")
+	if err != nil {
+		t.Fatalf("expected full ID to resolve: %v", err)
+	}
+	if got.GetID() != "abcd1234-0000-4000-8000-000000000002" {
+		t.Errorf("expected entry ...0002, got %s", got.GetID())
+	}
+
+	if _, err := j.Get("ffff"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestJournalGetByDate(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	date := time.Date(2024, 11, 19, 10, 0, 0, 0, time.Local)
+	if _, err := journal.GetByDate(date); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound for an empty date, got %v", err)
+	}
+
+	first, err := journal.AddWithDate("First", nil, date)
+	if err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+
+	got, err := journal.GetByDate(date)
+	if err != nil {
+		t.Fatalf("GetByDate failed: %v", err)
+	}
+	if got.GetID() != first.GetID() {
+		t.Errorf("expected entry %s, got %s", first.GetID(), got.GetID())
+	}
+
+	if _, err := journal.AddWithDate("Second", nil, date.Add(time.Hour)); err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+
+	if _, err := journal.GetByDate(date); !errors.Is(err, ErrAmbiguousDate) {
+		t.Errorf("expected ErrAmbiguousDate, got %v", err)
+	}
+}
+
+func TestJournalDeleteAndUpdate_NotFound(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if err := journal.Delete("nonexistent-id"); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound from Delete, got: %v", err)
+	}
+
+	if _, err := journal.Update("nonexistent-id", "content", nil); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound from Update, got: %v", err)
+	}
+}
+
+func TestJournalAddRecipient_Duplicate(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	recipients, err := journal.ListRecipients()
+	if err != nil {
+		t.Fatalf("ListRecipients failed: %v", err)
+	}
+
+	if err := journal.AddRecipient(recipients[0]); !errors.Is(err, crypto.ErrRecipientExists) {
+		t.Errorf("expected crypto.ErrRecipientExists, got: %v", err)
+	}
+}
+
+func TestJournalDelete(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry to delete", []string{})
+	entryID := entry.GetID()
+
+	err := journal.Delete(entryID)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if len(journal.index.Entries) != 0 {
+		t.Errorf("expected 0 entries in index, got %d", len(journal.index.Entries))
+	}
+
+	_, err = journal.Get(entryID)
+	if err == nil {
+		t.Fatal("expected error when getting deleted entry")
+	}
+}
+
+func TestJournalDelete_FileAlreadyRemoved(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry removed out-of-band", []string{"tag1"})
+	entryID := entry.GetID()
+
+	entryPath := filepath.Join(journalCfg.Path, "entries", entry.GetFilePath())
+	if err := os.Remove(entryPath); err != nil {
+		t.Fatalf("failed to remove entry file: %v", err)
+	}
+
+	if err := journal.Delete(entryID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, exists := journal.index.GetMetadata(entryID); exists {
+		t.Error("expected entry to be removed from index")
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	if _, exists := reopened.index.GetMetadata(entryID); exists {
+		t.Error("expected entry to be removed from persisted index")
+	}
+}
+
+func TestJournalUpdate(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Original content", []string{"tag1"})
+	entryID := entry.GetID()
+
+	updatedEntry, err := journal.Update(entryID, "Updated content", []string{"tag2", "tag3"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if updatedEntry.GetContent() != "Updated content" {
+		t.Errorf("expected content 'Updated content', got '%s'", updatedEntry.GetContent())
+	}
+
+	tags := updatedEntry.GetTags()
+	if len(tags) != 2 {
+		t.Errorf("expected 2 tags, got %d", len(tags))
+	}
+
+	retrievedEntry, err := journal.Get(entryID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if retrievedEntry.GetContent() != "Updated content" {
+		t.Errorf("expected persisted content 'Updated content', got '%s'", retrievedEntry.GetContent())
+	}
+
+	if retrievedEntry.GetContentHash() != models.HashContent("Updated content") {
+		t.Errorf("expected content hash of the updated content, got %q", retrievedEntry.GetContentHash())
+	}
+}
+
+func TestJournalAppend(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "First line\n", []string{"log"})
+
+	appended, err := journal.Append(entry.GetID(), "Second line")
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	if appended.GetContent() != "First line\nSecond line" {
+		t.Errorf("expected appended content, got %q", appended.GetContent())
+	}
+
+	if !appended.GetDate().Equal(entry.GetDate()) {
+		t.Errorf("expected date to be unchanged, got %v", appended.GetDate())
+	}
+
+	if len(appended.GetTags()) != 1 || appended.GetTags()[0] != "log" {
+		t.Errorf("expected tags to be unchanged, got %v", appended.GetTags())
+	}
+
+	persisted, err := journal.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if persisted.GetContent() != "First line\nSecond line" {
+		t.Errorf("expected persisted appended content, got %q", persisted.GetContent())
+	}
+}
+
+func TestJournalTouch(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Misdated entry", []string{"log"})
+	oldPath := filepath.Join(journalCfg.Path, storage.EntriesDir, entry.GetFilePath())
+
+	newDate := time.Date(2020, 3, 5, 9, 30, 0, 0, time.UTC)
+	touched, err := journal.Touch(entry.GetID(), newDate)
+	if err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	if !touched.GetDate().Equal(newDate) {
+		t.Errorf("expected date %v, got %v", newDate, touched.GetDate())
+	}
+	if !strings.HasPrefix(touched.GetFilePath(), filepath.Join("2020", "03")+string(filepath.Separator)) {
+		t.Errorf("expected file under 2020/03, got %s", touched.GetFilePath())
+	}
+	if touched.GetContent() != "Misdated entry" {
+		t.Errorf("expected content to be unchanged, got %q", touched.GetContent())
+	}
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("expected old entry file to be removed, got %v", err)
+	}
+
+	persisted, err := journal.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !persisted.GetDate().Equal(newDate) {
+		t.Errorf("expected persisted date %v, got %v", newDate, persisted.GetDate())
+	}
+
+	if ids := journal.index.FindByDate(newDate); !slices.Equal(ids, []string{entry.GetID()}) {
+		t.Errorf("expected entry in the new date bucket, got %v", ids)
+	}
+	if ids := journal.index.FindByDate(entry.GetDate()); len(ids) != 0 {
+		t.Errorf("expected entry to leave the old date bucket, got %v", ids)
+	}
+}
+
+func TestJournalTouch_SameDayKeepsFile(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry", []string{})
+
+	touched, err := journal.Touch(entry.GetID(), entry.GetDate().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	if touched.GetFilePath() != entry.GetFilePath() {
+		t.Errorf("expected file %s to be kept, got %s", entry.GetFilePath(), touched.GetFilePath())
+	}
+}
+
+func TestJournalMixedVersions(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	legacy := models.NewEntryV1("legacy-entry", time.Now().Add(-time.Hour), "Old entry", []string{"work"}, "")
+	legacy.FilePath = journal.storage.GetEntryPath(legacy.GetDate(), legacy.GetID())
+	if err := journal.storage.SaveEntry(legacy); err != nil {
+		t.Fatalf("failed to save legacy entry: %v", err)
+	}
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	added := mustAddEntry(t, journal, "New entry", []string{"work"})
+	if added.GetVersion() != models.CurrentVersion {
+		t.Errorf("expected new entry version %d, got %d", models.CurrentVersion, added.GetVersion())
+	}
+
+	entries, err := journal.SearchByTag("work")
+	if err != nil {
+		t.Fatalf("SearchByTag failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected both versions to load, got %d entries", len(entries))
+	}
+
+	updated, err := journal.Update("legacy-entry", "Edited entry", []string{"work"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.GetVersion() != 2 {
+		t.Errorf("expected updated entry to be upgraded to version 2, got %d", updated.GetVersion())
+	}
+	if updated.GetModified().IsZero() {
+		t.Error("expected Update to set the modification time")
+	}
+
+	reloaded, err := journal.Get("legacy-entry")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reloaded.GetModified().Equal(updated.GetModified()) {
+		t.Errorf("expected saved modification time %v, got %v", updated.GetModified(), reloaded.GetModified())
+	}
+	if !reloaded.GetDate().Equal(legacy.GetDate()) {
+		t.Errorf("expected creation date to be kept, got %v", reloaded.GetDate())
+	}
+}
+
+func TestJournalReEncrypt_UpgradesEntries(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	legacy := models.NewEntryV1("legacy-entry", time.Now(), "Old entry", nil, "")
+	legacy.FilePath = journal.storage.GetEntryPath(legacy.GetDate(), legacy.GetID())
+	if err := journal.storage.SaveEntry(legacy); err != nil {
+		t.Fatalf("failed to save legacy entry: %v", err)
+	}
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	if err := journal.ReEncrypt(); err != nil {
+		t.Fatalf("ReEncrypt failed: %v", err)
+	}
+
+	reloaded, err := journal.Get("legacy-entry")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if reloaded.GetVersion() != 2 {
+		t.Errorf("expected re-encrypted entry to be version 2, got %d", reloaded.GetVersion())
+	}
+	if reloaded.GetContent() != "Old entry" {
+		t.Errorf("expected content to be kept, got %q", reloaded.GetContent())
+	}
+}
+
+func TestJournalOnThisDay(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	older, err := journal.AddWithDate("2022 entry", nil, time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+	newer, err := journal.AddWithDate("2023 entry", nil, time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+	if _, err := journal.AddWithDate("Other day", nil, time.Date(2023, 5, 2, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+
+	entries, err := journal.OnThisDay(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("OnThisDay failed: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].GetID() != newer.GetID() || entries[1].GetID() != older.GetID() {
+		t.Errorf("expected the May 1 entries newest first, got %v", entries)
+	}
+}
+
+func TestJournalSearchByDate(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	today := time.Now()
+	yesterday := today.AddDate(0, 0, -1)
+
+	mustAddEntry(t, journal, "Entry today", []string{})
+
+	entries, err := journal.SearchByDate(today)
+	if err != nil {
+		t.Fatalf("SearchByDate failed: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry for today, got %d", len(entries))
+	}
+
+	entries, err = journal.SearchByDate(yesterday)
+	if err != nil {
+		t.Fatalf("SearchByDate failed: %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected 0 entries for yesterday, got %d", len(entries))
+	}
+}
+
+func TestJournalSearchByDateRange(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{})
+	mustAddEntry(t, journal, "Entry 2", []string{})
+
+	start := time.Now().AddDate(0, 0, -1)
+	end := time.Now().AddDate(0, 0, 1)
+
+	entries, err := journal.SearchByDateRange(start, end)
+	if err != nil {
+		t.Fatalf("SearchByDateRange failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries, got %d", len(entries))
+	}
+}
+
+func TestJournalSearchByTag(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{"work"})
+	mustAddEntry(t, journal, "Entry 2", []string{"personal"})
+	mustAddEntry(t, journal, "Entry 3", []string{"work", "important"})
+
+	entries, err := journal.SearchByTag("work")
+	if err != nil {
+		t.Fatalf("SearchByTag failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries with tag 'work', got %d", len(entries))
+	}
+}
+
+func TestJournalSearchByTags(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{"work", "important"})
+	mustAddEntry(t, journal, "Entry 2", []string{"work"})
+	mustAddEntry(t, journal, "Entry 3", []string{"important"})
+
+	entries, err := journal.SearchByTags([]string{"work", "important"})
+	if err != nil {
+		t.Fatalf("SearchByTags failed: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("expected 1 entry with both tags, got %d", len(entries))
+	}
+}
+
+func TestJournalSearchByAnyTag(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{"work", "important"})
+	mustAddEntry(t, journal, "Entry 2", []string{"work"})
+	mustAddEntry(t, journal, "Entry 3", []string{"important"})
+	mustAddEntry(t, journal, "Entry 4", []string{"personal"})
+
+	entries, err := journal.SearchByAnyTag([]string{"work", "important"})
+	if err != nil {
+		t.Fatalf("SearchByAnyTag failed: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries with either tag, got %d", len(entries))
+	}
+}
+
+func TestJournalSearchByTagPrefix(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "Entry 1", []string{"project/foo", "project/bar"})
+	time.Sleep(time.Millisecond) // Ensure different timestamps
+	second := mustAddEntry(t, journal, "Entry 2", []string{"project/bar/review"})
+	mustAddEntry(t, journal, "Entry 3", []string{"personal"})
+
+	entries, err := journal.SearchByTagPrefix("project/")
+	if err != nil {
+		t.Fatalf("SearchByTagPrefix failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries under project/, got %d", len(entries))
+	}
+	if entries[0].GetID() != second.GetID() || entries[1].GetID() != first.GetID() {
+		t.Error("expected entries newest first")
+	}
+}
+
+func TestJournalListRecent(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	for i := 1; i <= 5; i++ {
+		mustAddEntry(t, journal, "Entry", []string{})
+		time.Sleep(time.Millisecond) // Ensure different timestamps
+	}
+
+	entries, err := journal.ListRecent(3)
+	if err != nil {
+		t.Fatalf("ListRecent failed: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries, got %d", len(entries))
+	}
+
+	// Verify entries are sorted by date descending
+	for i := 0; i < len(entries)-1; i++ {
+		if entries[i].GetDate().Before(entries[i+1].GetDate()) {
+			t.Error("entries are not sorted by date descending")
+		}
+	}
+}
+
+func TestJournalListAll(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	for i := 1; i <= 3; i++ {
+		mustAddEntry(t, journal, "Entry", []string{})
+	}
+
+	metas := journal.ListAll()
+
+	if len(metas) != 3 {
+		t.Errorf("expected 3 metadata entries, got %d", len(metas))
+	}
+}
+
+func TestJournalListByTag(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	older := mustAddEntry(t, journal, "Older", []string{"work"})
+	mustAddEntry(t, journal, "Personal", []string{"personal"})
+	newer := mustAddEntry(t, journal, "Newer", []string{"work", "personal"})
+	if _, err := journal.Touch(older.GetID(), time.Now().Add(-24*time.Hour)); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	metas := journal.ListByTag("work")
+	if len(metas) != 2 {
+		t.Fatalf("expected 2 entries with tag 'work', got %d", len(metas))
+	}
+	if metas[0].Id != newer.GetID() || metas[1].Id != older.GetID() {
+		t.Errorf("expected newest first, got %s then %s", metas[0].Id, metas[1].Id)
+	}
+
+	if metas := journal.ListByTag("missing"); len(metas) != 0 {
+		t.Errorf("expected no entries for an unknown tag, got %d", len(metas))
+	}
+}
+
+func TestJournalCount(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if journal.Count() != 0 {
+		t.Errorf("expected 0 entries, got %d", journal.Count())
+	}
+
+	mustAddEntry(t, journal, "Entry 1", []string{"work"})
+	mustAddEntry(t, journal, "Entry 2", []string{"work", "personal"})
+	mustAddEntry(t, journal, "Entry 3", []string{})
+
+	if journal.Count() != 3 {
+		t.Errorf("expected 3 entries, got %d", journal.Count())
+	}
+
+	if count := journal.CountByTag("work"); count != 2 {
+		t.Errorf("expected 2 entries with tag 'work', got %d", count)
+	}
+
+	if count := journal.CountByTag("missing"); count != 0 {
+		t.Errorf("expected 0 entries with tag 'missing', got %d", count)
+	}
+
+	today := time.Now()
+	if count := journal.CountByDateRange(today, today); count != 3 {
+		t.Errorf("expected 3 entries today, got %d", count)
+	}
+}
+
+func TestJournalIndexSnapshot(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	target := mustAddEntry(t, journal, "Linked entry", nil)
+	e, err := journal.AddWithOptions("Entry", []string{"work"}, AddOptions{Links: []string{target.GetID()}})
+	if err != nil {
+		t.Fatalf("AddWithOptions failed: %v", err)
+	}
+
+	snapshot := journal.IndexSnapshot()
+
+	meta := snapshot.Entries[e.GetID()]
+	meta.Tags[0] = "mutated"
+	meta.Links[0] = "mutated"
+	snapshot.Entries[e.GetID()] = meta
+	snapshot.ByTag["work"][0] = "mutated"
+	delete(snapshot.Entries, e.GetID())
+	snapshot.ByTag["extra"] = []string{"ghost"}
+
+	internal, exists := journal.index.GetMetadata(e.GetID())
+	if !exists {
+		t.Fatal("expected entry to remain in internal index")
+	}
+
+	if internal.Tags[0] != "work" {
+		t.Errorf("expected internal tags to be unaffected, got %v", internal.Tags)
+	}
+
+	if internal.Links[0] != target.GetID() {
+		t.Errorf("expected internal links to be unaffected, got %v", internal.Links)
+	}
+
+	if ids := journal.index.FindByTag("work"); len(ids) != 1 || ids[0] != e.GetID() {
+		t.Errorf("expected internal tag bucket to be unaffected, got %v", ids)
+	}
+
+	if _, exists := journal.index.ByTag["extra"]; exists {
+		t.Error("expected tag added to snapshot not to appear in internal index")
+	}
+}
+
+func TestJournalIterate(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, content := range []string{"Second", "First", "Third"} {
+		offset := []int{1, 0, 2}[i]
+		if _, err := journal.AddWithDate(content, nil, base.AddDate(0, 0, offset)); err != nil {
+			t.Fatalf("failed to add entry: %v", err)
+		}
+	}
+
+	var visited []string
+	if err := journal.Iterate(func(entry models.Entry) error {
+		visited = append(visited, entry.GetContent())
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate failed: %v", err)
+	}
+	if strings.Join(visited, ",") != "First,Second,Third" {
+		t.Errorf("expected entries oldest first, got %v", visited)
+	}
+
+	errStop := errors.New("stop")
+	visited = nil
+	err := journal.Iterate(func(entry models.Entry) error {
+		visited = append(visited, entry.GetContent())
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected callback error to be returned, got %v", err)
+	}
+	if len(visited) != 1 {
+		t.Errorf("expected iteration to stop after the first entry, visited %v", visited)
+	}
+}
+
+func TestJournalSearchByContent(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	match := mustAddEntry(t, journal, "Walked the DOG in the park", nil)
+	mustAddEntry(t, journal, "Quiet day at home", nil)
+
+	results, err := journal.SearchByContent("dog")
+	if err != nil {
+		t.Fatalf("SearchByContent failed: %v", err)
+	}
+	if len(results) != 1 || results[0].GetID() != match.GetID() {
+		t.Errorf("expected only the matching entry, got %d results", len(results))
+	}
+}
+
+func TestJournalSearchByRegex(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	match := mustAddEntry(t, journal, "Ran 5km before work", nil)
+	mustAddEntry(t, journal, "Ran a few errands", nil)
+
+	results, err := journal.SearchByRegex(`\d+km`)
+	if err != nil {
+		t.Fatalf("SearchByRegex failed: %v", err)
+	}
+	if len(results) != 1 || results[0].GetID() != match.GetID() {
+		t.Errorf("expected only the matching entry, got %d results", len(results))
+	}
+
+	if _, err := journal.SearchByRegex("(unclosed"); err == nil || !strings.Contains(err.Error(), "invalid regular expression") {
+		t.Errorf("expected invalid regular expression error, got %v", err)
+	}
+}
+
+func TestJournalIndexDrift(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	kept := mustAddEntry(t, journal, "Entry 1", []string{"tag1"})
+	unindexed := mustAddEntry(t, journal, "Entry 2", []string{"tag2"})
+	fileless := mustAddEntry(t, journal, "Entry 3", nil)
+
+	added, removed, err := journal.IndexDrift()
+	if err != nil {
+		t.Fatalf("IndexDrift failed: %v", err)
+	}
+	if len(added) != 0 || len(removed) != 0 {
+		t.Fatalf("expected no drift, got added %v, removed %v", added, removed)
+	}
+
+	// Simulate an index from another machine that lacks one entry
+	journal.index.Remove(unindexed.GetID())
+
+	// Simulate an entry file that was removed by a merge while the index kept it
+	if err := os.Remove(filepath.Join(journalCfg.Path, "entries", fileless.GetFilePath())); err != nil {
+		t.Fatalf("failed to remove entry file: %v", err)
+	}
+
+	added, removed, err = journal.IndexDrift()
+	if err != nil {
+		t.Fatalf("IndexDrift failed: %v", err)
+	}
+	if len(added) != 1 || added[0] != unindexed.GetID() {
+		t.Errorf("expected added [%s], got %v", unindexed.GetID(), added)
+	}
+	if len(removed) != 1 || removed[0] != fileless.GetID() {
+		t.Errorf("expected removed [%s], got %v", fileless.GetID(), removed)
+	}
+
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	added, removed, err = journal.IndexDrift()
+	if err != nil {
+		t.Fatalf("IndexDrift failed: %v", err)
+	}
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("expected rebuild to fix drift, got added %v, removed %v", added, removed)
+	}
+	if _, err := journal.Get(kept.GetID()); err != nil {
+		t.Errorf("expected untouched entry to remain: %v", err)
+	}
+}
+
+func TestJournalRebuildIndex(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{"tag1"})
+	mustAddEntry(t, journal, "Entry 2", []string{"tag2"})
+
+	err := journal.RebuildIndex()
+	if err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	if len(journal.index.Entries) != 2 {
+		t.Errorf("expected 2 entries in rebuilt index, got %d", len(journal.index.Entries))
+	}
+
+	journal2, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to create new journal: %v", err)
+	}
+
+	if len(journal2.index.Entries) != 2 {
+		t.Errorf("expected 2 entries in reloaded index, got %d", len(journal2.index.Entries))
+	}
+}
+
+func TestJournalRebuildIndex_RepairsFilePaths(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	moved := mustAddEntry(t, journal, "Entry moved by hand", []string{})
+	stale := mustAddEntry(t, journal, "Entry with a stale index path", []string{})
+
+	// Move one file to another directory, so the path recorded inside it no longer matches
+	entriesDir := filepath.Join(journalCfg.Path, storage.EntriesDir)
+	newRelPath := filepath.Join("archive", filepath.Base(moved.GetFilePath()))
+	if err := os.MkdirAll(filepath.Join(entriesDir, "archive"), 0700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.Rename(filepath.Join(entriesDir, moved.GetFilePath()), filepath.Join(entriesDir, newRelPath)); err != nil {
+		t.Fatalf("failed to move entry: %v", err)
+	}
+
+	// Point the other entry's index path outside the entries directory
+	meta := journal.index.Entries[stale.GetID()]
+	meta.FilePath = filepath.Join(entriesDir, stale.GetFilePath())
+	journal.index.Entries[stale.GetID()] = meta
+	if err := journal.storage.SaveIndex(journal.index); err != nil {
+		t.Fatalf("failed to save index: %v", err)
+	}
+
+	result, err := journal.RebuildIndexWithOptions(RebuildOptions{})
+	if err != nil {
+		t.Fatalf("RebuildIndexWithOptions failed: %v", err)
+	}
+	if result.Repaired != 1 {
+		t.Errorf("expected 1 repaired entry, got %d", result.Repaired)
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	if errs := reopened.index.Validate(); len(errs) > 0 {
+		t.Errorf("expected a consistent index after rebuild, got %v", errs)
+	}
+	for id, want := range map[string]string{moved.GetID(): newRelPath, stale.GetID(): stale.GetFilePath()} {
+		if got := reopened.index.Entries[id].FilePath; got != want {
+			t.Errorf("expected index path %q for %s, got %q", want, id, got)
+		}
+		ent, err := reopened.Get(id)
+		if err != nil {
+			t.Fatalf("failed to load entry %s after rebuild: %v", id, err)
+		}
+		if ent.GetFilePath() != want {
+			t.Errorf("expected recorded path %q in entry %s, got %q", want, id, ent.GetFilePath())
+		}
+	}
+
+	// The corrected path was saved in the file, so a second rebuild has nothing to repair
+	result, err = reopened.RebuildIndexWithOptions(RebuildOptions{})
+	if err != nil {
+		t.Fatalf("RebuildIndexWithOptions failed: %v", err)
+	}
+	if result.Repaired != 0 {
+		t.Errorf("expected nothing to repair on the second rebuild, got %d", result.Repaired)
+	}
+}
+
+func TestJournalRebuildIndexWithOptions_SkipsUnreadable(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	broken := mustAddEntry(t, journal, "Entry to corrupt", []string{})
+	mustAddEntry(t, journal, "Healthy entry", []string{})
+
+	brokenPath := filepath.Join(journalCfg.Path, "entries", broken.GetFilePath())
+	if err := os.WriteFile(brokenPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	var progressCalls int
+	result, err := journal.RebuildIndexWithOptions(RebuildOptions{
+		Progress: func(done, total int, filePath string) { progressCalls++ },
+	})
+	if err != nil {
+		t.Fatalf("RebuildIndexWithOptions failed: %v", err)
+	}
+
+	if result.TotalFiles != 2 || result.Indexed != 1 {
+		t.Errorf("expected 1 of 2 entries indexed, got %d of %d", result.Indexed, result.TotalFiles)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].FilePath != broken.GetFilePath() {
+		t.Fatalf("expected %s to be skipped, got %v", broken.GetFilePath(), result.Skipped)
+	}
+	if result.Skipped[0].Error == nil {
+		t.Error("expected a reason for the skipped file")
+	}
+	if progressCalls != 2 {
+		t.Errorf("expected 2 progress calls, got %d", progressCalls)
+	}
+	if _, ok := journal.index.Entries[broken.GetID()]; ok {
+		t.Error("expected the unreadable entry to be left out of the index")
+	}
+}
+
+func TestJournalRebuildIndex_BackfillsContentHashes(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	legacy := models.NewEntryV1("legacy-entry", time.Now().Add(-time.Hour), "Old entry", nil, "")
+	legacy.FilePath = journal.storage.GetEntryPath(legacy.GetDate(), legacy.GetID())
+	if err := journal.storage.SaveEntry(legacy); err != nil {
+		t.Fatalf("failed to save legacy entry: %v", err)
+	}
+	mustAddEntry(t, journal, "New entry", nil)
+
+	result, err := journal.RebuildIndexWithOptions(RebuildOptions{})
+	if err != nil {
+		t.Fatalf("RebuildIndexWithOptions failed: %v", err)
+	}
+	if result.Backfilled != 1 {
+		t.Errorf("expected 1 backfilled entry, got %d", result.Backfilled)
+	}
+
+	loaded, err := journal.Get(legacy.GetID())
+	if err != nil {
+		t.Fatalf("failed to get legacy entry: %v", err)
+	}
+	if loaded.GetContentHash() != models.HashContent("Old entry") {
+		t.Errorf("expected backfilled content hash, got %q", loaded.GetContentHash())
+	}
+	if loaded.GetVersion() != 1 {
+		t.Errorf("expected backfilling to keep version 1, got %d", loaded.GetVersion())
+	}
+}
+
+func TestJournalRebuildIndex_BackfillsTitles(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	// An entry saved before titles existed has no title field
+	legacy := models.NewEntryV1("legacy-entry", time.Now(), "Old entry\nwith more text", nil, "")
+	legacy.FilePath = journal.storage.GetEntryPath(legacy.GetDate(), legacy.GetID())
+	if err := journal.storage.SaveEntry(legacy); err != nil {
+		t.Fatalf("failed to save legacy entry: %v", err)
+	}
+
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	meta, exists := journal.index.GetMetadata("legacy-entry")
+	if !exists {
+		t.Fatal("expected legacy entry in rebuilt index")
+	}
+	if meta.Title != "Old entry" {
+		t.Errorf("expected backfilled title 'Old entry', got %q", meta.Title)
+	}
+}
+
+func TestJournalRebuildIndex_FilenameTemplate(t *testing.T) {
+	_, journalCfg := setupTestJournal(t)
+
+	journalCfg.FilenameTemplate = "{date}-{id}"
+	journal, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to create journal: %v", err)
+	}
+
+	entry := mustAddEntry(t, journal, "Entry 1", []string{"tag1"})
+
+	expectedName := entry.GetDate().Format("2006-01-02") + "-" + entry.GetID() + ".yaml"
+	if filepath.Base(entry.GetFilePath()) != expectedName {
+		t.Errorf("expected file name %s, got %s", expectedName, filepath.Base(entry.GetFilePath()))
+	}
+
+	if err := journal.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+
+	if _, err := journal.Get(entry.GetID()); err != nil {
+		t.Errorf("expected entry to be found after rebuild: %v", err)
+	}
+}
+
+func TestJournalAddRecipient(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	publicKey2 := identity2.Recipient().String()
+
+	err = journal.AddRecipient(publicKey2)
+	if err != nil {
+		t.Fatalf("AddRecipient failed: %v", err)
+	}
+
+	recipients, err := crypto.ReadSOPSConfig(journal.config.Path)
+	if err != nil {
+		t.Fatalf("ReadSOPSConfig failed: %v", err)
+	}
+
+	if len(recipients) != 2 {
+		t.Errorf("expected 2 recipients, got %d", len(recipients))
+	}
+}
+
+func TestJournalRemoveRecipient(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	publicKey2 := identity2.Recipient().String()
+
+	err = journal.AddRecipient(publicKey2)
+	if err != nil {
+		t.Fatalf("AddRecipient failed: %v", err)
+	}
+
+	err = journal.RemoveRecipient(publicKey2)
+	if err != nil {
+		t.Fatalf("RemoveRecipient failed: %v", err)
+	}
+
+	recipients, err := crypto.ReadSOPSConfig(journalCfg.Path)
+	if err != nil {
+		t.Fatalf("ReadSOPSConfig failed: %v", err)
+	}
+
+	if len(recipients) != 1 {
+		t.Errorf("expected 1 recipient, got %d", len(recipients))
+	}
+}
+
+func TestJournalListRecipients(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	recipients, err := journal.ListRecipients()
+	if err != nil {
+		t.Fatalf("ListRecipients failed: %v", err)
+	}
+
+	if len(recipients) != 1 {
+		t.Errorf("expected 1 recipient, got %d", len(recipients))
+	}
+}
+
+func TestJournalReEncrypt(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry1 := mustAddEntry(t, journal, "Entry 1", []string{})
+	mustAddEntry(t, journal, "Entry 2", []string{})
+
+	err := journal.ReEncrypt()
+	if err != nil {
+		t.Fatalf("ReEncrypt failed: %v", err)
+	}
+
+	retrievedEntry, err := journal.Get(entry1.GetID())
+	if err != nil {
+		t.Fatalf("Get failed after re-encrypt: %v", err)
+	}
+
+	if retrievedEntry.GetContent() != "Entry 1" {
+		t.Errorf("expected content 'Entry 1', got '%s'", retrievedEntry.GetContent())
+	}
+}
+
+func TestJournalReEncryptWithOptions_Progress(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{})
+	mustAddEntry(t, journal, "Entry 2", []string{})
+
+	calls := 0
+	opts := ReEncryptOptions{
+		Progress: func(done, total int, filePath string) {
+			calls++
+			if total != 2 {
+				t.Errorf("expected total 2, got %d", total)
+			}
+		},
+	}
+
+	if err := journal.ReEncryptWithOptions(opts); err != nil {
+		t.Fatalf("ReEncryptWithOptions failed: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 progress calls, got %d", calls)
+	}
+}
+
+func TestJournalReEncryptWithOptions_Entries(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	untouched := mustAddEntry(t, journal, "Entry 1", []string{})
+	target := mustAddEntry(t, journal, "Entry 2", []string{})
+
+	untouchedMeta, _ := journal.index.GetMetadata(untouched.GetID())
+	targetMeta, _ := journal.index.GetMetadata(target.GetID())
+	untouchedPath := filepath.Join(journalCfg.Path, storage.EntriesDir, untouchedMeta.FilePath)
+	before, err := os.ReadFile(untouchedPath)
+	if err != nil {
+		t.Fatalf("failed to read entry file: %v", err)
+	}
+
+	var processed []string
+	opts := ReEncryptOptions{
+		Entries: []string{targetMeta.FilePath, "2000/01/deleted.yaml"},
+		Progress: func(done, total int, filePath string) {
+			processed = append(processed, filePath)
+		},
+	}
+	if err := journal.ReEncryptWithOptions(opts); err != nil {
+		t.Fatalf("ReEncryptWithOptions failed: %v", err)
+	}
+
+	if !slices.Equal(processed, []string{targetMeta.FilePath}) {
+		t.Errorf("expected only %s to be re-encrypted, got %v", targetMeta.FilePath, processed)
+	}
+
+	after, err := os.ReadFile(untouchedPath)
+	if err != nil {
+		t.Fatalf("failed to read entry file: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("expected entry outside Entries to be left as is")
+	}
+
+	if got, err := journal.Get(target.GetID()); err != nil || got.GetContent() != "Entry 2" {
+		t.Errorf("expected re-encrypted entry to be readable (err: %v)", err)
+	}
+}
+
+func TestJournalReEncryptScopes(t *testing.T) {
+	tests := []struct {
+		name         string
+		reEncrypt    func(j *Journal) error
+		entryChanged bool
+		indexChanged bool
+	}{
+		{"index only", (*Journal).ReEncryptIndex, false, true},
+		{"entries only", (*Journal).ReEncryptEntries, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journal, journalCfg := setupTestJournal(t)
+
+			entry := mustAddEntry(t, journal, "Entry", []string{})
+			entryPath := filepath.Join(journalCfg.Path, storage.EntriesDir, entry.GetFilePath())
+			indexPath := filepath.Join(journalCfg.Path, storage.IndexFileName)
+
+			read := func(path string) string {
+				t.Helper()
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("failed to read %s: %v", path, err)
+				}
+				return string(data)
+			}
+			entryBefore, indexBefore := read(entryPath), read(indexPath)
+
+			if err := tt.reEncrypt(journal); err != nil {
+				t.Fatalf("re-encrypt failed: %v", err)
+			}
+
+			// SOPS encrypts with a fresh data key every time, so a rewritten file always differs
+			if changed := read(entryPath) != entryBefore; changed != tt.entryChanged {
+				t.Errorf("expected entry rewritten: %v, got %v", tt.entryChanged, changed)
+			}
+			if changed := read(indexPath) != indexBefore; changed != tt.indexChanged {
+				t.Errorf("expected index rewritten: %v, got %v", tt.indexChanged, changed)
+			}
+
+			if got, err := journal.Get(entry.GetID()); err != nil || got.GetContent() != "Entry" {
+				t.Errorf("expected entry to stay readable (err: %v)", err)
+			}
+			if backups, _ := filepath.Glob(filepath.Join(journalCfg.Path, ".sops.yaml.backup.*")); len(backups) != 0 {
+				t.Errorf("expected .sops.yaml backup to be removed, got %v", backups)
+			}
+		})
+	}
+}
+
+func TestJournalReEncryptWithRecipients(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Shared entry", []string{})
+
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	recipients, err := journal.ListRecipients()
+	if err != nil {
+		t.Fatalf("ListRecipients failed: %v", err)
+	}
+
+	if err := journal.ReEncryptWithRecipients(append(recipients, identity2.Recipient().String())); err != nil {
+		t.Fatalf("ReEncryptWithRecipients failed: %v", err)
+	}
+
+	// The new recipient alone must be able to read existing entries
+	keyPath := filepath.Join(t.TempDir(), "key2.txt")
+	if err := os.WriteFile(keyPath, []byte(identity2.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("new recipient failed to open journal: %v", err)
+	}
+
+	retrieved, err := reopened.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("new recipient failed to read entry: %v", err)
+	}
+
+	if retrieved.GetContent() != "Shared entry" {
+		t.Errorf("expected content 'Shared entry', got '%s'", retrieved.GetContent())
+	}
+}
+
+func TestJournalRebuildIndex_QuarantinesCorruptedEntry(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	broken := mustAddEntry(t, journal, "Entry to corrupt", []string{})
+	mustAddEntry(t, journal, "Healthy entry", []string{})
+
+	brokenPath := filepath.Join(journalCfg.Path, "entries", broken.GetFilePath())
+	if err := os.WriteFile(brokenPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
+
+	result, err := journal.RebuildIndexWithOptions(RebuildOptions{})
+	if err != nil {
+		t.Fatalf("RebuildIndexWithOptions failed: %v", err)
+	}
+	if len(result.Skipped) != 1 {
+		t.Fatalf("expected 1 skipped file, got %d", len(result.Skipped))
+	}
+
+	quarantined, err := QuarantinedFiles(journalCfg)
+	if err != nil {
+		t.Fatalf("QuarantinedFiles failed: %v", err)
+	}
+	if len(quarantined) != 1 || quarantined[0].FilePath != broken.GetFilePath() || quarantined[0].Reason == "" {
+		t.Fatalf("expected %s to be quarantined with a reason, got %v", broken.GetFilePath(), quarantined)
+	}
+
+	// Once the file is fixed or removed, the next full scan clears it from the log
+	if err := os.Remove(brokenPath); err != nil {
+		t.Fatalf("failed to remove entry: %v", err)
+	}
+	if _, err := VerifyJournal(journalCfg); err != nil {
+		t.Fatalf("VerifyJournal failed: %v", err)
+	}
+
+	quarantined, err = QuarantinedFiles(journalCfg)
 	if err != nil {
-		t.Fatalf("Get failed: %v", err)
+		t.Fatalf("QuarantinedFiles failed: %v", err)
+	}
+	if len(quarantined) != 0 {
+		t.Errorf("expected the quarantine to be cleared, got %v", quarantined)
 	}
+}
 
-	if retrievedEntry.GetID() != entryID {
-		t.Errorf("expected ID %s, got %s", entryID, retrievedEntry.GetID())
+func TestJournalReEncryptWithRecipients_FailureRollback(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	broken := mustAddEntry(t, journal, "Entry to corrupt", []string{})
+	healthy := mustAddEntry(t, journal, "Healthy entry", []string{})
+
+	originalRecipients, err := journal.ListRecipients()
+	if err != nil {
+		t.Fatalf("ListRecipients failed: %v", err)
 	}
 
-	if retrievedEntry.GetContent() != "Test content" {
-		t.Errorf("expected content 'Test content', got '%s'", retrievedEntry.GetContent())
+	brokenPath := filepath.Join(journalCfg.Path, "entries", broken.GetFilePath())
+	if err := os.WriteFile(brokenPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
 	}
-}
 
-func TestJournalGet_NotFound(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
 
-	_, err := journal.Get("nonexistent-id")
+	err = journal.ReEncryptWithRecipients([]string{identity2.Recipient().String()})
 	if err == nil {
-		t.Fatal("expected error when getting nonexistent entry")
+		t.Fatal("expected re-encryption to fail")
 	}
-}
-
-func TestJournalDelete(t *testing.T) {
-	journal, _ := setupTestJournal(t)
 
-	entry := mustAddEntry(t, journal, "Entry to delete", []string{})
-	entryID := entry.GetID()
+	if !strings.Contains(err.Error(), broken.GetFilePath()) {
+		t.Errorf("expected error details to name the failed file, got: %v", err)
+	}
 
-	err := journal.Delete(entryID)
+	recipients, err := crypto.ReadSOPSConfig(journalCfg.Path)
 	if err != nil {
-		t.Fatalf("Delete failed: %v", err)
+		t.Fatalf("ReadSOPSConfig failed: %v", err)
+	}
+	if len(recipients) != 1 || recipients[0] != originalRecipients[0] {
+		t.Errorf("expected original recipients to be restored, got %v", recipients)
 	}
 
-	if len(journal.index.Entries) != 0 {
-		t.Errorf("expected 0 entries in index, got %d", len(journal.index.Entries))
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("original recipient failed to open journal: %v", err)
 	}
 
-	_, err = journal.Get(entryID)
-	if err == nil {
-		t.Fatal("expected error when getting deleted entry")
+	if _, err := reopened.Get(healthy.GetID()); err != nil {
+		t.Errorf("expected healthy entry to stay readable by the original recipient: %v", err)
 	}
 }
 
-func TestJournalUpdate(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+func TestJournalKeyFiles(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
 
-	entry := mustAddEntry(t, journal, "Original content", []string{"tag1"})
-	entryID := entry.GetID()
+	entry := mustAddEntry(t, journal, "Entry", []string{})
 
-	updatedEntry, err := journal.Update(entryID, "Updated content", []string{"tag2", "tag3"})
+	// The key written by setupTestJournal is the journal's recipient; pair it with an unrelated one
+	recipientKey := os.Getenv("SOPS_AGE_KEY_FILE")
+	other, err := age.GenerateX25519Identity()
 	if err != nil {
-		t.Fatalf("Update failed: %v", err)
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	otherKey := filepath.Join(t.TempDir(), "other.txt")
+	if err := os.WriteFile(otherKey, []byte(other.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
 	}
 
-	if updatedEntry.GetContent() != "Updated content" {
-		t.Errorf("expected content 'Updated content', got '%s'", updatedEntry.GetContent())
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	journalCfg.KeyFiles = []string{otherKey, recipientKey}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal with key files: %v", err)
 	}
 
-	tags := updatedEntry.GetTags()
-	if len(tags) != 2 {
-		t.Errorf("expected 2 tags, got %d", len(tags))
+	if _, err := reopened.Get(entry.GetID()); err != nil {
+		t.Errorf("expected entry to decrypt with the second key file: %v", err)
 	}
+}
 
-	retrievedEntry, err := journal.Get(entryID)
+func TestJournalVerify(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry 1", []string{})
+	mustAddEntry(t, journal, "Entry 2", []string{})
+
+	result, err := journal.Verify()
 	if err != nil {
-		t.Fatalf("Get failed: %v", err)
+		t.Fatalf("Verify failed: %v", err)
 	}
 
-	if retrievedEntry.GetContent() != "Updated content" {
-		t.Errorf("expected persisted content 'Updated content', got '%s'", retrievedEntry.GetContent())
+	if !result.OK() {
+		t.Errorf("expected all files to verify, got %d failures (index error: %v)", len(result.FailedFiles), result.IndexError)
+	}
+
+	if result.TotalFiles != 2 || result.ReadableFiles != 2 {
+		t.Errorf("expected 2 of 2 readable files, got %d of %d", result.ReadableFiles, result.TotalFiles)
 	}
 }
 
-func TestJournalSearchByDate(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+func TestJournalVerify_CorruptedEntry(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
 
-	today := time.Now()
-	yesterday := today.AddDate(0, 0, -1)
+	entry := mustAddEntry(t, journal, "Entry to corrupt", []string{})
+	mustAddEntry(t, journal, "Healthy entry", []string{})
 
-	mustAddEntry(t, journal, "Entry today", []string{})
+	entryPath := filepath.Join(journalCfg.Path, "entries", entry.GetFilePath())
+	if err := os.WriteFile(entryPath, []byte("not: encrypted\n"), 0600); err != nil {
+		t.Fatalf("failed to corrupt entry: %v", err)
+	}
 
-	entries, err := journal.SearchByDate(today)
+	result, err := journal.Verify()
 	if err != nil {
-		t.Fatalf("SearchByDate failed: %v", err)
+		t.Fatalf("Verify failed: %v", err)
 	}
 
-	if len(entries) != 1 {
-		t.Errorf("expected 1 entry for today, got %d", len(entries))
+	if result.OK() {
+		t.Fatal("expected verification to report the corrupted entry")
 	}
 
-	entries, err = journal.SearchByDate(yesterday)
-	if err != nil {
-		t.Fatalf("SearchByDate failed: %v", err)
+	if len(result.FailedFiles) != 1 || result.FailedFiles[0].FilePath != entry.GetFilePath() {
+		t.Errorf("expected %s to be reported as failed, got %v", entry.GetFilePath(), result.FailedFiles)
 	}
 
-	if len(entries) != 0 {
-		t.Errorf("expected 0 entries for yesterday, got %d", len(entries))
+	if result.ReadableFiles != 1 {
+		t.Errorf("expected 1 readable file, got %d", result.ReadableFiles)
 	}
 }
 
-func TestJournalSearchByDateRange(t *testing.T) {
+func TestJournalVerify_ContentHashMismatch(t *testing.T) {
 	journal, _ := setupTestJournal(t)
 
-	mustAddEntry(t, journal, "Entry 1", []string{})
-	mustAddEntry(t, journal, "Entry 2", []string{})
+	entry := mustAddEntry(t, journal, "Original content", []string{})
+	if entry.GetContentHash() != models.HashContent("Original content") {
+		t.Fatalf("expected Add to record the content hash, got %q", entry.GetContentHash())
+	}
 
-	start := time.Now().AddDate(0, 0, -1)
-	end := time.Now().AddDate(0, 0, 1)
+	// Re-encrypt the entry with changed content but its old hash, as a bad write before encryption would
+	tampered, err := models.Upgrade(entry)
+	if err != nil {
+		t.Fatalf("Upgrade failed: %v", err)
+	}
+	tampered.Content = "Tampered content"
+	if err := journal.storage.SaveEntry(tampered); err != nil {
+		t.Fatalf("failed to save tampered entry: %v", err)
+	}
 
-	entries, err := journal.SearchByDateRange(start, end)
+	result, err := journal.Verify()
 	if err != nil {
-		t.Fatalf("SearchByDateRange failed: %v", err)
+		t.Fatalf("Verify failed: %v", err)
 	}
 
-	if len(entries) != 2 {
-		t.Errorf("expected 2 entries, got %d", len(entries))
+	if result.OK() {
+		t.Fatal("expected verification to report the content hash mismatch")
+	}
+	if len(result.FailedFiles) != 0 {
+		t.Errorf("expected the tampered entry to still decrypt, got %v", result.FailedFiles)
+	}
+	if len(result.CorruptFiles) != 1 || !errors.Is(result.CorruptFiles[0].Error, models.ErrContentHashMismatch) {
+		t.Errorf("expected one content hash mismatch, got %v", result.CorruptFiles)
 	}
 }
 
-func TestJournalSearchByTag(t *testing.T) {
+// futureEntry is an entry as a newer version of journal might write it, with a field this version lacks
+type futureEntry struct {
+	models.EntryV2 `yaml:",inline"`
+	Location       string `yaml:"location"`
+}
+
+func TestJournalUpdate_RefusesUnknownFields(t *testing.T) {
 	journal, _ := setupTestJournal(t)
 
-	mustAddEntry(t, journal, "Entry 1", []string{"work"})
-	mustAddEntry(t, journal, "Entry 2", []string{"personal"})
-	mustAddEntry(t, journal, "Entry 3", []string{"work", "important"})
+	entry := mustAddEntry(t, journal, "Written by a newer version", []string{})
+	upgraded, err := models.Upgrade(entry)
+	if err != nil {
+		t.Fatalf("Upgrade failed: %v", err)
+	}
+	if err := journal.storage.SaveEntry(&futureEntry{EntryV2: *upgraded, Location: "Berlin"}); err != nil {
+		t.Fatalf("failed to save entry: %v", err)
+	}
 
-	entries, err := journal.SearchByTag("work")
+	entryPath := filepath.Join(journal.storage.GetBasePath(), storage.EntriesDir, entry.GetFilePath())
+	before, err := os.ReadFile(entryPath)
 	if err != nil {
-		t.Fatalf("SearchByTag failed: %v", err)
+		t.Fatalf("failed to read entry file: %v", err)
 	}
 
-	if len(entries) != 2 {
-		t.Errorf("expected 2 entries with tag 'work', got %d", len(entries))
+	if _, err := journal.Update(entry.GetID(), "Rewritten", nil); !errors.Is(err, models.ErrUnknownFields) {
+		t.Errorf("expected ErrUnknownFields, got %v", err)
+	}
+	if err := journal.ReEncrypt(); err == nil || !strings.Contains(err.Error(), models.ErrUnknownFields.Error()) {
+		t.Errorf("expected re-encryption to fail on the entry, got %v", err)
+	}
+
+	after, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("failed to read entry file: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("expected the entry file to be left untouched")
 	}
 }
 
-func TestJournalSearchByTags(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+func TestVerifyJournal_CorruptedIndex(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
 
-	mustAddEntry(t, journal, "Entry 1", []string{"work", "important"})
-	mustAddEntry(t, journal, "Entry 2", []string{"work"})
-	mustAddEntry(t, journal, "Entry 3", []string{"important"})
+	mustAddEntry(t, journal, "Entry 1", []string{})
 
-	entries, err := journal.SearchByTags([]string{"work", "important"})
+	indexPath := filepath.Join(journalCfg.Path, "index.yaml")
+	if err := os.WriteFile(indexPath, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("failed to corrupt index: %v", err)
+	}
+
+	result, err := VerifyJournal(journalCfg)
 	if err != nil {
-		t.Fatalf("SearchByTags failed: %v", err)
+		t.Fatalf("VerifyJournal failed: %v", err)
 	}
 
-	if len(entries) != 1 {
-		t.Errorf("expected 1 entry with both tags, got %d", len(entries))
+	if result.IndexError == nil {
+		t.Error("expected the corrupted index to be reported")
+	}
+	if result.ReadableFiles != 1 {
+		t.Errorf("expected 1 readable entry, got %d", result.ReadableFiles)
 	}
 }
 
-func TestJournalListRecent(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+func TestOpenJournalReadOnly(t *testing.T) {
+	j, cfg := setupTestJournal(t)
+	ent := mustAddEntry(t, j, "Read me", []string{"ro"})
 
-	for i := 1; i <= 5; i++ {
-		mustAddEntry(t, journal, "Entry", []string{})
-		time.Sleep(time.Millisecond) // Ensure different timestamps
+	ro, err := OpenJournalReadOnly(cfg)
+	if err != nil {
+		t.Fatalf("OpenJournalReadOnly failed: %v", err)
 	}
 
-	entries, err := journal.ListRecent(3)
+	got, err := ro.Get(ent.GetID())
 	if err != nil {
-		t.Fatalf("ListRecent failed: %v", err)
+		t.Fatalf("Get failed: %v", err)
 	}
-
-	if len(entries) != 3 {
-		t.Errorf("expected 3 entries, got %d", len(entries))
+	if got.GetContent() != "Read me" {
+		t.Errorf("expected content 'Read me', got %q", got.GetContent())
+	}
+	if all := ro.ListAll(); len(all) != 1 {
+		t.Errorf("expected 1 entry from ListAll, got %d", len(all))
 	}
 
-	// Verify entries are sorted by date descending
-	for i := 0; i < len(entries)-1; i++ {
-		if entries[i].GetDate().Before(entries[i+1].GetDate()) {
-			t.Error("entries are not sorted by date descending")
+	writes := map[string]func() error{
+		"Add": func() error {
+			_, err := ro.Add("New", nil)
+			return err
+		},
+		"Update": func() error {
+			_, err := ro.Update(ent.GetID(), "Changed", nil)
+			return err
+		},
+		"Delete":       func() error { return ro.Delete(ent.GetID()) },
+		"SaveTemplate": func() error { return ro.SaveTemplate("daily", "# Daily") },
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, err)
 		}
 	}
+
+	if got, err := j.Get(ent.GetID()); err != nil || got.GetContent() != "Read me" {
+		t.Errorf("entry changed through read-only journal (err: %v)", err)
+	}
 }
 
-func TestJournalListAll(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+func TestOpenJournalReadOnly_DoesNotCreateDirs(t *testing.T) {
+	_, cfg := setupTestJournal(t)
 
-	for i := 1; i <= 3; i++ {
-		mustAddEntry(t, journal, "Entry", []string{})
+	// A fresh clone of a journal without entries has no entries directory
+	entriesDir := filepath.Join(cfg.Path, storage.EntriesDir)
+	if err := os.RemoveAll(entriesDir); err != nil {
+		t.Fatalf("failed to remove entries dir: %v", err)
+	}
+	if err := os.Chmod(cfg.Path, 0500); err != nil {
+		t.Fatalf("failed to make journal read-only: %v", err)
 	}
+	t.Cleanup(func() { _ = os.Chmod(cfg.Path, 0700) })
 
-	metas := journal.ListAll()
+	ro, err := OpenJournalReadOnly(cfg)
+	if err != nil {
+		t.Fatalf("OpenJournalReadOnly failed on read-only dir: %v", err)
+	}
+	if count := ro.Count(); count != 0 {
+		t.Errorf("expected 0 entries, got %d", count)
+	}
+	if _, err := os.Stat(entriesDir); !os.IsNotExist(err) {
+		t.Errorf("expected entries dir not to be created, got %v", err)
+	}
 
-	if len(metas) != 3 {
-		t.Errorf("expected 3 metadata entries, got %d", len(metas))
+	// Root ignores directory permissions, so only check the writable open elsewhere
+	if os.Geteuid() != 0 {
+		if _, err := NewJournalFromConfig(cfg); err == nil {
+			t.Error("expected NewJournalFromConfig to fail on a read-only dir")
+		}
 	}
 }
 
-func TestJournalRebuildIndex(t *testing.T) {
-	journal, journalCfg := setupTestJournal(t)
+func TestJournalCompress(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+	content := strings.Repeat("A long and repetitive diary paragraph. ", 2000)
 
-	mustAddEntry(t, journal, "Entry 1", []string{"tag1"})
-	mustAddEntry(t, journal, "Entry 2", []string{"tag2"})
+	plain := mustAddEntry(t, journal, content, []string{"work"})
+	plainPath := filepath.Join(cfg.Path, storage.EntriesDir, plain.GetFilePath())
+	plainInfo, err := os.Stat(plainPath)
+	if err != nil {
+		t.Fatalf("failed to stat entry: %v", err)
+	}
 
-	err := journal.RebuildIndex()
+	cfg.Compress = true
+	journal, err = NewJournalFromConfig(cfg)
 	if err != nil {
-		t.Fatalf("RebuildIndex failed: %v", err)
+		t.Fatalf("failed to reopen journal: %v", err)
 	}
+	compressed := mustAddEntry(t, journal, content, []string{"work"})
 
-	if len(journal.index.Entries) != 2 {
-		t.Errorf("expected 2 entries in rebuilt index, got %d", len(journal.index.Entries))
+	result, err := journal.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if result.ReadableFiles != 2 {
+		t.Errorf("expected both entries to verify, got %+v", result)
 	}
 
-	journal2, err := NewJournalFromConfig(journalCfg)
+	// Re-encrypting rewrites the uncompressed entry in the current format
+	if err := journal.ReEncrypt(); err != nil {
+		t.Fatalf("ReEncrypt failed: %v", err)
+	}
+	info, err := os.Stat(plainPath)
 	if err != nil {
-		t.Fatalf("failed to create new journal: %v", err)
+		t.Fatalf("failed to stat entry: %v", err)
+	}
+	if info.Size() >= plainInfo.Size() {
+		t.Errorf("expected re-encrypt to compress the entry, size went from %d to %d", plainInfo.Size(), info.Size())
 	}
 
-	if len(journal2.index.Entries) != 2 {
-		t.Errorf("expected 2 entries in reloaded index, got %d", len(journal2.index.Entries))
+	for _, id := range []string{plain.GetID(), compressed.GetID()} {
+		loaded, err := journal.Get(id)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if loaded.GetContent() != content {
+			t.Errorf("%s: content changed", id)
+		}
 	}
 }
 
-func TestJournalAddRecipient(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+func TestJournalAddBatch(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+	cfg.DefaultTags = []string{"imported"}
 
-	identity2, err := age.GenerateX25519Identity()
+	entries, err := journal.AddBatch([]NewEntryInput{
+		{ID: "first", Content: "First entry", Tags: []string{"work"}, Options: AddOptions{Date: time.Date(2024, 11, 19, 9, 0, 0, 0, time.UTC)}},
+		{Content: "Second entry", Options: AddOptions{Title: "Second", Links: []string{"first"}}},
+	})
 	if err != nil {
-		t.Fatalf("failed to generate identity: %v", err)
+		t.Fatalf("AddBatch failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].GetID() != "first" || entries[1].GetID() == "" {
+		t.Errorf("expected the given ID to be kept and a new one generated, got %s and %s", entries[0].GetID(), entries[1].GetID())
+	}
+	if !slices.Equal(entries[0].GetTags(), []string{"imported", "work"}) {
+		t.Errorf("expected default tags to be added, got %v", entries[0].GetTags())
 	}
-	publicKey2 := identity2.Recipient().String()
 
-	err = journal.AddRecipient(publicKey2)
+	// The index is saved, so a fresh open sees both entries and the link between them
+	reopened, err := NewJournalFromConfig(cfg)
 	if err != nil {
-		t.Fatalf("AddRecipient failed: %v", err)
+		t.Fatalf("failed to reopen journal: %v", err)
 	}
-
-	recipients, err := crypto.ReadSOPSConfig(journal.config.Path)
+	if count := reopened.Count(); count != 2 {
+		t.Errorf("expected 2 entries after reopening, got %d", count)
+	}
+	backlinks, err := reopened.Backlinks("first")
 	if err != nil {
-		t.Fatalf("ReadSOPSConfig failed: %v", err)
+		t.Fatalf("Backlinks failed: %v", err)
 	}
-
-	if len(recipients) != 2 {
-		t.Errorf("expected 2 recipients, got %d", len(recipients))
+	if !slices.Equal(backlinks, []string{entries[1].GetID()}) {
+		t.Errorf("expected the second entry to link to the first, got %v", backlinks)
 	}
 }
 
-func TestJournalRemoveRecipient(t *testing.T) {
-	journal, journalCfg := setupTestJournal(t)
+func TestJournalAddBatch_KeepsEntriesBeforeFailure(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
 
-	identity2, err := age.GenerateX25519Identity()
-	if err != nil {
-		t.Fatalf("failed to generate identity: %v", err)
+	entries, err := journal.AddBatch([]NewEntryInput{
+		{ID: "a", Content: "Saved"},
+		{ID: "a", Content: "Duplicate ID"},
+		{ID: "c", Content: "Never reached"},
+	})
+	if !errors.Is(err, ErrEntryExists) {
+		t.Fatalf("expected ErrEntryExists, got %v", err)
+	}
+	if len(entries) != 1 || entries[0].GetID() != "a" {
+		t.Fatalf("expected the first entry to be returned, got %d entries", len(entries))
 	}
-	publicKey2 := identity2.Recipient().String()
 
-	err = journal.AddRecipient(publicKey2)
+	reopened, err := NewJournalFromConfig(cfg)
 	if err != nil {
-		t.Fatalf("AddRecipient failed: %v", err)
+		t.Fatalf("failed to reopen journal: %v", err)
 	}
+	if count := reopened.Count(); count != 1 {
+		t.Errorf("expected the entry saved before the failure to be indexed, got %d entries", count)
+	}
+}
 
-	err = journal.RemoveRecipient(publicKey2)
+func TestJournalAddBatch_Interrupted(t *testing.T) {
+	journal, cfg := setupTestJournal(t)
+
+	originalSize := addBatchSize
+	addBatchSize = 2
+	t.Cleanup(func() { addBatchSize = originalSize })
+
+	date := time.Date(2024, 11, 19, 9, 0, 0, 0, time.UTC)
+	inputs := make([]NewEntryInput, 5)
+	for i := range inputs {
+		inputs[i] = NewEntryInput{ID: "entry-" + strconv.Itoa(i), Content: "Imported entry", Options: AddOptions{Date: date}}
+	}
+
+	// A panic after the third entry stands in for the process being killed mid-import
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the interruption to panic")
+			}
+		}()
+		if _, err := journal.addEntries(inputs, func(done int) {
+			if done == 3 {
+				panic("interrupted")
+			}
+		}); err != nil {
+			t.Fatalf("addEntries failed before the interruption: %v", err)
+		}
+	}()
+
+	// The first batch was saved to the index, so only the third entry needs a rebuild or a re-import
+	reopened, err := NewJournalFromConfig(cfg)
 	if err != nil {
-		t.Fatalf("RemoveRecipient failed: %v", err)
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	if count := reopened.Count(); count != 2 {
+		t.Errorf("expected the first batch to be indexed, got %d entries", count)
 	}
 
-	recipients, err := crypto.ReadSOPSConfig(journalCfg.Path)
+	// Re-adding the entries missing from the index, as a resumed import does, rewrites the unindexed file
+	added, err := reopened.AddBatch(inputs[2:])
 	if err != nil {
-		t.Fatalf("ReadSOPSConfig failed: %v", err)
+		t.Fatalf("AddBatch failed: %v", err)
 	}
-
-	if len(recipients) != 1 {
-		t.Errorf("expected 1 recipient, got %d", len(recipients))
+	if len(added) != 3 || reopened.Count() != 5 {
+		t.Errorf("expected to resume with the remaining entries, added %d, indexed %d", len(added), reopened.Count())
 	}
 }
 
-func TestJournalListRecipients(t *testing.T) {
-	journal, _ := setupTestJournal(t)
+// benchmarkImportEntries is the number of entries each bulk-insert benchmark iteration adds
+const benchmarkImportEntries = 1000
+
+func benchmarkImportInputs() []NewEntryInput {
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	inputs := make([]NewEntryInput, benchmarkImportEntries)
+	for i := range inputs {
+		inputs[i] = NewEntryInput{
+			Content: "Imported entry",
+			Tags:    []string{"imported"},
+			Options: AddOptions{Date: start.AddDate(0, 0, i)},
+		}
+	}
+	return inputs
+}
 
-	recipients, err := journal.ListRecipients()
-	if err != nil {
-		t.Fatalf("ListRecipients failed: %v", err)
+// BenchmarkAddBatch adds benchmarkImportEntries entries in one batch, saving the index every addBatchSize entries
+// Run with: go test ./internal/entry -run '^$' -bench Add -benchtime 1x
+func BenchmarkAddBatch(b *testing.B) {
+	inputs := benchmarkImportInputs()
+	for b.Loop() {
+		b.StopTimer()
+		journal, _ := setupTestJournal(b)
+		b.StartTimer()
+
+		if _, err := journal.AddBatch(inputs); err != nil {
+			b.Fatalf("AddBatch failed: %v", err)
+		}
 	}
+}
 
-	if len(recipients) != 1 {
-		t.Errorf("expected 1 recipient, got %d", len(recipients))
+// BenchmarkAddPerEntry adds the same entries one at a time, re-encrypting the growing index after each,
+// as imports did before AddBatch
+func BenchmarkAddPerEntry(b *testing.B) {
+	inputs := benchmarkImportInputs()
+	for b.Loop() {
+		b.StopTimer()
+		journal, _ := setupTestJournal(b)
+		b.StartTimer()
+
+		for _, input := range inputs {
+			if _, err := journal.AddWithOptions(input.Content, input.Tags, input.Options); err != nil {
+				b.Fatalf("AddWithOptions failed: %v", err)
+			}
+		}
 	}
 }
 
-func TestJournalReEncrypt(t *testing.T) {
+func TestJournalTagLookupsIgnoreCase(t *testing.T) {
 	journal, _ := setupTestJournal(t)
+	mustAddEntry(t, journal, "Lowercase", []string{"work"})
+	mustAddEntry(t, journal, "Mixed case", []string{"Work"})
+	mustAddEntry(t, journal, "Other", []string{"personal"})
 
-	entry1 := mustAddEntry(t, journal, "Entry 1", []string{})
-	mustAddEntry(t, journal, "Entry 2", []string{})
-
-	err := journal.ReEncrypt()
-	if err != nil {
-		t.Fatalf("ReEncrypt failed: %v", err)
+	if count := journal.CountByTag("WORK"); count != 2 {
+		t.Errorf("expected CountByTag to match both spellings, got %d", count)
+	}
+	if metas := journal.ListByTag("work"); len(metas) != 2 {
+		t.Errorf("expected ListByTag to match both spellings, got %d", len(metas))
 	}
 
-	retrievedEntry, err := journal.Get(entry1.GetID())
+	deleted, err := journal.DeleteByTag("Work")
 	if err != nil {
-		t.Fatalf("Get failed after re-encrypt: %v", err)
+		t.Fatalf("DeleteByTag failed: %v", err)
 	}
-
-	if retrievedEntry.GetContent() != "Entry 1" {
-		t.Errorf("expected content 'Entry 1', got '%s'", retrievedEntry.GetContent())
+	if deleted != 2 || journal.Count() != 1 {
+		t.Errorf("expected both spellings deleted, got %d deleted and %d left", deleted, journal.Count())
 	}
 }
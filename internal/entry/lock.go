@@ -0,0 +1,41 @@
+package entry
+
+import (
+	"fmt"
+)
+
+// lockIndex acquires the index lock and reloads the index so that changes saved by
+// other processes since the journal was opened are not overwritten
+// The returned function releases the lock; a failure to release is only reported, since the
+// lock is treated as stale once it is no longer refreshed anyway
+func (j *Journal) lockIndex() (func(), error) {
+	if err := j.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	release, err := j.storage.LockIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock index: %w", err)
+	}
+
+	// Without an index on disk there is nothing to reload: changes only touch entry files,
+	// and rescanning them on every change would make it needlessly slow
+	if j.UsesIndex() {
+		// Inconsistencies were reported when the journal was opened, so they are not repeated on every change
+		index, _, err := j.storage.LoadIndex()
+		if err != nil {
+			j.releaseIndexLock(release)
+			return nil, fmt.Errorf("failed to reload index: %w", err)
+		}
+		j.index = index
+	}
+
+	return func() { j.releaseIndexLock(release) }, nil
+}
+
+// releaseIndexLock releases the index lock, passing a failure to the warn func
+func (j *Journal) releaseIndexLock(release func() error) {
+	if err := release(); err != nil {
+		j.warnf("failed to release index lock: %w", err)
+	}
+}
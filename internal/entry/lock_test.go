@@ -0,0 +1,77 @@
+package entry
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestJournalConcurrentAdds(t *testing.T) {
+	_, journalCfg := setupTestJournal(t)
+
+	const writers = 4
+	const entriesPerWriter = 3
+
+	// Each writer opens its own journal, as separate processes would
+	journals := make([]*Journal, writers)
+	for i := range journals {
+		j, err := NewJournalFromConfig(journalCfg)
+		if err != nil {
+			t.Fatalf("failed to open journal: %v", err)
+		}
+		journals[i] = j
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*entriesPerWriter)
+	for i, j := range journals {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range entriesPerWriter {
+				if _, err := j.Add(fmt.Sprintf("Writer %d entry %d", i, n), []string{"concurrent"}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Add failed: %v", err)
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	if count := reopened.Count(); count != writers*entriesPerWriter {
+		t.Errorf("expected %d entries in index, got %d", writers*entriesPerWriter, count)
+	}
+}
+
+func TestJournalReEncrypt_ReloadsIndex(t *testing.T) {
+	first, journalCfg := setupTestJournal(t)
+
+	// Another process adds an entry after this journal was opened
+	second, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	entry := mustAddEntry(t, second, "Added elsewhere", []string{"work"})
+
+	// Re-encryption rewrites the index under the lock, so it must not drop that entry
+	if err := first.ReEncrypt(); err != nil {
+		t.Fatalf("ReEncrypt failed: %v", err)
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	if _, err := reopened.Get(entry.GetID()); err != nil {
+		t.Errorf("expected the entry added elsewhere to stay in the index: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package entry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// JournalStats summarizes a journal's entries
+type JournalStats struct {
+	Entries int
+	// First and Last are the dates of the oldest and newest entries; zero if there are none
+	First time.Time
+	Last  time.Time
+	// Tags maps each tag to the number of entries carrying it
+	Tags map[string]int
+	// Months holds the number of entries in each month that has any, oldest first
+	Months []MonthCount
+	Words  int
+	Mood   MoodStats
+}
+
+// MonthFormat is the layout of the months in MonthCount and MonthMood
+const MonthFormat = "2006-01"
+
+// MonthCount is the number of entries in one month
+type MonthCount struct {
+	// Month is formatted as MonthFormat
+	Month   string `json:"month"`
+	Entries int    `json:"entries"`
+}
+
+// Stats computes statistics over every entry, mostly from the index
+// Only entries indexed before word counts existed are decrypted, to count their words;
+// 'journal rebuild' records the counts so later calls need not decrypt anything
+func (j *Journal) Stats() (*JournalStats, error) {
+	stats := &JournalStats{
+		Entries: len(j.index.Entries),
+		Tags:    make(map[string]int),
+		Months:  j.Months(),
+		Mood:    j.MoodStats(),
+	}
+
+	if first, ok := j.FirstEntry(); ok {
+		stats.First = first.Date
+	}
+	if last, ok := j.LastEntry(); ok {
+		stats.Last = last.Date
+	}
+
+	uncounted := make(map[string]bool)
+	for id, meta := range j.index.Entries {
+		for _, tag := range meta.Tags {
+			stats.Tags[tag]++
+		}
+
+		if meta.Words == 0 {
+			uncounted[id] = true
+		}
+		stats.Words += meta.Words
+	}
+
+	if len(uncounted) > 0 {
+		err := j.iterate(uncounted, false, func(entry models.Entry) error {
+			stats.Words += models.CountWords(entry.GetContent())
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count words: %w", err)
+		}
+	}
+
+	return stats, nil
+}
+
+// FirstEntry returns the metadata of the oldest entry from the index, or false if the journal is empty
+func (j *Journal) FirstEntry() (models.Metadata, bool) {
+	return j.index.FirstEntry()
+}
+
+// LastEntry returns the metadata of the newest entry from the index, or false if the journal is empty
+func (j *Journal) LastEntry() (models.Metadata, bool) {
+	return j.index.LastEntry()
+}
+
+// MoodStats summarizes the mood ratings of a journal's entries
+// Unrated entries are counted in Entries but left out of every average
+type MoodStats struct {
+	Entries int
+	Rated   int
+	Average float64
+	// Months holds one summary per month with rated entries, oldest first
+	Months []MonthMood
+}
+
+// MonthMood summarizes the mood ratings of one month's entries
+type MonthMood struct {
+	// Month is formatted as MonthFormat
+	Month   string
+	Rated   int
+	Average float64
+}
+
+// WordCount returns the number of words in entry, taken from the index when it has been recorded
+func (j *Journal) WordCount(entry models.Entry) int {
+	if meta, ok := j.index.GetMetadata(entry.GetID()); ok && meta.Words > 0 {
+		return meta.Words
+	}
+	return models.CountWords(entry.GetContent())
+}
+
+// Months returns the number of entries in each month that has any, oldest first, from the index's day keys
+func (j *Journal) Months() []MonthCount {
+	counts := make(map[string]int)
+	for day, ids := range j.index.ByDate {
+		if len(ids) > 0 {
+			counts[day[:len(MonthFormat)]] += len(ids)
+		}
+	}
+
+	var months []MonthCount
+	for month, count := range counts {
+		months = append(months, MonthCount{Month: month, Entries: count})
+	}
+	slices.SortFunc(months, func(a, b MonthCount) int {
+		return strings.Compare(a.Month, b.Month)
+	})
+	return months
+}
+
+// MonthEntries returns the metadata of the entries in month, formatted as MonthFormat, oldest first
+// Like Months it only reads the index, so no entries are decrypted
+func (j *Journal) MonthEntries(month string) []models.Metadata {
+	var metas []models.Metadata
+	for _, meta := range j.ListAll() {
+		if models.DateKey(meta.Date)[:len(MonthFormat)] == month {
+			metas = append(metas, meta)
+		}
+	}
+	slices.Reverse(metas)
+	return metas
+}
+
+// MoodStats computes mood statistics from the index, without decrypting any entries
+func (j *Journal) MoodStats() MoodStats {
+	stats := MoodStats{Entries: len(j.index.Entries)}
+
+	total := 0
+	monthTotals := make(map[string]int)
+	monthCounts := make(map[string]int)
+	for _, meta := range j.index.Entries {
+		if meta.Mood == 0 {
+			continue
+		}
+		month := models.DateKey(meta.Date)[:len(MonthFormat)]
+		monthTotals[month] += meta.Mood
+		monthCounts[month]++
+		total += meta.Mood
+		stats.Rated++
+	}
+
+	if stats.Rated > 0 {
+		stats.Average = float64(total) / float64(stats.Rated)
+	}
+
+	for month, count := range monthCounts {
+		stats.Months = append(stats.Months, MonthMood{
+			Month:   month,
+			Rated:   count,
+			Average: float64(monthTotals[month]) / float64(count),
+		})
+	}
+	slices.SortFunc(stats.Months, func(a, b MonthMood) int {
+		return strings.Compare(a.Month, b.Month)
+	})
+
+	return stats
+}
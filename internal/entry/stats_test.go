@@ -0,0 +1,229 @@
+package entry
+
+import (
+	"maps"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+func TestJournalMoodStats(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	for _, e := range []struct {
+		date time.Time
+		mood int
+	}{
+		{time.Date(2024, 10, 3, 9, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, 10, 20, 9, 0, 0, 0, time.UTC), 4},
+		{time.Date(2024, 11, 5, 9, 0, 0, 0, time.UTC), 5},
+		{time.Date(2024, 11, 6, 9, 0, 0, 0, time.UTC), 0},
+	} {
+		if _, err := journal.AddWithOptions("Entry", nil, AddOptions{Date: e.date, Mood: e.mood}); err != nil {
+			t.Fatalf("AddWithOptions failed: %v", err)
+		}
+	}
+
+	stats := journal.MoodStats()
+	if stats.Entries != 4 || stats.Rated != 3 {
+		t.Errorf("expected 4 entries with 3 rated, got %d and %d", stats.Entries, stats.Rated)
+	}
+	if stats.Average < 3.66 || stats.Average > 3.67 {
+		t.Errorf("expected average of about 3.67, got %f", stats.Average)
+	}
+
+	if len(stats.Months) != 2 {
+		t.Fatalf("expected 2 months, got %v", stats.Months)
+	}
+	if stats.Months[0].Month != "2024-10" || stats.Months[0].Average != 3 || stats.Months[0].Rated != 2 {
+		t.Errorf("unexpected October summary: %+v", stats.Months[0])
+	}
+	if stats.Months[1].Month != "2024-11" || stats.Months[1].Average != 5 || stats.Months[1].Rated != 1 {
+		t.Errorf("expected the unrated November entry to be excluded, got %+v", stats.Months[1])
+	}
+}
+
+func TestJournalMoodStats_NoRatings(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+	mustAddEntry(t, journal, "Unrated", nil)
+
+	stats := journal.MoodStats()
+	if stats.Rated != 0 || stats.Average != 0 || len(stats.Months) != 0 {
+		t.Errorf("expected no mood statistics, got %+v", stats)
+	}
+}
+
+func TestJournalStats(t *testing.T) {
+	fixture := []struct {
+		content string
+		tags    []string
+		date    time.Time
+	}{
+		{"Started the new project today", []string{"work"}, time.Date(2024, 10, 3, 9, 0, 0, 0, time.UTC)},
+		{"Long walk", []string{"health", "weekend"}, time.Date(2024, 10, 20, 9, 0, 0, 0, time.UTC)},
+		{"Shipped it", []string{"work"}, time.Date(2024, 12, 5, 9, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name    string
+		entries int
+		want    JournalStats
+	}{
+		{
+			name: "empty journal",
+			want: JournalStats{Tags: map[string]int{}},
+		},
+		{
+			name:    "one entry",
+			entries: 1,
+			want: JournalStats{
+				Entries: 1,
+				First:   fixture[0].date,
+				Last:    fixture[0].date,
+				Tags:    map[string]int{"work": 1},
+				Months:  []MonthCount{{Month: "2024-10", Entries: 1}},
+				Words:   5,
+			},
+		},
+		{
+			name:    "several months",
+			entries: 3,
+			want: JournalStats{
+				Entries: 3,
+				First:   fixture[0].date,
+				Last:    fixture[2].date,
+				Tags:    map[string]int{"work": 2, "health": 1, "weekend": 1},
+				Months:  []MonthCount{{Month: "2024-10", Entries: 2}, {Month: "2024-12", Entries: 1}},
+				Words:   9,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journal, _ := setupTestJournal(t)
+			for _, e := range fixture[:tt.entries] {
+				if _, err := journal.AddWithDate(e.content, e.tags, e.date); err != nil {
+					t.Fatalf("AddWithDate failed: %v", err)
+				}
+			}
+
+			stats, err := journal.Stats()
+			if err != nil {
+				t.Fatalf("Stats failed: %v", err)
+			}
+
+			if stats.Entries != tt.want.Entries || stats.Words != tt.want.Words {
+				t.Errorf("expected %d entries and %d words, got %d and %d", tt.want.Entries, tt.want.Words, stats.Entries, stats.Words)
+			}
+			if !stats.First.Equal(tt.want.First) || !stats.Last.Equal(tt.want.Last) {
+				t.Errorf("expected dates %v to %v, got %v to %v", tt.want.First, tt.want.Last, stats.First, stats.Last)
+			}
+			if !maps.Equal(stats.Tags, tt.want.Tags) {
+				t.Errorf("expected tags %v, got %v", tt.want.Tags, stats.Tags)
+			}
+			if !reflect.DeepEqual(stats.Months, tt.want.Months) {
+				t.Errorf("expected months %v, got %v", tt.want.Months, stats.Months)
+			}
+		})
+	}
+}
+
+func TestJournalStats_UncountedWords(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+	entry := mustAddEntry(t, journal, "Three little words", nil)
+
+	// Indexes written before word counts existed have none recorded
+	meta := journal.index.Entries[entry.GetID()]
+	meta.Words = 0
+	journal.index.Entries[entry.GetID()] = meta
+
+	stats, err := journal.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Words != models.CountWords("Three little words") {
+		t.Errorf("expected words of unindexed entries to be counted, got %d", stats.Words)
+	}
+}
+
+func TestJournalMonthEntries(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	// In this offset the entry is still on October 31, although it is November in UTC
+	est := time.FixedZone("EST", -5*60*60)
+	october := mustAddEntryWithDate(t, journal, time.Date(2024, 10, 31, 22, 0, 0, 0, est))
+	november := mustAddEntryWithDate(t, journal, time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC))
+
+	months := journal.Months()
+	want := []MonthCount{{Month: "2024-10", Entries: 1}, {Month: "2024-11", Entries: 1}}
+	if !reflect.DeepEqual(months, want) {
+		t.Errorf("expected months %v, got %v", want, months)
+	}
+
+	if metas := journal.MonthEntries("2024-10"); len(metas) != 1 || metas[0].Id != october.GetID() {
+		t.Errorf("expected only the October entry, got %v", metas)
+	}
+	if metas := journal.MonthEntries("2024-11"); len(metas) != 1 || metas[0].Id != november.GetID() {
+		t.Errorf("expected only the November entry, got %v", metas)
+	}
+	if metas := journal.MonthEntries("2024-12"); len(metas) != 0 {
+		t.Errorf("expected no entries in December, got %v", metas)
+	}
+}
+
+func TestJournalFirstAndLastEntry(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, ok := journal.FirstEntry(); ok {
+		t.Error("expected no first entry in an empty journal")
+	}
+	if _, ok := journal.LastEntry(); ok {
+		t.Error("expected no last entry in an empty journal")
+	}
+
+	newest := mustAddEntryWithDate(t, journal, time.Date(2024, 11, 19, 9, 0, 0, 0, time.UTC))
+	oldest := mustAddEntryWithDate(t, journal, time.Date(2023, 1, 5, 9, 0, 0, 0, time.UTC))
+	mustAddEntryWithDate(t, journal, time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC))
+
+	if first, ok := journal.FirstEntry(); !ok || first.Id != oldest.GetID() {
+		t.Errorf("expected first entry %s, got %s (ok=%v)", oldest.GetID(), first.Id, ok)
+	}
+	if last, ok := journal.LastEntry(); !ok || last.Id != newest.GetID() {
+		t.Errorf("expected last entry %s, got %s (ok=%v)", newest.GetID(), last.Id, ok)
+	}
+}
+
+func mustAddEntryWithDate(t *testing.T, journal *Journal, date time.Time) models.Entry {
+	t.Helper()
+	entry, err := journal.AddWithDate("Entry", nil, date)
+	if err != nil {
+		t.Fatalf("AddWithDate failed: %v", err)
+	}
+	return entry
+}
+
+func TestJournalWordCount(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+	entry := mustAddEntry(t, journal, "Three little words", nil)
+
+	if words := journal.WordCount(entry); words != 3 {
+		t.Errorf("expected 3 words, got %d", words)
+	}
+
+	// The indexed count is preferred, and the content is only counted without one
+	meta := journal.index.Entries[entry.GetID()]
+	meta.Words = 7
+	journal.index.Entries[entry.GetID()] = meta
+	if words := journal.WordCount(entry); words != 7 {
+		t.Errorf("expected the indexed word count, got %d", words)
+	}
+
+	meta.Words = 0
+	journal.index.Entries[entry.GetID()] = meta
+	if words := journal.WordCount(entry); words != 3 {
+		t.Errorf("expected words counted from the content, got %d", words)
+	}
+}
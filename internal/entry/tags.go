@@ -0,0 +1,135 @@
+package entry
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// UpdateTags replaces the tags of an entry without changing its content
+func (j *Journal) UpdateTags(id string, tags []string) (models.Entry, error) {
+	return j.modifyEntry(id, func(entry *models.EntryV2) {
+		entry.Tags = dedupeTags(tags)
+	})
+}
+
+// AddTags adds tags to an entry, ignoring tags it already has
+func (j *Journal) AddTags(id string, tags []string) (models.Entry, error) {
+	return j.modifyEntry(id, func(entry *models.EntryV2) {
+		entry.Tags = dedupeTags(append(append([]string(nil), entry.Tags...), tags...))
+	})
+}
+
+// RemoveTags removes tags from an entry, ignoring tags it does not have
+func (j *Journal) RemoveTags(id string, tags []string) (models.Entry, error) {
+	return j.modifyEntry(id, func(entry *models.EntryV2) {
+		var result []string
+		for _, tag := range entry.Tags {
+			if !slices.Contains(tags, tag) {
+				result = append(result, tag)
+			}
+		}
+		entry.Tags = result
+	})
+}
+
+// RenameTag replaces oldTag with newTag on every entry carrying it
+// If an entry already has newTag, the tags are merged without duplicates
+// Returns the number of entries changed
+func (j *Journal) RenameTag(oldTag, newTag string) (int, error) {
+	if oldTag == "" || newTag == "" {
+		return 0, fmt.Errorf("tag names must not be empty")
+	}
+	if oldTag == newTag {
+		return 0, nil
+	}
+
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Copy the IDs since the index bucket is modified while renaming
+	ids := append([]string(nil), j.index.FindByTag(oldTag)...)
+
+	changed := 0
+	for _, id := range ids {
+		meta, exists := j.index.GetMetadata(id)
+		if !exists {
+			continue
+		}
+
+		entry, err := j.storage.LoadEntry(id, meta.FilePath)
+		if err != nil {
+			return changed, j.saveIndexAfterError(fmt.Errorf("failed to load entry %s: %w", id, err))
+		}
+
+		updated, err := models.Upgrade(entry)
+		if err != nil {
+			return changed, j.saveIndexAfterError(fmt.Errorf("failed to update entry %s: %w", id, err))
+		}
+
+		updated.Tags = replaceTag(updated.Tags, oldTag, newTag)
+		updated.Modified = time.Now()
+
+		if err := j.storage.SaveEntry(updated); err != nil {
+			return changed, j.saveIndexAfterError(fmt.Errorf("failed to save entry %s: %w", id, err))
+		}
+
+		j.index.Remove(id)
+		j.index.Add(updated)
+		changed++
+	}
+
+	if changed == 0 {
+		return 0, nil
+	}
+
+	if err := j.saveIndex(); err != nil {
+		return changed, fmt.Errorf("failed to save index: %w", err)
+	}
+
+	return changed, nil
+}
+
+// saveIndexAfterError persists index changes made before err occurred and returns err
+func (j *Journal) saveIndexAfterError(err error) error {
+	if saveErr := j.saveIndex(); saveErr != nil {
+		return fmt.Errorf("%w (additionally failed to save index: %v)", err, saveErr)
+	}
+	return err
+}
+
+// dedupeTags returns tags without duplicates or empty tags, keeping the first occurrence
+func dedupeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// replaceTag returns tags with oldTag replaced by newTag, dropping any duplicates
+func replaceTag(tags []string, oldTag, newTag string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag == oldTag {
+			tag = newTag
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
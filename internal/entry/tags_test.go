@@ -0,0 +1,188 @@
+package entry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJournalRenameTag(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "Entry 1", []string{"wrok", "meeting"})
+	second := mustAddEntry(t, journal, "Entry 2", []string{"wrok", "work"})
+	mustAddEntry(t, journal, "Entry 3", []string{"personal"})
+
+	changed, err := journal.RenameTag("wrok", "work")
+	if err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+
+	if changed != 2 {
+		t.Errorf("expected 2 entries changed, got %d", changed)
+	}
+
+	if ids := journal.index.FindByTag("wrok"); len(ids) != 0 {
+		t.Errorf("expected old tag to be gone from index, got %v", ids)
+	}
+
+	if ids := journal.index.FindByTag("work"); len(ids) != 2 {
+		t.Errorf("expected 2 entries with new tag, got %d", len(ids))
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+
+	renamed, err := reopened.Get(first.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(renamed.GetTags(), []string{"work", "meeting"}) {
+		t.Errorf("expected tags [work meeting], got %v", renamed.GetTags())
+	}
+
+	merged, err := reopened.Get(second.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !reflect.DeepEqual(merged.GetTags(), []string{"work"}) {
+		t.Errorf("expected merged tags [work], got %v", merged.GetTags())
+	}
+}
+
+func TestJournalRenameTag_NoMatches(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	mustAddEntry(t, journal, "Entry", []string{"work"})
+
+	changed, err := journal.RenameTag("missing", "work")
+	if err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+
+	if changed != 0 {
+		t.Errorf("expected 0 entries changed, got %d", changed)
+	}
+}
+
+func TestJournalRenameTag_EmptyName(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, err := journal.RenameTag("work", ""); err == nil {
+		t.Error("expected error for empty tag name")
+	}
+}
+
+func TestReplaceTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{"simple rename", []string{"a", "old", "b"}, []string{"a", "new", "b"}},
+		{"merge into existing", []string{"new", "old"}, []string{"new"}},
+		{"existing duplicates", []string{"old", "old", "a", "a"}, []string{"new", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := replaceTag(tt.tags, "old", "new")
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestJournalUpdateTags(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry content", []string{"old"})
+
+	updated, err := journal.UpdateTags(entry.GetID(), []string{"new", "other", "new"})
+	if err != nil {
+		t.Fatalf("UpdateTags failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(updated.GetTags(), []string{"new", "other"}) {
+		t.Errorf("expected tags [new other], got %v", updated.GetTags())
+	}
+
+	if updated.GetContent() != "Entry content" {
+		t.Errorf("expected content to be unchanged, got '%s'", updated.GetContent())
+	}
+
+	if ids := journal.index.FindByTag("old"); len(ids) != 0 {
+		t.Errorf("expected old tag to be removed from index, got %v", ids)
+	}
+}
+
+func TestJournalAddTags(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry content", []string{"work"})
+
+	updated, err := journal.AddTags(entry.GetID(), []string{"work", "meeting"})
+	if err != nil {
+		t.Fatalf("AddTags failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(updated.GetTags(), []string{"work", "meeting"}) {
+		t.Errorf("expected tags [work meeting], got %v", updated.GetTags())
+	}
+
+	if ids := journal.index.FindByTag("meeting"); len(ids) != 1 {
+		t.Errorf("expected added tag in index, got %v", ids)
+	}
+}
+
+func TestJournalRemoveTags(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry content", []string{"work", "meeting", "draft"})
+
+	updated, err := journal.RemoveTags(entry.GetID(), []string{"draft", "missing"})
+	if err != nil {
+		t.Fatalf("RemoveTags failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(updated.GetTags(), []string{"work", "meeting"}) {
+		t.Errorf("expected tags [work meeting], got %v", updated.GetTags())
+	}
+
+	persisted, err := journal.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(persisted.GetTags(), []string{"work", "meeting"}) {
+		t.Errorf("expected persisted tags [work meeting], got %v", persisted.GetTags())
+	}
+}
+
+func TestJournalUpdateTags_NotFound(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, err := journal.UpdateTags("nonexistent-id", []string{"tag"}); err == nil {
+		t.Error("expected error for nonexistent entry")
+	}
+}
+
+func TestJournalAdd_DefaultTags(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+	journalCfg.DefaultTags = []string{"work", "daily"}
+
+	entry, err := journal.Add("Standup notes", []string{"meeting", "work"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if got := strings.Join(entry.GetTags(), ","); got != "work,daily,meeting" {
+		t.Errorf("expected default tags merged without duplicates, got %s", got)
+	}
+	if len(journal.index.FindByTag("daily")) != 1 {
+		t.Error("expected default tag to be indexed")
+	}
+}
@@ -0,0 +1,56 @@
+package entry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/data-castle/journal/internal/storage"
+)
+
+// ErrTemplateNotFound is returned when a named template does not exist
+var ErrTemplateNotFound = errors.New("template not found")
+
+// SaveTemplate stores content as a named template, encrypted like the entries
+// Saving under an existing name replaces that template
+func (j *Journal) SaveTemplate(name string, content string) error {
+	if err := j.checkWritable(); err != nil {
+		return err
+	}
+	return j.storage.SaveTemplate(name, content)
+}
+
+// GetTemplate returns the content of a named template
+func (j *Journal) GetTemplate(name string) (string, error) {
+	if err := j.checkTemplateExists(name); err != nil {
+		return "", err
+	}
+	return j.storage.LoadTemplate(name)
+}
+
+// ListTemplates returns the sorted names of the journal's templates
+func (j *Journal) ListTemplates() ([]string, error) {
+	return j.storage.ListTemplates()
+}
+
+// DeleteTemplate removes a named template
+func (j *Journal) DeleteTemplate(name string) error {
+	if err := j.checkWritable(); err != nil {
+		return err
+	}
+	if err := j.checkTemplateExists(name); err != nil {
+		return err
+	}
+	return j.storage.DeleteTemplate(name)
+}
+
+// checkTemplateExists returns ErrTemplateNotFound if no template is saved under name
+func (j *Journal) checkTemplateExists(name string) error {
+	if err := storage.ValidateTemplateName(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(j.storage.GetTemplatePath(name)); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package entry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestJournalTemplates(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, err := journal.GetTemplate("daily"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+
+	if err := journal.SaveTemplate("daily", "Mood:"); err != nil {
+		t.Fatalf("SaveTemplate failed: %v", err)
+	}
+	content, err := journal.GetTemplate("daily")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	if content != "Mood:" {
+		t.Errorf("expected 'Mood:', got %q", content)
+	}
+
+	if err := journal.DeleteTemplate("daily"); err != nil {
+		t.Fatalf("DeleteTemplate failed: %v", err)
+	}
+	if err := journal.DeleteTemplate("daily"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestJournalReEncrypt_Templates(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	if err := journal.SaveTemplate("daily", "Shared template"); err != nil {
+		t.Fatalf("SaveTemplate failed: %v", err)
+	}
+
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	recipients, err := journal.ListRecipients()
+	if err != nil {
+		t.Fatalf("ListRecipients failed: %v", err)
+	}
+	if err := journal.ReEncryptWithRecipients(append(recipients, identity2.Recipient().String())); err != nil {
+		t.Fatalf("ReEncryptWithRecipients failed: %v", err)
+	}
+
+	// The new recipient alone must be able to read the template
+	keyPath := filepath.Join(t.TempDir(), "key2.txt")
+	if err := os.WriteFile(keyPath, []byte(identity2.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("new recipient failed to open journal: %v", err)
+	}
+	content, err := reopened.GetTemplate("daily")
+	if err != nil {
+		t.Fatalf("new recipient failed to read template: %v", err)
+	}
+	if content != "Shared template" {
+		t.Errorf("expected 'Shared template', got %q", content)
+	}
+}
@@ -0,0 +1,104 @@
+package entry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// Restore moves a trashed entry back into the journal and re-adds it to the index
+func (j *Journal) Restore(id string) (models.Entry, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, exists := j.index.GetMetadata(id); exists {
+		return nil, fmt.Errorf("%w: %s", ErrEntryExists, id)
+	}
+
+	relPath, err := j.storage.FindTrashedEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := j.storage.RestoreFromTrash(relPath); err != nil {
+		return nil, err
+	}
+
+	entry, err := j.storage.LoadEntry(id, relPath)
+	if err != nil {
+		if moveErr := j.storage.MoveToTrash(relPath); moveErr != nil {
+			return nil, fmt.Errorf("failed to load restored entry: %w (additionally failed to move it back to trash: %v)", err, moveErr)
+		}
+		return nil, fmt.Errorf("failed to load restored entry: %w", err)
+	}
+
+	j.index.Add(entry)
+
+	if err := j.saveIndex(); err != nil {
+		return nil, fmt.Errorf("failed to save index: %w", err)
+	}
+
+	j.updateContentIndex(func(ci *models.ContentIndex) { indexContent(ci, entry) })
+
+	return entry, nil
+}
+
+// ListTrash returns all trashed entries, newest first
+// Entries that cannot be decrypted are passed to the warn func and skipped
+func (j *Journal) ListTrash() ([]models.Entry, error) {
+	files, err := j.storage.ListTrash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	var entries []models.Entry
+	for _, file := range files {
+		entry, err := j.storage.LoadTrashedEntry(file)
+		if err != nil {
+			j.warnf("failed to load trashed entry %s: %w", file, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].GetDate().After(entries[j].GetDate())
+	})
+
+	return entries, nil
+}
+
+// EmptyTrash permanently deletes all trashed entries and their attachments and returns how many were removed
+// Attachments of an entry that is also in the journal, e.g. after an undo, are kept
+func (j *Journal) EmptyTrash() (int, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	files, err := j.storage.ListTrash()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	count, err := j.storage.EmptyTrash()
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	for _, file := range files {
+		id := j.storage.EntryIDFromPath(file)
+		if _, exists := j.index.GetMetadata(id); exists {
+			continue
+		}
+		if err := j.storage.DeleteAttachments(id); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,286 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+)
+
+func TestJournalDelete_MovesToTrash(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry to trash", []string{"work"})
+
+	if err := journal.Delete(entry.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, "entries", entry.GetFilePath())); !os.IsNotExist(err) {
+		t.Error("expected entry file to be removed from entries directory")
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, "trash", entry.GetFilePath())); err != nil {
+		t.Errorf("expected entry file in trash with same year/month layout: %v", err)
+	}
+
+	trashed, err := journal.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+
+	if len(trashed) != 1 || trashed[0].GetID() != entry.GetID() {
+		t.Errorf("expected trashed entry %s, got %d entries", entry.GetID(), len(trashed))
+	}
+}
+
+func TestJournalDeleteByTag(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	first := mustAddEntry(t, journal, "Draft 1", []string{"draft"})
+	mustAddEntry(t, journal, "Draft 2", []string{"draft", "work"})
+	kept := mustAddEntry(t, journal, "Keeper", []string{"work"})
+
+	deleted, err := journal.DeleteByTag("draft")
+	if err != nil {
+		t.Fatalf("DeleteByTag failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 entries deleted, got %d", deleted)
+	}
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("failed to reopen journal: %v", err)
+	}
+	if reopened.Count() != 1 || reopened.CountByTag("draft") != 0 {
+		t.Errorf("expected only the untagged entry to remain, got %d entries", reopened.Count())
+	}
+	if _, err := reopened.Get(kept.GetID()); err != nil {
+		t.Errorf("expected entry without the tag to be kept: %v", err)
+	}
+
+	if _, err := reopened.Restore(first.GetID()); err != nil {
+		t.Errorf("expected batch-deleted entry to be restorable from the trash: %v", err)
+	}
+
+	if deleted, err := reopened.DeleteByTag("missing"); err != nil || deleted != 0 {
+		t.Errorf("expected nothing deleted for an unknown tag, got %d, %v", deleted, err)
+	}
+}
+
+func TestJournalDeleteByDateRange(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	for _, day := range []int{1, 10, 20} {
+		if _, err := journal.AddWithDate("Entry", nil, time.Date(2024, 3, day, 9, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatalf("AddWithDate failed: %v", err)
+		}
+	}
+
+	deleted, err := journal.DeleteByDateRange(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("DeleteByDateRange failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 entries deleted, got %d", deleted)
+	}
+	if journal.Count() != 1 {
+		t.Errorf("expected 1 entry left, got %d", journal.Count())
+	}
+}
+
+func TestJournalRestore(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry to restore", []string{"work"})
+
+	if err := journal.Delete(entry.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	restored, err := journal.Restore(entry.GetID())
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if restored.GetContent() != "Entry to restore" {
+		t.Errorf("expected restored content, got '%s'", restored.GetContent())
+	}
+
+	if ids := journal.index.FindByTag("work"); len(ids) != 1 {
+		t.Errorf("expected restored entry to be indexed by tag, got %v", ids)
+	}
+
+	if _, err := journal.Get(entry.GetID()); err != nil {
+		t.Errorf("expected restored entry to be readable: %v", err)
+	}
+
+	trashed, err := journal.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+
+	if len(trashed) != 0 {
+		t.Errorf("expected empty trash after restore, got %d entries", len(trashed))
+	}
+}
+
+func TestJournalRestore_NotInTrash(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, err := journal.Restore("nonexistent-id"); err == nil {
+		t.Error("expected error when restoring an entry that is not in the trash")
+	}
+}
+
+func TestJournalDeletePermanently(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry to purge", []string{})
+
+	if err := journal.DeletePermanently(entry.GetID()); err != nil {
+		t.Fatalf("DeletePermanently failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, "trash", entry.GetFilePath())); !os.IsNotExist(err) {
+		t.Error("expected permanently deleted entry not to be in trash")
+	}
+
+	if _, err := journal.Restore(entry.GetID()); err == nil {
+		t.Error("expected restore to fail after permanent delete")
+	}
+}
+
+func TestJournalPurge_Attachments(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	addWithAttachment := func(content string) string {
+		entry := mustAddEntry(t, journal, content, []string{})
+		if err := journal.AddAttachment(entry.GetID(), writeTestAttachment(t, "notes.txt", []byte(content))); err != nil {
+			t.Fatalf("AddAttachment failed: %v", err)
+		}
+		return entry.GetID()
+	}
+	attachmentDir := func(id string) string {
+		return filepath.Join(journalCfg.Path, "attachments", id)
+	}
+
+	trashed := addWithAttachment("Trashed entry")
+	purged := addWithAttachment("Purged entry")
+
+	// Trashed entries keep their attachments, so a restore brings them back intact
+	if err := journal.Delete(trashed); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(attachmentDir(trashed)); err != nil {
+		t.Errorf("expected attachments of a trashed entry to be kept: %v", err)
+	}
+
+	if _, err := journal.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+	if _, err := os.Stat(attachmentDir(trashed)); !os.IsNotExist(err) {
+		t.Error("expected emptying the trash to delete the attachments")
+	}
+
+	if err := journal.DeletePermanently(purged); err != nil {
+		t.Fatalf("DeletePermanently failed: %v", err)
+	}
+	if _, err := os.Stat(attachmentDir(purged)); !os.IsNotExist(err) {
+		t.Error("expected a permanent delete to delete the attachments")
+	}
+
+	// Undoing the permanent delete restores the entry without the attachments that are gone
+	_, restored, err := journal.Undo()
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if attachments := restored.GetAttachments(); len(attachments) != 0 {
+		t.Errorf("expected no attachments on the restored entry, got %v", attachments)
+	}
+}
+
+func TestJournalEmptyTrash(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	for i := 0; i < 2; i++ {
+		entry := mustAddEntry(t, journal, "Entry", []string{})
+		if err := journal.Delete(entry.GetID()); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+	}
+
+	count, err := journal.EmptyTrash()
+	if err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+
+	if count != 2 {
+		t.Errorf("expected 2 purged entries, got %d", count)
+	}
+
+	trashed, err := journal.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+
+	if len(trashed) != 0 {
+		t.Errorf("expected empty trash, got %d entries", len(trashed))
+	}
+}
+
+func TestJournalReEncrypt_Trash(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Trashed entry", []string{"work"})
+	src := writeTestAttachment(t, "notes.txt", []byte("trashed notes"))
+	if err := journal.AddAttachment(entry.GetID(), src); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if err := journal.Delete(entry.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	// Replace the only recipient, as remove-recipient does after adding a new one
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	opts := ReEncryptOptions{Recipients: []string{identity2.Recipient().String()}, SkipVerify: true}
+	if err := journal.ReEncryptWithOptions(opts); err != nil {
+		t.Fatalf("ReEncryptWithOptions failed: %v", err)
+	}
+
+	if _, err := journal.storage.LoadTrashedEntry(entry.GetFilePath()); err == nil {
+		t.Error("expected the removed recipient to be unable to read the trashed entry")
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "key2.txt")
+	if err := os.WriteFile(keyPath, []byte(identity2.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("new recipient failed to open journal: %v", err)
+	}
+	restored, err := reopened.Restore(entry.GetID())
+	if err != nil {
+		t.Fatalf("new recipient failed to restore entry: %v", err)
+	}
+	if restored.GetContent() != "Trashed entry" {
+		t.Errorf("expected content 'Trashed entry', got %q", restored.GetContent())
+	}
+
+	dest := filepath.Join(t.TempDir(), "notes.txt")
+	if err := reopened.ExtractAttachment(entry.GetID(), "notes.txt", dest); err != nil {
+		t.Fatalf("new recipient failed to extract attachment: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "trashed notes" {
+		t.Errorf("expected 'trashed notes', got %q", data)
+	}
+}
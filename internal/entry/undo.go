@@ -0,0 +1,119 @@
+package entry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// Operations that can be undone
+const (
+	OperationDelete = "delete"
+	OperationUpdate = "update"
+)
+
+// ErrNothingToUndo is returned by Undo when no operation has been recorded
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// lastOperation is the undo record for the most recent destructive operation
+type lastOperation struct {
+	Operation string          `yaml:"operation"`
+	Timestamp time.Time       `yaml:"timestamp"`
+	Entry     *models.EntryV2 `yaml:"entry"`
+}
+
+// recordOperation stores the prior state of an entry so the operation can be undone
+// Failing to record is passed to the warn func but does not fail the operation itself
+func (j *Journal) recordOperation(operation string, prior models.Entry) {
+	entry, err := models.Upgrade(prior)
+	if err != nil {
+		j.warnf("failed to record %s for undo: %w", operation, err)
+		return
+	}
+
+	op := lastOperation{
+		Operation: operation,
+		Timestamp: time.Now(),
+		Entry:     entry,
+	}
+
+	if err := j.storage.SaveLastOperation(&op); err != nil {
+		j.warnf("failed to record %s for undo: %w", operation, err)
+	}
+}
+
+// Undo reverts the most recent Delete or Update by restoring the recorded entry
+// Returns the undone operation name and the restored entry
+func (j *Journal) Undo() (string, models.Entry, error) {
+	unlock, err := j.lockIndex()
+	if err != nil {
+		return "", nil, err
+	}
+	defer unlock()
+
+	var op lastOperation
+	if err := j.storage.LoadLastOperation(&op); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, ErrNothingToUndo
+		}
+		return "", nil, err
+	}
+
+	if op.Entry == nil {
+		return "", nil, fmt.Errorf("last operation record has no entry")
+	}
+	entry := op.Entry
+
+	switch op.Operation {
+	case OperationDelete:
+		if _, exists := j.index.GetMetadata(entry.GetID()); exists {
+			return "", nil, fmt.Errorf("%w: %s, it may have been restored already", ErrEntryExists, entry.GetID())
+		}
+		// Attachments are gone if the entry was deleted permanently or the trash was emptied since
+		entry.Attachments = slices.DeleteFunc(entry.Attachments, func(name string) bool {
+			return !j.storage.HasAttachment(entry.GetID(), name)
+		})
+	case OperationUpdate:
+	default:
+		return "", nil, fmt.Errorf("unknown operation in undo record: %s", op.Operation)
+	}
+
+	if err := j.storage.SaveEntry(entry); err != nil {
+		return "", nil, fmt.Errorf("failed to save entry: %w", err)
+	}
+
+	if op.Operation == OperationDelete {
+		if err := j.storage.DeleteTrashedEntry(entry.GetFilePath()); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// An update that changed the date may have moved the entry to another file
+	current, moved := j.index.GetMetadata(entry.GetID())
+	moved = moved && current.FilePath != entry.GetFilePath()
+
+	j.index.Remove(entry.GetID())
+	j.index.Add(entry)
+
+	if err := j.saveIndex(); err != nil {
+		return "", nil, fmt.Errorf("failed to save index: %w", err)
+	}
+
+	if moved {
+		if err := j.storage.DeleteEntry(current.FilePath); err != nil {
+			return "", nil, err
+		}
+	}
+
+	j.updateContentIndex(func(ci *models.ContentIndex) { indexContent(ci, entry) })
+
+	if err := j.storage.ClearLastOperation(); err != nil {
+		return "", nil, err
+	}
+
+	return op.Operation, entry, nil
+}
@@ -0,0 +1,174 @@
+package entry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+)
+
+func TestJournalUndo_Delete(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry to undelete", []string{"work"})
+
+	if err := journal.Delete(entry.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	operation, restored, err := journal.Undo()
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if operation != OperationDelete {
+		t.Errorf("expected operation %s, got %s", OperationDelete, operation)
+	}
+
+	if restored.GetID() != entry.GetID() {
+		t.Errorf("expected restored ID %s, got %s", entry.GetID(), restored.GetID())
+	}
+
+	got, err := journal.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("expected undeleted entry to be readable: %v", err)
+	}
+
+	if got.GetContent() != "Entry to undelete" {
+		t.Errorf("expected original content, got '%s'", got.GetContent())
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, "trash", entry.GetFilePath())); !os.IsNotExist(err) {
+		t.Error("expected trashed copy to be removed after undo")
+	}
+}
+
+func TestJournalUndo_Update(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Original content", []string{"tag1"})
+
+	if _, err := journal.Update(entry.GetID(), "Updated content", []string{"tag2"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	operation, _, err := journal.Undo()
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if operation != OperationUpdate {
+		t.Errorf("expected operation %s, got %s", OperationUpdate, operation)
+	}
+
+	got, err := journal.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if got.GetContent() != "Original content" {
+		t.Errorf("expected original content, got '%s'", got.GetContent())
+	}
+
+	if !reflect.DeepEqual(got.GetTags(), []string{"tag1"}) {
+		t.Errorf("expected original tags [tag1], got %v", got.GetTags())
+	}
+
+	if ids := journal.index.FindByTag("tag2"); len(ids) != 0 {
+		t.Errorf("expected updated tag to be removed from index, got %v", ids)
+	}
+}
+
+func TestJournalUndo_Touch(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry", []string{})
+
+	touched, err := journal.Touch(entry.GetID(), time.Date(2020, 3, 5, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	if _, _, err := journal.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	got, err := journal.Get(entry.GetID())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !got.GetDate().Equal(entry.GetDate()) || got.GetFilePath() != entry.GetFilePath() {
+		t.Errorf("expected original date and file, got %v at %s", got.GetDate(), got.GetFilePath())
+	}
+
+	if _, err := os.Stat(filepath.Join(journalCfg.Path, "entries", touched.GetFilePath())); !os.IsNotExist(err) {
+		t.Errorf("expected moved file to be removed after undo, got %v", err)
+	}
+}
+
+func TestJournalUndo_NothingToUndo(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	if _, _, err := journal.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestJournalUndo_OnlyOnce(t *testing.T) {
+	journal, _ := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry", []string{})
+
+	if err := journal.Delete(entry.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, _, err := journal.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if _, _, err := journal.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo on second undo, got %v", err)
+	}
+}
+
+func TestJournalReEncrypt_UndoRecord(t *testing.T) {
+	journal, journalCfg := setupTestJournal(t)
+
+	entry := mustAddEntry(t, journal, "Entry to undelete", []string{"work"})
+	if err := journal.Delete(entry.GetID()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	identity2, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+	opts := ReEncryptOptions{Recipients: []string{identity2.Recipient().String()}, SkipVerify: true}
+	if err := journal.ReEncryptWithOptions(opts); err != nil {
+		t.Fatalf("ReEncryptWithOptions failed: %v", err)
+	}
+
+	var op lastOperation
+	if err := journal.storage.LoadLastOperation(&op); err == nil {
+		t.Error("expected the removed recipient to be unable to read the undo record")
+	}
+
+	keyPath := filepath.Join(t.TempDir(), "key2.txt")
+	if err := os.WriteFile(keyPath, []byte(identity2.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY_FILE", keyPath)
+
+	reopened, err := NewJournalFromConfig(journalCfg)
+	if err != nil {
+		t.Fatalf("new recipient failed to open journal: %v", err)
+	}
+	if _, restored, err := reopened.Undo(); err != nil || restored.GetContent() != "Entry to undelete" {
+		t.Fatalf("expected the new recipient to undo the delete, got error %v", err)
+	}
+}
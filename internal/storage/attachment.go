@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AttachmentsDir holds encrypted attachments in one directory per entry ID
+const AttachmentsDir = "attachments"
+
+// attachmentFile is the plaintext form of an attachment before encryption
+// SOPS encrypts YAML string values, so the file contents are stored base64-encoded
+// to keep binary files intact
+type attachmentFile struct {
+	Name string `yaml:"name"`
+	Data string `yaml:"data"`
+}
+
+// ValidateAttachmentName checks that name can be used as a file name inside an entry's attachment directory
+func ValidateAttachmentName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid attachment name: %q", name)
+	}
+	return nil
+}
+
+// GetAttachmentPath returns the absolute path of an entry's attachment
+func (s *Storage) GetAttachmentPath(id string, name string) string {
+	return filepath.Join(s.basePath, AttachmentsDir, id, name)
+}
+
+// SaveAttachment encrypts data and saves it as attachments/<id>/<name>, replacing any existing attachment
+func (s *Storage) SaveAttachment(id string, name string, data []byte) error {
+	if err := ValidateAttachmentName(name); err != nil {
+		return err
+	}
+
+	filePath := s.GetAttachmentPath(id, name)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+
+	file := attachmentFile{
+		Name: name,
+		Data: base64.StdEncoding.EncodeToString(data),
+	}
+	if err := s.encryptor.EncryptYAMLInMemory(&file, filePath); err != nil {
+		return fmt.Errorf("failed to encrypt and save attachment: %w", err)
+	}
+
+	return nil
+}
+
+// LoadAttachment decrypts an entry's attachment and returns its contents
+func (s *Storage) LoadAttachment(id string, name string) ([]byte, error) {
+	if err := ValidateAttachmentName(name); err != nil {
+		return nil, err
+	}
+
+	var file attachmentFile
+	if err := s.encryptor.DecryptYAML(s.GetAttachmentPath(id, name), &file); err != nil {
+		return nil, fmt.Errorf("failed to decrypt attachment: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attachment: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteAttachment removes an entry's attachment
+// A file that is already missing is not an error
+func (s *Storage) DeleteAttachment(id string, name string) error {
+	if err := ValidateAttachmentName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.GetAttachmentPath(id, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAttachments removes all of an entry's attachments, e.g. when the entry is deleted permanently
+// An entry without attachments is not an error
+func (s *Storage) DeleteAttachments(id string) error {
+	if err := ValidateAttachmentName(id); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(filepath.Join(s.basePath, AttachmentsDir, id)); err != nil {
+		return fmt.Errorf("failed to delete attachments: %w", err)
+	}
+
+	return nil
+}
+
+// HasAttachment reports whether an entry's attachment file exists
+func (s *Storage) HasAttachment(id string, name string) bool {
+	if ValidateAttachmentName(name) != nil {
+		return false
+	}
+	_, err := os.Stat(s.GetAttachmentPath(id, name))
+	return err == nil
+}
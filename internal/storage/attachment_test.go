@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestStorageAttachmentRoundTrip(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	// Bytes that are not valid UTF-8 must survive the YAML encoding
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe, '\n', 0x1a}
+
+	if err := storage.SaveAttachment("entry-1", "image.png", data); err != nil {
+		t.Fatalf("SaveAttachment failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(storage.GetAttachmentPath("entry-1", "image.png"))
+	if err != nil {
+		t.Fatalf("failed to read attachment file: %v", err)
+	}
+	if !bytes.Contains(raw, []byte("sops:")) {
+		t.Error("expected attachment file to be SOPS-encrypted")
+	}
+
+	loaded, err := storage.LoadAttachment("entry-1", "image.png")
+	if err != nil {
+		t.Fatalf("LoadAttachment failed: %v", err)
+	}
+	if !bytes.Equal(loaded, data) {
+		t.Errorf("expected %v, got %v", data, loaded)
+	}
+
+	if err := storage.DeleteAttachment("entry-1", "image.png"); err != nil {
+		t.Fatalf("DeleteAttachment failed: %v", err)
+	}
+	if _, err := storage.LoadAttachment("entry-1", "image.png"); err == nil {
+		t.Error("expected error loading a deleted attachment")
+	}
+}
+
+func TestStorageDeleteAttachments(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := storage.SaveAttachment("entry-1", name, []byte(name)); err != nil {
+			t.Fatalf("SaveAttachment failed: %v", err)
+		}
+	}
+	if !storage.HasAttachment("entry-1", "a.txt") {
+		t.Fatal("expected the saved attachment to exist")
+	}
+
+	if err := storage.DeleteAttachments("entry-1"); err != nil {
+		t.Fatalf("DeleteAttachments failed: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if storage.HasAttachment("entry-1", name) {
+			t.Errorf("expected %s to be deleted", name)
+		}
+	}
+
+	if err := storage.DeleteAttachments("entry-1"); err != nil {
+		t.Errorf("expected deleting missing attachments to succeed: %v", err)
+	}
+	if err := storage.DeleteAttachments(".."); err == nil {
+		t.Error("expected an ID outside the attachments directory to be rejected")
+	}
+}
+
+func TestValidateAttachmentName(t *testing.T) {
+	for _, name := range []string{"", ".", "..", "../escape", `dir\file`} {
+		if err := ValidateAttachmentName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+
+	if err := ValidateAttachmentName("notes.txt"); err != nil {
+		t.Errorf("expected notes.txt to be accepted: %v", err)
+	}
+}
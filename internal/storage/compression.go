@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/data-castle/journal/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// CompressionGzip marks an entry file holding the entry as gzip-compressed YAML
+const CompressionGzip = "gzip"
+
+// compressedEntryFile is the plaintext of a compressed entry file; Compression tells it apart from
+// an entry, which has no such field, so compressed and uncompressed files can be mixed in one journal
+type compressedEntryFile struct {
+	Compression string `yaml:"compression"`
+	// Data is the entry YAML, gzip-compressed and base64-encoded
+	Data string `yaml:"data"`
+}
+
+// SetCompression sets whether entries are gzip-compressed before they are encrypted
+// Entries are read either way; existing files keep their format until they are saved again
+func (s *Storage) SetCompression(enabled bool) {
+	s.compress = enabled
+}
+
+// compressEntry marshals entry to YAML and wraps it, gzip-compressed, in a compressedEntryFile
+func compressEntry(entry models.Entry) (*compressedEntryFile, error) {
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress entry: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress entry: %w", err)
+	}
+
+	return &compressedEntryFile{
+		Compression: CompressionGzip,
+		Data:        base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// DecompressEntry returns the entry YAML held in the decrypted entry file data, which is data
+// itself unless the file is a compressed entry file
+func DecompressEntry(data []byte) ([]byte, error) {
+	var file compressedEntryFile
+	if err := yaml.Unmarshal(data, &file); err != nil || file.Compression == "" {
+		// Not a compressed entry; parsing the entry itself reports malformed YAML
+		return data, nil
+	}
+	if file.Compression != CompressionGzip {
+		return nil, fmt.Errorf("unsupported entry compression: %s", file.Compression)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode compressed entry: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress entry: %w", err)
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+
+	plaintext, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress entry: %w", err)
+	}
+	return plaintext, nil
+}
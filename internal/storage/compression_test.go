@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// largeEntryContent is a verbose entry of about 60 KB, like a long diary page with recurring phrases
+func largeEntryContent() string {
+	var sb strings.Builder
+	for i := range 600 {
+		fmt.Fprintf(&sb, "Day %d: went for a walk in the park, wrote some notes, and thought about the week ahead.\n", i)
+	}
+	return sb.String()
+}
+
+func TestStorageCompression_Roundtrip(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	date := time.Date(2024, 11, 19, 14, 30, 0, 0, time.UTC)
+	content := largeEntryContent()
+
+	plain := models.NewEntryV2("plain-entry", date, content, []string{"work"}, "")
+	if err := storage.SaveEntry(plain); err != nil {
+		t.Fatalf("failed to save uncompressed entry: %v", err)
+	}
+
+	storage.SetCompression(true)
+	compressed := models.NewEntryV2("compressed-entry", date, content, []string{"work"}, "")
+	if err := storage.SaveEntry(compressed); err != nil {
+		t.Fatalf("failed to save compressed entry: %v", err)
+	}
+
+	plainInfo, err := os.Stat(filepath.Join(tmpDir, EntriesDir, storage.GetEntryPath(date, "plain-entry")))
+	if err != nil {
+		t.Fatalf("failed to stat uncompressed entry: %v", err)
+	}
+	compressedInfo, err := os.Stat(filepath.Join(tmpDir, EntriesDir, storage.GetEntryPath(date, "compressed-entry")))
+	if err != nil {
+		t.Fatalf("failed to stat compressed entry: %v", err)
+	}
+	t.Logf("uncompressed entry file: %d bytes, compressed: %d bytes", plainInfo.Size(), compressedInfo.Size())
+	if compressedInfo.Size()*4 > plainInfo.Size() {
+		t.Errorf("expected compression to shrink the entry file at least fourfold, got %d bytes from %d", compressedInfo.Size(), plainInfo.Size())
+	}
+
+	// Both formats load whether or not compression is enabled
+	for _, enabled := range []bool{true, false} {
+		storage.SetCompression(enabled)
+		for _, id := range []string{"plain-entry", "compressed-entry"} {
+			loaded, err := storage.LoadEntry(id, storage.GetEntryPath(date, id))
+			if err != nil {
+				t.Fatalf("failed to load %s with compression %v: %v", id, enabled, err)
+			}
+			if loaded.GetContent() != content || loaded.GetID() != id {
+				t.Errorf("%s: loaded entry does not match the saved one", id)
+			}
+		}
+	}
+}
+
+func TestParseEntry_UnsupportedCompression(t *testing.T) {
+	if _, err := ParseEntry([]byte("compression: zstd\ndata: AAAA\n")); err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Errorf("expected an unsupported compression error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// requireSOPS returns the path of the external sops binary, skipping the test when it is not installed
+func requireSOPS(t *testing.T) string {
+	t.Helper()
+	sops, err := exec.LookPath("sops")
+	if err != nil {
+		t.Skip("sops binary not found in PATH")
+	}
+	return sops
+}
+
+func TestStorageEntry_DecryptsWithSOPSCLI(t *testing.T) {
+	sops := requireSOPS(t)
+	storage, tmpDir := setupTestStorage(t)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("failed to initialize storage: %v", err)
+	}
+
+	entryDate := time.Now()
+	entry := models.NewEntryV1("interop-id", entryDate, "Written by journal", []string{"interop"}, storage.GetEntryPath(entryDate, "interop-id"))
+	if err := storage.SaveEntry(entry); err != nil {
+		t.Fatalf("SaveEntry failed: %v", err)
+	}
+
+	// SOPS_AGE_KEY_FILE is set by setupTestStorage and inherited by sops
+	cmd := exec.Command(sops, "--decrypt", filepath.Join(EntriesDir, entry.GetFilePath()))
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sops --decrypt failed: %v", err)
+	}
+
+	decrypted, err := models.ParseYaml(output)
+	if err != nil {
+		t.Fatalf("failed to parse sops output: %v", err)
+	}
+	if decrypted.GetID() != "interop-id" || decrypted.GetContent() != "Written by journal" {
+		t.Errorf("sops decrypted a different entry: id %q, content %q", decrypted.GetID(), decrypted.GetContent())
+	}
+}
+
+func TestStorageLoadEntry_EncryptedWithSOPSCLI(t *testing.T) {
+	sops := requireSOPS(t)
+	storage, tmpDir := setupTestStorage(t)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("failed to initialize storage: %v", err)
+	}
+
+	entryDate := time.Now()
+	entry := models.NewEntryV1("interop-id", entryDate, "Written by sops", []string{"interop"}, storage.GetEntryPath(entryDate, "interop-id"))
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		t.Fatalf("failed to marshal entry: %v", err)
+	}
+
+	relPath := filepath.Join(EntriesDir, entry.GetFilePath())
+	fullPath := filepath.Join(tmpDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+		t.Fatalf("failed to create entry directory: %v", err)
+	}
+	if err := os.WriteFile(fullPath, data, 0600); err != nil {
+		t.Fatalf("failed to write entry: %v", err)
+	}
+
+	// sops finds the journal's .sops.yaml from the working directory and picks the entries rule
+	cmd := exec.Command(sops, "--encrypt", "--in-place", relPath)
+	cmd.Dir = tmpDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sops --encrypt failed: %v\n%s", err, output)
+	}
+
+	loaded, err := storage.LoadEntry("interop-id", entry.GetFilePath())
+	if err != nil {
+		t.Fatalf("LoadEntry failed for a file encrypted by sops: %v", err)
+	}
+	if loaded.GetContent() != "Written by sops" {
+		t.Errorf("expected content %q, got %q", "Written by sops", loaded.GetContent())
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IndexLockFileName is the lock file held while the index is read, modified, and saved
+const IndexLockFileName = ".index.lock"
+
+var (
+	lockRetryInterval = 20 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+	// staleLockAge is how old a lock file must be before it is assumed to be left by a crashed process
+	staleLockAge = time.Minute
+	// lockRefreshInterval is how often a held lock file is touched, well within staleLockAge,
+	// so long operations such as a rebuild or a large import keep their lock
+	lockRefreshInterval = 15 * time.Second
+)
+
+// LockIndex acquires the index lock, waiting while another process holds it
+// The lock is a file created exclusively, so it works on every platform; it holds the PID and a
+// random token of its owner and is touched while held, so only a lock left by a crashed process goes stale
+// The returned function releases the lock, unless another process has taken it over in the meantime
+func (s *Storage) LockIndex() (func() error, error) {
+	lockPath := filepath.Join(s.basePath, IndexLockFileName)
+	deadline := time.Now().Add(lockTimeout)
+
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to create lock token: %w", err)
+	}
+	owner := []byte(fmt.Sprintf("%d %s\n", os.Getpid(), hex.EncodeToString(token)))
+	movedPath := lockPath + ".stale-" + hex.EncodeToString(token)
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, writeErr := file.Write(owner)
+			if err := errors.Join(writeErr, file.Close()); err != nil {
+				return nil, errors.Join(fmt.Errorf("failed to write lock file: %w", err), os.Remove(lockPath))
+			}
+			return holdLock(lockPath, owner), nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			if err := breakStaleLock(lockPath, movedPath, info); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no other journal command is running", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// breakStaleLock removes the lock file at lockPath if it is still the stale one described by stale
+// Several waiters may see the same stale lock, so it is first renamed to movedPath, which only this
+// waiter uses; if another waiter had already replaced it with a fresh lock, that lock is put back
+func breakStaleLock(lockPath, movedPath string, stale os.FileInfo) error {
+	if err := os.Rename(lockPath, movedPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to move stale lock file: %w", err)
+	}
+
+	moved, err := os.Stat(movedPath)
+	if err != nil {
+		return fmt.Errorf("failed to check stale lock file: %w", err)
+	}
+	var restoreErr error
+	if !os.SameFile(moved, stale) || !moved.ModTime().Equal(stale.ModTime()) {
+		// Unlike a rename, a link does not replace a lock that yet another waiter created since
+		if err := os.Link(movedPath, lockPath); err != nil && !os.IsExist(err) {
+			restoreErr = fmt.Errorf("failed to restore lock file: %w", err)
+		}
+	}
+	if err := os.Remove(movedPath); err != nil {
+		return errors.Join(restoreErr, fmt.Errorf("failed to remove stale lock file: %w", err))
+	}
+	return restoreErr
+}
+
+// holdLock keeps the lock file at lockPath fresh until the returned release function is called,
+// which removes the file if it still holds owner and leaves a lock taken over by another process alone
+func holdLock(lockPath string, owner []byte) func() error {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(lockRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if ownsLock(lockPath, owner) {
+					now := time.Now()
+					// A failed refresh only risks the lock going stale, which the release reports
+					_ = os.Chtimes(lockPath, now, now)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			close(done)
+			<-stopped
+
+			data, readErr := os.ReadFile(lockPath)
+			if os.IsNotExist(readErr) {
+				return
+			}
+			if readErr != nil {
+				err = fmt.Errorf("failed to read lock file: %w", readErr)
+				return
+			}
+			if !bytes.Equal(data, owner) {
+				err = fmt.Errorf("lock file %s was taken over by another process", lockPath)
+				return
+			}
+			if removeErr := os.Remove(lockPath); removeErr != nil && !os.IsNotExist(removeErr) {
+				err = fmt.Errorf("failed to remove lock file: %w", removeErr)
+			}
+		})
+		return err
+	}
+}
+
+// ownsLock reports whether the lock file at lockPath exists and holds owner
+func ownsLock(lockPath string, owner []byte) bool {
+	data, err := os.ReadFile(lockPath)
+	return err == nil && bytes.Equal(data, owner)
+}
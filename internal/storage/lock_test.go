@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStorageLockIndex(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	unlock, err := storage.LockIndex()
+	if err != nil {
+		t.Fatalf("LockIndex failed: %v", err)
+	}
+
+	lockPath := filepath.Join(tmpDir, IndexLockFileName)
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Errorf("expected lock file to exist: %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock failed: %v", err)
+	}
+
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("expected lock file to be removed after unlock")
+	}
+}
+
+func TestStorageLockIndex_Timeout(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	originalTimeout := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = originalTimeout })
+
+	unlock, err := storage.LockIndex()
+	if err != nil {
+		t.Fatalf("LockIndex failed: %v", err)
+	}
+	defer func() {
+		if err := unlock(); err != nil {
+			t.Errorf("unlock failed: %v", err)
+		}
+	}()
+
+	if _, err := storage.LockIndex(); err == nil {
+		t.Error("expected timeout while the lock is held")
+	}
+}
+
+func TestStorageLockIndex_StaleLock(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	lockPath := filepath.Join(tmpDir, IndexLockFileName)
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0600); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+
+	unlock, err := storage.LockIndex()
+	if err != nil {
+		t.Fatalf("expected stale lock to be taken over: %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Errorf("unlock failed: %v", err)
+	}
+}
+
+func TestStorageLockIndex_StaleLockConcurrentWaiters(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+	lockPath := filepath.Join(tmpDir, IndexLockFileName)
+
+	for round := 0; round < 5; round++ {
+		if err := os.WriteFile(lockPath, []byte("12345\n"), 0600); err != nil {
+			t.Fatalf("failed to write lock file: %v", err)
+		}
+		old := time.Now().Add(-2 * staleLockAge)
+		if err := os.Chtimes(lockPath, old, old); err != nil {
+			t.Fatalf("failed to age lock file: %v", err)
+		}
+
+		// Every waiter sees the same stale lock, but only one at a time may hold the lock after it
+		var holders, maxHolders atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				unlock, err := storage.LockIndex()
+				if err != nil {
+					t.Errorf("LockIndex failed: %v", err)
+					return
+				}
+				n := holders.Add(1)
+				for {
+					if m := maxHolders.Load(); n <= m || maxHolders.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				holders.Add(-1)
+				if err := unlock(); err != nil {
+					t.Errorf("unlock failed: %v", err)
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		if maxHolders.Load() > 1 {
+			t.Fatalf("round %d: %d waiters held the lock at once", round, maxHolders.Load())
+		}
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read storage directory: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), IndexLockFileName) {
+			t.Errorf("expected no lock files left behind, found %s", entry.Name())
+		}
+	}
+}
+
+func TestBreakStaleLock_AlreadyReplaced(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+	lockPath := filepath.Join(tmpDir, IndexLockFileName)
+
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0600); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+
+	// Two waiters see the stale lock; the first breaks it and takes the lock before the second acts
+	stale, err := os.Stat(lockPath)
+	if err != nil {
+		t.Fatalf("failed to stat lock file: %v", err)
+	}
+	unlock, err := storage.LockIndex()
+	if err != nil {
+		t.Fatalf("LockIndex failed: %v", err)
+	}
+	held, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("failed to read lock file: %v", err)
+	}
+
+	if err := breakStaleLock(lockPath, lockPath+".stale-second", stale); err != nil {
+		t.Fatalf("breakStaleLock failed: %v", err)
+	}
+
+	data, err := os.ReadFile(lockPath)
+	if err != nil || string(data) != string(held) {
+		t.Fatalf("expected the fresh lock to be kept, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(lockPath + ".stale-second"); !os.IsNotExist(err) {
+		t.Errorf("expected the moved lock file to be cleaned up, got %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Errorf("unlock failed: %v", err)
+	}
+}
+
+func TestStorageLockIndex_Refresh(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	originalRefresh := lockRefreshInterval
+	lockRefreshInterval = 10 * time.Millisecond
+	t.Cleanup(func() { lockRefreshInterval = originalRefresh })
+
+	unlock, err := storage.LockIndex()
+	if err != nil {
+		t.Fatalf("LockIndex failed: %v", err)
+	}
+
+	lockPath := filepath.Join(tmpDir, IndexLockFileName)
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+
+	// A long operation keeps its lock fresh, so it is not taken for a crashed process's lock
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := os.Stat(lockPath)
+		if err != nil {
+			t.Fatalf("failed to stat lock file: %v", err)
+		}
+		if time.Since(info.ModTime()) < staleLockAge {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the held lock file to be refreshed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := unlock(); err != nil {
+		t.Errorf("unlock failed: %v", err)
+	}
+}
+
+func TestStorageLockIndex_ReleaseChecksOwner(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	unlock, err := storage.LockIndex()
+	if err != nil {
+		t.Fatalf("LockIndex failed: %v", err)
+	}
+
+	// Another process took over the lock, e.g. after this one stalled past staleLockAge
+	lockPath := filepath.Join(tmpDir, IndexLockFileName)
+	if err := os.WriteFile(lockPath, []byte("12345 other\n"), 0600); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	if err := unlock(); err == nil {
+		t.Error("expected releasing a lock taken over by another process to fail")
+	}
+	if data, err := os.ReadFile(lockPath); err != nil || string(data) != "12345 other\n" {
+		t.Errorf("expected the other process's lock file to be left alone, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// QuarantineLogFileName lists the entry files that failed to load in the last rebuild or verify
+// It is plain text, holding only file paths and error messages, so it can be read without keys
+const QuarantineLogFileName = ".quarantine.log"
+
+// QuarantinedFile is an entry file that could not be loaded, relative to the entries directory
+type QuarantinedFile struct {
+	FilePath string
+	Reason   string
+}
+
+// WriteQuarantineLog replaces the quarantine log with files, removing it when there are none
+func (s *Storage) WriteQuarantineLog(files []QuarantinedFile) error {
+	logPath := filepath.Join(s.basePath, QuarantineLogFileName)
+
+	if len(files) == 0 {
+		if err := os.Remove(logPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove quarantine log: %w", err)
+		}
+		return nil
+	}
+
+	var sb strings.Builder
+	for _, file := range files {
+		// One file per line, so multi-line errors are flattened
+		reason := strings.Join(strings.Fields(file.Reason), " ")
+		fmt.Fprintf(&sb, "%s\t%s\n", file.FilePath, reason)
+	}
+
+	if err := os.WriteFile(logPath, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write quarantine log: %w", err)
+	}
+	return nil
+}
+
+// ReadQuarantineLog returns the files listed in the quarantine log, or none if there is no log
+func (s *Storage) ReadQuarantineLog() ([]QuarantinedFile, error) {
+	f, err := os.Open(filepath.Join(s.basePath, QuarantineLogFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine log: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var files []QuarantinedFile
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		path, reason, _ := strings.Cut(scanner.Text(), "\t")
+		files = append(files, QuarantinedFile{FilePath: path, Reason: reason})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quarantine log: %w", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStorageQuarantineLog(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	files, err := storage.ReadQuarantineLog()
+	if err != nil || len(files) != 0 {
+		t.Fatalf("expected no quarantined files without a log, got %v (err: %v)", files, err)
+	}
+
+	written := []QuarantinedFile{
+		{FilePath: filepath.Join("2024", "11", "a.yaml"), Reason: "failed to decrypt:\n  no identity matched"},
+		{FilePath: filepath.Join("2024", "12", "b.yaml"), Reason: "content hash mismatch"},
+	}
+	if err := storage.WriteQuarantineLog(written); err != nil {
+		t.Fatalf("WriteQuarantineLog failed: %v", err)
+	}
+
+	files, err = storage.ReadQuarantineLog()
+	if err != nil {
+		t.Fatalf("ReadQuarantineLog failed: %v", err)
+	}
+	want := []QuarantinedFile{
+		{FilePath: written[0].FilePath, Reason: "failed to decrypt: no identity matched"},
+		written[1],
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+
+	if err := storage.WriteQuarantineLog(nil); err != nil {
+		t.Fatalf("WriteQuarantineLog failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, QuarantineLogFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected an empty quarantine to remove the log, got %v", err)
+	}
+}
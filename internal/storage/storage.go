@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/data-castle/journal/internal/crypto"
@@ -11,14 +13,24 @@ import (
 )
 
 const (
-	IndexFileName = "index.yaml"
-	EntriesDir    = "entries"
+	IndexFileName  = "index.yaml"
+	EntriesDir     = "entries"
+	LastOpFileName = ".last_op.yaml"
+
+	// ContentIndexFileName holds the encrypted index of content tokens used by content search
+	ContentIndexFileName = "content_index.yaml"
+
+	// DefaultFilenameTemplate names entry files by ID alone
+	DefaultFilenameTemplate = "{id}"
 )
 
 // Storage handles file system operations using SOPS encryption
 type Storage struct {
-	basePath  string
-	encryptor *crypto.Encryptor
+	basePath         string
+	encryptor        *crypto.Encryptor
+	filenameTemplate string
+	// compress gzips entries before encrypting them; see SetCompression
+	compress bool
 }
 
 // NewStorage creates a new SOPS-based storage instance
@@ -43,6 +55,45 @@ func NewStorageWithEncryptor(basePath string, encryptor *crypto.Encryptor) *Stor
 	}
 }
 
+// SetFilenameTemplate sets the template used to name new entry files
+// Supported placeholders are {id} and {date} (YYYY-MM-DD); an empty template restores the default
+func (s *Storage) SetFilenameTemplate(template string) error {
+	if err := ValidateFilenameTemplate(template); err != nil {
+		return err
+	}
+
+	s.filenameTemplate = template
+	return nil
+}
+
+// ValidateFilenameTemplate returns an error if template cannot name entry files; empty is valid
+func ValidateFilenameTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.Contains(template, "{id}") {
+		return fmt.Errorf("filename template %q must contain {id}", template)
+	}
+	if strings.ContainsAny(template, `/\`) {
+		return fmt.Errorf("filename template %q must not contain path separators", template)
+	}
+	return nil
+}
+
+// FilenameTemplate returns the template used to name new entry files
+func (s *Storage) FilenameTemplate() string {
+	if s.filenameTemplate == "" {
+		return DefaultFilenameTemplate
+	}
+	return s.filenameTemplate
+}
+
+// SetKeyFiles sets the age key files or directories used to decrypt entries and the index
+// With no paths, SOPS looks up keys itself, e.g. from SOPS_AGE_KEY_FILE
+func (s *Storage) SetKeyFiles(paths []string) error {
+	return s.encryptor.SetKeyFiles(paths)
+}
+
 // GetBasePath returns the base path of the storage
 func (s *Storage) GetBasePath() string {
 	return s.basePath
@@ -55,6 +106,11 @@ func (s *Storage) Initialize() error {
 		return fmt.Errorf("failed to create entries directory: %w", err)
 	}
 
+	return s.CheckInitialized()
+}
+
+// CheckInitialized returns an error if the journal has no .sops.yaml, without creating anything
+func (s *Storage) CheckInitialized() error {
 	sopsConfigPath := filepath.Join(s.basePath, ".sops.yaml")
 	if _, err := os.Stat(sopsConfigPath); os.IsNotExist(err) {
 		return fmt.Errorf(".sops.yaml not found in %s - please initialize journal with recipients first", s.basePath)
@@ -64,35 +120,78 @@ func (s *Storage) Initialize() error {
 }
 
 // SaveEntry saves an entry to disk as encrypted YAML
+// New entries are placed by GetEntryPath using the entry date as given, so callers must not
+// convert it to another timezone first
 func (s *Storage) SaveEntry(entry models.Entry) error {
-	year := entry.GetDate().Format("2006")
-	month := entry.GetDate().Format("01")
+	// Existing entries keep their file even if the filename template has changed since
+	relFilePath := entry.GetFilePath()
+	if relFilePath == "" {
+		relFilePath = s.GetEntryPath(entry.GetDate(), entry.GetID())
+	}
 
-	dirPath := filepath.Join(s.basePath, EntriesDir, year, month)
-	if err := os.MkdirAll(dirPath, 0700); err != nil {
+	return s.SaveEntryAt(entry, relFilePath)
+}
+
+// SaveEntryAt saves an entry as encrypted YAML at the given path relative to the entries directory
+// Useful when rewriting an existing file in place, e.g. to re-encrypt it
+func (s *Storage) SaveEntryAt(entry models.Entry, relFilePath string) error {
+	return s.saveEntryFile(entry, filepath.Join(s.basePath, EntriesDir, relFilePath))
+}
+
+// saveEntryFile encrypts entry, compressed if enabled, and writes it to filePath
+func (s *Storage) saveEntryFile(entry models.Entry, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	filename := fmt.Sprintf("%s.yaml", entry.GetID())
-	filePath := filepath.Join(dirPath, filename)
+	var plaintext any = entry
+	if s.compress {
+		compressed, err := compressEntry(entry)
+		if err != nil {
+			return err
+		}
+		plaintext = compressed
+	}
 
-	if err := s.encryptor.EncryptYAMLInMemory(entry, filePath); err != nil {
+	if err := s.encryptor.EncryptYAMLInMemory(plaintext, filePath); err != nil {
 		return fmt.Errorf("failed to encrypt and save entry: %w", err)
 	}
 
 	return nil
 }
 
+// EntryFilePath returns the absolute path of an entry file given relative to the entries directory,
+// as stored in the index, rejecting paths that lead outside the entries directory
+func (s *Storage) EntryFilePath(relFilePath string) (string, error) {
+	if !filepath.IsLocal(relFilePath) {
+		return "", fmt.Errorf("invalid entry path %q: must be relative to the %s directory", relFilePath, EntriesDir)
+	}
+	return filepath.Join(s.basePath, EntriesDir, relFilePath), nil
+}
+
 // LoadEntry loads an entry from disk
 func (s *Storage) LoadEntry(id string, relFilePath string) (models.Entry, error) {
-	fullPath := filepath.Join(s.basePath, EntriesDir, relFilePath)
+	return s.loadEntryFile(filepath.Join(s.basePath, EntriesDir, relFilePath))
+}
 
+// loadEntryFile decrypts and parses the entry file at fullPath
+func (s *Storage) loadEntryFile(fullPath string) (models.Entry, error) {
 	decryptedData, err := s.encryptor.DecryptFile(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt entry: %w", err)
 	}
 
-	entry, err := models.ParseYaml(decryptedData)
+	return ParseEntry(decryptedData)
+}
+
+// ParseEntry parses the decrypted contents of an entry file, decompressing it if needed
+func ParseEntry(data []byte) (models.Entry, error) {
+	data, err := DecompressEntry(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := models.ParseYaml(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse entry: %w", err)
 	}
@@ -101,10 +200,11 @@ func (s *Storage) LoadEntry(id string, relFilePath string) (models.Entry, error)
 }
 
 // DeleteEntry deletes an entry from disk
+// A file that is already missing is not an error, so stale index entries can still be cleaned up
 func (s *Storage) DeleteEntry(relFilePath string) error {
 	fullPath := filepath.Join(s.basePath, EntriesDir, relFilePath)
 
-	if err := os.Remove(fullPath); err != nil {
+	if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete entry file: %w", err)
 	}
 
@@ -123,39 +223,114 @@ func (s *Storage) SaveIndex(index *models.Index) error {
 }
 
 // LoadIndex loads the index from disk
-func (s *Storage) LoadIndex() (*models.Index, error) {
+// The inconsistencies found by Index.Validate are returned alongside it; they do not fail the load
+func (s *Storage) LoadIndex() (*models.Index, []error, error) {
 	indexPath := filepath.Join(s.basePath, IndexFileName)
 
 	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
 		// Return new empty index
-		return models.NewIndex(), nil
+		return models.NewIndex(), nil, nil
 	}
 
 	var index models.Index
 	if err := s.encryptor.DecryptYAML(indexPath, &index); err != nil {
-		return nil, fmt.Errorf("failed to decrypt and parse index: %w", err)
+		return nil, nil, fmt.Errorf("failed to decrypt and parse index: %w", err)
+	}
+
+	return &index, index.Validate(), nil
+}
+
+// SaveContentIndex saves the content index to disk as encrypted YAML
+func (s *Storage) SaveContentIndex(index *models.ContentIndex) error {
+	indexPath := filepath.Join(s.basePath, ContentIndexFileName)
+
+	if err := s.encryptor.EncryptYAMLInMemory(index, indexPath); err != nil {
+		return fmt.Errorf("failed to encrypt and save content index: %w", err)
+	}
+
+	return nil
+}
+
+// LoadContentIndex loads the content index from disk, or returns an empty one if there is none
+func (s *Storage) LoadContentIndex() (*models.ContentIndex, error) {
+	indexPath := filepath.Join(s.basePath, ContentIndexFileName)
+
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		return models.NewContentIndex(), nil
+	}
+
+	var index models.ContentIndex
+	if err := s.encryptor.DecryptYAML(indexPath, &index); err != nil {
+		return nil, fmt.Errorf("failed to decrypt and parse content index: %w", err)
 	}
 
 	return &index, nil
 }
 
+// HasContentIndex reports whether a content index has been saved
+func (s *Storage) HasContentIndex() bool {
+	_, err := os.Stat(filepath.Join(s.basePath, ContentIndexFileName))
+	return err == nil
+}
+
+// SaveLastOperation saves the undo record for the most recent destructive operation as encrypted YAML
+func (s *Storage) SaveLastOperation(op any) error {
+	opPath := filepath.Join(s.basePath, LastOpFileName)
+
+	if err := s.encryptor.EncryptYAMLInMemory(op, opPath); err != nil {
+		return fmt.Errorf("failed to encrypt and save last operation: %w", err)
+	}
+
+	return nil
+}
+
+// LoadLastOperation loads the undo record into target
+// Returns an error wrapping os.ErrNotExist if no operation has been recorded
+func (s *Storage) LoadLastOperation(target any) error {
+	opPath := filepath.Join(s.basePath, LastOpFileName)
+
+	if _, err := os.Stat(opPath); err != nil {
+		return fmt.Errorf("failed to find last operation: %w", err)
+	}
+
+	if err := s.encryptor.DecryptYAML(opPath, target); err != nil {
+		return fmt.Errorf("failed to decrypt and parse last operation: %w", err)
+	}
+
+	return nil
+}
+
+// ClearLastOperation removes the undo record
+func (s *Storage) ClearLastOperation() error {
+	opPath := filepath.Join(s.basePath, LastOpFileName)
+
+	if err := os.Remove(opPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove last operation: %w", err)
+	}
+
+	return nil
+}
+
 // ListAllEntries recursively lists all entry files
 func (s *Storage) ListAllEntries() ([]string, error) {
-	var entries []string
+	return listYAMLFiles(filepath.Join(s.basePath, EntriesDir))
+}
 
-	entriesPath := filepath.Join(s.basePath, EntriesDir)
+// listYAMLFiles recursively lists .yaml files under root, relative to root
+func listYAMLFiles(root string) ([]string, error) {
+	var files []string
 
-	err := filepath.Walk(entriesPath, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if !info.IsDir() && filepath.Ext(path) == ".yaml" {
-			relPath, err := filepath.Rel(entriesPath, path)
+			relPath, err := filepath.Rel(root, path)
 			if err != nil {
 				return err
 			}
-			entries = append(entries, relPath)
+			files = append(files, relPath)
 		}
 
 		return nil
@@ -165,13 +340,41 @@ func (s *Storage) ListAllEntries() ([]string, error) {
 		return nil, fmt.Errorf("failed to list entries: %w", err)
 	}
 
-	return entries, nil
+	return files, nil
 }
 
 // GetEntryPath returns the relative path for an entry file
+// The year and month directories and {date} follow models.DateKey, so they use the date's own
+// location and agree with the entry's day in the index
 func (s *Storage) GetEntryPath(date time.Time, id string) string {
-	year := date.Format("2006")
-	month := date.Format("01")
-	filename := fmt.Sprintf("%s.yaml", id)
-	return filepath.Join(year, month, filename)
+	dateKey := models.DateKey(date)
+	year := dateKey[:4]
+	month := dateKey[5:7]
+	name := strings.NewReplacer(
+		"{id}", id,
+		"{date}", dateKey,
+	).Replace(s.FilenameTemplate())
+	return filepath.Join(year, month, name+".yaml")
+}
+
+// EntryIDFromPath extracts the entry ID from an entry file path
+// Files that do not match the current template, e.g. those written before it changed,
+// are assumed to be named by ID alone
+func (s *Storage) EntryIDFromPath(relFilePath string) string {
+	name := strings.TrimSuffix(filepath.Base(relFilePath), ".yaml")
+
+	pattern := regexp.QuoteMeta(s.FilenameTemplate())
+	pattern = strings.Replace(pattern, regexp.QuoteMeta("{id}"), `(?P<id>.+)`, 1)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{id}"), `.+`)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{date}"), `\d{4}-\d{2}-\d{2}`)
+
+	re, err := regexp.Compile("^" + pattern + "$")
+	if err != nil {
+		return name
+	}
+	match := re.FindStringSubmatch(name)
+	if match == nil {
+		return name
+	}
+	return match[re.SubexpIndex("id")]
 }
@@ -97,6 +97,24 @@ func TestStorageInitialize_MissingSOPSConfig(t *testing.T) {
 	}
 }
 
+func TestStorageCheckInitialized(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	if err := storage.CheckInitialized(); err != nil {
+		t.Errorf("CheckInitialized failed: %v", err)
+	}
+
+	entriesPath := filepath.Join(tmpDir, EntriesDir)
+	if _, err := os.Stat(entriesPath); !os.IsNotExist(err) {
+		t.Error("CheckInitialized must not create the entries directory")
+	}
+
+	missing := &Storage{basePath: t.TempDir()}
+	if err := missing.CheckInitialized(); err == nil {
+		t.Error("expected error when .sops.yaml is missing")
+	}
+}
+
 func TestStorageSaveAndLoadEntry(t *testing.T) {
 	storage, _ := setupTestStorage(t)
 
@@ -158,6 +176,19 @@ func TestStorageDeleteEntry(t *testing.T) {
 	}
 }
 
+func TestStorageDeleteEntry_AlreadyMissing(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("failed to initialize storage: %v", err)
+	}
+
+	err := storage.DeleteEntry(filepath.Join("2025", "11", "missing-id.yaml"))
+	if err != nil {
+		t.Errorf("expected no error when deleting a missing entry, got: %v", err)
+	}
+}
+
 func TestStorageSaveAndLoadIndex(t *testing.T) {
 	storage, _ := setupTestStorage(t)
 
@@ -179,7 +210,7 @@ func TestStorageSaveAndLoadIndex(t *testing.T) {
 		t.Fatalf("SaveIndex failed: %v", err)
 	}
 
-	loadedIndex, err := storage.LoadIndex()
+	loadedIndex, _, err := storage.LoadIndex()
 	if err != nil {
 		t.Fatalf("LoadIndex failed: %v", err)
 	}
@@ -198,6 +229,60 @@ func TestStorageSaveAndLoadIndex(t *testing.T) {
 	}
 }
 
+func TestStorageSave_FailureKeepsOldFiles(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for root")
+	}
+
+	storage, tmpDir := setupTestStorage(t)
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("failed to initialize storage: %v", err)
+	}
+
+	entryDate := time.Now()
+	entry := models.NewEntryV1("test-id", entryDate, "Old content", nil, storage.GetEntryPath(entryDate, "test-id"))
+	if err := storage.SaveEntry(entry); err != nil {
+		t.Fatalf("SaveEntry failed: %v", err)
+	}
+	index := models.NewIndex()
+	index.Add(entry)
+	if err := storage.SaveIndex(index); err != nil {
+		t.Fatalf("SaveIndex failed: %v", err)
+	}
+
+	// Read-only directories make the temporary files fail to be created, as a full disk would
+	entryDir := filepath.Dir(filepath.Join(tmpDir, EntriesDir, entry.GetFilePath()))
+	for _, dir := range []string{tmpDir, entryDir} {
+		if err := os.Chmod(dir, 0500); err != nil {
+			t.Fatalf("failed to chmod %s: %v", dir, err)
+		}
+		t.Cleanup(func() { _ = os.Chmod(dir, 0700) })
+	}
+
+	changed := models.NewEntryV1("test-id", entryDate, "New content", nil, entry.GetFilePath())
+	if err := storage.SaveEntry(changed); err == nil {
+		t.Error("expected SaveEntry to fail in a read-only directory")
+	}
+	if err := storage.SaveIndex(models.NewIndex()); err == nil {
+		t.Error("expected SaveIndex to fail in a read-only directory")
+	}
+
+	loadedEntry, err := storage.LoadEntry("test-id", entry.GetFilePath())
+	if err != nil {
+		t.Fatalf("expected the old entry file to stay readable: %v", err)
+	}
+	if loadedEntry.GetContent() != "Old content" {
+		t.Errorf("expected old entry content, got %q", loadedEntry.GetContent())
+	}
+	loadedIndex, _, err := storage.LoadIndex()
+	if err != nil {
+		t.Fatalf("expected the old index to stay readable: %v", err)
+	}
+	if _, ok := loadedIndex.Entries["test-id"]; !ok {
+		t.Error("expected the old index to be untouched")
+	}
+}
+
 func TestStorageLoadIndex_Empty(t *testing.T) {
 	storage, _ := setupTestStorage(t)
 
@@ -205,7 +290,7 @@ func TestStorageLoadIndex_Empty(t *testing.T) {
 		t.Fatalf("failed to initialize storage: %v", err)
 	}
 
-	index, err := storage.LoadIndex()
+	index, _, err := storage.LoadIndex()
 	if err != nil {
 		t.Fatalf("LoadIndex failed: %v", err)
 	}
@@ -216,6 +301,31 @@ func TestStorageLoadIndex_Empty(t *testing.T) {
 
 }
 
+func TestStorageLoadIndex_Inconsistencies(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("failed to initialize storage: %v", err)
+	}
+
+	index := models.NewIndex()
+	index.ByTag["ghost"] = []string{"missing-id"}
+	if err := storage.SaveIndex(index); err != nil {
+		t.Fatalf("SaveIndex failed: %v", err)
+	}
+
+	loaded, inconsistencies, err := storage.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if loaded == nil {
+		t.Fatal("expected the index to load despite its inconsistencies")
+	}
+	if len(inconsistencies) != 1 {
+		t.Errorf("expected 1 inconsistency, got %v", inconsistencies)
+	}
+}
+
 func TestStorageListAllEntries(t *testing.T) {
 	storage, _ := setupTestStorage(t)
 
@@ -260,3 +370,106 @@ func TestStorageGetEntryPath(t *testing.T) {
 		t.Errorf("expected path '%s', got '%s'", expected, path)
 	}
 }
+
+func TestStorageGetEntryPath_DateTemplate(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	if err := storage.SetFilenameTemplate("{date}-{id}"); err != nil {
+		t.Fatalf("SetFilenameTemplate failed: %v", err)
+	}
+
+	date := time.Date(2025, 11, 26, 15, 30, 0, 0, time.UTC)
+	path := storage.GetEntryPath(date, "test-id-123")
+
+	expected := filepath.Join("2025", "11", "2025-11-26-test-id-123.yaml")
+	if path != expected {
+		t.Errorf("expected path '%s', got '%s'", expected, path)
+	}
+}
+
+func TestStorageGetEntryPath_NonUTCMonthBoundary(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	if err := storage.SetFilenameTemplate("{date}-{id}"); err != nil {
+		t.Fatalf("SetFilenameTemplate failed: %v", err)
+	}
+
+	// 23:30 on January 31 in New York is already February 1 in UTC
+	newYork := time.FixedZone("EST", -5*60*60)
+	date := time.Date(2024, 1, 31, 23, 30, 0, 0, newYork)
+
+	expected := filepath.Join("2024", "01", "2024-01-31-test-id-123.yaml")
+	if path := storage.GetEntryPath(date, "test-id-123"); path != expected {
+		t.Errorf("expected path '%s', got '%s'", expected, path)
+	}
+
+	entry := models.NewEntryV2("test-id-123", date, "Late night entry", nil, "")
+	if err := storage.SaveEntry(entry); err != nil {
+		t.Fatalf("SaveEntry failed: %v", err)
+	}
+
+	loaded, err := storage.LoadEntry("test-id-123", expected)
+	if err != nil {
+		t.Fatalf("LoadEntry failed: %v", err)
+	}
+	if path := storage.GetEntryPath(loaded.GetDate(), "test-id-123"); path != expected {
+		t.Errorf("expected the stored date to keep its offset and path '%s', got '%s'", expected, path)
+	}
+	if models.DateKey(loaded.GetDate()) != "2024-01-31" {
+		t.Errorf("expected day 2024-01-31 after loading, got %s", models.DateKey(loaded.GetDate()))
+	}
+}
+
+func TestStorageEntryFilePath(t *testing.T) {
+	storage, tmpDir := setupTestStorage(t)
+
+	path, err := storage.EntryFilePath(filepath.Join("2024", "11", "test-id.yaml"))
+	if err != nil {
+		t.Fatalf("EntryFilePath failed: %v", err)
+	}
+	if expected := filepath.Join(tmpDir, EntriesDir, "2024", "11", "test-id.yaml"); path != expected {
+		t.Errorf("expected path '%s', got '%s'", expected, path)
+	}
+
+	for _, relFilePath := range []string{"../index.yaml", "/etc/passwd", "2024/../../.sops.yaml", ""} {
+		if _, err := storage.EntryFilePath(relFilePath); err == nil {
+			t.Errorf("expected error for path %q", relFilePath)
+		}
+	}
+}
+
+func TestStorageSetFilenameTemplate_Invalid(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	for _, template := range []string{"{date}", "{date}/{id}"} {
+		if err := storage.SetFilenameTemplate(template); err == nil {
+			t.Errorf("expected error for template %q", template)
+		}
+	}
+}
+
+func TestStorageEntryIDFromPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		expected string
+	}{
+		{"default template", "", filepath.Join("2025", "11", "abc-123.yaml"), "abc-123"},
+		{"date prefix", "{date}-{id}", filepath.Join("2025", "11", "2025-11-26-abc-123.yaml"), "abc-123"},
+		{"date suffix", "{id}_{date}", filepath.Join("2025", "11", "abc-123_2025-11-26.yaml"), "abc-123"},
+		{"file from before template", "{date}-{id}", filepath.Join("2025", "11", "abc-123.yaml"), "abc-123"},
+	}
+
+	storage, _ := setupTestStorage(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := storage.SetFilenameTemplate(tt.template); err != nil {
+				t.Fatalf("SetFilenameTemplate failed: %v", err)
+			}
+			if id := storage.EntryIDFromPath(tt.path); id != tt.expected {
+				t.Errorf("expected ID '%s', got '%s'", tt.expected, id)
+			}
+		})
+	}
+}
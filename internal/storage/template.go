@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TemplatesDir holds encrypted entry templates, one file per template
+const TemplatesDir = "templates"
+
+// templateFile is the plaintext form of a template before encryption
+type templateFile struct {
+	Name    string `yaml:"name"`
+	Content string `yaml:"content"`
+}
+
+// ValidateTemplateName checks that name can be used as a template file name
+func ValidateTemplateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid template name: %q", name)
+	}
+	return nil
+}
+
+// GetTemplatePath returns the absolute path of a template
+func (s *Storage) GetTemplatePath(name string) string {
+	return filepath.Join(s.basePath, TemplatesDir, name+".yaml")
+}
+
+// SaveTemplate encrypts content and saves it as templates/<name>.yaml, replacing any existing template
+func (s *Storage) SaveTemplate(name string, content string) error {
+	if err := ValidateTemplateName(name); err != nil {
+		return err
+	}
+
+	filePath := s.GetTemplatePath(name)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return fmt.Errorf("failed to create templates directory: %w", err)
+	}
+
+	file := templateFile{Name: name, Content: content}
+	if err := s.encryptor.EncryptYAMLInMemory(&file, filePath); err != nil {
+		return fmt.Errorf("failed to encrypt and save template: %w", err)
+	}
+
+	return nil
+}
+
+// LoadTemplate decrypts a template and returns its content
+func (s *Storage) LoadTemplate(name string) (string, error) {
+	if err := ValidateTemplateName(name); err != nil {
+		return "", err
+	}
+
+	var file templateFile
+	if err := s.encryptor.DecryptYAML(s.GetTemplatePath(name), &file); err != nil {
+		return "", fmt.Errorf("failed to decrypt template: %w", err)
+	}
+
+	return file.Content, nil
+}
+
+// ListTemplates returns the sorted names of all saved templates
+func (s *Storage) ListTemplates() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.basePath, TemplatesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// DeleteTemplate removes a template
+// A template that is already missing is not an error
+func (s *Storage) DeleteTemplate(name string) error {
+	if err := ValidateTemplateName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.GetTemplatePath(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	return nil
+}
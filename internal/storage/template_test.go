@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestStorageTemplateRoundTrip(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	content := "Grateful for:\n\nMood:"
+	if err := storage.SaveTemplate("daily", content); err != nil {
+		t.Fatalf("SaveTemplate failed: %v", err)
+	}
+	if err := storage.SaveTemplate("gratitude", "Three good things:"); err != nil {
+		t.Fatalf("SaveTemplate failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(storage.GetTemplatePath("daily"))
+	if err != nil {
+		t.Fatalf("failed to read template file: %v", err)
+	}
+	if !bytes.Contains(raw, []byte("sops:")) || bytes.Contains(raw, []byte("Grateful")) {
+		t.Error("expected template file to be SOPS-encrypted")
+	}
+
+	loaded, err := storage.LoadTemplate("daily")
+	if err != nil {
+		t.Fatalf("LoadTemplate failed: %v", err)
+	}
+	if loaded != content {
+		t.Errorf("expected %q, got %q", content, loaded)
+	}
+
+	names, err := storage.ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"daily", "gratitude"}) {
+		t.Errorf("expected [daily gratitude], got %v", names)
+	}
+
+	if err := storage.DeleteTemplate("daily"); err != nil {
+		t.Fatalf("DeleteTemplate failed: %v", err)
+	}
+	if _, err := storage.LoadTemplate("daily"); err == nil {
+		t.Error("expected error loading a deleted template")
+	}
+}
+
+func TestStorageListTemplates_NoDirectory(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	names, err := storage.ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("expected no templates, got %v", names)
+	}
+}
+
+func TestValidateTemplateName(t *testing.T) {
+	for _, name := range []string{"", ".", "..", ".hidden", "a/b", `a\b`} {
+		if err := ValidateTemplateName(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+	if err := ValidateTemplateName("weekly-review"); err != nil {
+		t.Errorf("expected weekly-review to be valid: %v", err)
+	}
+}
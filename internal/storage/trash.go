@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+// TrashDir holds soft-deleted entries, mirroring the year/month layout of EntriesDir
+const TrashDir = "trash"
+
+// MoveToTrash moves an entry file into the trash, keeping its relative path
+// A file that is already missing is not an error, so stale index entries can still be cleaned up
+func (s *Storage) MoveToTrash(relFilePath string) error {
+	src := filepath.Join(s.basePath, EntriesDir, relFilePath)
+	dst := filepath.Join(s.basePath, TrashDir, relFilePath)
+
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move entry to trash: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreFromTrash moves a trashed entry file back into the entries directory
+func (s *Storage) RestoreFromTrash(relFilePath string) error {
+	src := filepath.Join(s.basePath, TrashDir, relFilePath)
+	dst := filepath.Join(s.basePath, EntriesDir, relFilePath)
+
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("entry file already exists: %s", relFilePath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to restore entry from trash: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTrashedEntry permanently deletes a single trashed entry file
+// A file that is already missing is not an error
+func (s *Storage) DeleteTrashedEntry(relFilePath string) error {
+	fullPath := filepath.Join(s.basePath, TrashDir, relFilePath)
+
+	if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete trashed entry file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadTrashedEntry loads an entry from the trash
+func (s *Storage) LoadTrashedEntry(relFilePath string) (models.Entry, error) {
+	return s.loadEntryFile(filepath.Join(s.basePath, TrashDir, relFilePath))
+}
+
+// SaveTrashedEntry saves an entry as encrypted YAML at the given path relative to the trash directory,
+// e.g. to re-encrypt a trashed entry in place
+func (s *Storage) SaveTrashedEntry(entry models.Entry, relFilePath string) error {
+	return s.saveEntryFile(entry, filepath.Join(s.basePath, TrashDir, relFilePath))
+}
+
+// ListTrash recursively lists all trashed entry files
+func (s *Storage) ListTrash() ([]string, error) {
+	trashPath := filepath.Join(s.basePath, TrashDir)
+	if _, err := os.Stat(trashPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return listYAMLFiles(trashPath)
+}
+
+// FindTrashedEntry returns the relative path of the trashed file for an entry ID
+func (s *Storage) FindTrashedEntry(id string) (string, error) {
+	files, err := s.ListTrash()
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		if s.EntryIDFromPath(file) == id {
+			return file, nil
+		}
+	}
+
+	return "", fmt.Errorf("entry not found in trash: %s", id)
+}
+
+// EmptyTrash permanently deletes all trashed entries and returns how many were removed
+func (s *Storage) EmptyTrash() (int, error) {
+	files, err := s.ListTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(filepath.Join(s.basePath, TrashDir)); err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	return len(files), nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/data-castle/journal/pkg/models"
+)
+
+func TestStorageTrashRoundtrip(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	if err := storage.Initialize(); err != nil {
+		t.Fatalf("failed to initialize storage: %v", err)
+	}
+
+	entryID := "test-trash-id"
+	entryDate := time.Now()
+	entry := models.NewEntryV1(entryID, entryDate, "Entry to trash", []string{}, storage.GetEntryPath(entryDate, entryID))
+
+	if err := storage.SaveEntry(entry); err != nil {
+		t.Fatalf("SaveEntry failed: %v", err)
+	}
+
+	if err := storage.MoveToTrash(entry.GetFilePath()); err != nil {
+		t.Fatalf("MoveToTrash failed: %v", err)
+	}
+
+	if _, err := storage.LoadEntry(entryID, entry.GetFilePath()); err == nil {
+		t.Error("expected error when loading trashed entry from entries directory")
+	}
+
+	relPath, err := storage.FindTrashedEntry(entryID)
+	if err != nil {
+		t.Fatalf("FindTrashedEntry failed: %v", err)
+	}
+
+	if relPath != entry.GetFilePath() {
+		t.Errorf("expected trashed path %s, got %s", entry.GetFilePath(), relPath)
+	}
+
+	trashed, err := storage.LoadTrashedEntry(relPath)
+	if err != nil {
+		t.Fatalf("LoadTrashedEntry failed: %v", err)
+	}
+
+	if trashed.GetContent() != "Entry to trash" {
+		t.Errorf("expected trashed content, got '%s'", trashed.GetContent())
+	}
+
+	if err := storage.RestoreFromTrash(relPath); err != nil {
+		t.Fatalf("RestoreFromTrash failed: %v", err)
+	}
+
+	if _, err := storage.LoadEntry(entryID, entry.GetFilePath()); err != nil {
+		t.Errorf("expected restored entry to load: %v", err)
+	}
+}
+
+func TestStorageListTrash_NoTrashDir(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	files, err := storage.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash failed: %v", err)
+	}
+
+	if len(files) != 0 {
+		t.Errorf("expected no trashed files, got %d", len(files))
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ContentIndex maps lowercased content tokens to the IDs of entries containing them
+// It holds entry content, so it is stored encrypted like the entries themselves
+type ContentIndex struct {
+	Version string              `json:"version" yaml:"version"`
+	Tokens  map[string][]string `json:"tokens" yaml:"tokens"` // token -> []ID
+	// Entries records which entries have been tokenized; entries missing from it must be
+	// searched by decrypting them
+	Entries map[string]bool `json:"entries" yaml:"entries"`
+}
+
+// NewContentIndex creates a new empty content index
+func NewContentIndex() *ContentIndex {
+	return &ContentIndex{
+		Version: "1.0",
+		Tokens:  make(map[string][]string),
+		Entries: make(map[string]bool),
+	}
+}
+
+// Add records the tokens of text for the entry id, replacing any tokens recorded before
+func (ci *ContentIndex) Add(id string, text string) {
+	ci.Remove(id)
+	if ci.Tokens == nil {
+		ci.Tokens = make(map[string][]string)
+	}
+	if ci.Entries == nil {
+		ci.Entries = make(map[string]bool)
+	}
+
+	for _, token := range Tokenize(text) {
+		ci.Tokens[token] = appendUnique(ci.Tokens[token], id)
+	}
+	ci.Entries[id] = true
+}
+
+// Remove removes the entry id from the content index
+func (ci *ContentIndex) Remove(id string) {
+	if !ci.Entries[id] {
+		return
+	}
+	delete(ci.Entries, id)
+
+	for token, ids := range ci.Tokens {
+		if !slices.Contains(ids, id) {
+			continue
+		}
+		if ids = removeString(ids, id); len(ids) == 0 {
+			delete(ci.Tokens, token)
+		} else {
+			ci.Tokens[token] = ids
+		}
+	}
+}
+
+// Covers reports whether the entry id has been tokenized
+func (ci *ContentIndex) Covers(id string) bool {
+	return ci.Entries[id]
+}
+
+// Candidates returns the sorted IDs of tokenized entries that may contain query, ignoring case
+// Every token of query must be part of some token of the entry, so the result is a superset of the
+// entries containing query and each candidate must still be checked
+// ok is false if query has no tokens, in which case the index cannot narrow the search
+func (ci *ContentIndex) Candidates(query string) (ids []string, ok bool) {
+	queryTokens := Tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil, false
+	}
+
+	var matches map[string]bool
+	for _, queryToken := range queryTokens {
+		found := make(map[string]bool)
+		for token, tokenIDs := range ci.Tokens {
+			if !strings.Contains(token, queryToken) {
+				continue
+			}
+			for _, id := range tokenIDs {
+				if matches == nil || matches[id] {
+					found[id] = true
+				}
+			}
+		}
+		matches = found
+	}
+
+	return slices.Sorted(maps.Keys(matches)), true
+}
+
+// Tokenize splits text into its unique lowercased runs of letters and digits, in order of appearance
+func Tokenize(text string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[field] {
+			tokens = append(tokens, field)
+			seen[field] = true
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Walked the DOG; the dog's park, 5km!")
+	want := []string{"walked", "the", "dog", "s", "park", "5km"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if got := Tokenize(" -- "); got != nil {
+		t.Errorf("Expected no tokens, got %v", got)
+	}
+}
+
+func TestContentIndexCandidates(t *testing.T) {
+	ci := NewContentIndex()
+	ci.Add("entry-1", "Walked the dog in the park")
+	ci.Add("entry-2", "Parked the car downtown")
+	ci.Add("entry-3", "Quiet day at home")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"park", []string{"entry-1", "entry-2"}},
+		{"the PARK", []string{"entry-1", "entry-2"}},
+		{"dog in", []string{"entry-1"}},
+		{"garden", []string{}},
+	}
+	for _, tt := range tests {
+		got, ok := ci.Candidates(tt.query)
+		if !ok {
+			t.Fatalf("Candidates(%q) could not use the index", tt.query)
+		}
+		if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("Candidates(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if _, ok := ci.Candidates("!!"); ok {
+		t.Error("Expected a query without tokens not to use the index")
+	}
+}
+
+func TestContentIndexAddReplacesAndRemove(t *testing.T) {
+	ci := NewContentIndex()
+	ci.Add("entry-1", "first draft")
+	ci.Add("entry-1", "final version")
+
+	if got, _ := ci.Candidates("draft"); len(got) != 0 {
+		t.Errorf("Expected old tokens to be replaced, got %v", got)
+	}
+	if got, _ := ci.Candidates("final"); !reflect.DeepEqual(got, []string{"entry-1"}) {
+		t.Errorf("Expected entry-1 for new tokens, got %v", got)
+	}
+
+	ci.Remove("entry-1")
+	if ci.Covers("entry-1") {
+		t.Error("Expected removed entry not to be covered")
+	}
+	if len(ci.Tokens) != 0 {
+		t.Errorf("Expected no tokens after removal, got %v", ci.Tokens)
+	}
+}
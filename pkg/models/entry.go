@@ -1,7 +1,12 @@
 package models
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -9,7 +14,22 @@ import (
 
 const (
 	// CurrentVersion is the latest version of the Entry model
-	CurrentVersion = 1
+	CurrentVersion = 2
+
+	// MinMood and MaxMood bound an entry's mood rating; zero means the entry is unrated
+	MinMood = 1
+	MaxMood = 5
+)
+
+var (
+	// ErrContentHashMismatch is returned by CheckContentHash when an entry's content does not match its recorded hash
+	ErrContentHashMismatch = errors.New("content does not match its hash")
+	// ErrUnsupportedVersion is returned by ParseYaml for an entry version this build does not know,
+	// usually one written by a newer version of journal
+	ErrUnsupportedVersion = errors.New("unsupported entry version")
+	// ErrUnknownFields is returned by ParseYaml for an entry with fields its version does not define
+	// Saving such an entry again would drop them, so it is rejected rather than read partially
+	ErrUnknownFields = errors.New("entry has unknown fields")
 )
 
 // Entry is the interface that all entry versions must implement
@@ -18,6 +38,12 @@ type Entry interface {
 	GetDate() time.Time
 	GetTags() []string
 	GetFilePath() string
+	GetTitle() string
+	GetMood() int
+	GetContentHash() string
+	GetLinks() []string
+	GetModified() time.Time
+	GetAttachments() []string
 	GetContent() string
 	GetVersion() int
 	ToYaml() ([]byte, error)
@@ -30,6 +56,13 @@ type MetadataV1 struct {
 	Date     time.Time `json:"date" yaml:"date"`
 	Tags     []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	FilePath string    `json:"filepath" yaml:"filepath"`
+	Title    string    `json:"title,omitempty" yaml:"title,omitempty"`
+	// Mood rates the entry from MinMood to MaxMood; zero means unrated
+	Mood int `json:"mood,omitempty" yaml:"mood,omitempty"`
+	// ContentHash is the HashContent of the content when it was last saved; empty for entries saved before hashes existed
+	ContentHash string `json:"content_hash,omitempty" yaml:"content_hash,omitempty"`
+	// Links are the IDs of other entries this entry refers to, e.g. the entry it follows up on
+	Links []string `json:"links,omitempty" yaml:"links,omitempty"`
 }
 
 // GetID returns the metadata ID
@@ -52,6 +85,26 @@ func (m *MetadataV1) GetFilePath() string {
 	return m.FilePath
 }
 
+// GetTitle returns the metadata title
+func (m *MetadataV1) GetTitle() string {
+	return m.Title
+}
+
+// GetMood returns the metadata mood, or zero if unrated
+func (m *MetadataV1) GetMood() int {
+	return m.Mood
+}
+
+// GetContentHash returns the metadata content hash, or an empty string if none was recorded
+func (m *MetadataV1) GetContentHash() string {
+	return m.ContentHash
+}
+
+// GetLinks returns the IDs of the entries the metadata links to
+func (m *MetadataV1) GetLinks() []string {
+	return m.Links
+}
+
 // EntryV1 represents a journal entry (version 1)
 type EntryV1 struct {
 	MetadataV1 `json:",inline" yaml:",inline"`
@@ -92,6 +145,40 @@ func (e *EntryV1) GetFilePath() string {
 	return e.FilePath
 }
 
+// GetTitle returns the entry title
+// Entries saved before titles existed fall back to the first line of their content
+func (e *EntryV1) GetTitle() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return DefaultTitle(e.Content)
+}
+
+// GetMood returns the entry mood, or zero if unrated
+func (e *EntryV1) GetMood() int {
+	return e.Mood
+}
+
+// GetContentHash returns the recorded hash of the entry content, or an empty string if none was recorded
+func (e *EntryV1) GetContentHash() string {
+	return e.ContentHash
+}
+
+// GetLinks returns the IDs of the entries the entry links to
+func (e *EntryV1) GetLinks() []string {
+	return e.Links
+}
+
+// GetModified returns the zero time, since V1 entries do not record modifications
+func (e *EntryV1) GetModified() time.Time {
+	return time.Time{}
+}
+
+// GetAttachments returns nil, since V1 entries have no attachments
+func (e *EntryV1) GetAttachments() []string {
+	return nil
+}
+
 // GetContent returns the entry content
 func (e *EntryV1) GetContent() string {
 	return e.Content
@@ -108,37 +195,229 @@ func (e *EntryV1) ToYaml() ([]byte, error) {
 	return yaml.Marshal(e)
 }
 
+// EntryV2 represents a journal entry (version 2), which adds the time of the last modification
+type EntryV2 struct {
+	MetadataV1 `json:",inline" yaml:",inline"`
+	Modified   time.Time `json:"modified,omitzero" yaml:"modified,omitempty"`
+	// Attachments are the names of the entry's files stored under attachments/<id>/
+	Attachments []string `json:"attachments,omitempty" yaml:"attachments,omitempty"`
+	Content     string   `json:"content" yaml:"content"`
+}
+
+// NewEntryV2 creates a new V2 entry with version set
+func NewEntryV2(id string, date time.Time, content string, tags []string, filepath string) *EntryV2 {
+	return &EntryV2{
+		MetadataV1: MetadataV1{
+			Version:  2,
+			Id:       id,
+			Date:     date,
+			Tags:     tags,
+			FilePath: filepath,
+		},
+		Content: content,
+	}
+}
+
+// GetID returns the entry ID
+func (e *EntryV2) GetID() string {
+	return e.Id
+}
+
+// GetDate returns the entry date
+func (e *EntryV2) GetDate() time.Time {
+	return e.Date
+}
+
+// GetTags returns the entry tags
+func (e *EntryV2) GetTags() []string {
+	return e.Tags
+}
+
+// GetFilePath returns the file path
+func (e *EntryV2) GetFilePath() string {
+	return e.FilePath
+}
+
+// GetTitle returns the entry title, falling back to the first line of the content
+func (e *EntryV2) GetTitle() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return DefaultTitle(e.Content)
+}
+
+// GetMood returns the entry mood, or zero if unrated
+func (e *EntryV2) GetMood() int {
+	return e.Mood
+}
+
+// GetContentHash returns the recorded hash of the entry content, or an empty string if none was recorded
+func (e *EntryV2) GetContentHash() string {
+	return e.ContentHash
+}
+
+// GetLinks returns the IDs of the entries the entry links to
+func (e *EntryV2) GetLinks() []string {
+	return e.Links
+}
+
+// GetModified returns when the entry was last modified, or the zero time if never
+func (e *EntryV2) GetModified() time.Time {
+	return e.Modified
+}
+
+// GetAttachments returns the names of the entry's attachments
+func (e *EntryV2) GetAttachments() []string {
+	return e.Attachments
+}
+
+// GetContent returns the entry content
+func (e *EntryV2) GetContent() string {
+	return e.Content
+}
+
+// GetVersion returns the version number
+func (e *EntryV2) GetVersion() int {
+	return e.Version
+}
+
+// ToYaml converts an EntryV2 to YAML format
+func (e *EntryV2) ToYaml() ([]byte, error) {
+	e.Version = 2
+	return yaml.Marshal(e)
+}
+
+// Upgrade converts an entry of any version to the current version
+// The result is a copy, so changing it does not affect entry
+func Upgrade(entry Entry) (*EntryV2, error) {
+	// Note: When adding new entry versions, add a case here for each older version
+	switch e := entry.(type) {
+	case *EntryV2:
+		upgraded := *e
+		upgraded.Tags = append([]string(nil), e.Tags...)
+		upgraded.Links = append([]string(nil), e.Links...)
+		upgraded.Attachments = append([]string(nil), e.Attachments...)
+		return &upgraded, nil
+	case *EntryV1:
+		upgraded := &EntryV2{
+			MetadataV1: e.MetadataV1,
+			Content:    e.Content,
+		}
+		upgraded.Version = 2
+		upgraded.Tags = append([]string(nil), e.Tags...)
+		upgraded.Links = append([]string(nil), e.Links...)
+		return upgraded, nil
+	default:
+		return nil, fmt.Errorf("unsupported entry version: %d", entry.GetVersion())
+	}
+}
+
+// DefaultTitle returns the first non-blank line of content, used when an entry has no title
+func DefaultTitle(content string) string {
+	for line := range strings.Lines(content) {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CountWords returns the number of whitespace-separated words in content
+func CountWords(content string) int {
+	return len(strings.Fields(content))
+}
+
+// ValidateMood checks that mood is zero (unrated) or within MinMood and MaxMood
+func ValidateMood(mood int) error {
+	if mood != 0 && (mood < MinMood || mood > MaxMood) {
+		return fmt.Errorf("mood must be between %d and %d, got %d", MinMood, MaxMood, mood)
+	}
+	return nil
+}
+
+// HashContent returns the hex-encoded SHA-256 of content, as recorded in an entry's ContentHash
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckContentHash returns an error wrapping ErrContentHashMismatch if entry records a content hash
+// that does not match its content, e.g. because the content was corrupted before it was encrypted
+// Entries without a recorded hash always pass
+func CheckContentHash(entry Entry) error {
+	hash := entry.GetContentHash()
+	if hash == "" {
+		return nil
+	}
+	if actual := HashContent(entry.GetContent()); actual != hash {
+		return fmt.Errorf("%w: recorded %s, got %s", ErrContentHashMismatch, hash, actual)
+	}
+	return nil
+}
+
 // versionDetector is used to peek at the version field
 type versionDetector struct {
 	Version int `yaml:"version"`
 }
 
 // ParseYaml parses YAML content into an Entry interface
+// Entries with a version newer than CurrentVersion or with fields their version does not define
+// return errors wrapping ErrUnsupportedVersion and ErrUnknownFields, so an older build never
+// rewrites, and thereby truncates, an entry written by a newer one
 func ParseYaml(content []byte) (Entry, error) {
 	var detector versionDetector
 	if err := yaml.Unmarshal(content, &detector); err != nil {
 		return nil, fmt.Errorf("failed to detect version: %w", err)
 	}
 
+	var entry Entry
 	switch detector.Version {
 	case 1:
-		var entry EntryV1
-		if err := yaml.Unmarshal(content, &entry); err != nil {
+		var v1 EntryV1
+		if err := yaml.Unmarshal(content, &v1); err != nil {
 			return nil, fmt.Errorf("failed to parse YAML as V1: %w", err)
 		}
-		if entry.Version != 1 {
-			return nil, fmt.Errorf("failed to parse YAML as V1: invalid version: %d", entry.Version)
-		}
-		if entry.Id == "" {
-			return nil, fmt.Errorf("entry ID is required")
+		if v1.Version != 1 {
+			return nil, fmt.Errorf("failed to parse YAML as V1: invalid version: %d", v1.Version)
 		}
-		if entry.Date.IsZero() {
-			return nil, fmt.Errorf("entry date is required")
+		if err := checkKnownFields(content, &EntryV1{}); err != nil {
+			return nil, err
 		}
+		entry = &v1
 
-		return &entry, nil
+	case 2:
+		var v2 EntryV2
+		if err := yaml.Unmarshal(content, &v2); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML as V2: %w", err)
+		}
+		if err := checkKnownFields(content, &EntryV2{}); err != nil {
+			return nil, err
+		}
+		entry = &v2
 
 	default:
-		return nil, fmt.Errorf("unsupported entry version: %d", detector.Version)
+		if detector.Version > CurrentVersion {
+			return nil, fmt.Errorf("%w: %d is newer than the latest supported version %d; upgrade journal to read this entry", ErrUnsupportedVersion, detector.Version, CurrentVersion)
+		}
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, detector.Version)
+	}
+
+	if entry.GetID() == "" {
+		return nil, fmt.Errorf("entry ID is required")
+	}
+	if entry.GetDate().IsZero() {
+		return nil, fmt.Errorf("entry date is required")
+	}
+	return entry, nil
+}
+
+// checkKnownFields decodes content into target again, this time rejecting fields target does not define
+// content must already have decoded into target's type, so any error here is an unknown field
+func checkKnownFields(content []byte, target any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("%w (written by a newer version of journal?): %w", ErrUnknownFields, err)
 	}
+	return nil
 }
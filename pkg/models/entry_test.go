@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -103,3 +104,204 @@ func TestEntryToMetadata(t *testing.T) {
 		t.Error("Entry version should be 1")
 	}
 }
+
+func TestParseYaml_UnknownFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr error
+	}{
+		{"unknown field in V2", "version: 2\nid: test-id\ndate: 2024-11-19T14:30:00Z\nlocation: Berlin\ncontent: Entry", ErrUnknownFields},
+		{"V2 field in V1", "version: 1\nid: test-id\ndate: 2024-11-19T14:30:00Z\nattachments: [photo.jpg]\ncontent: Entry", ErrUnknownFields},
+		{"newer version", "version: 3\nid: test-id\ndate: 2024-11-19T14:30:00Z\nlocation: Berlin\ncontent: Entry", ErrUnsupportedVersion},
+		{"missing version", "id: test-id\ndate: 2024-11-19T14:30:00Z\ncontent: Entry", ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseYaml([]byte(tt.yaml)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := ParseYaml([]byte("version: 3\nid: test-id\ndate: 2024-11-19T14:30:00Z")); err == nil || !strings.Contains(err.Error(), "upgrade journal") {
+		t.Errorf("expected a hint to upgrade for a newer version, got %v", err)
+	}
+}
+
+func TestParseYaml_RoundTripHasNoUnknownFields(t *testing.T) {
+	entry := NewEntryV2("test-id", time.Date(2024, 11, 19, 14, 30, 0, 0, time.UTC), "Content", []string{"work"}, "2024/11/test-id.yaml")
+	entry.Title = "Title"
+	entry.Mood = 3
+	entry.Links = []string{"other-id"}
+	entry.Attachments = []string{"photo.jpg"}
+	entry.Modified = entry.Date
+	entry.ContentHash = HashContent(entry.Content)
+
+	data, err := entry.ToYaml()
+	if err != nil {
+		t.Fatalf("ToYaml failed: %v", err)
+	}
+	if _, err := ParseYaml(data); err != nil {
+		t.Errorf("expected an entry saved by this version to parse, got %v", err)
+	}
+}
+
+func TestParseYaml_WithoutTitle(t *testing.T) {
+	yamlData := `version: 1
+id: test-id-123
+date: 2024-11-19T14:30:00Z
+content: |
+  First line
+  Second line`
+
+	entry, err := ParseYaml([]byte(yamlData))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+
+	if entry.GetTitle() != "First line" {
+		t.Errorf("Expected title to fall back to first line, got '%s'", entry.GetTitle())
+	}
+}
+
+func TestDefaultTitle(t *testing.T) {
+	tests := map[string]string{
+		"":                      "",
+		"Single line":           "Single line",
+		"  \n\n  Title  \nBody": "Title",
+		"\n\n":                  "",
+	}
+
+	for content, want := range tests {
+		if got := DefaultTitle(content); got != want {
+			t.Errorf("DefaultTitle(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestParseYaml_Mood(t *testing.T) {
+	entry, err := ParseYaml([]byte("version: 2\nid: test-id-123\ndate: 2024-11-19T14:30:00Z\nmood: 4\ncontent: Good day"))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	if entry.GetMood() != 4 {
+		t.Errorf("Expected mood 4, got %d", entry.GetMood())
+	}
+
+	older, err := ParseYaml([]byte("version: 1\nid: test-id-456\ndate: 2024-11-19T14:30:00Z\ncontent: Old entry"))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	if older.GetMood() != 0 {
+		t.Errorf("Expected entries without a mood to be unrated, got %d", older.GetMood())
+	}
+}
+
+func TestValidateMood(t *testing.T) {
+	for _, mood := range []int{0, MinMood, 3, MaxMood} {
+		if err := ValidateMood(mood); err != nil {
+			t.Errorf("ValidateMood(%d) returned error: %v", mood, err)
+		}
+	}
+	for _, mood := range []int{-1, MaxMood + 1} {
+		if err := ValidateMood(mood); err == nil {
+			t.Errorf("ValidateMood(%d) expected error", mood)
+		}
+	}
+}
+
+func TestCheckContentHash(t *testing.T) {
+	entry := NewEntryV2("test-id-123", time.Now(), "Original content", nil, "")
+	entry.ContentHash = HashContent(entry.Content)
+
+	if err := CheckContentHash(entry); err != nil {
+		t.Fatalf("CheckContentHash returned error for matching content: %v", err)
+	}
+
+	data, err := entry.ToYaml()
+	if err != nil {
+		t.Fatalf("Failed to convert to YAML: %v", err)
+	}
+	parsed, err := ParseYaml(data)
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	if parsed.GetContentHash() != entry.ContentHash {
+		t.Errorf("Expected content hash %s after round trip, got %s", entry.ContentHash, parsed.GetContentHash())
+	}
+
+	entry.Content = "Tampered content"
+	if err := CheckContentHash(entry); !errors.Is(err, ErrContentHashMismatch) {
+		t.Errorf("Expected ErrContentHashMismatch, got %v", err)
+	}
+
+	older := NewEntryV1("test-id-456", time.Now(), "Old entry", nil, "")
+	if err := CheckContentHash(older); err != nil {
+		t.Errorf("Expected entries without a hash to pass, got %v", err)
+	}
+}
+
+func TestParseYaml_V2(t *testing.T) {
+	yamlData := `version: 2
+id: test-id-123
+date: 2024-11-19T14:30:00Z
+modified: 2024-11-20T09:00:00Z
+content: Edited entry`
+
+	entry, err := ParseYaml([]byte(yamlData))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+
+	if entry.GetVersion() != 2 {
+		t.Errorf("Expected version 2, got %d", entry.GetVersion())
+	}
+
+	want := time.Date(2024, 11, 20, 9, 0, 0, 0, time.UTC)
+	if !entry.GetModified().Equal(want) {
+		t.Errorf("Expected modified %v, got %v", want, entry.GetModified())
+	}
+}
+
+func TestEntryV2ToYaml_RoundTrip(t *testing.T) {
+	entry := NewEntryV2("test-id-123", time.Date(2024, 11, 19, 14, 30, 0, 0, time.UTC), "Content", []string{"work"}, "")
+
+	yamlData, err := entry.ToYaml()
+	if err != nil {
+		t.Fatalf("Failed to convert to YAML: %v", err)
+	}
+	if strings.Contains(string(yamlData), "modified") {
+		t.Error("YAML should omit modified for an unmodified entry")
+	}
+
+	parsed, err := ParseYaml(yamlData)
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	if _, ok := parsed.(*EntryV2); !ok {
+		t.Errorf("Expected *EntryV2, got %T", parsed)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	v1 := NewEntryV1("test-id-123", time.Date(2024, 11, 19, 14, 30, 0, 0, time.UTC), "Content", []string{"work"}, "2024/11/test-id-123.yaml")
+
+	v2, err := Upgrade(v1)
+	if err != nil {
+		t.Fatalf("Upgrade failed: %v", err)
+	}
+
+	if v2.GetVersion() != 2 {
+		t.Errorf("Expected version 2, got %d", v2.GetVersion())
+	}
+	if v2.GetID() != v1.GetID() || v2.GetContent() != v1.GetContent() || v2.GetFilePath() != v1.GetFilePath() {
+		t.Errorf("Expected fields to be copied, got %+v", v2)
+	}
+
+	v2.Tags[0] = "changed"
+	if v1.Tags[0] != "work" {
+		t.Error("Expected Upgrade to copy tags")
+	}
+}
@@ -2,15 +2,48 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 )
 
+var (
+	// ErrPrefixNotFound is returned by ResolvePrefix when no entry ID starts with the prefix
+	ErrPrefixNotFound = errors.New("no entry matches ID prefix")
+	// ErrAmbiguousPrefix is returned by ResolvePrefix when several entry IDs start with the prefix
+	ErrAmbiguousPrefix = errors.New("ambiguous ID prefix")
+)
+
 // IndexableMetadata represents metadata fields needed for indexing
 type IndexableMetadata interface {
 	GetID() string
 	GetDate() time.Time
 	GetTags() []string
 	GetFilePath() string
+	GetTitle() string
+	GetMood() int
+	GetLinks() []string
+}
+
+// contentHolder is implemented by full entries, whose content gives Index.Add a word count
+type contentHolder interface {
+	GetContent() string
+}
+
+// DateKeyFormat is the layout of the day keys in Index.ByDate
+const DateKeyFormat = "2006-01-02"
+
+// DateKey returns the day key for t, the calendar day in t's own location
+// This is the journal's timezone policy: an entry belongs to the day (and the month directory)
+// of the wall clock it was written with, and its date keeps that UTC offset in the entry file
+// and the index, so the day does not shift when the journal is read in another timezone
+// Index keys, entry paths, and date searches all use DateKey, so they always agree
+func DateKey(t time.Time) string {
+	return t.Format(DateKeyFormat)
 }
 
 // Metadata is the version-agnostic metadata stored in the index
@@ -19,6 +52,12 @@ type Metadata struct {
 	Date     time.Time `json:"date" yaml:"date"`
 	Tags     []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	FilePath string    `json:"filepath" yaml:"filepath"`
+	Title    string    `json:"title,omitempty" yaml:"title,omitempty"`
+	Mood     int       `json:"mood,omitempty" yaml:"mood,omitempty"`
+	// Words is the CountWords of the entry content; zero for entries indexed before word counts existed
+	Words int `json:"words,omitempty" yaml:"words,omitempty"`
+	// Links are the IDs of the entries this entry links to, kept here so backlinks are found without decrypting
+	Links []string `json:"links,omitempty" yaml:"links,omitempty"`
 }
 
 // Index contains all entry metadata for fast searching
@@ -40,17 +79,24 @@ func NewIndex() *Index {
 }
 
 // Add adds an entry to the index (accepts any IndexableMetadata)
+// Full entries also have their words counted; bare metadata is indexed with a word count of zero
 func (idx *Index) Add(meta IndexableMetadata) {
 	commonMeta := Metadata{
 		Id:       meta.GetID(),
 		Date:     meta.GetDate(),
 		Tags:     meta.GetTags(),
 		FilePath: meta.GetFilePath(),
+		Title:    meta.GetTitle(),
+		Mood:     meta.GetMood(),
+		Links:    meta.GetLinks(),
+	}
+	if entry, ok := meta.(contentHolder); ok {
+		commonMeta.Words = CountWords(entry.GetContent())
 	}
 
 	idx.Entries[commonMeta.Id] = commonMeta
 
-	dateKey := commonMeta.Date.Format("2006-01-02")
+	dateKey := DateKey(commonMeta.Date)
 	idx.ByDate[dateKey] = appendUnique(idx.ByDate[dateKey], commonMeta.Id)
 
 	for _, tag := range commonMeta.Tags {
@@ -67,7 +113,7 @@ func (idx *Index) Remove(id string) {
 
 	delete(idx.Entries, id)
 
-	dateKey := meta.Date.Format("2006-01-02")
+	dateKey := DateKey(meta.Date)
 	idx.ByDate[dateKey] = removeString(idx.ByDate[dateKey], id)
 	if len(idx.ByDate[dateKey]) == 0 {
 		delete(idx.ByDate, dateKey)
@@ -83,7 +129,7 @@ func (idx *Index) Remove(id string) {
 
 // FindByDate returns entry IDs for a specific date
 func (idx *Index) FindByDate(date time.Time) []string {
-	dateKey := date.Format("2006-01-02")
+	dateKey := DateKey(date)
 	return idx.ByDate[dateKey]
 }
 
@@ -94,7 +140,7 @@ func (idx *Index) FindByDateRange(start, end time.Time) []string {
 
 	// Iterate through each day in range
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-		dateKey := d.Format("2006-01-02")
+		dateKey := DateKey(d)
 		for _, id := range idx.ByDate[dateKey] {
 			if !seen[id] {
 				results = append(results, id)
@@ -106,12 +152,40 @@ func (idx *Index) FindByDateRange(start, end time.Time) []string {
 	return results
 }
 
-// FindByTag returns entry IDs with a specific tag
+// FindByTag returns entry IDs with exactly the given tag; see FindByTagCaseInsensitive
 func (idx *Index) FindByTag(tag string) []string {
 	return idx.ByTag[tag]
 }
 
-// FindByTags returns entry IDs that have ALL specified tags (AND operation)
+// FindByTagCaseInsensitive returns IDs of entries with a tag equal to tag ignoring case, so "Work"
+// also finds entries tagged "work"; an entry matching several spellings appears once
+// Tags are stored as written, so the buckets of every spelling are merged in tag order
+func (idx *Index) FindByTagCaseInsensitive(tag string) []string {
+	var spellings []string
+	for indexed := range idx.ByTag {
+		if strings.EqualFold(indexed, tag) {
+			spellings = append(spellings, indexed)
+		}
+	}
+	if len(spellings) == 1 {
+		return idx.ByTag[spellings[0]]
+	}
+	slices.Sort(spellings)
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, spelling := range spellings {
+		for _, id := range idx.ByTag[spelling] {
+			if !seen[id] {
+				ids = append(ids, id)
+				seen[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+// FindByTags returns entry IDs that have ALL specified tags (AND operation), ignoring case
 func (idx *Index) FindByTags(tags []string) []string {
 	if len(tags) == 0 {
 		return nil
@@ -119,14 +193,14 @@ func (idx *Index) FindByTags(tags []string) []string {
 
 	// Start with entries that have the first tag
 	results := make(map[string]bool)
-	for _, id := range idx.ByTag[tags[0]] {
+	for _, id := range idx.FindByTagCaseInsensitive(tags[0]) {
 		results[id] = true
 	}
 
 	// Filter by remaining tags
 	for _, tag := range tags[1:] {
 		tagIDs := make(map[string]bool)
-		for _, id := range idx.ByTag[tag] {
+		for _, id := range idx.FindByTagCaseInsensitive(tag) {
 			tagIDs[id] = true
 		}
 
@@ -144,12 +218,256 @@ func (idx *Index) FindByTags(tags []string) []string {
 	return ids
 }
 
+// FindByAnyTag returns entry IDs that have ANY of the specified tags (OR operation), ignoring case
+// IDs are returned in the order they first appear across the given tags
+func (idx *Index) FindByAnyTag(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for _, id := range idx.FindByTagCaseInsensitive(tag) {
+			if !seen[id] {
+				ids = append(ids, id)
+				seen[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+// FindByTagPrefix returns IDs of entries with any tag starting with prefix, ignoring case, for
+// hierarchical tags like "project/foo" and "project/bar"; an entry matching several tags appears once
+// IDs are sorted by date (oldest first), then by ID, so the order is deterministic
+func (idx *Index) FindByTagPrefix(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+
+	prefix = strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	var ids []string
+	for tag, tagIDs := range idx.ByTag {
+		if !strings.HasPrefix(strings.ToLower(tag), prefix) {
+			continue
+		}
+		for _, id := range tagIDs {
+			if !seen[id] {
+				ids = append(ids, id)
+				seen[id] = true
+			}
+		}
+	}
+
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := idx.Entries[a].Date.Compare(idx.Entries[b].Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return ids
+}
+
+// FindByMinMood returns IDs of entries rated at least minMood, sorted by date (oldest first)
+// Unrated entries never match
+func (idx *Index) FindByMinMood(minMood int) []string {
+	var metas []Metadata
+	for _, meta := range idx.Entries {
+		if meta.Mood != 0 && meta.Mood >= minMood {
+			metas = append(metas, meta)
+		}
+	}
+	slices.SortFunc(metas, func(a, b Metadata) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	var ids []string
+	for _, meta := range metas {
+		ids = append(ids, meta.Id)
+	}
+	return ids
+}
+
+// FindByMonthDay returns IDs of entries on the month and day of date in any year, oldest first
+// Days are compared by DateKey, so each entry's day is the one in its own timezone
+func (idx *Index) FindByMonthDay(date time.Time) []string {
+	monthDay := DateKey(date)[len("2006-"):]
+
+	var metas []Metadata
+	for _, meta := range idx.Entries {
+		if DateKey(meta.Date)[len("2006-"):] == monthDay {
+			metas = append(metas, meta)
+		}
+	}
+	slices.SortFunc(metas, func(a, b Metadata) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	var ids []string
+	for _, meta := range metas {
+		ids = append(ids, meta.Id)
+	}
+	return ids
+}
+
+// FindLinkingTo returns IDs of entries that link to the entry with the given ID, sorted by date (oldest first)
+func (idx *Index) FindLinkingTo(id string) []string {
+	var metas []Metadata
+	for _, meta := range idx.Entries {
+		if slices.Contains(meta.Links, id) {
+			metas = append(metas, meta)
+		}
+	}
+	slices.SortFunc(metas, func(a, b Metadata) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	var ids []string
+	for _, meta := range metas {
+		ids = append(ids, meta.Id)
+	}
+	return ids
+}
+
 // GetMetadata returns metadata for a specific entry ID
 func (idx *Index) GetMetadata(id string) (Metadata, bool) {
 	meta, exists := idx.Entries[id]
 	return meta, exists
 }
 
+// FirstEntry returns the metadata of the oldest entry, or false if the index is empty
+func (idx *Index) FirstEntry() (Metadata, bool) {
+	if len(idx.Entries) == 0 {
+		return Metadata{}, false
+	}
+	return slices.MinFunc(slices.Collect(maps.Values(idx.Entries)), compareMetadataByDate), true
+}
+
+// LastEntry returns the metadata of the newest entry, or false if the index is empty
+func (idx *Index) LastEntry() (Metadata, bool) {
+	if len(idx.Entries) == 0 {
+		return Metadata{}, false
+	}
+	return slices.MaxFunc(slices.Collect(maps.Values(idx.Entries)), compareMetadataByDate), true
+}
+
+// compareMetadataByDate orders metadata by date, then by ID so entries at the same time have a stable order
+func compareMetadataByDate(a, b Metadata) int {
+	if c := a.Date.Compare(b.Date); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Id, b.Id)
+}
+
+// ResolvePrefix expands an ID prefix to the full ID of the only entry it matches
+// An exact ID always resolves to itself; when several IDs match, the error lists all of them
+func (idx *Index) ResolvePrefix(prefix string) (string, error) {
+	if _, exists := idx.Entries[prefix]; exists {
+		return prefix, nil
+	}
+	if prefix == "" {
+		return "", fmt.Errorf("%w: empty prefix", ErrPrefixNotFound)
+	}
+
+	var matches []string
+	for id := range idx.Entries {
+		if strings.HasPrefix(id, prefix) {
+			matches = append(matches, id)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrPrefixNotFound, prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		slices.Sort(matches)
+		return "", fmt.Errorf("%w %s matches %s", ErrAmbiguousPrefix, prefix, strings.Join(matches, ", "))
+	}
+}
+
+// Validate checks that ByDate and ByTag are consistent with Entries
+// Returns one error per inconsistency, or nil if the index is consistent
+func (idx *Index) Validate() []error {
+	var errs []error
+
+	for _, dateKey := range slices.Sorted(maps.Keys(idx.ByDate)) {
+		for _, id := range idx.ByDate[dateKey] {
+			meta, exists := idx.Entries[id]
+			if !exists {
+				errs = append(errs, fmt.Errorf("date %s references missing entry %s", dateKey, id))
+				continue
+			}
+			if DateKey(meta.Date) != dateKey {
+				errs = append(errs, fmt.Errorf("entry %s is listed under date %s but dated %s", id, dateKey, DateKey(meta.Date)))
+			}
+		}
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(idx.ByTag)) {
+		for _, id := range idx.ByTag[tag] {
+			meta, exists := idx.Entries[id]
+			if !exists {
+				errs = append(errs, fmt.Errorf("tag %q references missing entry %s", tag, id))
+				continue
+			}
+			if !slices.Contains(meta.Tags, tag) {
+				errs = append(errs, fmt.Errorf("entry %s is listed under tag %q but does not carry it", id, tag))
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(idx.Entries)) {
+		meta := idx.Entries[id]
+		if meta.Id != id {
+			errs = append(errs, fmt.Errorf("entry %s is stored under key %s", meta.Id, id))
+		}
+		if !filepath.IsLocal(meta.FilePath) {
+			errs = append(errs, fmt.Errorf("entry %s has file path %q outside the entries directory", id, meta.FilePath))
+		}
+
+		dateKey := DateKey(meta.Date)
+		if !slices.Contains(idx.ByDate[dateKey], id) {
+			errs = append(errs, fmt.Errorf("entry %s is missing from date %s", id, dateKey))
+		}
+
+		for _, tag := range meta.Tags {
+			if !slices.Contains(idx.ByTag[tag], id) {
+				errs = append(errs, fmt.Errorf("entry %s is missing from tag %q", id, tag))
+			}
+		}
+	}
+
+	return errs
+}
+
+// Clone returns a deep copy of the index that shares no maps or slices with the original
+func (idx *Index) Clone() *Index {
+	clone := &Index{
+		Version: idx.Version,
+		Entries: make(map[string]Metadata, len(idx.Entries)),
+		ByDate:  make(map[string][]string, len(idx.ByDate)),
+		ByTag:   make(map[string][]string, len(idx.ByTag)),
+	}
+
+	for id, meta := range idx.Entries {
+		meta.Tags = cloneStrings(meta.Tags)
+		meta.Links = cloneStrings(meta.Links)
+		clone.Entries[id] = meta
+	}
+	for date, ids := range idx.ByDate {
+		clone.ByDate[date] = cloneStrings(ids)
+	}
+	for tag, ids := range idx.ByTag {
+		clone.ByTag[tag] = cloneStrings(ids)
+	}
+
+	return clone
+}
+
 // ToJSON serializes the index to JSON
 func (idx *Index) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(idx, "", "  ")
@@ -172,6 +490,13 @@ func appendUnique(slice []string, item string) []string {
 	return append(slice, item)
 }
 
+func cloneStrings(slice []string) []string {
+	if slice == nil {
+		return nil
+	}
+	return append([]string(nil), slice...)
+}
+
 func removeString(slice []string, item string) []string {
 	result := make([]string, 0, len(slice))
 	for _, s := range slice {
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -57,6 +61,328 @@ func TestIndexAddAndFind(t *testing.T) {
 	}
 }
 
+func TestIndexDateKey_NonUTCMonthBoundary(t *testing.T) {
+	idx := NewIndex()
+
+	// 23:30 on January 31 in New York is already February 1 in UTC
+	newYork := time.FixedZone("EST", -5*60*60)
+	date := time.Date(2024, 1, 31, 23, 30, 0, 0, newYork)
+	idx.Add(&MetadataV1{
+		Version:  1,
+		Id:       "late-entry",
+		Date:     date,
+		FilePath: "2024/01/late-entry.yaml",
+	})
+
+	data, err := idx.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	loaded, err := FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON failed: %v", err)
+	}
+
+	for _, index := range []*Index{idx, loaded} {
+		if ids := index.ByDate["2024-01-31"]; !reflect.DeepEqual(ids, []string{"late-entry"}) {
+			t.Errorf("Expected entry under its local day 2024-01-31, got %v", index.ByDate)
+		}
+		if errs := index.Validate(); len(errs) != 0 {
+			t.Errorf("Expected consistent index, got %v", errs)
+		}
+	}
+
+	if ids := loaded.FindByDate(time.Date(2024, 1, 31, 0, 0, 0, 0, newYork)); len(ids) != 1 {
+		t.Errorf("Expected FindByDate on January 31 to find the entry, got %v", ids)
+	}
+	if ids := loaded.FindByDate(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)); len(ids) != 0 {
+		t.Errorf("Expected no entry on the UTC day, got %v", ids)
+	}
+}
+
+func TestIndexAdd_WordCount(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(NewEntryV2("with-content", time.Now(), "one two  three\nfour", nil, ""))
+	idx.Add(&MetadataV1{Id: "metadata-only", Date: time.Now()})
+
+	if words := idx.Entries["with-content"].Words; words != 4 {
+		t.Errorf("expected 4 words for a full entry, got %d", words)
+	}
+	if words := idx.Entries["metadata-only"].Words; words != 0 {
+		t.Errorf("expected no word count for bare metadata, got %d", words)
+	}
+}
+
+func TestIndexFindByAnyTag(t *testing.T) {
+	idx := NewIndex()
+
+	for i, tags := range [][]string{{"work", "meeting"}, {"personal"}, {"meeting"}} {
+		idx.Add(&MetadataV1{
+			Version:  1,
+			Id:       fmt.Sprintf("entry-%d", i+1),
+			Date:     time.Date(2024, 11, 19+i, 10, 0, 0, 0, time.UTC),
+			Tags:     tags,
+			FilePath: fmt.Sprintf("2024/11/entry-%d.age", i+1),
+		})
+	}
+
+	results := idx.FindByAnyTag([]string{"meeting", "work", "missing"})
+	expected := []string{"entry-1", "entry-3"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	if results := idx.FindByAnyTag(nil); results != nil {
+		t.Errorf("Expected nil for empty tag list, got %v", results)
+	}
+}
+
+func TestIndexFindByTagCaseInsensitive(t *testing.T) {
+	idx := NewIndex()
+
+	for i, tags := range [][]string{{"work"}, {"Work", "meeting"}, {"WORK", "work"}, {"workout"}} {
+		idx.Add(&MetadataV1{
+			Version:  1,
+			Id:       fmt.Sprintf("entry-%d", i+1),
+			Date:     time.Date(2024, 11, 19+i, 10, 0, 0, 0, time.UTC),
+			Tags:     tags,
+			FilePath: fmt.Sprintf("2024/11/entry-%d.age", i+1),
+		})
+	}
+
+	results := idx.FindByTagCaseInsensitive("Work")
+	expected := []string{"entry-3", "entry-2", "entry-1"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	if results := idx.FindByTag("Work"); !reflect.DeepEqual(results, []string{"entry-2"}) {
+		t.Errorf("Expected FindByTag to stay exact, got %v", results)
+	}
+
+	results = idx.FindByTags([]string{"WORK", "Meeting"})
+	if !reflect.DeepEqual(results, []string{"entry-2"}) {
+		t.Errorf("Expected [entry-2] for all tags, got %v", results)
+	}
+
+	if results := idx.FindByTagCaseInsensitive("missing"); len(results) != 0 {
+		t.Errorf("Expected no results for a missing tag, got %v", results)
+	}
+}
+
+func TestIndexFindByTagPrefix(t *testing.T) {
+	idx := NewIndex()
+
+	for i, tags := range [][]string{
+		{"project/foo", "project/bar"},
+		{"project/foo/design"},
+		{"projects"},
+		{"personal"},
+		{"project/bar"},
+	} {
+		idx.Add(&MetadataV1{
+			Version:  1,
+			Id:       fmt.Sprintf("entry-%d", i+1),
+			Date:     time.Date(2024, 11, 19+i, 10, 0, 0, 0, time.UTC),
+			Tags:     tags,
+			FilePath: fmt.Sprintf("2024/11/entry-%d.age", i+1),
+		})
+	}
+
+	results := idx.FindByTagPrefix("project/")
+	expected := []string{"entry-1", "entry-2", "entry-5"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	results = idx.FindByTagPrefix("project")
+	expected = []string{"entry-1", "entry-2", "entry-3", "entry-5"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	results = idx.FindByTagPrefix("project/foo")
+	expected = []string{"entry-1", "entry-2"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	if results := idx.FindByTagPrefix(""); results != nil {
+		t.Errorf("Expected nil for empty prefix, got %v", results)
+	}
+}
+
+func TestIndexFindByMonthDay(t *testing.T) {
+	idx := NewIndex()
+	est := time.FixedZone("EST", -5*60*60)
+
+	for id, date := range map[string]time.Time{
+		"2022":        time.Date(2022, 3, 14, 8, 0, 0, 0, time.UTC),
+		"2024":        time.Date(2024, 3, 14, 20, 0, 0, 0, time.UTC),
+		"other-day":   time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC),
+		"other-month": time.Date(2023, 4, 14, 8, 0, 0, 0, time.UTC),
+		// March 15 in UTC, but still March 14 where it was written
+		"est-evening": time.Date(2023, 3, 14, 22, 0, 0, 0, est),
+	} {
+		idx.Add(&MetadataV1{Id: id, Date: date})
+	}
+
+	results := idx.FindByMonthDay(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC))
+	expected := []string{"2022", "est-evening", "2024"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestIndexFindByMinMood(t *testing.T) {
+	idx := NewIndex()
+
+	for i, mood := range []int{4, 0, 2, 5} {
+		idx.Add(&MetadataV1{
+			Version:  1,
+			Id:       fmt.Sprintf("entry-%d", i+1),
+			Date:     time.Date(2024, 11, 19+i, 10, 0, 0, 0, time.UTC),
+			FilePath: fmt.Sprintf("2024/11/entry-%d.age", i+1),
+			Mood:     mood,
+		})
+	}
+
+	results := idx.FindByMinMood(4)
+	expected := []string{"entry-1", "entry-4"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	if results := idx.FindByMinMood(1); len(results) != 3 {
+		t.Errorf("Expected unrated entries to be excluded, got %v", results)
+	}
+}
+
+func TestIndexFindLinkingTo(t *testing.T) {
+	idx := NewIndex()
+
+	links := [][]string{nil, {"entry-1"}, {"entry-2"}, {"entry-1", "entry-2"}}
+	for i, entryLinks := range links {
+		idx.Add(&MetadataV1{
+			Version:  1,
+			Id:       fmt.Sprintf("entry-%d", i+1),
+			Date:     time.Date(2024, 11, 19+i, 10, 0, 0, 0, time.UTC),
+			FilePath: fmt.Sprintf("2024/11/entry-%d.age", i+1),
+			Links:    entryLinks,
+		})
+	}
+
+	results := idx.FindLinkingTo("entry-1")
+	expected := []string{"entry-2", "entry-4"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+
+	if results := idx.FindLinkingTo("entry-4"); len(results) != 0 {
+		t.Errorf("Expected no backlinks, got %v", results)
+	}
+}
+
+func TestIndexFirstAndLastEntry(t *testing.T) {
+	idx := NewIndex()
+
+	if _, ok := idx.FirstEntry(); ok {
+		t.Error("Expected no first entry in an empty index")
+	}
+	if _, ok := idx.LastEntry(); ok {
+		t.Error("Expected no last entry in an empty index")
+	}
+
+	for id, date := range map[string]time.Time{
+		"middle": time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+		"oldest": time.Date(2023, 1, 5, 9, 0, 0, 0, time.UTC),
+		"newest": time.Date(2024, 11, 19, 9, 0, 0, 0, time.UTC),
+	} {
+		idx.Add(&MetadataV1{Version: 1, Id: id, Date: date, FilePath: id + ".yaml"})
+	}
+
+	if first, ok := idx.FirstEntry(); !ok || first.Id != "oldest" {
+		t.Errorf("Expected first entry 'oldest', got %q (ok=%v)", first.Id, ok)
+	}
+	if last, ok := idx.LastEntry(); !ok || last.Id != "newest" {
+		t.Errorf("Expected last entry 'newest', got %q (ok=%v)", last.Id, ok)
+	}
+}
+
+func TestIndexResolvePrefix(t *testing.T) {
+	idx := NewIndex()
+	for _, id := range []string{"abcd1234-0001", "abcd1234-0002", "ef012345-0003"} {
+		idx.Add(&MetadataV1{Version: 1, Id: id, Date: time.Now(), FilePath: id + ".yaml"})
+	}
+
+	if id, err := idx.ResolvePrefix("ef01"); err != nil || id != "ef012345-0003" {
+		t.Errorf("ResolvePrefix(ef01) = %q, %v; want ef012345-0003", id, err)
+	}
+	if id, err := idx.ResolvePrefix("abcd1234-0002"); err != nil || id != "abcd1234-0002" {
+		t.Errorf("ResolvePrefix(full ID) = %q, %v; want abcd1234-0002", id, err)
+	}
+
+	_, err := idx.ResolvePrefix("abcd1234")
+	if !errors.Is(err, ErrAmbiguousPrefix) {
+		t.Fatalf("expected ErrAmbiguousPrefix, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "abcd1234-0001") || !strings.Contains(err.Error(), "abcd1234-0002") {
+		t.Errorf("expected error to list both matches, got %v", err)
+	}
+
+	for _, prefix := range []string{"9999", ""} {
+		if _, err := idx.ResolvePrefix(prefix); !errors.Is(err, ErrPrefixNotFound) {
+			t.Errorf("ResolvePrefix(%q): expected ErrPrefixNotFound, got %v", prefix, err)
+		}
+	}
+}
+
+func TestIndexValidate(t *testing.T) {
+	idx := NewIndex()
+
+	idx.Add(&MetadataV1{
+		Version:  1,
+		Id:       "entry-1",
+		Date:     time.Date(2024, 11, 19, 14, 0, 0, 0, time.UTC),
+		Tags:     []string{"work"},
+		FilePath: "2024/11/entry-1.age",
+	})
+
+	if errs := idx.Validate(); len(errs) != 0 {
+		t.Fatalf("Expected consistent index, got %v", errs)
+	}
+
+	// Dangling ID in a tag bucket
+	idx.ByTag["personal"] = []string{"entry-2"}
+	// Entry missing from its date bucket
+	delete(idx.ByDate, "2024-11-19")
+	// Entry listed under the wrong date
+	idx.ByDate["2024-11-20"] = []string{"entry-1"}
+
+	errs := idx.Validate()
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 inconsistencies, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestIndexValidate_FilePathOutsideEntries(t *testing.T) {
+	idx := NewIndex()
+
+	for _, filePath := range []string{"/home/user/journal/entries/2024/11/entry-1.yaml", "../2024/11/entry-1.yaml", ""} {
+		idx.Add(&MetadataV1{
+			Version:  1,
+			Id:       "entry-1",
+			Date:     time.Date(2024, 11, 19, 14, 0, 0, 0, time.UTC),
+			FilePath: filePath,
+		})
+
+		if errs := idx.Validate(); len(errs) != 1 {
+			t.Errorf("Expected 1 inconsistency for file path %q, got %v", filePath, errs)
+		}
+		idx.Remove("entry-1")
+	}
+}
+
 func TestIndexRemove(t *testing.T) {
 	idx := NewIndex()
 